@@ -548,9 +548,10 @@ func NewFs(ctx context.Context, name, rootPath string, m configmap.Mapper) (fs.F
 	f.features.DirCacheFlush = f.DirCacheFlush
 
 	rc.Add(rc.Call{
-		Path:  "cache/expire",
-		Fn:    f.httpExpireRemote,
-		Title: "Purge a remote from cache",
+		Path:   "cache/expire",
+		Global: true,
+		Fn:     f.httpExpireRemote,
+		Title:  "Purge a remote from cache",
 		Help: `
 Purge a remote from the cache backend. Supports either a directory or a file.
 Params:
@@ -565,18 +566,20 @@ Eg
 	})
 
 	rc.Add(rc.Call{
-		Path:  "cache/stats",
-		Fn:    f.httpStats,
-		Title: "Get cache stats",
+		Path:   "cache/stats",
+		Global: true,
+		Fn:     f.httpStats,
+		Title:  "Get cache stats",
 		Help: `
 Show statistics for the cache remote.
 `,
 	})
 
 	rc.Add(rc.Call{
-		Path:  "cache/fetch",
-		Fn:    f.rcFetch,
-		Title: "Fetch file chunks",
+		Path:   "cache/fetch",
+		Global: true,
+		Fn:     f.rcFetch,
+		Title:  "Fetch file chunks",
 		Help: `
 Ensure the specified file chunks are cached on disk.
 
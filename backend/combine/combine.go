@@ -1,10 +1,6 @@
 // Package combine implements a backend to combine multiple remotes in a directory tree
 package combine
 
-/*
-   Have API to add/remove branches in the combine
-*/
-
 import (
 	"context"
 	"errors"
@@ -31,6 +27,7 @@ func init() {
 		Name:        "combine",
 		Description: "Combine several remotes into one",
 		NewFs:       NewFs,
+		CommandHelp: commandHelp,
 		MetadataInfo: &fs.MetadataInfo{
 			Help: `Any metadata supported by the underlying remote is read and written.`,
 		},
@@ -70,7 +67,8 @@ type Fs struct {
 	root      string               // the path we are working on
 	hashSet   hash.Set             // common hashes
 	when      time.Time            // directory times
-	upstreams map[string]*upstream // map of upstreams
+	mu        sync.RWMutex         // protects upstreams
+	upstreams map[string]*upstream // map of upstreams - replaced not modified after NewFs
 }
 
 // adjustment stores the info to add a prefix to a path or chop characters off
@@ -130,6 +128,25 @@ type upstream struct {
 	pathAdjustment adjustment // how to fiddle with the path
 }
 
+// parseUpstream parses an upstream definition of the form dir=remote:path
+func parseUpstream(upstream string) (dir, remote string, err error) {
+	equal := strings.IndexRune(upstream, '=')
+	if equal < 0 {
+		return "", "", fmt.Errorf("no \"=\" in upstream definition %q", upstream)
+	}
+	dir, remote = upstream[:equal], upstream[equal+1:]
+	if dir == "" {
+		return "", "", fmt.Errorf("empty dir in upstream definition %q", upstream)
+	}
+	if remote == "" {
+		return "", "", fmt.Errorf("empty remote in upstream definition %q", upstream)
+	}
+	if strings.ContainsRune(dir, '/') {
+		return "", "", fmt.Errorf("dirs can't contain / (yet): %q", dir)
+	}
+	return dir, remote, nil
+}
+
 // Create an upstream from the directory it is mounted on and the remote
 func (f *Fs) newUpstream(ctx context.Context, dir, remote string) (*upstream, error) {
 	uFs, err := cache.Get(ctx, remote)
@@ -188,19 +205,9 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (outFs fs
 	for _, upstream := range opt.Upstreams {
 		upstream := upstream
 		g.Go(func() (err error) {
-			equal := strings.IndexRune(upstream, '=')
-			if equal < 0 {
-				return fmt.Errorf("no \"=\" in upstream definition %q", upstream)
-			}
-			dir, remote := upstream[:equal], upstream[equal+1:]
-			if dir == "" {
-				return fmt.Errorf("empty dir in upstream definition %q", upstream)
-			}
-			if remote == "" {
-				return fmt.Errorf("empty remote in upstream definition %q", upstream)
-			}
-			if strings.ContainsRune(dir, '/') {
-				return fmt.Errorf("dirs can't contain / (yet): %q", dir)
+			dir, remote, err := parseUpstream(upstream)
+			if err != nil {
+				return err
 			}
 			u, err := f.newUpstream(gCtx, dir, remote)
 			if err != nil {
@@ -353,10 +360,19 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (outFs fs
 	return f, nil
 }
 
+// getUpstreams returns the current map of upstreams
+//
+// The map returned must not be modified
+func (f *Fs) getUpstreams() map[string]*upstream {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.upstreams
+}
+
 // Run a function over all the upstreams in parallel
 func (f *Fs) multithread(ctx context.Context, fn func(context.Context, *upstream) error) error {
 	g, gCtx := errgroup.WithContext(ctx)
-	for _, u := range f.upstreams {
+	for _, u := range f.getUpstreams() {
 		u := u
 		g.Go(func() (err error) {
 			return fn(gCtx, u)
@@ -380,7 +396,7 @@ func join(elem ...string) string {
 // find the upstream for the remote passed in, returning the upstream and the adjusted path
 func (f *Fs) findUpstream(remote string) (u *upstream, uRemote string, err error) {
 	// defer log.Trace(remote, "")("f=%v, uRemote=%q, err=%v", &u, &uRemote, &err)
-	for _, u := range f.upstreams {
+	for _, u := range f.getUpstreams() {
 		uRemote, err = u.pathAdjustment.undo(remote)
 		if err == nil {
 			return u, uRemote, nil
@@ -597,7 +613,7 @@ func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string
 func (f *Fs) ChangeNotify(ctx context.Context, notifyFunc func(string, fs.EntryType), ch <-chan time.Duration) {
 	var uChans []chan time.Duration
 
-	for _, u := range f.upstreams {
+	for _, u := range f.getUpstreams() {
 		u := u
 		if do := u.f.Features().ChangeNotify; do != nil {
 			ch := make(chan time.Duration)
@@ -702,7 +718,7 @@ func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
 		Free:    new(int64),
 		Objects: new(int64),
 	}
-	for _, u := range f.upstreams {
+	for _, u := range f.getUpstreams() {
 		doAbout := u.f.Features().About
 		if doAbout == nil {
 			continue
@@ -781,8 +797,9 @@ func (u *upstream) wrapEntries(ctx context.Context, entries fs.DirEntries) (fs.D
 func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	// defer log.Trace(f, "dir=%q", dir)("entries = %v, err=%v", &entries, &err)
 	if f.root == "" && dir == "" {
-		entries = make(fs.DirEntries, 0, len(f.upstreams))
-		for combineDir := range f.upstreams {
+		upstreams := f.getUpstreams()
+		entries = make(fs.DirEntries, 0, len(upstreams))
+		for combineDir := range upstreams {
 			d := fs.NewDir(combineDir, f.when)
 			entries = append(entries, d)
 		}
@@ -881,7 +898,7 @@ func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 // Precision is the greatest Precision of all upstreams
 func (f *Fs) Precision() time.Duration {
 	var greatestPrecision time.Duration
-	for _, u := range f.upstreams {
+	for _, u := range f.getUpstreams() {
 		uPrecision := u.f.Precision()
 		if uPrecision > greatestPrecision {
 			greatestPrecision = uPrecision
@@ -1083,6 +1100,121 @@ func (o *Object) SetTier(tier string) error {
 	return do.SetTier(tier)
 }
 
+var commandHelp = []fs.CommandHelp{{
+	Name:  "add",
+	Short: "Add upstreams to a running combine remote",
+	Long: `This command adds upstreams to the combine remote. They take the
+same form as the upstreams setting, for example
+
+    rclone rc backend/command command=add fs=combine: -a "dir=remote:path" -a "dir2=remote2:"
+
+This is only useful with a combine remote which is in use by a
+long running rclone such as a mount started with the rc, as the
+upstreams setting in the config is not changed.
+
+The features of the combine remote, such as which hashes it supports,
+are not recalculated and changes from ChangeNotify won't be seen from
+the new upstreams.
+`,
+}, {
+	Name:  "remove",
+	Short: "Remove upstreams from a running combine remote",
+	Long: `This command removes the upstreams mounted in the directories
+passed in from the combine remote, for example
+
+    rclone rc backend/command command=remove fs=combine: -a dir -a dir2
+`,
+}, {
+	Name:  "upstreams",
+	Short: "Show the upstreams of the combine remote",
+	Long: `This command returns a map of the directories of the combine remote
+to the remotes mounted on them.
+`,
+}}
+
+// addUpstreams adds the upstream definitions passed in
+func (f *Fs) addUpstreams(ctx context.Context, defs []string) error {
+	newUpstreams := make(map[string]*upstream, len(defs))
+	for _, def := range defs {
+		dir, remote, err := parseUpstream(def)
+		if err != nil {
+			return err
+		}
+		if _, found := newUpstreams[dir]; found {
+			return fmt.Errorf("duplicate directory name %q", dir)
+		}
+		u, err := f.newUpstream(ctx, dir, remote)
+		if err != nil {
+			return err
+		}
+		newUpstreams[dir] = u
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upstreams := make(map[string]*upstream, len(f.upstreams)+len(newUpstreams))
+	for dir, u := range f.upstreams {
+		upstreams[dir] = u
+	}
+	for dir, u := range newUpstreams {
+		if _, found := upstreams[dir]; found {
+			return fmt.Errorf("duplicate directory name %q", dir)
+		}
+		upstreams[dir] = u
+	}
+	f.upstreams = upstreams
+	return nil
+}
+
+// removeUpstreams removes the upstreams mounted on the dirs passed in
+func (f *Fs) removeUpstreams(dirs []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upstreams := make(map[string]*upstream, len(f.upstreams))
+	for dir, u := range f.upstreams {
+		upstreams[dir] = u
+	}
+	for _, dir := range dirs {
+		if _, found := upstreams[dir]; !found {
+			return fmt.Errorf("upstream %q: %w", dir, fs.ErrorDirNotFound)
+		}
+		delete(upstreams, dir)
+	}
+	f.upstreams = upstreams
+	return nil
+}
+
+// Command the backend to run a named command
+//
+// The command run is name
+// args may be used to read arguments from
+// opts may be used to read optional arguments from
+//
+// The result should be capable of being JSON encoded
+// If it is a string or a []string it will be shown to the user
+// otherwise it will be JSON encoded and shown to the user like that
+func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	switch name {
+	case "add":
+		if len(arg) == 0 {
+			return nil, errors.New("need at least 1 argument")
+		}
+		return nil, f.addUpstreams(ctx, arg)
+	case "remove":
+		if len(arg) == 0 {
+			return nil, errors.New("need at least 1 argument")
+		}
+		return nil, f.removeUpstreams(arg)
+	case "upstreams":
+		upstreams := map[string]string{}
+		for dir, u := range f.getUpstreams() {
+			upstreams[dir] = fs.ConfigString(u.f)
+		}
+		return upstreams, nil
+	default:
+		return nil, fs.ErrorCommandNotFound
+	}
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs              = (*Fs)(nil)
@@ -1101,5 +1233,6 @@ var (
 	_ fs.MergeDirser     = (*Fs)(nil)
 	_ fs.CleanUpper      = (*Fs)(nil)
 	_ fs.OpenWriterAter  = (*Fs)(nil)
+	_ fs.Commander       = (*Fs)(nil)
 	_ fs.FullObject      = (*Object)(nil)
 )
@@ -1,10 +1,15 @@
 package combine
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	_ "github.com/rclone/rclone/backend/memory"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAdjustmentDo(t *testing.T) {
@@ -92,3 +97,45 @@ func TestAdjustmentUndo(t *testing.T) {
 	}
 
 }
+
+func TestCommandUpstreams(t *testing.T) {
+	ctx := context.Background()
+	fFs, err := NewFs(ctx, "TestCommandUpstreams", "", configmap.Simple{
+		"upstreams": "dir1=:memory:dir1",
+	})
+	require.NoError(t, err)
+	f := fFs.(*Fs)
+
+	listDirs := func() (dirs []string) {
+		entries, err := f.List(ctx, "")
+		require.NoError(t, err)
+		entries.ForDir(func(dir fs.Directory) {
+			dirs = append(dirs, dir.Remote())
+		})
+		return dirs
+	}
+	assert.Equal(t, []string{"dir1"}, listDirs())
+
+	_, err = f.Command(ctx, "add", []string{"dir2=:memory:dir2", "dir3=:memory:dir3"}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dir1", "dir2", "dir3"}, listDirs())
+
+	_, err = f.Command(ctx, "add", []string{"dir1=:memory:other"}, nil)
+	assert.ErrorContains(t, err, "duplicate")
+	_, err = f.Command(ctx, "add", []string{"dir4"}, nil)
+	assert.Error(t, err)
+
+	_, err = f.Command(ctx, "remove", []string{"dir2"}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dir1", "dir3"}, listDirs())
+
+	_, err = f.Command(ctx, "remove", []string{"dir2"}, nil)
+	assert.ErrorIs(t, err, fs.ErrorDirNotFound)
+
+	out, err := f.Command(ctx, "upstreams", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"dir1": ":memory:dir1", "dir3": ":memory:dir3"}, out)
+
+	_, err = f.Command(ctx, "potato", nil, nil)
+	assert.Equal(t, fs.ErrorCommandNotFound, err)
+}
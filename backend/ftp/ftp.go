@@ -487,6 +487,8 @@ func (f *Fs) getFtpConnection(ctx context.Context) (c *ftp.ServerConn, err error
 	if f.opt.Concurrency > 0 {
 		f.tokens.Get()
 	}
+	// Apply the limits set for this remote
+	ctx = fs.WithRemoteName(ctx, f.name)
 	accounting.LimitTPS(ctx)
 	f.poolMu.Lock()
 	if len(f.pool) > 0 {
@@ -27,6 +27,7 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/lib/encoder"
 	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/lib/random"
 	"github.com/rclone/rclone/lib/readers"
 	"golang.org/x/text/unicode/norm"
 )
@@ -211,6 +212,23 @@ when copying to a CIFS mount owned by another user. If this option is
 enabled, rclone will no longer update the modtime after copying a file.`,
 			Default:  false,
 			Advanced: true,
+		}, {
+			Name: "copy_is_hardlink",
+			Help: `Set to enable server side copies using hardlinks.
+
+Normally server side copies are not allowed with the local backend.
+
+If this flag is set then server side copies are done by making a
+hardlink from the source to the destination. The source and the
+destination must be on the same file system.
+
+Note that hardlinking two files together will use no additional space
+as the source and the destination will be the same file. This means
+that if either is updated in place the other will change too, so this
+is only safe when files are replaced rather than modified, for example
+when making backups into a new directory with --copy-dest.`,
+			Default:  false,
+			Advanced: true,
 		}, {
 			Name:     config.ConfigEncoding,
 			Help:     config.ConfigEncodingHelp,
@@ -235,6 +253,7 @@ type Options struct {
 	NoPreAllocate     bool                 `config:"no_preallocate"`
 	NoSparse          bool                 `config:"no_sparse"`
 	NoSetModTime      bool                 `config:"no_set_modtime"`
+	CopyIsHardlink    bool                 `config:"copy_is_hardlink"`
 	Enc               encoder.MultiEncoder `config:"encoding"`
 }
 
@@ -311,6 +330,11 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		FilterAware:             true,
 		PartialUploads:          true,
 	}).Fill(ctx, f)
+	if !opt.CopyIsHardlink {
+		// Disable server side copy unless --local-copy-is-hardlink is set
+		f.features.Copy = nil
+	}
+	f.features.CopyIsLink = opt.CopyIsHardlink
 	if opt.FollowSymlinks {
 		f.lstat = os.Stat
 	}
@@ -804,6 +828,102 @@ func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	return dstObj, nil
 }
 
+// Copy src to this remote using a hardlink
+//
+// # This is only enabled with --local-copy-is-hardlink
+//
+// If it isn't possible then return fs.ErrorCantCopy
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	if !f.opt.CopyIsHardlink {
+		return nil, fs.ErrorCantCopy
+	}
+	srcObj, ok := src.(*Object)
+	if !ok || srcObj.translatedLink {
+		fs.Debugf(src, "Can't copy - not same remote type")
+		return nil, fs.ErrorCantCopy
+	}
+
+	// Temporary Object under construction
+	dstObj := f.newObject(remote)
+	if srcObj.path == dstObj.path {
+		return nil, fs.ErrorCantCopy
+	}
+
+	// Check it is a file if it exists
+	err := dstObj.lstat()
+	if os.IsNotExist(err) {
+		// OK
+	} else if err != nil {
+		return nil, err
+	} else {
+		dstObj.fs.objectMetaMu.RLock()
+		dstObjMode := dstObj.mode
+		dstObj.fs.objectMetaMu.RUnlock()
+		if !dstObj.fs.isRegular(dstObjMode) {
+			// It isn't a file
+			return nil, errors.New("can't copy file onto non-file")
+		}
+		if sameFile(srcObj.path, dstObj.path) {
+			// It is already a link to the source
+			return dstObj, nil
+		}
+	}
+
+	// Create destination
+	err = dstObj.mkdirAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Links can't overwrite so link to a temporary name then
+	// rename it over the destination so the destination is kept
+	// if the link fails
+	tmpPath := dstObj.path + ".rclone-link-" + random.String(8)
+	err = os.Link(srcObj.path, tmpPath)
+	if err != nil {
+		// probably trying to link across file system boundaries
+		fs.Debugf(src, "Can't copy: %v: trying normal copy", err)
+		return nil, fs.ErrorCantCopy
+	}
+	err = os.Rename(tmpPath, dstObj.path)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+
+	// Update the info
+	err = dstObj.lstat()
+	if err != nil {
+		return nil, err
+	}
+
+	return dstObj, nil
+}
+
+// SameFile returns true if other is a local object which is the same
+// file as o, for example a hard link to it
+func (o *Object) SameFile(other fs.Object) bool {
+	otherObj, ok := other.(*Object)
+	if !ok {
+		return false
+	}
+	return sameFile(o.path, otherObj.path)
+}
+
+// sameFile returns true if the files at path1 and path2 are the same
+// file, e.g. hard links to each other
+func sameFile(path1, path2 string) bool {
+	fi1, err := os.Lstat(path1)
+	if err != nil {
+		return false
+	}
+	fi2, err := os.Lstat(path2)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fi1, fi2)
+}
+
 // DirMove moves src, srcRemote to this remote at dstRemote
 // using server-side move operations.
 //
@@ -1420,6 +1540,18 @@ func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error)
 	return metadata, nil
 }
 
+// SetMetadata sets the keys in metadata on the file
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	if o.translatedLink {
+		return fs.ErrorNotImplemented
+	}
+	err := o.writeMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	return o.lstat()
+}
+
 // Write the metadata on the object
 func (o *Object) writeMetadata(metadata fs.Metadata) (err error) {
 	err = o.setXattr(metadata)
@@ -1465,9 +1597,11 @@ var (
 	_ fs.Purger         = &Fs{}
 	_ fs.PutStreamer    = &Fs{}
 	_ fs.Mover          = &Fs{}
+	_ fs.Copier         = &Fs{}
 	_ fs.DirMover       = &Fs{}
 	_ fs.Commander      = &Fs{}
 	_ fs.OpenWriterAter = &Fs{}
 	_ fs.Object         = &Object{}
 	_ fs.Metadataer     = &Object{}
+	_ fs.SetMetadataer  = &Object{}
 )
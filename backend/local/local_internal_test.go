@@ -555,3 +555,59 @@ func TestCopySymlink(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "file.txt", linkContents)
 }
+
+func TestCopyIsHardlink(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	when := time.Now()
+	f := r.Flocal.(*Fs)
+
+	r.WriteFile("src/file.txt", "hello world", when)
+	src, err := f.NewObject(ctx, "src/file.txt")
+	require.NoError(t, err)
+
+	// Without the option server side copies aren't possible
+	_, err = f.Copy(ctx, src, "dst/file.txt")
+	assert.Equal(t, fs.ErrorCantCopy, err)
+
+	f.opt.CopyIsHardlink = true
+	defer func() {
+		f.opt.CopyIsHardlink = false
+	}()
+	dst, err := f.Copy(ctx, src, "dst/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "dst/file.txt", dst.Remote())
+	assert.Equal(t, int64(11), dst.Size())
+
+	srcInfo, err := os.Stat(filepath.Join(r.LocalName, "src", "file.txt"))
+	require.NoError(t, err)
+	dstInfo, err := os.Stat(filepath.Join(r.LocalName, "dst", "file.txt"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(srcInfo, dstInfo))
+
+	// Copying again replaces the destination
+	_, err = f.Copy(ctx, src, "dst/file.txt")
+	require.NoError(t, err)
+
+	// Copying onto another file replaces it with a link
+	r.WriteFile("dst/other.txt", "other", when)
+	_, err = f.Copy(ctx, src, "dst/other.txt")
+	require.NoError(t, err)
+	otherInfo, err := os.Stat(filepath.Join(r.LocalName, "dst", "other.txt"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(srcInfo, otherInfo))
+
+	// Copying onto a directory fails and leaves it alone
+	require.NoError(t, os.Mkdir(filepath.Join(r.LocalName, "dst", "dir"), 0777))
+	_, err = f.Copy(ctx, src, "dst/dir")
+	assert.ErrorContains(t, err, "can't copy file onto non-file")
+	fi, err := os.Stat(filepath.Join(r.LocalName, "dst", "dir"))
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(r.LocalName, "dst"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+}
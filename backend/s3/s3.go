@@ -3899,7 +3899,7 @@ func (f *Fs) list(ctx context.Context, opt listOpt, fn listFn) error {
 		var resp *s3.ListObjectsV2Output
 		var err error
 		var versionIDs []*string
-		err = f.pacer.Call(func() (bool, error) {
+		err = f.pacer.CallCtx(ctx, func() (bool, error) {
 			listBucket.URLEncodeListings(urlEncodeListings)
 			resp, versionIDs, err = listBucket.List(ctx)
 			if err != nil && !urlEncodeListings {
@@ -4088,7 +4088,7 @@ func (f *Fs) listDir(ctx context.Context, bucket, directory, prefix string, addB
 func (f *Fs) listBuckets(ctx context.Context) (entries fs.DirEntries, err error) {
 	req := s3.ListBucketsInput{}
 	var resp *s3.ListBucketsOutput
-	err = f.pacer.Call(func() (bool, error) {
+	err = f.pacer.CallCtx(ctx, func() (bool, error) {
 		resp, err = f.c.ListBucketsWithContext(ctx, &req)
 		return f.shouldRetry(ctx, err)
 	})
@@ -4212,7 +4212,7 @@ func (f *Fs) bucketExists(ctx context.Context, bucket string) (bool, error) {
 	req := s3.HeadBucketInput{
 		Bucket: &bucket,
 	}
-	err := f.pacer.Call(func() (bool, error) {
+	err := f.pacer.CallCtx(ctx, func() (bool, error) {
 		_, err := f.c.HeadBucketWithContext(ctx, &req)
 		return f.shouldRetry(ctx, err)
 	})
@@ -4308,7 +4308,7 @@ func (f *Fs) makeBucket(ctx context.Context, bucket string) error {
 				LocationConstraint: &f.opt.LocationConstraint,
 			}
 		}
-		err := f.pacer.Call(func() (bool, error) {
+		err := f.pacer.CallCtx(ctx, func() (bool, error) {
 			_, err := f.c.CreateBucketWithContext(ctx, &req)
 			return f.shouldRetry(ctx, err)
 		})
@@ -4359,7 +4359,7 @@ func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 		req := s3.DeleteBucketInput{
 			Bucket: &bucket,
 		}
-		err := f.pacer.Call(func() (bool, error) {
+		err := f.pacer.CallCtx(ctx, func() (bool, error) {
 			_, err := f.c.DeleteBucketWithContext(ctx, &req)
 			return f.shouldRetry(ctx, err)
 		})
@@ -4422,7 +4422,7 @@ func (f *Fs) copy(ctx context.Context, req *s3.CopyObjectInput, dstBucket, dstPa
 	if src.bytes >= int64(f.opt.CopyCutoff) {
 		return f.copyMultipart(ctx, req, dstBucket, dstPath, srcBucket, srcPath, src)
 	}
-	return f.pacer.Call(func() (bool, error) {
+	return f.pacer.CallCtx(ctx, func() (bool, error) {
 		_, err := f.c.CopyObjectWithContext(ctx, req)
 		return f.shouldRetry(ctx, err)
 	})
@@ -4467,7 +4467,7 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 	req.Key = &dstPath
 
 	var cout *s3.CreateMultipartUploadOutput
-	if err := f.pacer.Call(func() (bool, error) {
+	if err := f.pacer.CallCtx(ctx, func() (bool, error) {
 		var err error
 		cout, err = f.c.CreateMultipartUploadWithContext(ctx, req)
 		return f.shouldRetry(ctx, err)
@@ -4479,7 +4479,7 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 	defer atexit.OnError(&err, func() {
 		// Try to abort the upload, but ignore the error.
 		fs.Debugf(src, "Cancelling multipart copy")
-		_ = f.pacer.Call(func() (bool, error) {
+		_ = f.pacer.CallCtx(ctx, func() (bool, error) {
 			_, err := f.c.AbortMultipartUploadWithContext(context.Background(), &s3.AbortMultipartUploadInput{
 				Bucket:       &dstBucket,
 				Key:          &dstPath,
@@ -4498,7 +4498,7 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 
 	var parts []*s3.CompletedPart
 	for partNum := int64(1); partNum <= numParts; partNum++ {
-		if err := f.pacer.Call(func() (bool, error) {
+		if err := f.pacer.CallCtx(ctx, func() (bool, error) {
 			partNum := partNum
 			uploadPartReq := &s3.UploadPartCopyInput{}
 			//structs.SetFrom(uploadPartReq, copyReq)
@@ -4522,7 +4522,7 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 		}
 	}
 
-	return f.pacer.Call(func() (bool, error) {
+	return f.pacer.CallCtx(ctx, func() (bool, error) {
 		_, err := f.c.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
 			Bucket: &dstBucket,
 			Key:    &dstPath,
@@ -4844,7 +4844,7 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 			reqCopy.Bucket = &bucket
 			reqCopy.Key = &bucketPath
 			reqCopy.VersionId = o.versionID
-			err = f.pacer.Call(func() (bool, error) {
+			err = f.pacer.CallCtx(ctx, func() (bool, error) {
 				_, err = f.c.RestoreObject(&reqCopy)
 				return f.shouldRetry(ctx, err)
 			})
@@ -4966,7 +4966,7 @@ func (f *Fs) listMultipartUploads(ctx context.Context, bucket, key string) (uplo
 			Prefix:         &key,
 		}
 		var resp *s3.ListMultipartUploadsOutput
-		err = f.pacer.Call(func() (bool, error) {
+		err = f.pacer.CallCtx(ctx, func() (bool, error) {
 			resp, err = f.c.ListMultipartUploads(&req)
 			return f.shouldRetry(ctx, err)
 		})
@@ -5091,7 +5091,7 @@ func (f *Fs) setGetVersioning(ctx context.Context, arg ...string) (status string
 			Bucket:                  &f.rootBucket,
 			VersioningConfiguration: &versioning,
 		}
-		err := f.pacer.Call(func() (bool, error) {
+		err := f.pacer.CallCtx(ctx, func() (bool, error) {
 			_, err = f.c.PutBucketVersioningWithContext(ctx, &req)
 			return f.shouldRetry(ctx, err)
 		})
@@ -5103,7 +5103,7 @@ func (f *Fs) setGetVersioning(ctx context.Context, arg ...string) (status string
 		Bucket: &f.rootBucket,
 	}
 	var resp *s3.GetBucketVersioningOutput
-	err = f.pacer.Call(func() (bool, error) {
+	err = f.pacer.CallCtx(ctx, func() (bool, error) {
 		resp, err = f.c.GetBucketVersioningWithContext(ctx, &req)
 		return f.shouldRetry(ctx, err)
 	})
@@ -5314,7 +5314,7 @@ func (f *Fs) headObject(ctx context.Context, req *s3.HeadObjectInput) (resp *s3.
 	if f.opt.SSECustomerKeyMD5 != "" {
 		req.SSECustomerKeyMD5 = &f.opt.SSECustomerKeyMD5
 	}
-	err = f.pacer.Call(func() (bool, error) {
+	err = f.pacer.CallCtx(ctx, func() (bool, error) {
 		var err error
 		resp, err = f.c.HeadObjectWithContext(ctx, req)
 		return f.shouldRetry(ctx, err)
@@ -5485,7 +5485,7 @@ func (o *Object) downloadFromURL(ctx context.Context, bucketPath string, options
 		RootURL: url,
 		Options: options,
 	}
-	err = o.fs.pacer.Call(func() (bool, error) {
+	err = o.fs.pacer.CallCtx(ctx, func() (bool, error) {
 		resp, err = o.fs.srvRest.Call(ctx, &opts)
 		return o.fs.shouldRetry(ctx, err)
 	})
@@ -5584,7 +5584,7 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.Read
 			}
 		}
 	}
-	err = o.fs.pacer.Call(func() (bool, error) {
+	err = o.fs.pacer.CallCtx(ctx, func() (bool, error) {
 		var err error
 		httpReq.HTTPRequest = httpReq.HTTPRequest.WithContext(ctx)
 		err = httpReq.Send()
@@ -5698,7 +5698,7 @@ func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectIn
 	}
 
 	var mOut *s3.CreateMultipartUploadOutput
-	err = f.pacer.Call(func() (bool, error) {
+	err = f.pacer.CallCtx(ctx, func() (bool, error) {
 		mOut, err = f.c.CreateMultipartUploadWithContext(ctx, &mReq)
 		return f.shouldRetry(ctx, err)
 	})
@@ -5794,7 +5794,7 @@ func (w *s3ChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader
 		SSECustomerKeyMD5:    w.multiPartUploadInput.SSECustomerKeyMD5,
 	}
 	var uout *s3.UploadPartOutput
-	err = w.f.pacer.Call(func() (bool, error) {
+	err = w.f.pacer.CallCtx(ctx, func() (bool, error) {
 		// rewind the reader on retry and after reading md5
 		_, err = reader.Seek(0, io.SeekStart)
 		if err != nil {
@@ -5822,7 +5822,7 @@ func (w *s3ChunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader
 
 // Abort the multipart upload
 func (w *s3ChunkWriter) Abort(ctx context.Context) error {
-	err := w.f.pacer.Call(func() (bool, error) {
+	err := w.f.pacer.CallCtx(ctx, func() (bool, error) {
 		_, err := w.f.c.AbortMultipartUploadWithContext(context.Background(), &s3.AbortMultipartUploadInput{
 			Bucket:       w.bucket,
 			Key:          w.key,
@@ -5845,7 +5845,7 @@ func (w *s3ChunkWriter) Close(ctx context.Context) (err error) {
 		return *w.completedParts[i].PartNumber < *w.completedParts[j].PartNumber
 	})
 	var resp *s3.CompleteMultipartUploadOutput
-	err = w.f.pacer.Call(func() (bool, error) {
+	err = w.f.pacer.CallCtx(ctx, func() (bool, error) {
 		resp, err = w.f.c.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
 			Bucket: w.bucket,
 			Key:    w.key,
@@ -5926,7 +5926,7 @@ func (o *Object) uploadSinglepartPutObject(ctx context.Context, req *s3.PutObjec
 	r.SetContext(ctx)
 	r.HTTPRequest.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
 
-	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
+	err = o.fs.pacer.CallNoRetryCtx(ctx, func() (bool, error) {
 		err := r.Send()
 		return o.fs.shouldRetry(ctx, err)
 	})
@@ -5986,7 +5986,7 @@ func (o *Object) uploadSinglepartPresignedRequest(ctx context.Context, req *s3.P
 	httpReq.ContentLength = size
 
 	var resp *http.Response
-	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
+	err = o.fs.pacer.CallNoRetryCtx(ctx, func() (bool, error) {
 		var err error
 		resp, err = o.fs.srv.Do(httpReq)
 		if err != nil {
@@ -6286,7 +6286,7 @@ func (o *Object) Remove(ctx context.Context) error {
 	if o.fs.opt.RequesterPays {
 		req.RequestPayer = aws.String(s3.RequestPayerRequester)
 	}
-	err := o.fs.pacer.Call(func() (bool, error) {
+	err := o.fs.pacer.CallCtx(ctx, func() (bool, error) {
 		_, err := o.fs.c.DeleteObjectWithContext(ctx, &req)
 		return o.fs.shouldRetry(ctx, err)
 	})
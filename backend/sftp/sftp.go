@@ -30,6 +30,7 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/lib/env"
 	"github.com/rclone/rclone/lib/pacer"
+	"github.com/rclone/rclone/lib/random"
 	"github.com/rclone/rclone/lib/readers"
 	sshagent "github.com/xanzy/ssh-agent"
 	"golang.org/x/crypto/ssh"
@@ -695,6 +696,8 @@ func (f *Fs) newSftpClient(client sshClient, opts ...sftp.ClientOption) (*sftp.C
 
 // Get an SFTP connection from the pool, or open a new one
 func (f *Fs) getSftpConnection(ctx context.Context) (c *conn, err error) {
+	// Apply the limits set for this remote
+	ctx = fs.WithRemoteName(ctx, f.name)
 	accounting.LimitTPS(ctx)
 	f.poolMu.Lock()
 	for len(f.pool) > 0 {
@@ -1074,6 +1077,7 @@ func NewFsWithConnection(ctx context.Context, f *Fs, name string, root string, m
 		// Disable server side copy unless --sftp-copy-is-hardlink is set
 		f.features.Copy = nil
 	}
+	f.features.CopyIsLink = opt.CopyIsHardlink
 	// Make a connection and pool it to return errors early
 	c, err := f.getSftpConnection(ctx)
 	if err != nil {
@@ -1427,6 +1431,9 @@ func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object,
 }
 
 // Copy server side copies a remote sftp file object using hardlinks
+//
+// Links can't overwrite so the link is made with a temporary name
+// then renamed over the destination, which is kept if the link fails.
 func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	if !f.opt.CopyIsHardlink {
 		return nil, fs.ErrorCantCopy
@@ -1436,6 +1443,14 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 		fs.Debugf(src, "Can't copy - not same remote type")
 		return nil, fs.ErrorCantCopy
 	}
+	srcPath, dstPath := srcObj.path(), path.Join(f.absRoot, remote)
+	if srcPath == dstPath {
+		return nil, fs.ErrorCantCopy
+	}
+	// If the destination is already a link to the source leave it
+	if dstObj, err := f.NewObject(ctx, remote); err == nil && srcObj.SameFile(dstObj) {
+		return dstObj, nil
+	}
 	err := f.mkParentDir(ctx, remote)
 	if err != nil {
 		return nil, fmt.Errorf("Copy mkParentDir failed: %w", err)
@@ -1444,10 +1459,10 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	if err != nil {
 		return nil, fmt.Errorf("Copy: %w", err)
 	}
-	srcPath, dstPath := srcObj.path(), path.Join(f.absRoot, remote)
-	err = c.sftpClient.Link(srcPath, dstPath)
-	f.putSftpConnection(&c, err)
+	tmpPath := dstPath + ".rclone-link-" + random.String(8)
+	err = c.sftpClient.Link(srcPath, tmpPath)
 	if err != nil {
+		f.putSftpConnection(&c, err)
 		if sftpErr, ok := err.(*sftp.StatusError); ok {
 			if sftpErr.FxCode() == sftp.ErrSSHFxOpUnsupported {
 				// Remote doesn't support Link
@@ -1456,6 +1471,26 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 		}
 		return nil, fmt.Errorf("Copy failed: %w", err)
 	}
+	if _, ok := c.sftpClient.HasExtension("posix-rename@openssh.com"); ok {
+		err = c.sftpClient.PosixRename(tmpPath, dstPath)
+	} else {
+		// If haven't got PosixRename then remove the destination
+		// first before renaming
+		err = c.sftpClient.Remove(dstPath)
+		if err != nil && !errors.Is(err, iofs.ErrNotExist) {
+			fs.Errorf(f, "Copy: Failed to remove existing file %q: %v", dstPath, err)
+		}
+		err = c.sftpClient.Rename(tmpPath, dstPath)
+	}
+	if err != nil {
+		if removeErr := c.sftpClient.Remove(tmpPath); removeErr != nil {
+			fs.Errorf(f, "Copy: Failed to remove temporary link %q: %v", tmpPath, removeErr)
+		}
+	}
+	f.putSftpConnection(&c, err)
+	if err != nil {
+		return nil, fmt.Errorf("Copy Rename failed: %w", err)
+	}
 	dstObj, err := f.NewObject(ctx, remote)
 	if err != nil {
 		return nil, fmt.Errorf("Copy NewObject failed: %w", err)
@@ -1872,6 +1907,32 @@ func (f *Fs) remoteShellPath(remote string) string {
 	return shellPath
 }
 
+// sameFile returns true if the files at the shell paths path1 and
+// path2 are the same file, e.g. hard links to each other.
+//
+// SFTP doesn't say which file a path is so this runs stat with the
+// shell. It returns false if that isn't possible.
+func (f *Fs) sameFile(ctx context.Context, path1, path2 string) bool {
+	if f.shellType != "unix" {
+		return false
+	}
+	arg1, err := f.quoteOrEscapeShellPath(path1)
+	if err != nil {
+		return false
+	}
+	arg2, err := f.quoteOrEscapeShellPath(path2)
+	if err != nil {
+		return false
+	}
+	out, err := f.run(ctx, "stat -c %d:%i "+arg1+" "+arg2)
+	if err != nil {
+		fs.Debugf(f, "Can't find out if %q and %q are the same file: %v", path1, path2, err)
+		return false
+	}
+	ids := strings.Fields(string(out))
+	return len(ids) == 2 && ids[0] == ids[1]
+}
+
 // Converts a byte array from the SSH session returned by
 // an invocation of md5sum/sha1sum to a hash string
 // as expected by the rest of this application
@@ -1930,6 +1991,19 @@ func (o *Object) shellPath() string {
 	return o.fs.remoteShellPath(o.remote)
 }
 
+// SameFile returns true if other is an sftp object on the same remote
+// which is the same file as o, for example a hard link to it
+//
+// This needs the shell so is always false with shell types other
+// than unix.
+func (o *Object) SameFile(other fs.Object) bool {
+	otherObj, ok := other.(*Object)
+	if !ok || o.fs.name != otherObj.fs.name {
+		return false
+	}
+	return o.fs.sameFile(context.TODO(), o.shellPath(), otherObj.shellPath())
+}
+
 // setMetadata updates the info in the object from the stat result passed in
 func (o *Object) setMetadata(info os.FileInfo) {
 	o.modTime = info.ModTime()
@@ -105,7 +105,7 @@ func (f *Fs) getSessions() int32 {
 func (f *Fs) newConnection(ctx context.Context, share string) (c *conn, err error) {
 	// As we are pooling these connections we need to decouple
 	// them from the current context
-	bgCtx := context.Background()
+	bgCtx := fs.WithRemoteName(context.Background(), f.name)
 
 	c, err = f.dial(bgCtx, "tcp", f.opt.Host+":"+f.opt.Port)
 	if err != nil {
@@ -147,6 +147,8 @@ func (c *conn) mountShare(share string) (err error) {
 
 // Get a SMB connection from the pool, or open a new one
 func (f *Fs) getConnection(ctx context.Context, share string) (c *conn, err error) {
+	// Apply the limits set for this remote
+	ctx = fs.WithRemoteName(ctx, f.name)
 	accounting.LimitTPS(ctx)
 	f.poolMu.Lock()
 	for len(f.pool) > 0 {
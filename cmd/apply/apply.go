@@ -0,0 +1,91 @@
+// Package apply provides the apply command.
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/sync"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+}
+
+// ReadPlan reads a plan made with sync --plan-out from fileName
+func ReadPlan(fileName string) (*sync.Plan, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var plan sync.Plan
+	err = json.Unmarshal(data, &plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plan %q: %w", fileName, err)
+	}
+	if plan.Source == "" || plan.Destination == "" {
+		return nil, fmt.Errorf("plan %q has no source or destination", fileName)
+	}
+	return &plan, nil
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "apply plan.json",
+	Short: `Apply a plan made with sync --plan-out.`,
+	Long: `
+Applies the changes in a plan made with ` + "`rclone sync --plan-out plan.json`" + `
+to the destination of the sync. This means the changes a sync would
+make can be reviewed before they are made.
+
+    rclone sync --plan-out plan.json source:path dest:path
+    # review plan.json
+    rclone apply plan.json
+
+The plan contains exactly the files to copy, delete and rename along
+with a fingerprint (size, modification time and a hash if it is quick
+to read) of each file involved. Before changing anything ` + "`apply`" + `
+checks all these files are the same as when the plan was made. If any
+have changed, been created or been removed it makes no changes and
+returns an error, in which case make a new plan.
+
+Renames are done first, then copies, then deletes. No files are
+deleted if any of the copies fail.
+
+The plan records the source and destination so they aren't given on
+the command line. Global flags such as ` + "`--transfers`" + `,
+` + "`--backup-dir`" + ` and ` + "`--suffix`" + ` aren't saved in the plan and need
+giving again if required. Files overwritten or deleted by the plan are
+then moved to the backup directory as ` + "`sync`" + ` would.
+
+Files which the sync would have server-side copied from
+` + "`--copy-dest`" + ` are recorded in the plan along with their
+` + "`--copy-dest`" + ` remote and fingerprint, and are server-side copied
+from there again when the plan is applied, so ` + "`--copy-dest`" + `
+doesn't need giving again. If the file couldn't be found in
+` + "`--copy-dest`" + ` when making the plan this is logged and it is
+copied from the source instead.
+
+Empty directories aren't part of the plan so aren't created or removed.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+		"groups":            "Sync,Important",
+	},
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		plan, err := ReadPlan(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fsrc := cmd.NewFsDir([]string{plan.Source})
+		fdst := cmd.NewFsDir([]string{plan.Destination})
+		cmd.Run(true, true, command, func() error {
+			return sync.ApplyPlan(context.Background(), fdst, fsrc, plan)
+		})
+	},
+}
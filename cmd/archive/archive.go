@@ -0,0 +1,205 @@
+// Package archive provides the archive command.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/spf13/cobra"
+)
+
+// Formats of archive supported
+const (
+	FormatTar   = "tar"
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+)
+
+var (
+	format = ""
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.AddCommand(createCommand)
+	commandDefinition.AddCommand(extractCommand)
+	commandDefinition.AddCommand(listCommand)
+	for _, command := range []*cobra.Command{createCommand, extractCommand, listCommand} {
+		cmdFlags := command.Flags()
+		flags.StringVarP(cmdFlags, &format, "format", "", format, "Archive format: tar, tar.gz or zip (default: from the file extension)", "")
+	}
+}
+
+// FormatFromName returns the archive format from the extension of
+// name or an error if it isn't recognised.
+func FormatFromName(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return FormatTar, nil
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, nil
+	}
+	return "", fmt.Errorf("can't work out archive format from %q - use --format", name)
+}
+
+// getFormat returns the format set with --format or the format from
+// the extension of name.
+func getFormat(name string) (string, error) {
+	switch format {
+	case "":
+		return FormatFromName(name)
+	case FormatTar, FormatTarGz, FormatZip:
+		return format, nil
+	case "tgz":
+		return FormatTarGz, nil
+	}
+	return "", fmt.Errorf("unknown archive format %q - use tar, tar.gz or zip", format)
+}
+
+// newArchiveObject returns the archive object pointed to by remote
+// and its format.
+func newArchiveObject(ctx context.Context, remote string) (fs.Object, string) {
+	f, fileName := cmd.NewFsFile(remote)
+	if fileName == "" {
+		log.Fatalf("%q is not an archive file", remote)
+	}
+	archiveFormat, err := getFormat(fileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	o, err := f.NewObject(ctx, fileName)
+	if err != nil {
+		log.Fatalf("Failed to find archive %q: %v", remote, err)
+	}
+	return o, archiveFormat
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "archive <action> [opts] <source> [<destination>]",
+	Short: `Create, extract and list tar and zip archives on remotes.`,
+	Long: `
+rclone archive creates and extracts tar and zip archives directly
+on remotes without needing to copy the files to the local disk
+first.
+
+    rclone archive create remote:dir remote2:backup.tar.gz
+    rclone archive extract remote2:backup.tar.gz remote3:dir
+    rclone archive list remote2:backup.tar.gz
+
+The format of the archive is worked out from its extension - ` + "`.tar`" + `,
+` + "`.tar.gz`" + ` (or ` + "`.tgz`" + `) and ` + "`.zip`" + ` are supported. Use
+` + "`--format`" + ` to set it explicitly.
+
+Modification times are preserved in the archive, to the second for
+zip files. If ` + "`--metadata`" + ` is set then the metadata of the files
+is also stored in the archive - as PAX records for tar files and in
+the file comment for zip files - and is set on the files when they
+are extracted.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+	},
+}
+
+var createCommand = &cobra.Command{
+	Use:   "create source:path dest:path/archive.{tar,tar.gz,zip}",
+	Short: `Create an archive from the files in source:path.`,
+	Long: `
+Create an archive of the files in source:path uploading it to
+dest:path/archive as it is made.
+
+    rclone archive create remote:dir remote2:backup.zip
+
+The files are read and the archive written as a stream so no local
+disk space is needed. If the destination remote doesn't support
+streaming uploads then the archive will be spooled to a temporary
+local file first - see ` + "`rclone rcat`" + ` for details.
+
+The normal filtering flags can be used to choose which files go into
+the archive. Empty directories are stored in the archive.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+	},
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fsrc := cmd.NewFsSrc(args)
+		fdst, dstFileName := cmd.NewFsDstFile(args[1:])
+		archiveFormat, err := getFormat(dstFileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cmd.Run(true, true, command, func() error {
+			return Create(context.Background(), fsrc, fdst, dstFileName, archiveFormat)
+		})
+	},
+}
+
+var extractCommand = &cobra.Command{
+	Use:   "extract source:path/archive.{tar,tar.gz,zip} dest:path",
+	Short: `Extract the files in an archive to dest:path.`,
+	Long: `
+Extract the files in the archive source:path/archive to dest:path.
+
+    rclone archive extract remote:backup.tar.gz remote2:dir
+
+Tar archives are read as a stream. Zip archives are read using range
+requests so the central directory at the end of the file is read
+first and then each file in turn.
+
+Files in the destination with the same names as those in the archive
+are overwritten. The normal filtering flags can be used to choose
+which files are extracted.
+
+Entries whose names would be extracted outside dest:path are skipped
+with an error, as are entries other than files and directories such
+as symlinks.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+	},
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		o, archiveFormat := newArchiveObject(context.Background(), args[0])
+		fdst := cmd.NewFsDir(args[1:])
+		cmd.Run(true, true, command, func() error {
+			return Extract(context.Background(), o, archiveFormat, fdst)
+		})
+	},
+}
+
+var listCommand = &cobra.Command{
+	Use:   "list source:path/archive.{tar,tar.gz,zip}",
+	Short: `List the contents of an archive.`,
+	Long: `
+List the size, modification time and path of the entries in the
+archive source:path/archive. Directories are shown with a size of -1
+and a trailing ` + "`/`" + `.
+
+    $ rclone archive list remote:backup.zip
+           -1 2023-11-02 10:11:12.000000000 dir/
+         6012 2023-11-02 10:11:12.000000000 dir/file.txt
+
+For zip files only the central directory at the end of the archive is
+read. Tar files have to be read in full to list them.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+	},
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		o, archiveFormat := newArchiveObject(context.Background(), args[0])
+		cmd.Run(false, false, command, func() error {
+			return List(context.Background(), o, archiveFormat, os.Stdout)
+		})
+	},
+}
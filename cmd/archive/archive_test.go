@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	t1 = fstest.Time("2017-02-03T04:05:06.499999999Z")
+	t2 = fstest.Time("2020-09-10T11:12:13.000000000Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestFormatFromName(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"a.tar", FormatTar},
+		{"a.TAR.GZ", FormatTarGz},
+		{"a.tgz", FormatTarGz},
+		{"dir/a.zip", FormatZip},
+		{"a.rar", ""},
+	} {
+		got, err := FormatFromName(test.in)
+		assert.Equal(t, test.want, got, test.in)
+		assert.Equal(t, test.want == "", err != nil, test.in)
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"file.txt", "file.txt", nil},
+		{"dir/", "dir", nil},
+		{"./dir/../file.txt", "file.txt", nil},
+		{`dir\file.txt`, "dir/file.txt", nil},
+		{"./", "", nil},
+		{"/etc/passwd", "", errUnsafeName},
+		{"../file.txt", "", errUnsafeName},
+		{"dir/../../file.txt", "", errUnsafeName},
+	} {
+		got, err := cleanName(test.in)
+		assert.Equal(t, test.want, got, test.in)
+		assert.Equal(t, test.err, err, test.in)
+	}
+}
+
+func TestCreateExtract(t *testing.T) {
+	ctx := context.Background()
+	for _, archiveFormat := range []string{FormatTar, FormatTarGz, FormatZip} {
+		t.Run(archiveFormat, func(t *testing.T) {
+			r := fstest.NewRun(t)
+			file1 := r.WriteFile("file1.txt", "hello world", t1)
+			file2 := r.WriteFile("dir/file2.txt", strings.Repeat("potato", 1000), t2)
+			r.CheckLocalItems(t, file1, file2)
+
+			archiveName := "test." + archiveFormat
+			require.NoError(t, Create(ctx, r.Flocal, r.Fremote, archiveName, archiveFormat))
+
+			o, err := r.Fremote.NewObject(ctx, archiveName)
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			require.NoError(t, List(ctx, o, archiveFormat, &out))
+			listing := out.String()
+			assert.Contains(t, listing, "       11 ")
+			assert.Contains(t, listing, " file1.txt\n")
+			assert.Contains(t, listing, "       -1 ")
+			assert.Contains(t, listing, " dir/\n")
+			assert.Contains(t, listing, "     6000 ")
+			assert.Contains(t, listing, " dir/file2.txt\n")
+
+			fout, err := fs.NewFs(ctx, r.FremoteName+"/out")
+			require.NoError(t, err)
+			require.NoError(t, Extract(ctx, o, archiveFormat, fout))
+			precision := fs.GetModifyWindow(ctx, r.Flocal, fout)
+			if archiveFormat == FormatZip {
+				// zip files only store times to the second
+				precision = time.Second
+			}
+			fstest.CheckListingWithPrecision(t, fout, []fstest.Item{file1, file2}, []string{"dir"}, precision)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/walk"
+)
+
+// paxMetadataPrefix is the prefix of the PAX records the metadata of
+// a file is stored in
+const paxMetadataPrefix = "RCLONE.metadata."
+
+// archiveWriter writes entries to an archive
+type archiveWriter interface {
+	// addDir adds the directory d
+	addDir(ctx context.Context, d fs.Directory) error
+	// addFile adds the file o reading its contents from in
+	addFile(ctx context.Context, o fs.Object, meta fs.Metadata, in io.Reader) error
+	// Close finishes the archive
+	Close() error
+}
+
+// newArchiveWriter makes an archiveWriter for format writing to out
+func newArchiveWriter(out io.Writer, format string) (archiveWriter, error) {
+	switch format {
+	case FormatTar:
+		return &tarWriter{tw: tar.NewWriter(out)}, nil
+	case FormatTarGz:
+		gz := gzip.NewWriter(out)
+		return &tarWriter{tw: tar.NewWriter(gz), gz: gz}, nil
+	case FormatZip:
+		return &zipWriter{zw: zip.NewWriter(out)}, nil
+	}
+	return nil, fmt.Errorf("unknown archive format %q", format)
+}
+
+// fileMode returns the permissions from the "mode" metadata or def if
+// not set
+func fileMode(meta fs.Metadata, def os.FileMode) os.FileMode {
+	mode, err := strconv.ParseUint(meta["mode"], 8, 32)
+	if err != nil {
+		return def
+	}
+	return os.FileMode(mode).Perm()
+}
+
+// tarWriter writes tar archives, optionally gzipped
+type tarWriter struct {
+	tw *tar.Writer
+	gz *gzip.Writer // may be nil
+}
+
+func (w *tarWriter) addDir(ctx context.Context, d fs.Directory) error {
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     d.Remote() + "/",
+		Mode:     0755,
+		ModTime:  d.ModTime(ctx),
+		Format:   tar.FormatPAX,
+	})
+}
+
+func (w *tarWriter) addFile(ctx context.Context, o fs.Object, meta fs.Metadata, in io.Reader) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     o.Remote(),
+		Size:     o.Size(),
+		Mode:     int64(fileMode(meta, 0644)),
+		ModTime:  o.ModTime(ctx),
+		Format:   tar.FormatPAX,
+	}
+	if len(meta) > 0 {
+		hdr.PAXRecords = make(map[string]string, len(meta))
+		for k, v := range meta {
+			hdr.PAXRecords[paxMetadataPrefix+k] = v
+		}
+	}
+	err := w.tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(w.tw, in, o.Size())
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	err := w.tw.Close()
+	if w.gz != nil {
+		gzErr := w.gz.Close()
+		if err == nil {
+			err = gzErr
+		}
+	}
+	return err
+}
+
+// zipWriter writes zip archives
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) addDir(ctx context.Context, d fs.Directory) error {
+	fh := &zip.FileHeader{
+		Name:     d.Remote() + "/",
+		Modified: d.ModTime(ctx),
+	}
+	fh.SetMode(os.ModeDir | 0755)
+	_, err := w.zw.CreateHeader(fh)
+	return err
+}
+
+func (w *zipWriter) addFile(ctx context.Context, o fs.Object, meta fs.Metadata, in io.Reader) error {
+	fh := &zip.FileHeader{
+		Name:     o.Remote(),
+		Method:   zip.Deflate,
+		Modified: o.ModTime(ctx),
+	}
+	fh.SetMode(fileMode(meta, 0644))
+	if len(meta) > 0 {
+		comment, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		fh.Comment = string(comment)
+	}
+	out, err := w.zw.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(out, in, o.Size())
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+// addObject adds o to the archive w
+func addObject(ctx context.Context, w archiveWriter, o fs.Object) (err error) {
+	ci := fs.GetConfig(ctx)
+	if o.Size() < 0 {
+		return fmt.Errorf("can't archive %q as its size is unknown", o.Remote())
+	}
+	var meta fs.Metadata
+	if ci.Metadata {
+		meta, err = fs.GetMetadata(ctx, o)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata of %q: %w", o.Remote(), err)
+		}
+	}
+	in, err := operations.Open(ctx, o)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", o.Remote(), err)
+	}
+	defer fs.CheckClose(in, &err)
+	err = w.addFile(ctx, o, meta, in)
+	if err != nil {
+		return fmt.Errorf("failed to add %q to archive: %w", o.Remote(), err)
+	}
+	fs.Debugf(o, "Added to archive")
+	return nil
+}
+
+// writeArchive writes an archive of the files in fsrc to out
+func writeArchive(ctx context.Context, fsrc fs.Fs, out io.Writer, format string) (err error) {
+	w, err := newArchiveWriter(out, format)
+	if err != nil {
+		return err
+	}
+	err = walk.Walk(ctx, fsrc, "", false, -1, func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			switch x := entry.(type) {
+			case fs.Object:
+				err = addObject(ctx, w, x)
+			case fs.Directory:
+				err = w.addDir(ctx, x)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// Create makes an archive in format of the files in fsrc and uploads
+// it to dstFileName in fdst.
+func Create(ctx context.Context, fsrc fs.Fs, fdst fs.Fs, dstFileName string, format string) error {
+	pipeReader, pipeWriter := io.Pipe()
+	errChan := make(chan error, 1)
+	go func() {
+		err := writeArchive(ctx, fsrc, pipeWriter, format)
+		_ = pipeWriter.CloseWithError(err)
+		errChan <- err
+	}()
+	_, err := operations.Rcat(ctx, fdst, dstFileName, pipeReader, time.Now(), nil)
+	// Stop the writer if the upload failed
+	_ = pipeReader.CloseWithError(err)
+	if writeErr := <-errChan; writeErr != nil {
+		return writeErr
+	}
+	return err
+}
@@ -0,0 +1,275 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// archiveEntry is an entry read from an archive
+type archiveEntry struct {
+	name    string // as stored in the archive
+	isDir   bool
+	size    int64
+	modTime time.Time
+	meta    fs.Metadata
+	open    func() (io.ReadCloser, error) // nil if not a file or directory
+}
+
+// forEachEntry calls fn for each entry in the archive o of format
+func forEachEntry(ctx context.Context, o fs.Object, format string, fn func(entry *archiveEntry) error) (err error) {
+	switch format {
+	case FormatTar, FormatTarGz:
+		return forEachTarEntry(ctx, o, format, fn)
+	case FormatZip:
+		return forEachZipEntry(ctx, o, fn)
+	}
+	return fmt.Errorf("unknown archive format %q", format)
+}
+
+// forEachTarEntry calls fn for each entry in a tar archive
+func forEachTarEntry(ctx context.Context, o fs.Object, format string, fn func(entry *archiveEntry) error) (err error) {
+	in, err := operations.Open(ctx, o)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer fs.CheckClose(in, &err)
+	var r io.Reader = in
+	if format == FormatTarGz {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip header: %w", err)
+		}
+		defer fs.CheckClose(gz, &err)
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		entry := &archiveEntry{
+			name:    hdr.Name,
+			size:    hdr.Size,
+			modTime: hdr.ModTime,
+		}
+		for k, v := range hdr.PAXRecords {
+			if strings.HasPrefix(k, paxMetadataPrefix) {
+				if entry.meta == nil {
+					entry.meta = make(fs.Metadata)
+				}
+				entry.meta[k[len(paxMetadataPrefix):]] = v
+			}
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entry.isDir = true
+			entry.size = -1
+			entry.open = func() (io.ReadCloser, error) { return nil, nil }
+		case tar.TypeReg, tar.TypeRegA:
+			entry.open = func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		}
+		err = fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// forEachZipEntry calls fn for each entry in a zip archive
+func forEachZipEntry(ctx context.Context, o fs.Object, fn func(entry *archiveEntry) error) (err error) {
+	ra := newObjectReaderAt(ctx, o)
+	defer fs.CheckClose(ra, &err)
+	zr, err := zip.NewReader(ra, o.Size())
+	if err != nil {
+		return fmt.Errorf("failed to read zip directory: %w", err)
+	}
+	for _, file := range zr.File {
+		file := file
+		mode := file.Mode()
+		entry := &archiveEntry{
+			name:    file.Name,
+			size:    int64(file.UncompressedSize64),
+			modTime: file.Modified,
+		}
+		if file.Comment != "" {
+			if err := json.Unmarshal([]byte(file.Comment), &entry.meta); err != nil {
+				fs.Debugf(o, "Ignoring comment on %q which isn't metadata: %v", file.Name, err)
+				entry.meta = nil
+			}
+		}
+		switch {
+		case mode.IsDir():
+			entry.isDir = true
+			entry.size = -1
+			entry.open = func() (io.ReadCloser, error) { return nil, nil }
+		case mode.IsRegular():
+			entry.open = file.Open
+		}
+		err = fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errUnsafeName is returned for names which would be extracted
+// outside the destination
+var errUnsafeName = errors.New("name is outside the destination")
+
+// cleanName returns the name to extract an entry to or an error if
+// it would be outside the destination.
+func cleanName(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if path.IsAbs(name) {
+		return "", errUnsafeName
+	}
+	name = path.Clean(name)
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", errUnsafeName
+	}
+	if name == "." {
+		return "", nil
+	}
+	return name, nil
+}
+
+// Extract extracts the files in the archive o of format into fdst.
+func Extract(ctx context.Context, o fs.Object, format string, fdst fs.Fs) error {
+	ci := fs.GetConfig(ctx)
+	fi := filter.GetConfig(ctx)
+	return forEachEntry(ctx, o, format, func(entry *archiveEntry) error {
+		name, err := cleanName(entry.name)
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(o, "Skipping %q: %v", entry.name, err)
+			return nil
+		}
+		if entry.open == nil {
+			err = fs.CountError(errors.New("not a file or directory"))
+			fs.Errorf(o, "Skipping %q: %v", entry.name, err)
+			return nil
+		}
+		if name == "" {
+			return nil
+		}
+		if entry.isDir {
+			include, err := fi.IncludeDirectory(ctx, fdst)(name)
+			if err != nil || !include {
+				return err
+			}
+			return operations.Mkdir(ctx, fdst, name)
+		}
+		meta := entry.meta
+		if !ci.Metadata {
+			meta = nil
+		}
+		if !fi.Include(name, entry.size, entry.modTime, meta) {
+			fs.Debugf(name, "Excluded from extraction")
+			return nil
+		}
+		in, err := entry.open()
+		if err != nil {
+			return fmt.Errorf("failed to read %q from archive: %w", entry.name, err)
+		}
+		_, err = operations.RcatSize(ctx, fdst, name, in, entry.size, entry.modTime, meta)
+		closeErr := in.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %q: %w", entry.name, err)
+		}
+		return nil
+	})
+}
+
+// List writes a listing of the archive o of format to out.
+func List(ctx context.Context, o fs.Object, format string, out io.Writer) error {
+	return forEachEntry(ctx, o, format, func(entry *archiveEntry) error {
+		name := entry.name
+		if entry.isDir && !strings.HasSuffix(name, "/") {
+			name += "/"
+		}
+		_, err := fmt.Fprintf(out, "%9d %s %s\n", entry.size, entry.modTime.Local().Format("2006-01-02 15:04:05.000000000"), name)
+		return err
+	})
+}
+
+// objectReaderAt reads an object at arbitrary offsets using range
+// requests.
+//
+// Reads which follow on from the previous one reuse the open stream
+// so reading sequentially only opens the object once.
+type objectReaderAt struct {
+	ctx context.Context
+	o   fs.Object
+	mu  sync.Mutex
+	in  io.ReadCloser // current stream or nil
+	pos int64         // offset of in
+}
+
+func newObjectReaderAt(ctx context.Context, o fs.Object) *objectReaderAt {
+	return &objectReaderAt{ctx: ctx, o: o}
+}
+
+// closeStream closes the current stream if any - call with mu held
+func (r *objectReaderAt) closeStream() error {
+	if r.in == nil {
+		return nil
+	}
+	err := r.in.Close()
+	r.in = nil
+	return err
+}
+
+// ReadAt reads len(p) bytes from offset off
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if off >= r.o.Size() {
+		return 0, io.EOF
+	}
+	if r.in == nil || r.pos != off {
+		_ = r.closeStream()
+		r.in, err = operations.Open(r.ctx, r.o, &fs.RangeOption{Start: off, End: -1})
+		if err != nil {
+			return 0, err
+		}
+		r.pos = off
+	}
+	n, err = io.ReadFull(r.in, p)
+	r.pos += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	if err != nil {
+		_ = r.closeStream()
+	}
+	return n, err
+}
+
+// Close the reader
+func (r *objectReaderAt) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeStream()
+}
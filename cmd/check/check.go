@@ -3,6 +3,7 @@ package check
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -27,7 +28,10 @@ var (
 	match             = ""
 	differ            = ""
 	errFile           = ""
+	outputFormat      = "text"
 	checkFileHashType = ""
+	manifests         = false
+	manifestName      = operations.DefaultManifestName
 )
 
 func init() {
@@ -35,6 +39,8 @@ func init() {
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &download, "download", "", download, "Check by downloading rather than with hash", "")
 	flags.StringVarP(cmdFlags, &checkFileHashType, "checkfile", "C", checkFileHashType, "Treat source:path as a SUM file with hashes of given type", "")
+	flags.BoolVarP(cmdFlags, &manifests, "manifests", "", manifests, "Check the files in each directory of remote:path against the manifests there", "")
+	flags.StringVarP(cmdFlags, &manifestName, "manifest-name", "", manifestName, "Name of the manifests without their extension", "")
 	AddFlags(cmdFlags)
 }
 
@@ -47,6 +53,7 @@ func AddFlags(cmdFlags *pflag.FlagSet) {
 	flags.StringVarP(cmdFlags, &match, "match", "", match, "Report all matching files to this file", "")
 	flags.StringVarP(cmdFlags, &differ, "differ", "", differ, "Report all non-matching files to this file", "")
 	flags.StringVarP(cmdFlags, &errFile, "error", "", errFile, "Report all files with errors (hashing or reading) to this file", "")
+	flags.StringVarP(cmdFlags, &outputFormat, "output-format", "", outputFormat, "Format of the reports text|json", "")
 }
 
 // FlagsHelp describes the flags for the help
@@ -73,6 +80,21 @@ you what happened to it. These are reminiscent of diff files.
 - |* path| means path was present in source and destination but different.
 - |! path| means there was an error reading or hashing the source or dest.
 
+If you supply |--output-format json| then instead of paths the reports
+contain one JSON object per line for each file, for example
+
+    {"path":"file.txt","action":"differ","reason":"hash","srcSize":6,"dstSize":6,"hashType":"md5","srcHash":"b1946ac92492d2347c6235b4d2611184","dstHash":"09f7e02f1290be211da707a266f153b3","start":"2023-10-27T12:00:00.123Z","elapsed":0.0012}
+
+The |action| is one of |match|, |differ|, |missing-on-src|,
+|missing-on-dst| or |error|. For files which differ |reason| is
+|size|, |hash| or, with |--download|, |contents|. The sizes and hashes
+are included where known, |error| contains the error for files with
+errors and |elapsed| is the time taken to check the file in seconds.
+
+If none of the report flags are given with |--output-format json| then
+the combined report is written to stdout. This can't be used with
+|--progress| as that writes to stdout too.
+
 The default number of parallel checks is 8. See the [--checkers=N](/docs/#checkers-n)
 option for more information.
 `, "|", "`")
@@ -87,6 +109,27 @@ func GetCheckOpt(fsrc, fdst fs.Fs) (opt *operations.CheckOpt, close func(), err
 		OneWay: oneway,
 	}
 
+	switch outputFormat {
+	case "text":
+	case "json":
+		opt.JSON = true
+		toStdout := false
+		if combined == "" && missingOnSrc == "" && missingOnDst == "" && match == "" && differ == "" && errFile == "" {
+			opt.Combined = os.Stdout
+			toStdout = true
+		}
+		for _, name := range []string{combined, missingOnSrc, missingOnDst, match, differ, errFile} {
+			if name == "-" {
+				toStdout = true
+			}
+		}
+		if toStdout && fs.GetConfig(context.Background()).Progress {
+			return nil, nil, errors.New("--output-format json can't be used with --progress when writing the reports to stdout")
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown --output-format %q - must be text or json", outputFormat)
+	}
+
 	open := func(name string, pout *io.Writer) error {
 		if name == "" {
 			return nil
@@ -136,7 +179,7 @@ func GetCheckOpt(fsrc, fdst fs.Fs) (opt *operations.CheckOpt, close func(), err
 }
 
 var commandDefinition = &cobra.Command{
-	Use:   "check source:path dest:path",
+	Use:   "check source:path dest:path | --manifests remote:path",
 	Short: `Checks the files in the source and destination match.`,
 	Long: strings.ReplaceAll(`
 Checks the files in the source and destination match.  It compares
@@ -157,11 +200,38 @@ to check all the data.
 
 If you supply the |--checkfile HASH| flag with a valid hash name,
 the |source:path| must point to a text file in the SUM format.
+
+If you supply the |--manifests| flag then only one |remote:path| is
+needed. The files in each directory are checked against the manifests
+in that directory, such as those written by
+|rclone hashsum --manifests|. Manifests are recognised by their name,
+|checksums| unless set with |--manifest-name|, and their extension
+which is either the name of a hash for manifests in the format of
+md5sum/sha1sum (e.g. |checksums.md5|, |checksums.sha256|), |.bsd| for
+manifests in the format of BSD md5 or |.sfv| for Simple File
+Verification manifests. A directory may have manifests for several
+hashes and all of them are checked while reading each file at most
+once. Files not in any manifest are reported as missing on the source
+and files in a manifest but not in the directory as missing on the
+destination.
 `, "|", "`") + FlagsHelp,
 	Annotations: map[string]string{
 		"groups": "Filter,Listing,Check",
 	},
 	RunE: func(command *cobra.Command, args []string) error {
+		if manifests {
+			cmd.CheckArgs(1, 1, command, args)
+			f := cmd.NewFsDir(args)
+			cmd.Run(false, true, command, func() error {
+				opt, close, err := GetCheckOpt(nil, f)
+				if err != nil {
+					return err
+				}
+				defer close()
+				return operations.CheckManifests(context.Background(), f, manifestName, opt, download)
+			})
+			return nil
+		}
 		cmd.CheckArgs(2, 2, command, args)
 		var (
 			fsrc, fdst fs.Fs
@@ -36,6 +36,7 @@ import (
 	"github.com/rclone/rclone/fs/rc/rcflags"
 	"github.com/rclone/rclone/fs/rc/rcserver"
 	fssync "github.com/rclone/rclone/fs/sync"
+	"github.com/rclone/rclone/fs/tracing"
 	"github.com/rclone/rclone/lib/atexit"
 	"github.com/rclone/rclone/lib/buildinfo"
 	"github.com/rclone/rclone/lib/exitcode"
@@ -419,6 +420,17 @@ func initConfig() {
 		log.Fatalf("Failed to load filters: %v", err)
 	}
 
+	// Start tracing if configured
+	stopTracing, err := tracing.Start(ctx)
+	if err != nil {
+		log.Fatalf("Failed to start tracing: %v", err)
+	}
+	atexit.Register(func() {
+		if err := stopTracing(context.Background()); err != nil {
+			fs.Errorf(nil, "Failed to stop tracing: %v", err)
+		}
+	})
+
 	// Write the args for debug purposes
 	fs.Debugf("rclone", "Version %q starting with parameters %q", fs.Version, os.Args)
 
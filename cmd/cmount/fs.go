@@ -164,8 +164,7 @@ func (fsys *FS) stat(node vfs.Node, stat *fuse.Stat_t) (errc int) {
 	stat.Ino = node.Inode() // FIXME do we need to set the inode number?
 	stat.Mode = uint32(Mode)
 	stat.Nlink = 1
-	stat.Uid = fsys.VFS.Opt.UID
-	stat.Gid = fsys.VFS.Opt.GID
+	stat.Uid, stat.Gid = node.Owner()
 	//stat.Rdev
 	stat.Size = int64(Size)
 	t := fuse.NewTimespec(modTime)
@@ -195,6 +194,7 @@ func (fsys *FS) Destroy() {
 
 // Getattr reads the attributes for path
 func (fsys *FS) Getattr(path string, stat *fuse.Stat_t, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Getattr")()
 	defer log.Trace(path, "fh=0x%X", fh)("errc=%v", &errc)
 	node, _, errc := fsys.getNode(path, fh)
 	if errc == 0 {
@@ -205,6 +205,7 @@ func (fsys *FS) Getattr(path string, stat *fuse.Stat_t, fh uint64) (errc int) {
 
 // Opendir opens path as a directory
 func (fsys *FS) Opendir(path string) (errc int, fh uint64) {
+	defer mountlib.TimeOp("Opendir")()
 	defer log.Trace(path, "")("errc=%d, fh=0x%X", &errc, &fh)
 	handle, err := fsys.VFS.OpenFile(path, os.O_RDONLY, 0777)
 	if err != nil {
@@ -219,6 +220,7 @@ func (fsys *FS) Readdir(dirPath string,
 	ofst int64,
 	fh uint64) (errc int) {
 	itemsRead := -1
+	defer mountlib.TimeOp("Readdir")()
 	defer log.Trace(dirPath, "ofst=%d, fh=0x%X", ofst, fh)("items=%d, errc=%d", &itemsRead, &errc)
 
 	dir, errc := fsys.lookupDir(dirPath)
@@ -270,12 +272,14 @@ func (fsys *FS) Readdir(dirPath string,
 
 // Releasedir finished reading the directory
 func (fsys *FS) Releasedir(path string, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Releasedir")()
 	defer log.Trace(path, "fh=0x%X", fh)("errc=%d", &errc)
 	return fsys.closeHandle(fh)
 }
 
 // Statfs reads overall stats on the filesystem
 func (fsys *FS) Statfs(path string, stat *fuse.Statfs_t) (errc int) {
+	defer mountlib.TimeOp("Statfs")()
 	defer log.Trace(path, "")("stat=%+v, errc=%d", stat, &errc)
 	const blockSize = 4096
 	total, _, free := fsys.VFS.Statfs()
@@ -295,6 +299,7 @@ func (fsys *FS) Statfs(path string, stat *fuse.Statfs_t) (errc int) {
 
 // OpenEx opens a file
 func (fsys *FS) OpenEx(path string, fi *fuse.FileInfo_t) (errc int) {
+	defer mountlib.TimeOp("OpenEx")()
 	defer log.Trace(path, "flags=0x%X", fi.Flags)("errc=%d, fh=0x%X", &errc, &fi.Fh)
 	fi.Fh = fhUnset
 
@@ -325,6 +330,7 @@ func (fsys *FS) Open(path string, flags int) (errc int, fh uint64) {
 
 // CreateEx creates and opens a file.
 func (fsys *FS) CreateEx(filePath string, mode uint32, fi *fuse.FileInfo_t) (errc int) {
+	defer mountlib.TimeOp("CreateEx")()
 	defer log.Trace(filePath, "flags=0x%X, mode=0%o", fi.Flags, mode)("errc=%d, fh=0x%X", &errc, &fi.Fh)
 	fi.Fh = fhUnset
 	leaf, parentDir, errc := fsys.lookupParentDir(filePath)
@@ -356,6 +362,7 @@ func (fsys *FS) Create(filePath string, flags int, mode uint32) (errc int, fh ui
 
 // Truncate truncates a file to size
 func (fsys *FS) Truncate(path string, size int64, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Truncate")()
 	defer log.Trace(path, "size=%d, fh=0x%X", size, fh)("errc=%d", &errc)
 	node, handle, errc := fsys.getNode(path, fh)
 	if errc != 0 {
@@ -375,6 +382,7 @@ func (fsys *FS) Truncate(path string, size int64, fh uint64) (errc int) {
 
 // Read data from file handle
 func (fsys *FS) Read(path string, buff []byte, ofst int64, fh uint64) (n int) {
+	defer mountlib.TimeOp("Read")()
 	defer log.Trace(path, "ofst=%d, fh=0x%X", ofst, fh)("n=%d", &n)
 	handle, errc := fsys.getHandle(fh)
 	if errc != 0 {
@@ -390,6 +398,7 @@ func (fsys *FS) Read(path string, buff []byte, ofst int64, fh uint64) (n int) {
 
 // Write data to file handle
 func (fsys *FS) Write(path string, buff []byte, ofst int64, fh uint64) (n int) {
+	defer mountlib.TimeOp("Write")()
 	defer log.Trace(path, "ofst=%d, fh=0x%X", ofst, fh)("n=%d", &n)
 	handle, errc := fsys.getHandle(fh)
 	if errc != 0 {
@@ -404,6 +413,7 @@ func (fsys *FS) Write(path string, buff []byte, ofst int64, fh uint64) (n int) {
 
 // Flush flushes an open file descriptor or path
 func (fsys *FS) Flush(path string, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Flush")()
 	defer log.Trace(path, "fh=0x%X", fh)("errc=%d", &errc)
 	handle, errc := fsys.getHandle(fh)
 	if errc != 0 {
@@ -414,6 +424,7 @@ func (fsys *FS) Flush(path string, fh uint64) (errc int) {
 
 // Release closes the file if still open
 func (fsys *FS) Release(path string, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Release")()
 	defer log.Trace(path, "fh=0x%X", fh)("errc=%d", &errc)
 	handle, errc := fsys.getHandle(fh)
 	if errc != 0 {
@@ -425,6 +436,7 @@ func (fsys *FS) Release(path string, fh uint64) (errc int) {
 
 // Unlink removes a file.
 func (fsys *FS) Unlink(filePath string) (errc int) {
+	defer mountlib.TimeOp("Unlink")()
 	defer log.Trace(filePath, "")("errc=%d", &errc)
 	leaf, parentDir, errc := fsys.lookupParentDir(filePath)
 	if errc != 0 {
@@ -435,6 +447,7 @@ func (fsys *FS) Unlink(filePath string) (errc int) {
 
 // Mkdir creates a directory.
 func (fsys *FS) Mkdir(dirPath string, mode uint32) (errc int) {
+	defer mountlib.TimeOp("Mkdir")()
 	defer log.Trace(dirPath, "mode=0%o", mode)("errc=%d", &errc)
 	leaf, parentDir, errc := fsys.lookupParentDir(dirPath)
 	if errc != 0 {
@@ -446,6 +459,7 @@ func (fsys *FS) Mkdir(dirPath string, mode uint32) (errc int) {
 
 // Rmdir removes a directory
 func (fsys *FS) Rmdir(dirPath string) (errc int) {
+	defer mountlib.TimeOp("Rmdir")()
 	defer log.Trace(dirPath, "")("errc=%d", &errc)
 	leaf, parentDir, errc := fsys.lookupParentDir(dirPath)
 	if errc != 0 {
@@ -456,6 +470,7 @@ func (fsys *FS) Rmdir(dirPath string) (errc int) {
 
 // Rename renames a file.
 func (fsys *FS) Rename(oldPath string, newPath string) (errc int) {
+	defer mountlib.TimeOp("Rename")()
 	defer log.Trace(oldPath, "newPath=%q", newPath)("errc=%d", &errc)
 	return translateError(fsys.VFS.Rename(oldPath, newPath))
 }
@@ -467,6 +482,7 @@ var invalidDateCutoff = time.Date(1601, 1, 2, 0, 0, 0, 0, time.UTC)
 
 // Utimens changes the access and modification times of a file.
 func (fsys *FS) Utimens(path string, tmsp []fuse.Timespec) (errc int) {
+	defer mountlib.TimeOp("Utimens")()
 	defer log.Trace(path, "tmsp=%+v", tmsp)("errc=%d", &errc)
 	node, errc := fsys.lookupNode(path)
 	if errc != 0 {
@@ -487,12 +503,14 @@ func (fsys *FS) Utimens(path string, tmsp []fuse.Timespec) (errc int) {
 
 // Mknod creates a file node.
 func (fsys *FS) Mknod(path string, mode uint32, dev uint64) (errc int) {
+	defer mountlib.TimeOp("Mknod")()
 	defer log.Trace(path, "mode=0x%X, dev=0x%X", mode, dev)("errc=%d", &errc)
 	return -fuse.ENOSYS
 }
 
 // Fsync synchronizes file contents.
 func (fsys *FS) Fsync(path string, datasync bool, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Fsync")()
 	defer log.Trace(path, "datasync=%v, fh=0x%X", datasync, fh)("errc=%d", &errc)
 	// This is a no-op for rclone
 	return 0
@@ -500,38 +518,60 @@ func (fsys *FS) Fsync(path string, datasync bool, fh uint64) (errc int) {
 
 // Link creates a hard link to a file.
 func (fsys *FS) Link(oldpath string, newpath string) (errc int) {
+	defer mountlib.TimeOp("Link")()
 	defer log.Trace(oldpath, "newpath=%q", newpath)("errc=%d", &errc)
 	return -fuse.ENOSYS
 }
 
 // Symlink creates a symbolic link.
 func (fsys *FS) Symlink(target string, newpath string) (errc int) {
+	defer mountlib.TimeOp("Symlink")()
 	defer log.Trace(target, "newpath=%q", newpath)("errc=%d", &errc)
 	return -fuse.ENOSYS
 }
 
 // Readlink reads the target of a symbolic link.
 func (fsys *FS) Readlink(path string) (errc int, linkPath string) {
+	defer mountlib.TimeOp("Readlink")()
 	defer log.Trace(path, "")("linkPath=%q, errc=%d", &linkPath, &errc)
 	return -fuse.ENOSYS, ""
 }
 
 // Chmod changes the permission bits of a file.
 func (fsys *FS) Chmod(path string, mode uint32) (errc int) {
+	defer mountlib.TimeOp("Chmod")()
 	defer log.Trace(path, "mode=0%o", mode)("errc=%d", &errc)
-	// This is a no-op for rclone
-	return 0
+	node, errc := fsys.lookupNode(path)
+	if errc != 0 {
+		return errc
+	}
+	// This is a no-op unless --vfs-metadata-perms is set
+	return translateError(node.Chmod(os.FileMode(mode).Perm()))
 }
 
 // Chown changes the owner and group of a file.
 func (fsys *FS) Chown(path string, uid uint32, gid uint32) (errc int) {
+	defer mountlib.TimeOp("Chown")()
 	defer log.Trace(path, "uid=%d, gid=%d", uid, gid)("errc=%d", &errc)
-	// This is a no-op for rclone
-	return 0
+	node, errc := fsys.lookupNode(path)
+	if errc != 0 {
+		return errc
+	}
+	// uid or gid of ^uint32(0) means don't change it
+	newUID, newGID := -1, -1
+	if uid != ^uint32(0) {
+		newUID = int(uid)
+	}
+	if gid != ^uint32(0) {
+		newGID = int(gid)
+	}
+	// This is a no-op unless --vfs-metadata-perms is set
+	return translateError(node.Chown(newUID, newGID))
 }
 
 // Access checks file access permissions.
 func (fsys *FS) Access(path string, mask uint32) (errc int) {
+	defer mountlib.TimeOp("Access")()
 	defer log.Trace(path, "mask=0%o", mask)("errc=%d", &errc)
 	// This is a no-op for rclone
 	return 0
@@ -539,6 +579,7 @@ func (fsys *FS) Access(path string, mask uint32) (errc int) {
 
 // Fsyncdir synchronizes directory contents.
 func (fsys *FS) Fsyncdir(path string, datasync bool, fh uint64) (errc int) {
+	defer mountlib.TimeOp("Fsyncdir")()
 	defer log.Trace(path, "datasync=%v, fh=0x%X", datasync, fh)("errc=%d", &errc)
 	// This is a no-op for rclone
 	return 0
@@ -546,24 +587,28 @@ func (fsys *FS) Fsyncdir(path string, datasync bool, fh uint64) (errc int) {
 
 // Setxattr sets extended attributes.
 func (fsys *FS) Setxattr(path string, name string, value []byte, flags int) (errc int) {
+	defer mountlib.TimeOp("Setxattr")()
 	defer log.Trace(path, "name=%q, value=%q, flags=%d", name, value, flags)("errc=%d", &errc)
 	return -fuse.ENOSYS
 }
 
 // Getxattr gets extended attributes.
 func (fsys *FS) Getxattr(path string, name string) (errc int, value []byte) {
+	defer mountlib.TimeOp("Getxattr")()
 	defer log.Trace(path, "name=%q", name)("errc=%d, value=%q", &errc, &value)
 	return -fuse.ENOSYS, nil
 }
 
 // Removexattr removes extended attributes.
 func (fsys *FS) Removexattr(path string, name string) (errc int) {
+	defer mountlib.TimeOp("Removexattr")()
 	defer log.Trace(path, "name=%q", name)("errc=%d", &errc)
 	return -fuse.ENOSYS
 }
 
 // Listxattr lists extended attributes.
 func (fsys *FS) Listxattr(path string, fill func(name string) bool) (errc int) {
+	defer mountlib.TimeOp("Listxattr")()
 	defer log.Trace(path, "fill=%p", fill)("errc=%d", &errc)
 	return -fuse.ENOSYS
 }
@@ -571,6 +616,7 @@ func (fsys *FS) Listxattr(path string, fill func(name string) bool) (errc int) {
 // Getpath allows a case-insensitive file system to report the correct case of
 // a file path.
 func (fsys *FS) Getpath(path string, fh uint64) (errc int, normalisedPath string) {
+	defer mountlib.TimeOp("Getpath")()
 	defer log.Trace(path, "Getpath fh=%d", fh)("errc=%d, normalisedPath=%q", &errc, &normalisedPath)
 	node, _, errc := fsys.getNode(path, fh)
 	if errc != 0 {
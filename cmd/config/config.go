@@ -33,6 +33,7 @@ func init() {
 	configCommand.AddCommand(configUpdateCommand)
 	configCommand.AddCommand(configDeleteCommand)
 	configCommand.AddCommand(configPasswordCommand)
+	configCommand.AddCommand(configEncryptSecretsCommand)
 	configCommand.AddCommand(configReconnectCommand)
 	configCommand.AddCommand(configDisconnectCommand)
 	configCommand.AddCommand(configUserInfoCommand)
@@ -376,6 +377,29 @@ var configDeleteCommand = &cobra.Command{
 	},
 }
 
+var configEncryptSecretsCommand = &cobra.Command{
+	Use:   "encrypt-secrets",
+	Short: `Encrypt the passwords and tokens in the config file.`,
+	Long: strings.ReplaceAll(`
+Encrypt the passwords, OAuth tokens and client secrets in the config
+file with the secrets key leaving the rest of the config readable.
+
+The secrets key is read from the file given with |--secrets-key-file|
+or from the output of |--secrets-key-command|. When either of these are
+set, secrets are encrypted whenever they are written to the config, so
+this command is only needed to encrypt the secrets already there.
+
+    rclone config encrypt-secrets --secrets-key-file ~/.rclone-secrets.key
+`, "|", "`"),
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+	},
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(0, 0, command, args)
+		return config.EncryptSecrets()
+	},
+}
+
 var configPasswordCommand = &cobra.Command{
 	Use:   "password name [key value]+",
 	Short: `Update password in an existing remote.`,
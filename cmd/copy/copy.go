@@ -3,6 +3,7 @@ package copy
 
 import (
 	"context"
+	"log"
 	"strings"
 
 	"github.com/rclone/rclone/cmd"
@@ -20,6 +21,7 @@ func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &createEmptySrcDirs, "create-empty-src-dirs", "", createEmptySrcDirs, "Create empty source dirs on destination after copy", "")
+	cmd.AddOutputFormatFlag(cmdFlags)
 }
 
 var commandDefinition = &cobra.Command{
@@ -82,19 +84,23 @@ recently very efficiently like this:
 **Note**: Use the |-P|/|--progress| flag to view real-time transfer statistics.
 
 **Note**: Use the |--dry-run| or the |--interactive|/|-i| flag to test without copying anything.
-`, "|", "`"),
+`, "|", "`") + "\n" + cmd.OutputFormatHelp,
 	Annotations: map[string]string{
 		"groups": "Copy,Filter,Listing,Important",
 	},
 	Run: func(command *cobra.Command, args []string) {
 
 		cmd.CheckArgs(2, 2, command, args)
+		ctx, err := cmd.OutputContext(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
 		fsrc, srcFileName, fdst := cmd.NewFsSrcFileDst(args)
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
-				return sync.CopyDir(context.Background(), fdst, fsrc, createEmptySrcDirs)
+				return sync.CopyDir(ctx, fdst, fsrc, createEmptySrcDirs)
 			}
-			return operations.CopyFile(context.Background(), fdst, fsrc, srcFileName, srcFileName)
+			return operations.CopyFile(ctx, fdst, fsrc, srcFileName, srcFileName)
 		})
 	},
 }
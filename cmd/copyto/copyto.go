@@ -3,6 +3,7 @@ package copyto
 
 import (
 	"context"
+	"log"
 
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/fs/operations"
@@ -12,6 +13,7 @@ import (
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
+	cmd.AddOutputFormatFlag(commandDefinition.Flags())
 }
 
 var commandDefinition = &cobra.Command{
@@ -45,19 +47,23 @@ by size and modification time or MD5SUM.  It doesn't delete files from
 the destination.
 
 **Note**: Use the ` + "`-P`" + `/` + "`--progress`" + ` flag to view real-time transfer statistics
-`,
+` + "\n" + cmd.OutputFormatHelp,
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.35",
 		"groups":            "Copy,Filter,Listing,Important",
 	},
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
+		ctx, err := cmd.OutputContext(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
 		fsrc, srcFileName, fdst, dstFileName := cmd.NewFsSrcDstFiles(args)
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
-				return sync.CopyDir(context.Background(), fdst, fsrc, false)
+				return sync.CopyDir(ctx, fdst, fsrc, false)
 			}
-			return operations.CopyFile(context.Background(), fdst, fsrc, dstFileName, srcFileName)
+			return operations.CopyFile(ctx, fdst, fsrc, dstFileName, srcFileName)
 		})
 	},
 }
@@ -134,6 +134,9 @@ For example, to rename all the identically named photos in your Google Photos di
 Or
 
     rclone dedupe rename "drive:Google Photos"
+
+To report files with identical contents across one or more remotes
+without changing anything see ` + "`rclone dedupe report`" + `.
 `,
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.27",
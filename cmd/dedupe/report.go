@@ -0,0 +1,169 @@
+package dedupe
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportHash   = hash.None
+	reportFormat = "text"
+	reportLink   = false
+)
+
+func init() {
+	commandDefinition.AddCommand(reportCommand)
+	cmdFlags := reportCommand.Flags()
+	flags.FVarP(cmdFlags, &reportHash, "hash", "", "Use this hash to find duplicates, e.g. MD5|SHA-1 - default is one supported by all the remotes", "")
+	flags.StringVarP(cmdFlags, &reportFormat, "format", "", reportFormat, "Output format text|json|csv", "")
+	flags.BoolVarP(cmdFlags, &reportLink, "link", "", reportLink, "Replace duplicates with hard links or shortcuts where the backend supports it", "")
+}
+
+var reportCommand = &cobra.Command{
+	Use:   "report remote:path [remote:path]...",
+	Short: `Report files with identical contents across one or more remotes.`,
+	Long: `
+This finds files with identical contents in all the remotes given,
+groups them and reports how much space the duplicates are wasting.
+Unlike ` + "`rclone dedupe --by-hash`" + ` it doesn't delete anything
+unless asked to and it can compare files in different remotes.
+
+Files are considered identical if they have the same size and the same
+hash. The hash used is one supported by all the remotes unless
+` + "`--hash`" + ` is given. Empty files are ignored. Files found twice
+because the remotes overlap are only counted once, and files which are
+already hard links to each other aren't duplicates. On SFTP remotes
+hard links can only be found if the remote runs a unix shell.
+
+The groups are listed with the most wasted space first. The first file
+in each group is the one which would be kept.
+
+    $ rclone dedupe report drive:photos /mnt/backup/photos
+    MD5 1eedaa9fe86fd4b8632e2ac549403b36 size 6048320 copies 3 wasted 11.536Mi
+      drive:photos/one.jpg
+      drive:photos/2016/one.jpg
+      /mnt/backup/photos/one.jpg
+
+    Found 2 duplicates of 1 files wasting 11.536Mi in 154 files of 1.203Gi
+
+Use ` + "`--format json`" + ` or ` + "`--format csv`" + ` for output
+which can be read by other programs. The CSV output has one line per
+file with the columns hash, size, wasted, remote and modtime.
+
+If ` + "`--link`" + ` is passed then each duplicate is replaced with a
+link to the first file in its group where the backend supports it.
+Duplicates on the local backend with
+` + "`--local-copy-is-hardlink`" + ` or the SFTP backend with
+` + "`--sftp-copy-is-hardlink`" + ` are replaced with hard links and
+duplicates on backends with a ` + "`shortcut`" + ` backend command,
+such as Google Drive, are replaced with shortcuts. Duplicates on a
+different remote from the first file in their group are only reported.
+The duplicate is only removed once the link has been made.
+
+**Important**: Since ` + "`--link`" + ` deletes files, test first with
+the ` + "`--dry-run` or the `--interactive`/`-i`" + ` flag.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+	},
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1e6, command, args)
+		if reportFormat != "text" && reportFormat != "json" && reportFormat != "csv" {
+			log.Fatalf("Unknown --format %q - must be text, json or csv", reportFormat)
+		}
+		fses := make([]fs.Fs, len(args))
+		for i := range args {
+			fses[i] = cmd.NewFsDir(args[i : i+1])
+		}
+		cmd.Run(false, false, command, func() error {
+			ctx := context.Background()
+			report, err := operations.DedupeReport(ctx, fses, reportHash)
+			if err != nil {
+				return err
+			}
+			if reportLink {
+				replaced, saved, err := operations.DedupeLink(ctx, report)
+				fs.Logf(nil, "Replaced %d duplicates with links saving %v", replaced, fs.SizeSuffix(saved))
+				if err != nil {
+					return err
+				}
+			}
+			return writeReport(os.Stdout, report, reportFormat)
+		})
+	},
+}
+
+// writeReport writes the report to out in the format given
+func writeReport(out io.Writer, report *operations.DuplicateReport, format string) error {
+	switch format {
+	case "text":
+		return writeReportText(out, report)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	case "csv":
+		return writeReportCSV(out, report)
+	}
+	return errors.New("unknown report format")
+}
+
+func writeReportText(out io.Writer, report *operations.DuplicateReport) error {
+	for _, group := range report.Groups {
+		_, err := fmt.Fprintf(out, "%s %s size %d copies %d wasted %v\n", report.HashType, group.Hash, group.Size, len(group.Files), fs.SizeSuffix(group.Wasted))
+		if err != nil {
+			return err
+		}
+		for _, file := range group.Files {
+			_, err = fmt.Fprintf(out, "  %s\n", file.Remote)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintln(out)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(out, "Found %d duplicates of %d files wasting %v in %d files of %v\n", report.Duplicates, len(report.Groups), fs.SizeSuffix(report.Wasted), report.Files, fs.SizeSuffix(report.Bytes))
+	return err
+}
+
+func writeReportCSV(out io.Writer, report *operations.DuplicateReport) error {
+	w := csv.NewWriter(out)
+	err := w.Write([]string{"hash", "size", "wasted", "remote", "modtime"})
+	if err != nil {
+		return err
+	}
+	for _, group := range report.Groups {
+		for _, file := range group.Files {
+			err = w.Write([]string{
+				group.Hash,
+				strconv.FormatInt(group.Size, 10),
+				strconv.FormatInt(group.Wasted, 10),
+				file.Remote,
+				file.ModTime.Format(time.RFC3339Nano),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
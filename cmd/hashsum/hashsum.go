@@ -3,8 +3,10 @@ package hashsum
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/fs"
@@ -23,10 +25,20 @@ var (
 	ChecksumFile   = ""
 )
 
+// Flags for writing manifests
+var (
+	manifests      = false
+	manifestFormat = operations.ManifestGNU
+	manifestName   = operations.DefaultManifestName
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	AddHashsumFlags(cmdFlags)
+	flags.BoolVarP(cmdFlags, &manifests, "manifests", "", manifests, "Write a manifest of the hashes into each directory of the remote", "")
+	flags.StringVarP(cmdFlags, &manifestFormat, "manifest-format", "", manifestFormat, "Format of the manifests gnu|bsd|sfv", "")
+	flags.StringVarP(cmdFlags, &manifestName, "manifest-name", "", manifestName, "Name of the manifests without their extension", "")
 }
 
 // AddHashsumFlags is a convenience function to add the command flags OutputBase64 and DownloadFlag to hashsum, md5sum, sha1sum
@@ -111,6 +123,40 @@ Then
     $ rclone hashsum MD5 remote:path
 
 Note that hash names are case insensitive and values are output in lower case.
+
+### Manifests
+
+Use ` + "`--manifests`" + ` to write the hashes into a manifest in each
+directory of the remote, rather than printing them. Each manifest
+lists the files in its own directory only. This is unlike
+` + "`--output-file`" + ` which writes the hashes of all the files to a
+single local file, so the two can't be used together. Several hashes
+can be given separated by commas, e.g.
+
+    $ rclone hashsum MD5,SHA256 --manifests remote:path
+
+The format is set with ` + "`--manifest-format`" + `:
+
+- ` + "`gnu`" + ` (the default) writes one manifest for each hash in the
+  format of md5sum/sha1sum named after the hash, e.g. ` + "`checksums.md5`" + `
+  and ` + "`checksums.sha256`" + `.
+- ` + "`bsd`" + ` writes all the hashes to ` + "`checksums.bsd`" + ` in the
+  format of BSD md5 with lines like ` + "`MD5 (file.txt) = d41d8cd98f00b204e9800998ecf8427e`" + `.
+- ` + "`sfv`" + ` writes CRC32 hashes to ` + "`checksums.sfv`" + ` in Simple
+  File Verification format.
+
+Use ` + "`--manifest-name`" + ` to call the manifests something other
+than ` + "`checksums`" + `. Directories with no files don't get a manifest.
+If any file in a directory can't be hashed, the manifests in that
+directory aren't written, so existing manifests aren't replaced with
+incomplete ones, and the command returns an error.
+
+The manifests can be verified with
+[rclone check --manifests](/commands/rclone_check/) or with standard
+tools.
+
+Hashes the remote doesn't support are calculated by downloading the
+files, reading each file only once however many hashes are needed.
 `,
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.41",
@@ -122,6 +168,31 @@ Note that hash names are case insensitive and values are output in lower case.
 			fmt.Print(hash.HelpString(0))
 			return nil
 		}
+		if manifests {
+			if HashsumOutfile != "" || ChecksumFile != "" || OutputBase64 {
+				return errors.New("--manifests can't be used with --output-file, --checkfile or --base64")
+			}
+			var hashes []hash.Type
+			for _, name := range strings.Split(args[0], ",") {
+				var ht hash.Type
+				if err := ht.Set(strings.TrimSpace(name)); err != nil {
+					fmt.Println(hash.HelpString(0))
+					return err
+				}
+				hashes = append(hashes, ht)
+			}
+			cmd.CheckArgs(2, 2, command, args)
+			f := cmd.NewFsDir(args[1:])
+			cmd.Run(true, false, command, func() error {
+				return operations.WriteManifests(context.Background(), f, &operations.ManifestOpt{
+					Name:     manifestName,
+					Format:   manifestFormat,
+					Hashes:   hashes,
+					Download: DownloadFlag,
+				})
+			})
+			return nil
+		}
 		var ht hash.Type
 		err := ht.Set(args[0])
 		if err != nil {
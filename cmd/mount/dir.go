@@ -30,6 +30,7 @@ var _ fusefs.Node = (*Dir)(nil)
 
 // Attr updates the attributes of a directory
 func (d *Dir) Attr(ctx context.Context, a *fuse.Attr) (err error) {
+	defer mountlib.TimeOp("Attr")()
 	defer log.Trace(d, "")("attr=%+v, err=%v", a, &err)
 	a.Valid = d.fsys.opt.AttrTimeout
 	a.Gid = d.VFS().Opt.GID
@@ -49,6 +50,7 @@ var _ fusefs.NodeSetattrer = (*Dir)(nil)
 
 // Setattr handles attribute changes from FUSE. Currently supports ModTime only.
 func (d *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) (err error) {
+	defer mountlib.TimeOp("Setattr")()
 	defer log.Trace(d, "stat=%+v", req)("err=%v", &err)
 	if d.VFS().Opt.NoModTime {
 		return nil
@@ -73,6 +75,7 @@ var _ fusefs.NodeRequestLookuper = (*Dir)(nil)
 //
 // Lookup need not to handle the names "." and "..".
 func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (node fusefs.Node, err error) {
+	defer mountlib.TimeOp("Lookup")()
 	defer log.Trace(d, "name=%q", req.Name)("node=%+v, err=%v", &node, &err)
 	mnode, err := d.Dir.Stat(req.Name)
 	if err != nil {
@@ -104,6 +107,7 @@ var _ fusefs.HandleReadDirAller = (*Dir)(nil)
 // ReadDirAll reads the contents of the directory
 func (d *Dir) ReadDirAll(ctx context.Context) (dirents []fuse.Dirent, err error) {
 	itemsRead := -1
+	defer mountlib.TimeOp("ReadDirAll")()
 	defer log.Trace(d, "")("item=%d, err=%v", &itemsRead, &err)
 	items, err := d.Dir.ReadDirAll()
 	if err != nil {
@@ -140,6 +144,7 @@ var _ fusefs.NodeCreater = (*Dir)(nil)
 
 // Create makes a new file
 func (d *Dir) Create(ctx context.Context, req *fuse.CreateRequest, resp *fuse.CreateResponse) (node fusefs.Node, handle fusefs.Handle, err error) {
+	defer mountlib.TimeOp("Create")()
 	defer log.Trace(d, "name=%q", req.Name)("node=%v, handle=%v, err=%v", &node, &handle, &err)
 	file, err := d.Dir.Create(req.Name, int(req.Flags))
 	if err != nil {
@@ -158,6 +163,7 @@ var _ fusefs.NodeMkdirer = (*Dir)(nil)
 
 // Mkdir creates a new directory
 func (d *Dir) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (node fusefs.Node, err error) {
+	defer mountlib.TimeOp("Mkdir")()
 	defer log.Trace(d, "name=%q", req.Name)("node=%+v, err=%v", &node, &err)
 	dir, err := d.Dir.Mkdir(req.Name)
 	if err != nil {
@@ -174,6 +180,7 @@ var _ fusefs.NodeRemover = (*Dir)(nil)
 // the receiver, which must be a directory.  The entry to be removed
 // may correspond to a file (unlink) or to a directory (rmdir).
 func (d *Dir) Remove(ctx context.Context, req *fuse.RemoveRequest) (err error) {
+	defer mountlib.TimeOp("Remove")()
 	defer log.Trace(d, "name=%q", req.Name)("err=%v", &err)
 	err = d.Dir.RemoveName(req.Name)
 	if err != nil {
@@ -196,6 +203,7 @@ var _ fusefs.NodeRenamer = (*Dir)(nil)
 
 // Rename the file
 func (d *Dir) Rename(ctx context.Context, req *fuse.RenameRequest, newDir fusefs.Node) (err error) {
+	defer mountlib.TimeOp("Rename")()
 	defer log.Trace(d, "oldName=%q, newName=%q, newDir=%+v", req.OldName, req.NewName, newDir)("err=%v", &err)
 	destDir, ok := newDir.(*Dir)
 	if !ok {
@@ -222,6 +230,7 @@ var _ fusefs.NodeFsyncer = (*Dir)(nil)
 
 // Fsync the directory
 func (d *Dir) Fsync(ctx context.Context, req *fuse.FsyncRequest) (err error) {
+	defer mountlib.TimeOp("Fsync")()
 	defer log.Trace(d, "")("err=%v", &err)
 	err = d.Dir.Sync()
 	if err != nil {
@@ -236,6 +245,7 @@ var _ fusefs.NodeLinker = (*Dir)(nil)
 // Link creates a new directory entry in the receiver based on an
 // existing Node. Receiver must be a directory.
 func (d *Dir) Link(ctx context.Context, req *fuse.LinkRequest, old fusefs.Node) (newNode fusefs.Node, err error) {
+	defer mountlib.TimeOp("Link")()
 	defer log.Trace(d, "req=%v, old=%v", req, old)("new=%v, err=%v", &newNode, &err)
 	return nil, syscall.ENOSYS
 }
@@ -247,6 +257,7 @@ var _ fusefs.NodeMknoder = (*Dir)(nil)
 // be called in preference, however NFS likes to call it for some
 // reason. We don't actually create a file here just the Node.
 func (d *Dir) Mknod(ctx context.Context, req *fuse.MknodRequest) (node fusefs.Node, err error) {
+	defer mountlib.TimeOp("Mknod")()
 	defer log.Trace(d, "name=%v, mode=%d, rdev=%d", req.Name, req.Mode, req.Rdev)("node=%v, err=%v", &node, &err)
 	if req.Rdev != 0 {
 		fs.Errorf(d, "Can't create device node %q", req.Name)
@@ -10,6 +10,7 @@ import (
 
 	"bazil.org/fuse"
 	fusefs "bazil.org/fuse/fs"
+	"github.com/rclone/rclone/cmd/mountlib"
 	"github.com/rclone/rclone/fs/log"
 	"github.com/rclone/rclone/vfs"
 )
@@ -25,14 +26,14 @@ var _ fusefs.Node = (*File)(nil)
 
 // Attr fills out the attributes for the file
 func (f *File) Attr(ctx context.Context, a *fuse.Attr) (err error) {
+	defer mountlib.TimeOp("Attr")()
 	defer log.Trace(f, "")("a=%+v, err=%v", a, &err)
 	a.Valid = f.fsys.opt.AttrTimeout
 	modTime := f.File.ModTime()
 	Size := uint64(f.File.Size())
 	Blocks := (Size + 511) / 512
-	a.Gid = f.VFS().Opt.GID
-	a.Uid = f.VFS().Opt.UID
-	a.Mode = f.VFS().Opt.FilePerms
+	a.Uid, a.Gid = f.File.Owner()
+	a.Mode = f.File.Mode().Perm()
 	a.Size = Size
 	a.Atime = modTime
 	a.Mtime = modTime
@@ -44,9 +45,30 @@ func (f *File) Attr(ctx context.Context, a *fuse.Attr) (err error) {
 // Check interface satisfied
 var _ fusefs.NodeSetattrer = (*File)(nil)
 
-// Setattr handles attribute changes from FUSE. Currently supports ModTime and Size only
+// Setattr handles attribute changes from FUSE. Currently supports
+// ModTime and Size, and Mode, Uid and Gid with --vfs-metadata-perms
 func (f *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) (err error) {
+	defer mountlib.TimeOp("Setattr")()
 	defer log.Trace(f, "a=%+v", req)("err=%v", &err)
+	if req.Valid.Mode() {
+		err = f.File.Chmod(req.Mode)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+	if req.Valid.Uid() || req.Valid.Gid() {
+		uid, gid := -1, -1
+		if req.Valid.Uid() {
+			uid = int(req.Uid)
+		}
+		if req.Valid.Gid() {
+			gid = int(req.Gid)
+		}
+		err = f.File.Chown(uid, gid)
+		if err != nil {
+			return translateError(err)
+		}
+	}
 	if !f.VFS().Opt.NoModTime {
 		if req.Valid.Mtime() {
 			err = f.File.SetModTime(req.Mtime)
@@ -65,6 +87,7 @@ var _ fusefs.NodeOpener = (*File)(nil)
 
 // Open the file for read or write
 func (f *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fh fusefs.Handle, err error) {
+	defer mountlib.TimeOp("Open")()
 	defer log.Trace(f, "flags=%v", req.Flags)("fh=%v, err=%v", &fh, &err)
 
 	// fuse flags are based off syscall flags as are os flags, so
@@ -89,6 +112,7 @@ var _ fusefs.NodeFsyncer = (*File)(nil)
 //
 // Note that we don't do anything except return OK
 func (f *File) Fsync(ctx context.Context, req *fuse.FsyncRequest) (err error) {
+	defer mountlib.TimeOp("Fsync")()
 	defer log.Trace(f, "")("err=%v", &err)
 	return nil
 }
@@ -55,6 +55,7 @@ var _ fusefs.FSStatfser = (*FS)(nil)
 // Statfs is called to obtain file system metadata.
 // It should write that data to resp.
 func (f *FS) Statfs(ctx context.Context, req *fuse.StatfsRequest, resp *fuse.StatfsResponse) (err error) {
+	defer mountlib.TimeOp("Statfs")()
 	defer log.Trace("", "")("stat=%+v, err=%v", resp, &err)
 	const blockSize = 4096
 	total, _, free := f.VFS.Statfs()
@@ -11,6 +11,7 @@ import (
 
 	fusefs "github.com/hanwen/go-fuse/v2/fs"
 	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/rclone/rclone/cmd/mountlib"
 	"github.com/rclone/rclone/fs/log"
 	"github.com/rclone/rclone/vfs"
 )
@@ -60,6 +61,7 @@ func (f *FileHandle) String() string {
 func (f *FileHandle) Read(ctx context.Context, dest []byte, off int64) (res fuse.ReadResult, errno syscall.Errno) {
 	var n int
 	var err error
+	defer mountlib.TimeOp("Read")()
 	defer log.Trace(f, "off=%d", off)("n=%d, off=%d, errno=%v", &n, &off, &errno)
 	n, err = f.h.ReadAt(dest, off)
 	if err == io.EOF {
@@ -75,6 +77,7 @@ var _ fusefs.FileReader = (*FileHandle)(nil)
 func (f *FileHandle) Write(ctx context.Context, data []byte, off int64) (written uint32, errno syscall.Errno) {
 	var n int
 	var err error
+	defer mountlib.TimeOp("Write")()
 	defer log.Trace(f, "off=%d", off)("n=%d, off=%d, errno=%v", &n, &off, &errno)
 	n, err = f.h.WriteAt(data, off)
 	return uint32(n), translateError(err)
@@ -117,6 +120,7 @@ var _ fusefs.FileFsyncer = (*FileHandle)(nil)
 // with the Options.NullPermissions setting. If blksize is unset, 4096
 // is assumed, and the 'blocks' field is set accordingly.
 func (f *FileHandle) Getattr(ctx context.Context, out *fuse.AttrOut) (errno syscall.Errno) {
+	defer mountlib.TimeOp("Getattr")()
 	defer log.Trace(f, "")("attr=%v, errno=%v", &out, &errno)
 	f.fsys.setAttrOut(f.h.Node(), out)
 	return 0
@@ -126,6 +130,7 @@ var _ fusefs.FileGetattrer = (*FileHandle)(nil)
 
 // Setattr sets attributes for an Inode.
 func (f *FileHandle) Setattr(ctx context.Context, in *fuse.SetAttrIn, out *fuse.AttrOut) (errno syscall.Errno) {
+	defer mountlib.TimeOp("Setattr")()
 	defer log.Trace(f, "in=%v", in)("attr=%v, errno=%v", &out, &errno)
 	var err error
 	f.fsys.setAttrOut(f.h.Node(), out)
@@ -67,9 +67,7 @@ func setAttr(node vfs.Node, attr *fuse.Attr) {
 	Blocks := (Size + BlockSize - 1) / BlockSize
 	modTime := node.ModTime()
 	// set attributes
-	vfs := node.VFS()
-	attr.Owner.Gid = vfs.Opt.GID
-	attr.Owner.Uid = vfs.Opt.UID
+	attr.Owner.Uid, attr.Owner.Gid = node.Owner()
 	attr.Mode = getMode(node)
 	attr.Size = Size
 	attr.Nlink = 1
@@ -84,6 +84,7 @@ func (n *Node) lookupVfsNodeInDir(leaf string) (vfsNode vfs.Node, errno syscall.
 // result.  This is because OSX filesystems must Statfs, or the mount
 // will not work.
 func (n *Node) Statfs(ctx context.Context, out *fuse.StatfsOut) syscall.Errno {
+	defer mountlib.TimeOp("Statfs")()
 	defer log.Trace(n, "")("out=%+v", &out)
 	const blockSize = 4096
 	total, _, free := n.fsys.VFS.Statfs()
@@ -119,6 +120,7 @@ var _ = (fusefs.NodeGetattrer)((*Node)(nil))
 
 // Setattr sets attributes for an Inode.
 func (n *Node) Setattr(ctx context.Context, f fusefs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) (errno syscall.Errno) {
+	defer mountlib.TimeOp("Setattr")()
 	defer log.Trace(n, "in=%v", in)("out=%#v, errno=%v", &out, &errno)
 	var err error
 	n.fsys.setAttrOut(n.node, out)
@@ -139,6 +141,30 @@ func (n *Node) Setattr(ctx context.Context, f fusefs.FileHandle, in *fuse.SetAtt
 		out.Attr.Mtime = uint64(mtime.Unix())
 		out.Attr.Mtimensec = uint32(mtime.Nanosecond())
 	}
+	mode, ok := in.GetMode()
+	if ok {
+		err = n.node.Chmod(os.FileMode(mode).Perm())
+		if err != nil {
+			return translateError(err)
+		}
+		out.Attr.Mode = getMode(n.node)
+	}
+	uid, uidOK := in.GetUID()
+	gid, gidOK := in.GetGID()
+	if uidOK || gidOK {
+		newUID, newGID := -1, -1
+		if uidOK {
+			newUID = int(uid)
+		}
+		if gidOK {
+			newGID = int(gid)
+		}
+		err = n.node.Chown(newUID, newGID)
+		if err != nil {
+			return translateError(err)
+		}
+		out.Attr.Owner.Uid, out.Attr.Owner.Gid = n.node.Owner()
+	}
 	return 0
 }
 
@@ -147,6 +173,7 @@ var _ = (fusefs.NodeSetattrer)((*Node)(nil))
 // Open opens an Inode (of regular file type) for reading. It
 // is optional but recommended to return a FileHandle.
 func (n *Node) Open(ctx context.Context, flags uint32) (fh fusefs.FileHandle, fuseFlags uint32, errno syscall.Errno) {
+	defer mountlib.TimeOp("Open")()
 	defer log.Trace(n, "flags=%#o", flags)("errno=%v", &errno)
 	// fuse flags are based off syscall flags as are os flags, so
 	// should be compatible
@@ -187,6 +214,7 @@ var _ = (fusefs.NodeOpener)((*Node)(nil))
 // children in directories. Hence, they also return *Inode and must
 // populate their fuse.EntryOut arguments.
 func (n *Node) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (inode *fusefs.Inode, errno syscall.Errno) {
+	defer mountlib.TimeOp("Lookup")()
 	defer log.Trace(n, "name=%q", name)("inode=%v, attr=%v, errno=%v", &inode, &out, &errno)
 	vfsNode, errno := n.lookupVfsNodeInDir(name)
 	if errno != 0 {
@@ -268,6 +296,7 @@ var _ fusefs.DirStream = (*dirStream)(nil)
 // currently known children from the tree is returned. This means that
 // static in-memory file systems need not implement NodeReaddirer.
 func (n *Node) Readdir(ctx context.Context) (ds fusefs.DirStream, errno syscall.Errno) {
+	defer mountlib.TimeOp("Readdir")()
 	defer log.Trace(n, "")("ds=%v, errno=%v", &ds, &errno)
 	if !n.node.IsDir() {
 		return nil, syscall.ENOTDIR
@@ -296,6 +325,7 @@ var _ = (fusefs.NodeReaddirer)((*Node)(nil))
 // Mkdir is similar to Lookup, but must create a directory entry and Inode.
 // Default is to return EROFS.
 func (n *Node) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (inode *fusefs.Inode, errno syscall.Errno) {
+	defer mountlib.TimeOp("Mkdir")()
 	defer log.Trace(name, "mode=0%o", mode)("inode=%v, errno=%v", &inode, &errno)
 	dir, ok := n.node.(*vfs.Dir)
 	if !ok {
@@ -318,6 +348,7 @@ var _ = (fusefs.NodeMkdirer)((*Node)(nil))
 // reference for future reads/writes.
 // Default is to return EROFS.
 func (n *Node) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (node *fusefs.Inode, fh fusefs.FileHandle, fuseFlags uint32, errno syscall.Errno) {
+	defer mountlib.TimeOp("Create")()
 	defer log.Trace(n, "name=%q, flags=%#o, mode=%#o", name, flags, mode)("node=%v, fh=%v, flags=%#o, errno=%v", &node, &fh, &fuseFlags, &errno)
 	dir, ok := n.node.(*vfs.Dir)
 	if !ok {
@@ -359,6 +390,7 @@ var _ = (fusefs.NodeCreater)((*Node)(nil))
 // return status is OK, the Inode is removed as child in the
 // FS tree automatically. Default is to return EROFS.
 func (n *Node) Unlink(ctx context.Context, name string) (errno syscall.Errno) {
+	defer mountlib.TimeOp("Unlink")()
 	defer log.Trace(n, "name=%q", name)("errno=%v", &errno)
 	vfsNode, errno := n.lookupVfsNodeInDir(name)
 	if errno != 0 {
@@ -372,6 +404,7 @@ var _ = (fusefs.NodeUnlinker)((*Node)(nil))
 // Rmdir is like Unlink but for directories.
 // Default is to return EROFS.
 func (n *Node) Rmdir(ctx context.Context, name string) (errno syscall.Errno) {
+	defer mountlib.TimeOp("Rmdir")()
 	defer log.Trace(n, "name=%q", name)("errno=%v", &errno)
 	vfsNode, errno := n.lookupVfsNodeInDir(name)
 	if errno != 0 {
@@ -386,6 +419,7 @@ var _ = (fusefs.NodeRmdirer)((*Node)(nil))
 // one. The change is effected in the FS tree if the return status is
 // OK. Default is to return EROFS.
 func (n *Node) Rename(ctx context.Context, oldName string, newParent fusefs.InodeEmbedder, newName string, flags uint32) (errno syscall.Errno) {
+	defer mountlib.TimeOp("Rename")()
 	defer log.Trace(n, "oldName=%q, newParent=%v, newName=%q", oldName, newParent, newName)("errno=%v", &errno)
 	oldDir, ok := n.node.(*vfs.Dir)
 	if !ok {
@@ -0,0 +1,31 @@
+package mountlib
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// opDuration is the time taken by mount operations
+var opDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "rclone",
+	Subsystem: "mount",
+	Name:      "op_duration_seconds",
+	Help:      "Time taken by mount operations",
+	Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+}, []string{"op"})
+
+func init() {
+	prometheus.MustRegister(opDuration)
+}
+
+// TimeOp starts timing the mount operation op. Call the function
+// returned when the operation has finished, eg
+//
+//	defer mountlib.TimeOp("Getattr")()
+func TimeOp(op string) func() {
+	start := time.Now()
+	return func() {
+		opDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	}
+}
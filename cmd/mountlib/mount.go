@@ -79,6 +79,7 @@ type MountPoint struct {
 	MountOpt   Options
 	VFSOpt     vfscommon.Options
 	Fs         fs.Fs
+	Remotes    Remotes // remotes mounted in a virtual root, nil if not in use
 	VFS        *vfs.VFS
 	MountFn    MountFn
 	UnmountFn  UnmountFn
@@ -127,6 +128,12 @@ func init() {
 // Opt contains options set by command line flags
 var Opt Options
 
+// Remotes to mount in a virtual root set by command line flags
+var (
+	remoteDefs      []string
+	remotesFilePath string
+)
+
 // AddFlags adds the non filing system specific flags to the command
 func AddFlags(flagSet *pflag.FlagSet) {
 	rc.AddOption("mount", &Opt)
@@ -169,7 +176,12 @@ func NewMountCommand(commandName string, hidden bool, mount MountFn) *cobra.Comm
 			"groups":            "Filter",
 		},
 		Run: func(command *cobra.Command, args []string) {
-			cmd.CheckArgs(2, 2, command, args)
+			useRemotes := len(remoteDefs) > 0 || remotesFilePath != ""
+			if useRemotes {
+				cmd.CheckArgs(1, 1, command, args)
+			} else {
+				cmd.CheckArgs(2, 2, command, args)
+			}
 
 			if fs.GetConfig(context.Background()).UseListR {
 				fs.Logf(nil, "--fast-list does nothing on a mount")
@@ -190,7 +202,17 @@ func NewMountCommand(commandName string, hidden bool, mount MountFn) *cobra.Comm
 				defer cmd.StartStats()()
 			}
 
-			mnt := NewMountPoint(mount, args[1], cmd.NewFsDir(args), &Opt, &vfsflags.Opt)
+			var mnt *MountPoint
+			if useRemotes {
+				remotes, f, err := newRemotesFsFromFlags(context.Background())
+				if err != nil {
+					log.Fatalf("Fatal error: %v", err)
+				}
+				mnt = NewMountPoint(mount, args[0], f, &Opt, &vfsflags.Opt)
+				mnt.Remotes = remotes
+			} else {
+				mnt = NewMountPoint(mount, args[1], cmd.NewFsDir(args), &Opt, &vfsflags.Opt)
+			}
 			daemon, err := mnt.Mount()
 
 			// Wait for foreground mount, if any...
@@ -246,10 +268,39 @@ func NewMountCommand(commandName string, hidden bool, mount MountFn) *cobra.Comm
 	cmdFlags := commandDefinition.Flags()
 	AddFlags(cmdFlags)
 	vfsflags.AddFlags(cmdFlags)
+	flags.StringArrayVarP(cmdFlags, &remoteDefs, "mount-remote", "", []string{}, "Mount remote:path as dir in a virtual root, as dir=remote:path (repeat if required)", "Mount")
+	flags.StringVarP(cmdFlags, &remotesFilePath, "mount-remotes-file", "", remotesFilePath, "Read remotes to mount in a virtual root from this YAML file", "Mount")
 
 	return commandDefinition
 }
 
+// newRemotesFsFromFlags makes the Fs for the remotes set with
+// --mount-remote and --mount-remotes-file
+func newRemotesFsFromFlags(ctx context.Context) (remotes Remotes, f fs.Fs, err error) {
+	remotes = Remotes{}
+	if remotesFilePath != "" {
+		remotes, err = LoadRemotes(remotesFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	extraRemotes, err := ParseRemotes(remoteDefs)
+	if err != nil {
+		return nil, nil, err
+	}
+	for dir, remote := range extraRemotes {
+		err = remotes.Add(dir, remote)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	f, err = NewRemotesFs(ctx, remotes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return remotes, f, nil
+}
+
 // Mount the remote at mountpoint
 func (m *MountPoint) Mount() (daemon *os.Process, err error) {
 
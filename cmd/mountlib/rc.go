@@ -48,6 +48,7 @@ func AddRc(mountUtilName string, mountFunction MountFn) {
 func init() {
 	rc.Add(rc.Call{
 		Path:         "mount/mount",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           mountRc,
 		Title:        "Create a new mount point",
@@ -58,7 +59,9 @@ If no mountType is provided, the priority is given as follows: 1. mount 2.cmount
 
 This takes the following parameters:
 
-- fs - a remote path to be mounted (required)
+- fs - a remote path to be mounted (required unless remotes is set)
+- remotes: a JSON object mapping directory names to remote paths to mount
+  several remotes in a virtual read only root instead of fs
 - mountPoint: valid path on the local machine (required)
 - mountType: one of the values (mount, cmount, mount2) specifies the mount implementation to use
 - mountOpt: a JSON object with Mount options in.
@@ -69,6 +72,10 @@ Example:
     rclone rc mount/mount fs=mydrive: mountPoint=/home/<user>/mountPoint
     rclone rc mount/mount fs=mydrive: mountPoint=/home/<user>/mountPoint mountType=mount
     rclone rc mount/mount fs=TestDrive: mountPoint=/mnt/tmp vfsOpt='{"CacheMode": 2}' mountOpt='{"AllowOther": true}'
+    rclone rc mount/mount mountPoint=/mnt/tmp remotes='{"docs": "drive:Documents", "photos": "gphotos:media/all"}'
+
+Remotes can be added to and removed from a mount made with remotes
+while it is running with mount/addremote and mount/removeremote.
 
 The vfsOpt are as described in options/get and can be seen in the the
 "vfs" section when running and the mountOpt can be seen in the "mount" section:
@@ -114,13 +121,34 @@ func mountRc(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 		return nil, errors.New("mount option specified is not registered, or is invalid")
 	}
 
-	// Get Fs.fs to be mounted from fs parameter in the params
-	fdst, err := rc.GetFs(ctx, in)
+	// Get Fs.fs to be mounted from the remotes or fs parameter in the params
+	var (
+		fdst    fs.Fs
+		remotes Remotes
+	)
+	if _, ok := in["remotes"]; ok {
+		var remotesIn map[string]string
+		err = in.GetStruct("remotes", &remotesIn)
+		if err != nil {
+			return nil, err
+		}
+		remotes = Remotes{}
+		for dir, remote := range remotesIn {
+			err = remotes.Add(dir, remote)
+			if err != nil {
+				return nil, rc.NewErrParamInvalid(err)
+			}
+		}
+		fdst, err = NewRemotesFs(ctx, remotes)
+	} else {
+		fdst, err = rc.GetFs(ctx, in)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	mnt := NewMountPoint(mountFn, mountPoint, fdst, &mountOpt, &vfsOpt)
+	mnt.Remotes = remotes
 	_, err = mnt.Mount()
 	if err != nil {
 		log.Printf("mount FAILED: %v", err)
@@ -145,6 +173,7 @@ func mountRc(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 func init() {
 	rc.Add(rc.Call{
 		Path:         "mount/unmount",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           unMountRc,
 		Title:        "Unmount selected active mount",
@@ -231,6 +260,9 @@ This takes no parameters and returns
 
 - mountPoints: list of current mount points
 
+Mounts made with remotes also show the remotes mounted in their
+virtual root.
+
 Eg
 
     rclone rc mount/listmounts
@@ -243,6 +275,7 @@ type MountInfo struct {
 	Fs         string    `json:"Fs"`
 	MountPoint string    `json:"MountPoint"`
 	MountedOn  time.Time `json:"MountedOn"`
+	Remotes    Remotes   `json:"Remotes,omitempty"`
 }
 
 // listMountsRc returns a list of current mounts sorted by mount path
@@ -262,6 +295,12 @@ func listMountsRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
 			MountPoint: m.MountPoint,
 			MountedOn:  m.MountedOn,
 		}
+		if m.Remotes != nil {
+			info.Remotes = make(Remotes, len(m.Remotes))
+			for dir, remote := range m.Remotes {
+				info.Remotes[dir] = remote
+			}
+		}
 		mountPoints = append(mountPoints, info)
 	}
 	return rc.Params{
@@ -272,6 +311,7 @@ func listMountsRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
 func init() {
 	rc.Add(rc.Call{
 		Path:         "mount/unmountall",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           unmountAll,
 		Title:        "Unmount all active mounts",
@@ -302,3 +342,101 @@ func unmountAll(_ context.Context, in rc.Params) (out rc.Params, err error) {
 	}
 	return nil, nil
 }
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "mount/addremote",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           addRemoteRc,
+		Title:        "Add a remote to the virtual root of a mount",
+		Help: `This mounts a remote as a new directory in the virtual root of a
+mount which was made with remotes.
+
+This takes the following parameters:
+
+- mountPoint: valid path on the local machine where the mount was created (required)
+- dir: name of the directory in the virtual root (required)
+- fs: the remote path to mount on dir (required)
+
+Eg
+
+    rclone rc mount/addremote mountPoint=/mnt/tmp dir=music fs=s3:bucket/music
+`,
+	})
+}
+
+// getLiveMount returns the mount for the mountPoint parameter
+//
+// call with mountMu held
+func getLiveMount(in rc.Params) (*MountPoint, error) {
+	mountPoint, err := in.GetString("mountPoint")
+	if err != nil {
+		return nil, err
+	}
+	mnt, found := liveMounts[mountPoint]
+	if !found {
+		return nil, errors.New("mount not found")
+	}
+	return mnt, nil
+}
+
+// addRemoteRc adds a remote to the virtual root of a mount
+func addRemoteRc(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	dir, err := in.GetString("dir")
+	if err != nil {
+		return nil, err
+	}
+	remote, err := in.GetString("fs")
+	if err != nil {
+		return nil, err
+	}
+	mountMu.Lock()
+	defer mountMu.Unlock()
+	mnt, err := getLiveMount(in)
+	if err != nil {
+		return nil, err
+	}
+	return nil, mnt.AddRemote(ctx, dir, remote)
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "mount/removeremote",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           removeRemoteRc,
+		Title:        "Remove a remote from the virtual root of a mount",
+		Help: `This removes a remote from the virtual root of a mount which was
+made with remotes.
+
+Files which are open in the remote will return errors, and files
+which are waiting to be uploaded from the VFS cache will fail to
+upload, so make sure they have been uploaded first.
+
+This takes the following parameters:
+
+- mountPoint: valid path on the local machine where the mount was created (required)
+- dir: name of the directory in the virtual root to remove (required)
+
+Eg
+
+    rclone rc mount/removeremote mountPoint=/mnt/tmp dir=music
+`,
+	})
+}
+
+// removeRemoteRc removes a remote from the virtual root of a mount
+func removeRemoteRc(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	dir, err := in.GetString("dir")
+	if err != nil {
+		return nil, err
+	}
+	mountMu.Lock()
+	defer mountMu.Unlock()
+	mnt, err := getLiveMount(in)
+	if err != nil {
+		return nil, err
+	}
+	return nil, mnt.RemoveRemote(ctx, dir)
+}
@@ -0,0 +1,175 @@
+package mountlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"gopkg.in/yaml.v2"
+)
+
+// Remotes maps the directories in the virtual root of a mount to the
+// remotes mounted on them, eg "docs" => "drive:Documents"
+type Remotes map[string]string
+
+// errNoRemotes is returned when trying to add or remove remotes from
+// a mount which doesn't have a virtual root
+var errNoRemotes = errors.New("mount doesn't have a virtual root - mount it with remotes to use this")
+
+// checkRemoteDir checks dir is valid as a directory in the virtual root
+func checkRemoteDir(dir string) error {
+	if dir == "" {
+		return errors.New("empty directory name for remote")
+	}
+	if strings.ContainsRune(dir, '/') {
+		return fmt.Errorf("directory name %q for remote can't contain /", dir)
+	}
+	return nil
+}
+
+// ParseRemotes parses definitions of the form dir=remote:path
+func ParseRemotes(defs []string) (remotes Remotes, err error) {
+	remotes = make(Remotes, len(defs))
+	for _, def := range defs {
+		dir, remote, ok := strings.Cut(def, "=")
+		if !ok {
+			return nil, fmt.Errorf("remote %q should be in the form dir=remote:path", def)
+		}
+		err = remotes.Add(dir, remote)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return remotes, nil
+}
+
+// remotesFile is the format of the file read by LoadRemotes
+type remotesFile struct {
+	Remotes Remotes `yaml:"remotes"`
+}
+
+// LoadRemotes reads remotes from a YAML file of the form
+//
+//	remotes:
+//	  docs: drive:Documents
+//	  photos: gphotos:media/all
+func LoadRemotes(path string) (remotes Remotes, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remotes file: %w", err)
+	}
+	var file remotesFile
+	err = yaml.UnmarshalStrict(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remotes file %q: %w", path, err)
+	}
+	remotes = make(Remotes, len(file.Remotes))
+	for dir, remote := range file.Remotes {
+		err = remotes.Add(dir, remote)
+		if err != nil {
+			return nil, fmt.Errorf("remotes file %q: %w", path, err)
+		}
+	}
+	return remotes, nil
+}
+
+// Add remote to be mounted on dir checking it is valid
+func (remotes Remotes) Add(dir, remote string) error {
+	err := checkRemoteDir(dir)
+	if err != nil {
+		return err
+	}
+	if remote == "" {
+		return fmt.Errorf("empty remote for directory %q", dir)
+	}
+	if _, found := remotes[dir]; found {
+		return fmt.Errorf("duplicate directory name %q", dir)
+	}
+	remotes[dir] = remote
+	return nil
+}
+
+// upstreams returns the remotes as sorted dir=remote:path definitions
+func (remotes Remotes) upstreams() fs.SpaceSepList {
+	upstreams := make(fs.SpaceSepList, 0, len(remotes))
+	for dir, remote := range remotes {
+		upstreams = append(upstreams, dir+"="+remote)
+	}
+	sort.Strings(upstreams)
+	return upstreams
+}
+
+// NewRemotesFs makes an Fs with a virtual read only root with a
+// directory for each of the remotes.
+//
+// This uses the combine backend and the Fs returned is not cached, so
+// remotes may be added and removed from it without affecting anything
+// else.
+func NewRemotesFs(ctx context.Context, remotes Remotes) (fs.Fs, error) {
+	if len(remotes) == 0 {
+		return nil, errors.New("no remotes to mount")
+	}
+	if _, err := fs.Find("combine"); err != nil {
+		return nil, fmt.Errorf("mounting multiple remotes needs the combine backend: %w", err)
+	}
+	upstreams := remotes.upstreams().String()
+	return fs.NewFs(ctx, ":combine,upstreams='"+strings.ReplaceAll(upstreams, "'", "''")+"':")
+}
+
+// forgetRemote clears the directory cache for dir in the virtual root
+func (m *MountPoint) forgetRemote(dir string) {
+	root, err := m.VFS.Root()
+	if err != nil {
+		fs.Errorf(m.VFS.Fs(), "Error reading root: %v", err)
+		return
+	}
+	root.ForgetPath(dir, fs.EntryDirectory)
+}
+
+// AddRemote mounts remote on dir in the virtual root of the mount
+//
+// The mount must have been made with remotes.
+func (m *MountPoint) AddRemote(ctx context.Context, dir, remote string) error {
+	if m.Remotes == nil {
+		return errNoRemotes
+	}
+	newRemotes := Remotes{}
+	err := newRemotes.Add(dir, remote)
+	if err != nil {
+		return err
+	}
+	if _, found := m.Remotes[dir]; found {
+		return fmt.Errorf("directory %q is already in use", dir)
+	}
+	_, err = m.VFS.Fs().Features().Command(ctx, "add", newRemotes.upstreams(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to add remote: %w", err)
+	}
+	m.Remotes[dir] = remote
+	m.forgetRemote(dir)
+	return nil
+}
+
+// RemoveRemote removes the remote mounted on dir in the virtual root
+// of the mount
+//
+// The mount must have been made with remotes.
+func (m *MountPoint) RemoveRemote(ctx context.Context, dir string) error {
+	if m.Remotes == nil {
+		return errNoRemotes
+	}
+	if _, found := m.Remotes[dir]; !found {
+		return fmt.Errorf("no remote mounted on directory %q", dir)
+	}
+	_, err := m.VFS.Fs().Features().Command(ctx, "remove", []string{dir}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove remote: %w", err)
+	}
+	delete(m.Remotes, dir)
+	m.forgetRemote(dir)
+	return nil
+}
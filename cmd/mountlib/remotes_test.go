@@ -0,0 +1,111 @@
+package mountlib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/combine"
+	_ "github.com/rclone/rclone/backend/memory"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemotes(t *testing.T) {
+	remotes, err := ParseRemotes([]string{"docs=drive:Documents", "photos=gphotos:media/all=1"})
+	require.NoError(t, err)
+	assert.Equal(t, Remotes{"docs": "drive:Documents", "photos": "gphotos:media/all=1"}, remotes)
+
+	for _, def := range []string{
+		"drive:Documents",
+		"=drive:Documents",
+		"docs=",
+		"sub/docs=drive:Documents",
+	} {
+		_, err = ParseRemotes([]string{def})
+		assert.Error(t, err, def)
+	}
+
+	_, err = ParseRemotes([]string{"docs=drive:", "docs=drive2:"})
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestLoadRemotes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "remotes.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`remotes:
+  docs: drive:Documents
+  "my photos": "gphotos:media/all"
+`), 0600))
+	remotes, err := LoadRemotes(path)
+	require.NoError(t, err)
+	assert.Equal(t, Remotes{"docs": "drive:Documents", "my photos": "gphotos:media/all"}, remotes)
+
+	require.NoError(t, os.WriteFile(path, []byte(`remote:
+  docs: drive:Documents
+`), 0600))
+	_, err = LoadRemotes(path)
+	assert.Error(t, err)
+
+	_, err = LoadRemotes(filepath.Join(dir, "notfound.yaml"))
+	assert.Error(t, err)
+}
+
+func TestRemotesUpstreams(t *testing.T) {
+	remotes := Remotes{"b": "remote:path with space", "a": "remote2:"}
+	assert.Equal(t, `a=remote2: "b=remote:path with space"`, remotes.upstreams().String())
+}
+
+// list the names in the root of the VFS
+func listRoot(t *testing.T, m *MountPoint) (names []string) {
+	root, err := m.VFS.Root()
+	require.NoError(t, err)
+	nodes, err := root.ReadDirAll()
+	require.NoError(t, err)
+	for _, node := range nodes {
+		names = append(names, node.Name())
+	}
+	return names
+}
+
+func TestMountPointRemotes(t *testing.T) {
+	ctx := context.Background()
+	remotes := Remotes{"one": ":memory:one", "two": ":memory:two"}
+	f, err := NewRemotesFs(ctx, remotes)
+	require.NoError(t, err)
+
+	m := NewMountPoint(nil, "/mnt", f, &DefaultOpt, &vfscommon.DefaultOpt)
+	m.Remotes = remotes
+	m.VFS = vfs.New(f, nil)
+	defer m.VFS.Shutdown()
+
+	assert.Equal(t, []string{"one", "two"}, listRoot(t, m))
+
+	// The virtual root is read only
+	root, err := m.VFS.Root()
+	require.NoError(t, err)
+	_, err = root.Mkdir("three")
+	assert.Error(t, err)
+
+	require.NoError(t, m.AddRemote(ctx, "three", ":memory:three"))
+	assert.Equal(t, []string{"one", "three", "two"}, listRoot(t, m))
+	assert.Equal(t, Remotes{"one": ":memory:one", "three": ":memory:three", "two": ":memory:two"}, m.Remotes)
+
+	assert.Error(t, m.AddRemote(ctx, "three", ":memory:three"))
+	assert.Error(t, m.AddRemote(ctx, "a/b", ":memory:three"))
+
+	require.NoError(t, m.RemoveRemote(ctx, "one"))
+	assert.Equal(t, []string{"three", "two"}, listRoot(t, m))
+	assert.Error(t, m.RemoveRemote(ctx, "one"))
+
+	// Check a mount without remotes can't be changed
+	m2 := NewMountPoint(nil, "/mnt2", f, &DefaultOpt, &vfscommon.DefaultOpt)
+	assert.Equal(t, errNoRemotes, m2.AddRemote(ctx, "four", ":memory:four"))
+	assert.Equal(t, errNoRemotes, m2.RemoveRemote(ctx, "two"))
+
+	_, err = NewRemotesFs(ctx, Remotes{})
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package moveto
 
 import (
 	"context"
+	"log"
 
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/fs/operations"
@@ -12,6 +13,7 @@ import (
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
+	cmd.AddOutputFormatFlag(commandDefinition.Flags())
 }
 
 var commandDefinition = &cobra.Command{
@@ -48,20 +50,24 @@ successful transfer.
 ` + "`--dry-run` or the `--interactive`/`-i`" + ` flag.
 
 **Note**: Use the ` + "`-P`" + `/` + "`--progress`" + ` flag to view real-time transfer statistics.
-`,
+` + "\n" + cmd.OutputFormatHelp,
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.35",
 		"groups":            "Filter,Listing,Important,Copy",
 	},
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
+		ctx, err := cmd.OutputContext(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
 		fsrc, srcFileName, fdst, dstFileName := cmd.NewFsSrcDstFiles(args)
 
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
-				return sync.MoveDir(context.Background(), fdst, fsrc, false, false)
+				return sync.MoveDir(ctx, fdst, fsrc, false, false)
 			}
-			return operations.MoveFile(context.Background(), fdst, fsrc, dstFileName, srcFileName)
+			return operations.MoveFile(ctx, fdst, fsrc, dstFileName, srcFileName)
 		})
 	},
 }
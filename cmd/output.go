@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/spf13/pflag"
+)
+
+// outputFormat is set by the --output-format flag added by
+// AddOutputFormatFlag
+var outputFormat = "text"
+
+// OutputFormatHelp describes the --output-format flag for the help of
+// the commands which use AddOutputFormatFlag
+const OutputFormatHelp = `If ` + "`--output-format json`" + ` is supplied then a JSON object is
+written to stdout, one per line, for each file which is copied,
+deleted, skipped or has an error, for example
+
+    {"path":"file.txt","action":"copied","reason":"modtime","srcSize":6,"dstSize":5,"start":"2023-10-27T12:00:00.123Z","elapsed":0.0123}
+
+The ` + "`action`" + ` is one of ` + "`copied`, `deleted`, `skipped`, `moved` or `error`" + `
+and ` + "`reason`" + ` says why, e.g. ` + "`new`, `size`, `modtime`, `hash` or `unchanged`" + `.
+The sizes are included where known and the hashes too if
+` + "`--checksum`" + ` is in use. ` + "`elapsed`" + ` is the time taken in seconds.
+The default ` + "`--output-format text`" + ` writes nothing to stdout.
+This can't be used with ` + "`--progress`" + ` as that writes to stdout too.
+`
+
+// AddOutputFormatFlag adds the --output-format flag to flagSet for
+// commands which can write a record of each file they process.
+//
+// Use OutputContext to read it.
+func AddOutputFormatFlag(flagSet *pflag.FlagSet) {
+	flags.StringVarP(flagSet, &outputFormat, "output-format", "", outputFormat, "Output a record of each file processed to stdout in this format text|json", "")
+}
+
+// OutputContext returns ctx set up to write a record of each file
+// processed to stdout in the format set with --output-format.
+//
+// This should be called before Run as it returns an error if the
+// output would be mixed up with the --progress output.
+func OutputContext(ctx context.Context) (context.Context, error) {
+	switch outputFormat {
+	case "text":
+		return ctx, nil
+	case "json":
+		if fs.GetConfig(ctx).Progress {
+			return nil, errors.New("--output-format json can't be used with --progress as both write to stdout")
+		}
+		return operations.WithResultWriter(ctx, operations.NewResultWriter(os.Stdout)), nil
+	}
+	return nil, fmt.Errorf("unknown --output-format %q - must be text or json", outputFormat)
+}
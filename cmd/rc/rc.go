@@ -188,7 +188,7 @@ func doCall(ctx context.Context, path string, in rc.Params) (out rc.Params, err
 		if call == nil {
 			return errorf(http.StatusBadRequest, path, "loopback: method %q not found", path)
 		}
-		_, out, err := jobs.NewJob(ctx, call.Fn, in)
+		_, out, err := jobs.NewCallJob(ctx, call, in)
 		if err != nil {
 			return errorf(http.StatusInternalServerError, path, "loopback: call failed: %w", err)
 		}
@@ -3,6 +3,7 @@ package dlna
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"net"
@@ -21,9 +22,12 @@ import (
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/dlna/data"
 	"github.com/rclone/rclone/cmd/serve/dlna/dlnaflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/lib/systemd"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +35,29 @@ import (
 func init() {
 	dlnaflags.AddFlags(Command.Flags())
 	vfsflags.AddFlags(Command.Flags())
+	servelib.AddRc("dlna", startRc)
+}
+
+// startRc starts a DLNA server for serve/start
+func startRc(_ context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := dlnaflags.Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newServer(f, &opt, vfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Serve()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // Command definition for cobra.
@@ -58,7 +85,7 @@ files that they are not able to play back correctly.
 		f := cmd.NewFsSrc(args)
 
 		cmd.Run(false, false, command, func() error {
-			s, err := newServer(f, &dlnaflags.Opt)
+			s, err := newServer(f, &dlnaflags.Opt, &vfsflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -103,7 +130,7 @@ type server struct {
 	vfs *vfs.VFS
 }
 
-func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
+func newServer(f fs.Fs, opt *dlnaflags.Options, vfsOpt *vfscommon.Options) (*server, error) {
 	friendlyName := opt.FriendlyName
 	if friendlyName == "" {
 		friendlyName = makeDefaultFriendlyName()
@@ -132,7 +159,7 @@ func newServer(f fs.Fs, opt *dlnaflags.Options) (*server, error) {
 		waitChan:         make(chan struct{}),
 		httpListenAddr:   opt.ListenAddr,
 		f:                f,
-		vfs:              vfs.New(f, &vfsflags.Opt),
+		vfs:              vfs.New(f, vfsOpt),
 	}
 
 	s.services = map[string]UPnPService{
@@ -319,13 +346,27 @@ func (s *server) Wait() {
 	<-s.waitChan
 }
 
-func (s *server) Close() {
+// Addr returns the address the HTTP server is listening on
+func (s *server) Addr() net.Addr {
+	return s.HTTPConn.Addr()
+}
+
+// Shutdown stops the server
+func (s *server) Shutdown() error {
 	err := s.HTTPConn.Close()
 	if err != nil {
-		fs.Errorf(s.f, "Error closing HTTP server: %v", err)
-		return
+		return err
 	}
 	close(s.waitChan)
+	return nil
+}
+
+// Close stops the server logging any errors
+func (s *server) Close() {
+	err := s.Shutdown()
+	if err != nil {
+		fs.Errorf(s.f, "Error closing HTTP server: %v", err)
+	}
 }
 
 // Run SSDP (multicast for server discovery) on all interfaces.
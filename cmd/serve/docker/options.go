@@ -236,6 +236,8 @@ func getVFSOption(vfsOpt *vfscommon.Options, opt rc.Params, key string) (ok bool
 		vfsOpt.CacheMaxAge, err = opt.GetDuration(key)
 	case "vfs-cache-max-size":
 		err = getFVarP(&vfsOpt.CacheMaxSize, opt, key)
+	case "vfs-cache-chunk-size":
+		err = getFVarP(&vfsOpt.CacheChunkSize, opt, key)
 	case "vfs-read-chunk-size":
 		err = getFVarP(&vfsOpt.ChunkSize, opt, key)
 	case "vfs-read-chunk-size-limit":
@@ -248,8 +250,15 @@ func getVFSOption(vfsOpt *vfscommon.Options, opt rc.Params, key string) (ok bool
 		vfsOpt.ReadWait, err = opt.GetDuration(key)
 	case "vfs-write-back":
 		vfsOpt.WriteBack, err = opt.GetDuration(key)
+	case "vfs-write-back-retries":
+		intVal, err = opt.GetInt64(key)
+		vfsOpt.WriteBackRetries = int(intVal)
 	case "vfs-read-ahead":
 		err = getFVarP(&vfsOpt.ReadAhead, opt, key)
+	case "vfs-read-ahead-max":
+		err = getFVarP(&vfsOpt.ReadAheadMax, opt, key)
+	case "vfs-metadata-perms":
+		vfsOpt.MetadataPerms, err = opt.GetBool(key)
 	case "vfs-used-is-size":
 		vfsOpt.UsedIsSize, err = opt.GetBool(key)
 
@@ -21,6 +21,7 @@ import (
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config/flags"
@@ -28,6 +29,7 @@ import (
 	"github.com/rclone/rclone/fs/log"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -74,6 +76,7 @@ func init() {
 	vfsflags.AddFlags(Command.Flags())
 	proxyflags.AddFlags(Command.Flags())
 	AddFlags(Command.Flags())
+	servelib.AddRc("ftp", startRc)
 }
 
 // Command definition for cobra
@@ -114,7 +117,7 @@ You can set a single username and password with the --user and --pass flags.
 			cmd.CheckArgs(0, 0, command, args)
 		}
 		cmd.Run(false, false, command, func() error {
-			s, err := newServer(context.Background(), f, &Opt)
+			s, err := newServer(context.Background(), f, &Opt, &vfsflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -131,6 +134,7 @@ type driver struct {
 	opt        Options
 	globalVFS  *vfs.VFS     // the VFS if not using auth proxy
 	proxy      *proxy.Proxy // may be nil if not in use
+	listener   net.Listener // set if listen has been called
 	useTLS     bool
 	userPassMu sync.Mutex        // to protect userPass
 	userPass   map[string]string // cache of username => password when using vfs proxy
@@ -139,7 +143,7 @@ type driver struct {
 var passivePortsRe = regexp.MustCompile(`^\s*\d+\s*-\s*\d+\s*$`)
 
 // Make a new FTP to serve the remote
-func newServer(ctx context.Context, f fs.Fs, opt *Options) (*driver, error) {
+func newServer(ctx context.Context, f fs.Fs, opt *Options, vfsOpt *vfscommon.Options) (*driver, error) {
 	host, port, err := net.SplitHostPort(opt.ListenAddr)
 	if err != nil {
 		return nil, errors.New("failed to parse host:port")
@@ -158,7 +162,7 @@ func newServer(ctx context.Context, f fs.Fs, opt *Options) (*driver, error) {
 		d.proxy = proxy.New(ctx, &proxyflags.Opt)
 		d.userPass = make(map[string]string, 16)
 	} else {
-		d.globalVFS = vfs.New(f, &vfsflags.Opt)
+		d.globalVFS = vfs.New(f, vfsOpt)
 	}
 	d.useTLS = d.opt.TLSKey != ""
 
@@ -196,14 +200,75 @@ func (d *driver) serve() error {
 	return d.srv.ListenAndServe()
 }
 
-// close stops the ftp server
-//
-//lint:ignore U1000 unused when not building linux
-func (d *driver) close() error {
+// Shutdown stops the ftp server
+func (d *driver) Shutdown() error {
 	fs.Logf(d.f, "Stopping FTP on %s", d.srv.Hostname+":"+strconv.Itoa(d.srv.Port))
 	return d.srv.Shutdown()
 }
 
+// listen makes the listener for Serve so the address the server is
+// listening on is known before it starts serving.
+//
+// This can't be used with TLS as the FTP server makes its own TLS
+// listener.
+func (d *driver) listen() (err error) {
+	d.listener, err = net.Listen("tcp", d.opt.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for connection: %w", err)
+	}
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (d *driver) Addr() net.Addr {
+	if d.listener != nil {
+		return d.listener.Addr()
+	}
+	addr, err := net.ResolveTCPAddr("tcp", d.opt.ListenAddr)
+	if err != nil {
+		return nil
+	}
+	return addr
+}
+
+// Serve runs the ftp server until Shutdown is called, using the
+// listener made by listen if it was called.
+func (d *driver) Serve() error {
+	if d.listener == nil {
+		return d.serve()
+	}
+	fs.Logf(d.f, "Serving FTP on %s", d.listener.Addr())
+	err := d.srv.Serve(d.listener)
+	if err == ftp.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// startRc starts an FTP server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newServer(ctx, f, &opt, vfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	if !d.useTLS {
+		err = d.listen()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return servelib.Background("ftp", d), nil
+}
+
 // Logger ftp logger output formatted message
 type Logger struct{}
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -18,12 +19,15 @@ import (
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/rc"
 	libhttp "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/http/serve"
 	"github.com/rclone/rclone/lib/systemd"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
@@ -56,6 +60,7 @@ func init() {
 	libhttp.AddTemplateFlagsPrefix(flagSet, flagPrefix, &Opt.Template)
 	vfsflags.AddFlags(flagSet)
 	proxyflags.AddFlags(flagSet)
+	servelib.AddRc("http", startRc)
 }
 
 // Command definition for cobra
@@ -88,7 +93,7 @@ control the stats printing.
 		}
 
 		cmd.Run(false, true, command, func() error {
-			s, err := run(context.Background(), f, Opt)
+			s, err := run(context.Background(), f, Opt, &vfsflags.Opt)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -135,7 +140,7 @@ func (s *HTTP) auth(user, pass string) (value interface{}, err error) {
 	return VFS, err
 }
 
-func run(ctx context.Context, f fs.Fs, opt Options) (s *HTTP, err error) {
+func run(ctx context.Context, f fs.Fs, opt Options, vfsOpt *vfscommon.Options) (s *HTTP, err error) {
 	s = &HTTP{
 		f:   f,
 		ctx: ctx,
@@ -147,7 +152,7 @@ func run(ctx context.Context, f fs.Fs, opt Options) (s *HTTP, err error) {
 		// override auth
 		s.opt.Auth.CustomAuthFn = s.auth
 	} else {
-		s._vfs = vfs.New(f, &vfsflags.Opt)
+		s._vfs = vfs.New(f, vfsOpt)
 	}
 
 	s.server, err = libhttp.NewServer(ctx,
@@ -172,6 +177,35 @@ func run(ctx context.Context, f fs.Fs, opt Options) (s *HTTP, err error) {
 	return s, nil
 }
 
+// startRc starts an HTTP server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, f, opt, vfsOpt)
+}
+
+// Addr returns the address the server is listening on
+func (s *HTTP) Addr() net.Addr {
+	return s.server.Addr()
+}
+
+// Shutdown stops the server
+func (s *HTTP) Shutdown() error {
+	return s.server.Shutdown()
+}
+
+// Wait blocks until the server has stopped
+func (s *HTTP) Wait() {
+	s.server.Wait()
+}
+
 // handler reads incoming requests and dispatches them
 func (s *HTTP) handler(w http.ResponseWriter, r *http.Request) {
 	isDir := strings.HasSuffix(r.URL.Path, "/")
@@ -214,7 +248,7 @@ func (s *HTTP) serveDir(w http.ResponseWriter, r *http.Request, dirRemote string
 	// Make the entries for display
 	directory := serve.NewDirectory(dirRemote, s.server.HTMLTemplate())
 	for _, node := range dirEntries {
-		if vfsflags.Opt.NoModTime {
+		if VFS.Opt.NoModTime {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), time.Time{})
 		} else {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), node.ModTime().UTC())
@@ -15,7 +15,9 @@ import (
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/rc"
 	libhttp "github.com/rclone/rclone/lib/http"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -44,7 +46,7 @@ func start(ctx context.Context, t *testing.T, f fs.Fs) (s *HTTP, testURL string)
 		opts.Auth.BasicPass = testPass
 	}
 
-	s, err := run(ctx, f, opts)
+	s, err := run(ctx, f, opts, &vfsflags.Opt)
 	require.NoError(t, err, "failed to start server")
 
 	urls := s.server.URLs()
@@ -267,3 +269,33 @@ func TestGET(t *testing.T) {
 func TestAuthProxy(t *testing.T) {
 	testGET(t, true)
 }
+
+func TestServeStart(t *testing.T) {
+	ctx := context.Background()
+	call := func(path string, in rc.Params) (rc.Params, error) {
+		fn := rc.Calls.Get(path)
+		require.NotNil(t, fn, path)
+		return fn.Fn(ctx, in)
+	}
+	out, err := call("serve/start", rc.Params{
+		"type":   "http",
+		"fs":     "testdata/files",
+		"opt":    rc.Params{"HTTP": rc.Params{"ListenAddr": []string{testBindAddress}}},
+		"vfsOpt": rc.Params{"NoModTime": true},
+	})
+	require.NoError(t, err)
+	testURL := "http://" + out["addr"].(string) + "/"
+
+	resp, err := http.Get(testURL + "two.txt")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123456789\n", string(body))
+
+	_, err = call("serve/stop", rc.Params{"id": out["id"]})
+	require.NoError(t, err)
+	_, err = http.Get(testURL + "two.txt")
+	assert.Error(t, err)
+}
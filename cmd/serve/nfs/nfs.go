@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/rc"
@@ -39,6 +40,25 @@ func AddFlags(flagSet *pflag.FlagSet, Opt *Options) {
 func init() {
 	vfsflags.AddFlags(Command.Flags())
 	AddFlags(Command.Flags(), &opt)
+	servelib.AddRc("nfs", startRc)
+}
+
+// startRc starts an NFS server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	nfsOpt := opt
+	err := servelib.GetOpt(in, &nfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewServer(ctx, vfs.New(f, vfsOpt), &nfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	return servelib.Background("nfs", s), nil
 }
 
 // Run the command
@@ -5,6 +5,8 @@ package nfs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 
 	nfs "github.com/willscott/go-nfs"
@@ -39,9 +41,9 @@ func NewServer(ctx context.Context, vfs *vfs.VFS, opt *Options) (s *Server, err
 	s.handler = newHandler(vfs)
 	s.listener, err = net.Listen("tcp", s.opt.ListenAddr)
 	if err != nil {
-		fs.Errorf(nil, "NFS server failed to listen: %v\n", err)
+		return nil, fmt.Errorf("NFS server failed to listen: %w", err)
 	}
-	return
+	return s, nil
 }
 
 // Addr returns the listening address of the server
@@ -54,8 +56,12 @@ func (s *Server) Shutdown() error {
 	return s.listener.Close()
 }
 
-// Serve starts the server
+// Serve starts the server returning when Shutdown is called
 func (s *Server) Serve() (err error) {
 	fs.Logf(nil, "NFS Server running at %s\n", s.listener.Addr())
-	return nfs.Serve(s.listener, s.handler)
+	err = nfs.Serve(s.listener, s.handler)
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
 }
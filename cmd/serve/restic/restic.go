@@ -16,10 +16,12 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fs/walk"
 	libhttp "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/http/serve"
@@ -60,6 +62,28 @@ func init() {
 	flags.BoolVarP(flagSet, &Opt.AppendOnly, "append-only", "", false, "Disallow deletion of repository data", "")
 	flags.BoolVarP(flagSet, &Opt.PrivateRepos, "private-repos", "", false, "Users can only access their private repo", "")
 	flags.BoolVarP(flagSet, &Opt.CacheObjects, "cache-objects", "", true, "Cache listed objects", "")
+	servelib.AddRc("restic", startRc)
+}
+
+// startRc starts a restic REST server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Stdio {
+		return nil, rc.NewErrParamInvalid(errors.New("can't serve on stdio with serve/start"))
+	}
+	if _, ok := in["vfsOpt"]; ok {
+		return nil, rc.NewErrParamInvalid(errors.New("restic doesn't use the VFS so can't take vfsOpt"))
+	}
+	s, err := newServer(ctx, f, &opt)
+	if err != nil {
+		return nil, err
+	}
+	fs.Logf(s.f, "Serving restic REST API on %s", s.URLs())
+	return s, nil
 }
 
 // Command definition for cobra
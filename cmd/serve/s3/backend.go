@@ -120,7 +120,7 @@ func (b *s3Backend) HeadObject(bucketName, objectName string) (*gofakes3.Object,
 
 	fobj := entry.(fs.Object)
 	size := node.Size()
-	hash := getFileHashByte(fobj)
+	hash := getFileHashByte(fobj, b.opt.HashType)
 
 	meta := map[string]string{
 		"Last-Modified": node.ModTime().Format(timeFormat),
@@ -169,7 +169,7 @@ func (b *s3Backend) GetObject(bucketName, objectName string, rangeRequest *gofak
 	file := node.(*vfs.File)
 
 	size := node.Size()
-	hash := getFileHashByte(fobj)
+	hash := getFileHashByte(fobj, b.opt.HashType)
 
 	in, err := file.Open(os.O_RDONLY)
 	if err != nil {
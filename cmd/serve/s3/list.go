@@ -41,7 +41,7 @@ func (b *s3Backend) entryListR(bucket, fdPath, name string, acceptComPrefix bool
 			item := &gofakes3.Content{
 				Key:          gofakes3.URLEncode(objectPath),
 				LastModified: gofakes3.NewContentTime(entry.ModTime()),
-				ETag:         getFileHash(entry),
+				ETag:         getFileHash(entry, b.opt.HashType),
 				Size:         entry.Size(),
 				StorageClass: gofakes3.StorageStandard,
 			}
@@ -70,7 +70,7 @@ func (b *s3Backend) getObjectsListArbitrary(bucket string, prefix *gofakes3.Pref
 				item := &gofakes3.Content{
 					Key:          gofakes3.URLEncode(object),
 					LastModified: gofakes3.NewContentTime(entry.ModTime(context.Background())),
-					ETag:         getFileHash(entry),
+					ETag:         getFileHash(entry, b.opt.HashType),
 					Size:         entry.Size(),
 					StorageClass: gofakes3.StorageStandard,
 				}
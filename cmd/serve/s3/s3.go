@@ -5,8 +5,11 @@ import (
 	_ "embed"
 
 	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/cmd/serve/servelib"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/rc"
 	httplib "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/vfs"
 	"github.com/rclone/rclone/vfs/vfsflags"
@@ -15,10 +18,10 @@ import (
 
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
-	pathBucketMode: true,
-	hashName:       "MD5",
-	hashType:       hash.MD5,
-	noCleanup:      false,
+	PathBucketMode: true,
+	HashName:       "MD5",
+	HashType:       hash.MD5,
+	NoCleanup:      false,
 	HTTP:           httplib.DefaultCfg(),
 }
 
@@ -31,10 +34,11 @@ func init() {
 	flagSet := Command.Flags()
 	httplib.AddHTTPFlagsPrefix(flagSet, flagPrefix, &Opt.HTTP)
 	vfsflags.AddFlags(flagSet)
-	flags.BoolVarP(flagSet, &Opt.pathBucketMode, "force-path-style", "", Opt.pathBucketMode, "If true use path style access if false use virtual hosted style (default true)", "")
-	flags.StringVarP(flagSet, &Opt.hashName, "etag-hash", "", Opt.hashName, "Which hash to use for the ETag, or auto or blank for off", "")
-	flags.StringArrayVarP(flagSet, &Opt.authPair, "auth-key", "", Opt.authPair, "Set key pair for v4 authorization: access_key_id,secret_access_key", "")
-	flags.BoolVarP(flagSet, &Opt.noCleanup, "no-cleanup", "", Opt.noCleanup, "Not to cleanup empty folder after object is deleted", "")
+	flags.BoolVarP(flagSet, &Opt.PathBucketMode, "force-path-style", "", Opt.PathBucketMode, "If true use path style access if false use virtual hosted style (default true)", "")
+	flags.StringVarP(flagSet, &Opt.HashName, "etag-hash", "", Opt.HashName, "Which hash to use for the ETag, or auto or blank for off", "")
+	flags.StringArrayVarP(flagSet, &Opt.AuthPair, "auth-key", "", Opt.AuthPair, "Set key pair for v4 authorization: access_key_id,secret_access_key", "")
+	flags.BoolVarP(flagSet, &Opt.NoCleanup, "no-cleanup", "", Opt.NoCleanup, "Not to cleanup empty folder after object is deleted", "")
+	servelib.AddRc("s3", startRc)
 }
 
 //go:embed serve_s3.md
@@ -54,16 +58,12 @@ var Command = &cobra.Command{
 		cmd.CheckArgs(1, 1, command, args)
 		f := cmd.NewFsSrc(args)
 
-		if Opt.hashName == "auto" {
-			Opt.hashType = f.Hashes().GetOne()
-		} else if Opt.hashName != "" {
-			err := Opt.hashType.Set(Opt.hashName)
-			if err != nil {
-				return err
-			}
+		err := Opt.setHashType(f)
+		if err != nil {
+			return err
 		}
 		cmd.Run(false, false, command, func() error {
-			s, err := newServer(context.Background(), f, &Opt)
+			s, err := newServer(context.Background(), f, &Opt, &vfsflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -79,3 +79,41 @@ var Command = &cobra.Command{
 		return nil
 	},
 }
+
+// setHashType sets HashType from HashName using f for "auto"
+func (opt *Options) setHashType(f fs.Fs) error {
+	opt.HashType = hash.None
+	if opt.HashName == "auto" {
+		opt.HashType = f.Hashes().GetOne()
+	} else if opt.HashName != "" {
+		return opt.HashType.Set(opt.HashName)
+	}
+	return nil
+}
+
+// startRc starts an S3 server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	err = opt.setHashType(f)
+	if err != nil {
+		return nil, rc.NewErrParamInvalid(err)
+	}
+	s, err := newServer(ctx, f, &opt, vfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	s.Bind(s.Router())
+	err = s.serve()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/rclone/rclone/fstest"
 	httplib "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/random"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,14 +43,14 @@ func serveS3(f fs.Fs) (testURL string, keyid string, keysec string) {
 	keysec = random.String(16)
 	serveropt := &Options{
 		HTTP:           httplib.DefaultCfg(),
-		pathBucketMode: true,
-		hashName:       "",
-		hashType:       hash.None,
-		authPair:       []string{fmt.Sprintf("%s,%s", keyid, keysec)},
+		PathBucketMode: true,
+		HashName:       "",
+		HashType:       hash.None,
+		AuthPair:       []string{fmt.Sprintf("%s,%s", keyid, keysec)},
 	}
 
 	serveropt.HTTP.ListenAddr = []string{endpoint}
-	w, _ := newServer(context.Background(), f, serveropt)
+	w, _ := newServer(context.Background(), f, serveropt, &vfsflags.Opt)
 	router := w.Router()
 
 	w.Bind(router)
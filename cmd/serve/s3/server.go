@@ -13,17 +13,17 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	httplib "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/vfs"
-	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/rclone/rclone/vfs/vfscommon"
 )
 
 // Options contains options for the http Server
 type Options struct {
 	//TODO add more options
-	pathBucketMode bool
-	hashName       string
-	hashType       hash.Type
-	authPair       []string
-	noCleanup      bool
+	PathBucketMode bool
+	HashName       string
+	HashType       hash.Type `json:"-"` // set from HashName
+	AuthPair       []string
+	NoCleanup      bool
 	HTTP           httplib.Config
 }
 
@@ -38,25 +38,25 @@ type Server struct {
 }
 
 // Make a new S3 Server to serve the remote
-func newServer(ctx context.Context, f fs.Fs, opt *Options) (s *Server, err error) {
+func newServer(ctx context.Context, f fs.Fs, opt *Options, vfsOpt *vfscommon.Options) (s *Server, err error) {
 	w := &Server{
 		f:   f,
 		ctx: ctx,
-		vfs: vfs.New(f, &vfsflags.Opt),
+		vfs: vfs.New(f, vfsOpt),
 	}
 
-	if len(opt.authPair) == 0 {
+	if len(opt.AuthPair) == 0 {
 		fs.Logf("serve s3", "No auth provided so allowing anonymous access")
 	}
 
 	var newLogger logger
 	w.faker = gofakes3.New(
 		newBackend(w.vfs, opt),
-		gofakes3.WithHostBucket(!opt.pathBucketMode),
+		gofakes3.WithHostBucket(!opt.PathBucketMode),
 		gofakes3.WithLogger(newLogger),
 		gofakes3.WithRequestID(rand.Uint64()),
 		gofakes3.WithoutVersioning(),
-		gofakes3.WithV4Auth(authlistResolver(opt.authPair)),
+		gofakes3.WithV4Auth(authlistResolver(opt.AuthPair)),
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
 
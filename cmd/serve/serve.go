@@ -56,6 +56,10 @@ subcommand to specify the protocol, e.g.
     rclone serve http remote:
 
 Each subcommand has its own options which you can see in their help.
+
+Servers can also be started and stopped on a running rclone with the
+[serve/start](/rc/#serve-start) and [serve/stop](/rc/#serve-stop) API
+calls.
 `,
 	Annotations: map[string]string{
 		"versionIntroduced": "v1.39",
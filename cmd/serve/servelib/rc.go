@@ -0,0 +1,260 @@
+package servelib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// Info describes a server started with serve/start
+type Info struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Fs        string    `json:"fs"`
+	Addr      string    `json:"addr"`
+	StartedOn time.Time `json:"startedOn"`
+}
+
+// server is a running server
+type server struct {
+	Info
+	handle Handle
+}
+
+var (
+	// mutex to protect all the variables in this block
+	serversMu sync.Mutex
+	// Map of ID => running server
+	servers = map[string]*server{}
+	// Number of the last server started
+	serverNumber int
+)
+
+// params which serve/start reads itself rather than the protocol
+var startParams = map[string]bool{
+	"type":   true,
+	"fs":     true,
+	"opt":    true,
+	"vfsOpt": true,
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/start",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           startRc,
+		Title:        "Start a server serving a remote over a protocol",
+		Help: `This starts a server of the given type, such as webdav or sftp,
+serving a remote in the same way as the "rclone serve" commands do.
+
+This takes the following parameters:
+
+- type - the protocol to serve, as returned by serve/types (required)
+- fs - the remote path to serve (required)
+- opt - a JSON object with the options of the protocol in (optional)
+- vfsOpt - a JSON object with VFS options in (optional)
+
+The opt are the options of the "rclone serve" command for the protocol
+named as they are in its Options struct, e.g. "ListenAddr" for
+"--addr". For the HTTP based protocols (http, webdav, restic, s3) the
+HTTP server options are in "HTTP" and the authentication options are
+in "Auth". Any options not set take the defaults of the serve command.
+It is an error to set an option the protocol doesn't have.
+
+The vfsOpt are as described in options/get and can be seen in the
+"vfs" section when running
+
+    rclone rc options/get
+
+The --auth-proxy is not supported with serve/start.
+
+This returns
+
+- id - the ID of the server, to pass to serve/stop
+- addr - the address the server is listening on
+
+Examples:
+
+    rclone rc serve/start type=webdav fs=remote:path opt='{"HTTP": {"ListenAddr": [":8080"]}}'
+    rclone rc serve/start type=sftp fs=remote: opt='{"ListenAddr": ":2022", "User": "user", "Pass": "pass"}' vfsOpt='{"CacheMode": 2}'
+    rclone rc serve/start type=nfs fs=remote: opt='{"ListenAddr": "localhost:2049"}' vfsOpt='{"CacheMode": 3}'
+`,
+	})
+}
+
+// startRc starts a server
+func startRc(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	serveType, err := in.GetString("type")
+	if err != nil {
+		return nil, err
+	}
+	fn := getStartFn(serveType)
+	if fn == nil {
+		return nil, rc.NewErrParamInvalid(fmt.Errorf("unknown serve type %q - use one of %s", serveType, strings.Join(types(), ", ")))
+	}
+	for key := range in {
+		if !startParams[key] && !strings.HasPrefix(key, "_") {
+			return nil, rc.NewErrParamInvalid(fmt.Errorf("unknown parameter %q - put the options of the protocol in opt", key))
+		}
+	}
+	f, err := rc.GetFs(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server outlives this call so give it a context which
+	// won't be cancelled but keeps any _config and _filter
+	serverCtx := fs.CopyConfig(context.Background(), ctx)
+	serverCtx = filter.CopyConfig(serverCtx, ctx)
+	handle, err := fn(serverCtx, f, in)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := ""
+	if a := handle.Addr(); a != nil {
+		addr = a.String()
+	}
+	serversMu.Lock()
+	serverNumber++
+	s := &server{
+		Info: Info{
+			ID:        fmt.Sprintf("%s-%d", serveType, serverNumber),
+			Type:      serveType,
+			Fs:        fs.ConfigString(f),
+			Addr:      addr,
+			StartedOn: time.Now(),
+		},
+		handle: handle,
+	}
+	servers[s.ID] = s
+	serversMu.Unlock()
+
+	// Remove the server from the list when it stops
+	go func() {
+		handle.Wait()
+		serversMu.Lock()
+		delete(servers, s.ID)
+		serversMu.Unlock()
+	}()
+
+	fs.Debugf(f, "Started %s server %s on %s", serveType, s.ID, s.Addr)
+	return rc.Params{
+		"id":   s.ID,
+		"addr": s.Addr,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/stop",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           stopRc,
+		Title:        "Stop a server started with serve/start",
+		Help: `This takes the following parameters:
+
+- id - the ID of the server as returned by serve/start or serve/list (required)
+
+Example:
+
+    rclone rc serve/stop id=webdav-1
+`,
+	})
+}
+
+// stopRc stops a server
+func stopRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	id, err := in.GetString("id")
+	if err != nil {
+		return nil, err
+	}
+	serversMu.Lock()
+	s, found := servers[id]
+	if found {
+		delete(servers, id)
+	}
+	serversMu.Unlock()
+	if !found {
+		return nil, errors.New("server not found")
+	}
+	err = s.handle.Shutdown()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stop server %s: %w", id, err)
+	}
+	s.handle.Wait()
+	return nil, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/list",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           listRc,
+		Title:        "Show the servers started with serve/start",
+		Help: `This takes no parameters and returns
+
+- list - the running servers in the order they were started with
+    - id - the ID of the server to pass to serve/stop
+    - type - the protocol being served
+    - fs - the remote being served
+    - addr - the address the server is listening on
+    - startedOn - when the server was started
+
+Example:
+
+    rclone rc serve/list
+`,
+	})
+}
+
+// listRc lists the running servers
+func listRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	serversMu.Lock()
+	list := make([]Info, 0, len(servers))
+	for _, s := range servers {
+		list = append(list, s.Info)
+	}
+	serversMu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartedOn.Before(list[j].StartedOn)
+	})
+	return rc.Params{
+		"list": list,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "serve/types",
+		AuthRequired: true,
+		Fn:           typesRc,
+		Title:        "Show the protocols which can be served with serve/start",
+		Help: `This takes no parameters and returns
+
+- types - list of protocols, e.g. "webdav", "sftp", which can be
+  passed to serve/start as the type parameter
+
+Example:
+
+    rclone rc serve/types
+`,
+	})
+}
+
+// typesRc returns the serve protocols
+func typesRc(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	return rc.Params{
+		"types": types(),
+	}, nil
+}
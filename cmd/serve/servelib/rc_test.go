@@ -0,0 +1,196 @@
+package servelib
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOptions are the options for the test protocol
+type testOptions struct {
+	ListenAddr []string
+	Greeting   string
+}
+
+// testDefaultOpt are the default options for the test protocol
+var testDefaultOpt = testOptions{
+	ListenAddr: []string{"localhost:0"},
+	Greeting:   "hello",
+}
+
+// testOpt are the options for the test protocol which share their
+// ListenAddr with testDefaultOpt like the Opt of the serve commands
+var testOpt = testDefaultOpt
+
+// testServer is a server for the test protocol
+type testServer struct {
+	opt      testOptions
+	listener net.Listener
+}
+
+func (s *testServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *testServer) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return nil
+		}
+		_, _ = conn.Write([]byte(s.opt.Greeting))
+		_ = conn.Close()
+	}
+}
+
+func (s *testServer) Shutdown() error {
+	return s.listener.Close()
+}
+
+func init() {
+	AddRc("test", func(ctx context.Context, f fs.Fs, in rc.Params) (Handle, error) {
+		opt := testOpt
+		err := GetOpt(in, &opt)
+		if err != nil {
+			return nil, err
+		}
+		s := &testServer{opt: opt}
+		s.listener, err = net.Listen("tcp", opt.ListenAddr[0])
+		if err != nil {
+			return nil, err
+		}
+		return Background("test", s), nil
+	})
+}
+
+func TestGetStrict(t *testing.T) {
+	var opt testOptions
+	require.NoError(t, getStrict(rc.Params{}, "opt", &opt))
+	assert.Equal(t, testOptions{}, opt)
+
+	require.NoError(t, getStrict(rc.Params{"opt": rc.Params{"Greeting": "hi"}}, "opt", &opt))
+	assert.Equal(t, "hi", opt.Greeting)
+
+	require.NoError(t, getStrict(rc.Params{"opt": `{"ListenAddr": [":1234"]}`}, "opt", &opt))
+	assert.Equal(t, []string{":1234"}, opt.ListenAddr)
+	assert.Equal(t, "hi", opt.Greeting)
+
+	// Setting an option doesn't change the options copied from
+	opt = testOpt
+	require.NoError(t, getStrict(rc.Params{"opt": `{"ListenAddr": [":1234"]}`}, "opt", &opt))
+	assert.Equal(t, []string{":1234"}, opt.ListenAddr)
+	assert.Equal(t, []string{"localhost:0"}, testOpt.ListenAddr)
+	assert.Equal(t, []string{"localhost:0"}, testDefaultOpt.ListenAddr)
+
+	err := getStrict(rc.Params{"opt": rc.Params{"Potato": true}}, "opt", &opt)
+	require.Error(t, err)
+	assert.True(t, rc.IsErrParamInvalid(err))
+	assert.Contains(t, err.Error(), "Potato")
+}
+
+func TestGetVFSOpt(t *testing.T) {
+	vfsOpt, err := GetVFSOpt(rc.Params{"vfsOpt": rc.Params{"ReadOnly": true}})
+	require.NoError(t, err)
+	assert.True(t, vfsOpt.ReadOnly)
+
+	_, err = GetVFSOpt(rc.Params{"vfsOpt": rc.Params{"Potato": true}})
+	assert.Error(t, err)
+}
+
+// call the rc function at path with in
+func call(t *testing.T, path string, in rc.Params) (rc.Params, error) {
+	fn := rc.Calls.Get(path)
+	require.NotNil(t, fn, path)
+	return fn.Fn(context.Background(), in)
+}
+
+func TestRc(t *testing.T) {
+	out, err := call(t, "serve/types", rc.Params{})
+	require.NoError(t, err)
+	assert.Contains(t, out["types"], "test")
+
+	// Errors
+	_, err = call(t, "serve/start", rc.Params{"type": "potato", "fs": t.TempDir()})
+	assert.True(t, rc.IsErrParamInvalid(err), err)
+	_, err = call(t, "serve/start", rc.Params{"type": "test", "fs": t.TempDir(), "Greeting": "hi"})
+	assert.True(t, rc.IsErrParamInvalid(err), err)
+	_, err = call(t, "serve/start", rc.Params{"type": "test", "fs": t.TempDir(), "opt": rc.Params{"Potato": "hi"}})
+	assert.True(t, rc.IsErrParamInvalid(err), err)
+	_, err = call(t, "serve/stop", rc.Params{"id": "potato"})
+	assert.Error(t, err)
+
+	// Start a server
+	out, err = call(t, "serve/start", rc.Params{
+		"type": "test",
+		"fs":   t.TempDir(),
+		"opt":  rc.Params{"Greeting": "hello from test"},
+	})
+	require.NoError(t, err)
+	id, addr := out["id"].(string), out["addr"].(string)
+	assert.Contains(t, id, "test-")
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	buf := make([]byte, 100)
+	n, _ := conn.Read(buf)
+	assert.Equal(t, "hello from test", string(buf[:n]))
+	require.NoError(t, conn.Close())
+
+	out, err = call(t, "serve/list", rc.Params{})
+	require.NoError(t, err)
+	list := out["list"].([]Info)
+	require.Equal(t, 1, len(list))
+	assert.Equal(t, id, list[0].ID)
+	assert.Equal(t, "test", list[0].Type)
+	assert.Equal(t, addr, list[0].Addr)
+
+	// Stop it
+	_, err = call(t, "serve/stop", rc.Params{"id": id})
+	require.NoError(t, err)
+	_, err = net.Dial("tcp", addr)
+	assert.Error(t, err)
+
+	out, err = call(t, "serve/list", rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(out["list"].([]Info)))
+
+	_, err = call(t, "serve/stop", rc.Params{"id": id})
+	assert.Error(t, err)
+}
+
+func TestRcStartTwice(t *testing.T) {
+	// Start two servers with different listen addresses
+	var ids, addrs []string
+	for _, listenAddr := range []string{"localhost:0", "127.0.0.1:0"} {
+		out, err := call(t, "serve/start", rc.Params{
+			"type": "test",
+			"fs":   t.TempDir(),
+			"opt":  rc.Params{"ListenAddr": []string{listenAddr}},
+		})
+		require.NoError(t, err)
+		ids = append(ids, out["id"].(string))
+		addrs = append(addrs, out["addr"].(string))
+	}
+	defer func() {
+		for _, id := range ids {
+			_, err := call(t, "serve/stop", rc.Params{"id": id})
+			assert.NoError(t, err)
+		}
+	}()
+	assert.NotEqual(t, addrs[0], addrs[1])
+
+	// The defaults of the protocol are unchanged
+	assert.Equal(t, []string{"localhost:0"}, testOpt.ListenAddr)
+	assert.Equal(t, []string{"localhost:0"}, testDefaultOpt.ListenAddr)
+
+	// So a server started without a listen address uses the default
+	out, err := call(t, "serve/start", rc.Params{"type": "test", "fs": t.TempDir()})
+	require.NoError(t, err)
+	ids = append(ids, out["id"].(string))
+}
@@ -0,0 +1,210 @@
+// Package servelib contains the parts common to the serve protocols
+// needed to start and stop them with the rc.
+package servelib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/rclone/rclone/vfs/vfsflags"
+)
+
+// Handle is a running server started with serve/start
+type Handle interface {
+	// Addr returns the address the server is listening on
+	Addr() net.Addr
+	// Shutdown stops the server
+	Shutdown() error
+	// Wait blocks until the server has stopped
+	Wait()
+}
+
+// StartFn starts a server serving f with the options in the
+// parameters in. It should return when the server is running.
+//
+// ctx is not cancelled when the rc call returns so can be used for
+// the lifetime of the server.
+type StartFn func(ctx context.Context, f fs.Fs, in rc.Params) (Handle, error)
+
+var (
+	startFnsMu sync.Mutex
+	startFns   = map[string]StartFn{}
+)
+
+// AddRc adds a serve protocol called name so it can be started with
+// serve/start
+func AddRc(name string, fn StartFn) {
+	startFnsMu.Lock()
+	defer startFnsMu.Unlock()
+	startFns[name] = fn
+}
+
+// getStartFn returns the StartFn for the protocol called name or nil
+func getStartFn(name string) StartFn {
+	startFnsMu.Lock()
+	defer startFnsMu.Unlock()
+	return startFns[name]
+}
+
+// types returns the names of the serve protocols sorted
+func types() []string {
+	startFnsMu.Lock()
+	defer startFnsMu.Unlock()
+	names := make([]string, 0, len(startFns))
+	for name := range startFns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unshare replaces the slices, maps and pointers in v with copies.
+//
+// The options are copied from globals such as the Opt of the protocol
+// with a plain assignment which shares these with the global. Decoding
+// JSON into a slice, map or pointer reuses what is there, so without
+// this setting an option would change the global too.
+func unshare(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				unshare(field)
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			unshare(v.Index(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		for i := 0; i < c.Len(); i++ {
+			unshare(c.Index(i))
+		}
+		v.Set(c)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+		v.Set(c)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		unshare(c.Elem())
+		v.Set(c)
+	}
+}
+
+// getStrict reads the parameter key from in into out which should be
+// a pointer to a struct filled in with the defaults. It is not an
+// error if key is missing.
+//
+// Unlike rc.Params.GetStruct it is an error to set a field which
+// out doesn't have so misspelt options aren't silently ignored.
+//
+// The defaults in out may share their slices and maps with a global
+// as they are copies of it. These are copied before being decoded
+// into so the global isn't changed.
+func getStrict(in rc.Params, key string, out interface{}) error {
+	value, ok := in[key]
+	if !ok {
+		return nil
+	}
+	unshare(reflect.ValueOf(out).Elem())
+	var data []byte
+	if s, ok := value.(string); ok {
+		// JSON passed as a string, e.g. from rclone rc
+		data = []byte(s)
+	} else {
+		var err error
+		data, err = json.Marshal(value)
+		if err != nil {
+			return rc.NewErrParamInvalid(fmt.Errorf("key %q: %w", key, err))
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(out)
+	if err != nil {
+		return rc.NewErrParamInvalid(fmt.Errorf("key %q: %w", key, err))
+	}
+	return nil
+}
+
+// GetOpt reads the "opt" parameter from in into opt which should be
+// a pointer to the Options of the protocol filled in with its
+// defaults.
+func GetOpt(in rc.Params, opt interface{}) error {
+	return getStrict(in, "opt", opt)
+}
+
+// GetVFSOpt returns the VFS options for the server made from the
+// "vfsOpt" parameter in in on top of the global VFS options.
+func GetVFSOpt(in rc.Params) (*vfscommon.Options, error) {
+	vfsOpt := vfsflags.Opt
+	err := getStrict(in, "vfsOpt", &vfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	return &vfsOpt, nil
+}
+
+// Server is a server whose Serve method blocks until Shutdown is
+// called
+type Server interface {
+	// Addr returns the address the server is listening on
+	Addr() net.Addr
+	// Serve runs the server until Shutdown is called
+	Serve() error
+	// Shutdown stops the server
+	Shutdown() error
+}
+
+// background runs a Server in the background
+type background struct {
+	Server
+	done chan struct{}
+}
+
+// Background runs s.Serve in the background returning a Handle to
+// control it. Any error Serve returns is logged.
+func Background(what string, s Server) Handle {
+	b := &background{
+		Server: s,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(b.done)
+		err := s.Serve()
+		if err != nil {
+			fs.Errorf(nil, "serve %s: server on %v failed: %v", what, s.Addr(), err)
+		}
+	}()
+	return b
+}
+
+// Wait blocks until Serve has returned
+func (b *background) Wait() {
+	<-b.done
+}
@@ -29,7 +29,7 @@ import (
 	"github.com/rclone/rclone/lib/env"
 	"github.com/rclone/rclone/lib/file"
 	"github.com/rclone/rclone/vfs"
-	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -45,7 +45,7 @@ type server struct {
 	proxy    *proxy.Proxy
 }
 
-func newServer(ctx context.Context, f fs.Fs, opt *Options) *server {
+func newServer(ctx context.Context, f fs.Fs, opt *Options, vfsOpt *vfscommon.Options) *server {
 	s := &server{
 		f:        f,
 		ctx:      ctx,
@@ -55,7 +55,7 @@ func newServer(ctx context.Context, f fs.Fs, opt *Options) *server {
 	if proxyflags.Opt.AuthProxy != "" {
 		s.proxy = proxy.New(ctx, &proxyflags.Opt)
 	} else {
-		s.vfs = vfs.New(f, &vfsflags.Opt)
+		s.vfs = vfs.New(f, vfsOpt)
 	}
 	return s
 }
@@ -279,8 +279,8 @@ func (s *server) serve() (err error) {
 }
 
 // Addr returns the address the server is listening on
-func (s *server) Addr() string {
-	return s.listener.Addr().String()
+func (s *server) Addr() net.Addr {
+	return s.listener.Addr()
 }
 
 // Serve runs the sftp server in the background.
@@ -299,14 +299,22 @@ func (s *server) Wait() {
 	<-s.waitChan
 }
 
-// Close shuts the running server down
-func (s *server) Close() {
+// Shutdown shuts the running server down
+func (s *server) Shutdown() error {
 	err := s.listener.Close()
 	if err != nil {
-		fs.Errorf(nil, "Error on closing SFTP server: %v", err)
-		return
+		return err
 	}
 	close(s.waitChan)
+	return nil
+}
+
+// Close shuts the running server down logging any errors
+func (s *server) Close() {
+	err := s.Shutdown()
+	if err != nil {
+		fs.Errorf(nil, "Error on closing SFTP server: %v", err)
+	}
 }
 
 func loadPrivateKey(keyPath string) (ssh.Signer, error) {
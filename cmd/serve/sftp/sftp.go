@@ -6,10 +6,12 @@ package sftp
 
 import (
 	"context"
+	"errors"
 
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/rc"
@@ -56,6 +58,29 @@ func init() {
 	vfsflags.AddFlags(Command.Flags())
 	proxyflags.AddFlags(Command.Flags())
 	AddFlags(Command.Flags(), &Opt)
+	servelib.AddRc("sftp", startRc)
+}
+
+// startRc starts an SFTP server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Stdio {
+		return nil, rc.NewErrParamInvalid(errors.New("can't serve on stdio with serve/start"))
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	s := newServer(ctx, f, &opt, vfsOpt)
+	err = s.Serve()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 // Command definition for cobra
@@ -131,7 +156,7 @@ provided by OpenSSH in this case.
 			if Opt.Stdio {
 				return serveStdio(f)
 			}
-			s := newServer(context.Background(), f, &Opt)
+			s := newServer(context.Background(), f, &Opt, &vfsflags.Opt)
 			err := s.Serve()
 			if err != nil {
 				return err
@@ -19,6 +19,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,11 +47,11 @@ func TestSftp(t *testing.T) {
 		opt.User = testUser
 		opt.Pass = testPass
 
-		w := newServer(context.Background(), f, &opt)
+		w := newServer(context.Background(), f, &opt, &vfsflags.Opt)
 		require.NoError(t, w.serve())
 
 		// Read the host and port we started on
-		addr := w.Addr()
+		addr := w.Addr().String()
 		colon := strings.LastIndex(addr, ":")
 
 		// Config for the backend we'll use to connect to the server
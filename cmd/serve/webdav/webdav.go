@@ -19,13 +19,16 @@ import (
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/proxy"
 	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
+	"github.com/rclone/rclone/cmd/serve/servelib"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/rc"
 	libhttp "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/http/serve"
 	"github.com/rclone/rclone/lib/systemd"
 	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/webdav"
@@ -66,6 +69,7 @@ func init() {
 	proxyflags.AddFlags(flagSet)
 	flags.StringVarP(flagSet, &Opt.HashName, "etag-hash", "", "", "Which hash to use for the ETag, or auto or blank for off", "")
 	flags.BoolVarP(flagSet, &Opt.DisableGETDir, "disable-dir-list", "", false, "Disable HTML directory list on GET request for a directory", "")
+	servelib.AddRc("webdav", startRc)
 }
 
 // Command definition for cobra
@@ -125,20 +129,12 @@ https://learn.microsoft.com/en-us/office/troubleshoot/powerpoint/office-opens-bl
 		} else {
 			cmd.CheckArgs(0, 0, command, args)
 		}
-		Opt.HashType = hash.None
-		if Opt.HashName == "auto" {
-			Opt.HashType = f.Hashes().GetOne()
-		} else if Opt.HashName != "" {
-			err := Opt.HashType.Set(Opt.HashName)
-			if err != nil {
-				return err
-			}
-		}
-		if Opt.HashType != hash.None {
-			fs.Debugf(f, "Using hash %v for ETag", Opt.HashType)
+		err := Opt.setHashType(f)
+		if err != nil {
+			return err
 		}
 		cmd.Run(false, false, command, func() error {
-			s, err := newWebDAV(context.Background(), f, &Opt)
+			s, err := newWebDAV(context.Background(), f, &Opt, &vfsflags.Opt)
 			if err != nil {
 				return err
 			}
@@ -154,6 +150,49 @@ https://learn.microsoft.com/en-us/office/troubleshoot/powerpoint/office-opens-bl
 	},
 }
 
+// setHashType sets HashType from HashName using f for "auto"
+func (opt *Options) setHashType(f fs.Fs) error {
+	opt.HashType = hash.None
+	if opt.HashName == "auto" {
+		opt.HashType = f.Hashes().GetOne()
+	} else if opt.HashName != "" {
+		err := opt.HashType.Set(opt.HashName)
+		if err != nil {
+			return err
+		}
+	}
+	if opt.HashType != hash.None {
+		fs.Debugf(f, "Using hash %v for ETag", opt.HashType)
+	}
+	return nil
+}
+
+// startRc starts a WebDAV server for serve/start
+func startRc(ctx context.Context, f fs.Fs, in rc.Params) (servelib.Handle, error) {
+	opt := Opt
+	err := servelib.GetOpt(in, &opt)
+	if err != nil {
+		return nil, err
+	}
+	vfsOpt, err := servelib.GetVFSOpt(in)
+	if err != nil {
+		return nil, err
+	}
+	err = opt.setHashType(f)
+	if err != nil {
+		return nil, rc.NewErrParamInvalid(err)
+	}
+	s, err := newWebDAV(ctx, f, &opt, vfsOpt)
+	if err != nil {
+		return nil, err
+	}
+	err = s.serve()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 // WebDAV is a webdav.FileSystem interface
 //
 // A FileSystem implements access to a collection of named files. The elements
@@ -180,7 +219,7 @@ type WebDAV struct {
 var _ webdav.FileSystem = (*WebDAV)(nil)
 
 // Make a new WebDAV to serve the remote
-func newWebDAV(ctx context.Context, f fs.Fs, opt *Options) (w *WebDAV, err error) {
+func newWebDAV(ctx context.Context, f fs.Fs, opt *Options, vfsOpt *vfscommon.Options) (w *WebDAV, err error) {
 	w = &WebDAV{
 		f:   f,
 		ctx: ctx,
@@ -191,7 +230,7 @@ func newWebDAV(ctx context.Context, f fs.Fs, opt *Options) (w *WebDAV, err error
 		// override auth
 		w.opt.Auth.CustomAuthFn = w.auth
 	} else {
-		w._vfs = vfs.New(f, &vfsflags.Opt)
+		w._vfs = vfs.New(f, vfsOpt)
 	}
 
 	w.Server, err = libhttp.NewServer(ctx,
@@ -360,7 +399,7 @@ func (w *WebDAV) serveDir(rw http.ResponseWriter, r *http.Request, dirRemote str
 	// Make the entries for display
 	directory := serve.NewDirectory(dirRemote, w.Server.HTMLTemplate())
 	for _, node := range dirEntries {
-		if vfsflags.Opt.NoModTime {
+		if VFS.Opt.NoModTime {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), time.Time{})
 		} else {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), node.ModTime().UTC())
@@ -25,6 +25,7 @@ import (
 	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/rclone/rclone/fs/filter"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/webdav"
@@ -58,7 +59,7 @@ func TestWebDav(t *testing.T) {
 		opt.HashType = hash.MD5
 
 		// Start the server
-		w, err := newWebDAV(context.Background(), f, &opt)
+		w, err := newWebDAV(context.Background(), f, &opt, &vfsflags.Opt)
 		require.NoError(t, err)
 		require.NoError(t, w.serve())
 
@@ -104,7 +105,7 @@ func TestHTTPFunction(t *testing.T) {
 	opt.Template.Path = testTemplate
 
 	// Start the server
-	w, err := newWebDAV(context.Background(), f, &opt)
+	w, err := newWebDAV(context.Background(), f, &opt, &vfsflags.Opt)
 	assert.NoError(t, err)
 	require.NoError(t, w.serve())
 	defer func() {
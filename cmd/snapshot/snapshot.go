@@ -0,0 +1,406 @@
+// Package snapshot provides the snapshot command.
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/sync"
+	"github.com/spf13/cobra"
+)
+
+// Options for making and pruning snapshots
+type Options struct {
+	KeepLast    int // keep this many of the most recent snapshots
+	KeepDaily   int // keep the last snapshot of this many days
+	KeepWeekly  int // keep the last snapshot of this many weeks
+	KeepMonthly int // keep the last snapshot of this many months
+	KeepYearly  int // keep the last snapshot of this many years
+}
+
+// pruning returns true if any of the keep options are set
+func (opt *Options) pruning() bool {
+	return opt.KeepLast > 0 || opt.KeepDaily > 0 || opt.KeepWeekly > 0 || opt.KeepMonthly > 0 || opt.KeepYearly > 0
+}
+
+var (
+	opt = Options{}
+)
+
+// Format of the snapshot directory names - these are in UTC
+const snapshotFormat = "2006-01-02T150405Z"
+
+// Suffix of the manifest file stored next to each snapshot
+const manifestSuffix = ".json"
+
+// For overriding in unittests.
+var timeNow = time.Now
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.IntVarP(cmdFlags, &opt.KeepLast, "keep-last", "", opt.KeepLast, "Keep this many of the most recent snapshots", "")
+	flags.IntVarP(cmdFlags, &opt.KeepDaily, "keep-daily", "", opt.KeepDaily, "Keep the last snapshot of each day for this many days", "")
+	flags.IntVarP(cmdFlags, &opt.KeepWeekly, "keep-weekly", "", opt.KeepWeekly, "Keep the last snapshot of each week for this many weeks", "")
+	flags.IntVarP(cmdFlags, &opt.KeepMonthly, "keep-monthly", "", opt.KeepMonthly, "Keep the last snapshot of each month for this many months", "")
+	flags.IntVarP(cmdFlags, &opt.KeepYearly, "keep-yearly", "", opt.KeepYearly, "Keep the last snapshot of each year for this many years", "")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "snapshot source:path dest:path",
+	Short: `Make a dated incremental snapshot of source:path in dest:path.`,
+	Long: `
+Make a snapshot of source:path in a new directory in dest:path named
+after the current time in UTC, for example
+` + "`dest:path/2023-11-02T101112Z`" + `.
+
+    rclone snapshot /home/user remote:backups --keep-daily 7 --keep-weekly 4 --keep-monthly 12
+
+Each snapshot is a complete copy of the source. However files which
+haven't changed since the previous snapshot are copied from it with
+server-side copies (like ` + "`--copy-dest`" + `) rather than being
+uploaded again. On backends where a server-side copy is a hardlink
+(the local backend with ` + "`--local-copy-is-hardlink`" + ` and the sftp
+backend with ` + "`--sftp-copy-is-hardlink`" + `) unchanged files take up no
+extra space, like rsnapshot. If the destination doesn't support
+server-side copies then every file is uploaded in full.
+
+A manifest is written next to each snapshot, for example
+` + "`dest:path/2023-11-02T101112Z.json`" + `. It records the source, the
+previous snapshot, when the snapshot was started and finished, and the
+size, modification time and (if the destination has a fast hash) hash
+of every file in ` + "`rclone lsjson`" + ` format.
+
+After the snapshot is made old snapshots are pruned if any of the
+` + "`--keep-*`" + ` flags are set. A snapshot is kept if any of the
+flags select it, the rest are deleted along with their manifests.
+
+- ` + "`--keep-last N`" + ` keeps the N most recent snapshots
+- ` + "`--keep-daily N`" + ` keeps the most recent snapshot of each of the last N days with snapshots
+- ` + "`--keep-weekly N`" + ` keeps the most recent snapshot of each of the last N weeks with snapshots
+- ` + "`--keep-monthly N`" + ` keeps the most recent snapshot of each of the last N months with snapshots
+- ` + "`--keep-yearly N`" + ` keeps the most recent snapshot of each of the last N years with snapshots
+
+If none of them are set then no snapshots are deleted.
+
+Snapshots without a manifest are from runs which failed, so are never
+selected by the flags and are deleted when pruning, and aren't used as
+the previous snapshot. Don't make two snapshots in the same dest:path
+at once.
+
+Only directories in dest:path whose names are snapshot times are
+considered to be snapshots - anything else is left alone.
+
+The normal filtering flags can be used to choose what goes in the
+snapshot. Use ` + "`--dry-run`" + ` to see what would be pruned.
+`,
+	Annotations: map[string]string{
+		"versionIntroduced": "v1.66",
+		"groups":            "Copy,Filter,Important",
+	},
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fsrc, fdst := cmd.NewFsSrcDst(args)
+		cmd.Run(true, true, command, func() error {
+			return Snapshot(context.Background(), fsrc, fdst, &opt)
+		})
+	},
+}
+
+// Manifest describes a snapshot
+type Manifest struct {
+	Name     string                     `json:"name"`               // name of the snapshot directory
+	Source   string                     `json:"source"`             // the source the snapshot was made from
+	Previous string                     `json:"previous,omitempty"` // name of the previous snapshot if any
+	Started  time.Time                  `json:"started"`            // when the snapshot was started
+	Finished time.Time                  `json:"finished"`           // when the snapshot was finished
+	Files    int64                      `json:"files"`              // number of files in the snapshot
+	Bytes    int64                      `json:"bytes"`              // total size of the files in the snapshot
+	Entries  []*operations.ListJSONItem `json:"entries"`            // the files in the snapshot
+}
+
+// listSnapshots returns the names of the snapshots in f sorted with
+// the most recent first.
+//
+// complete is set for the snapshots which have a manifest. Those
+// without are from runs which failed or are still running.
+func listSnapshots(ctx context.Context, f fs.Fs) (names []string, complete map[string]bool, err error) {
+	complete = map[string]bool{}
+	entries, err := f.List(ctx, "")
+	if errors.Is(err, fs.ErrorDirNotFound) {
+		return nil, complete, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	manifests := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Remote()
+		if _, ok := entry.(fs.Directory); !ok {
+			if strings.HasSuffix(name, manifestSuffix) {
+				manifests[strings.TrimSuffix(name, manifestSuffix)] = true
+			}
+			continue
+		}
+		if _, err := time.Parse(snapshotFormat, name); err == nil {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if manifests[name] {
+			complete[name] = true
+		}
+	}
+	// The names sort in time order
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, complete, nil
+}
+
+// subFs returns the Fs for dir in f
+func subFs(ctx context.Context, f fs.Fs, dir string) (fs.Fs, error) {
+	return cache.Get(ctx, fspath.JoinRootPath(fs.ConfigStringFull(f), dir))
+}
+
+// Snapshot makes a new snapshot of fsrc in fdst then prunes the old
+// snapshots according to opt.
+func Snapshot(ctx context.Context, fsrc, fdst fs.Fs, opt *Options) error {
+	snapshots, complete, err := listSnapshots(ctx, fdst)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	started := timeNow().UTC()
+	name := started.Format(snapshotFormat)
+	if len(snapshots) > 0 && snapshots[0] >= name {
+		return fmt.Errorf("snapshot %q is not older than the new snapshot %q", snapshots[0], name)
+	}
+	fsnapshot, err := subFs(ctx, fdst, name)
+	if err != nil {
+		return err
+	}
+
+	manifest := &Manifest{
+		Name:    name,
+		Source:  fs.ConfigString(fsrc),
+		Started: started,
+	}
+	syncCtx, ci := fs.AddConfig(ctx)
+	for _, snapshot := range snapshots {
+		if complete[snapshot] {
+			manifest.Previous = snapshot
+			break
+		}
+	}
+	if manifest.Previous != "" {
+		if fsnapshot.Features().Copy == nil {
+			fs.Logf(fdst, "Destination doesn't support server-side copy so unchanged files will be uploaded again")
+		} else {
+			ci.CopyDest = []string{fspath.JoinRootPath(fs.ConfigStringFull(fdst), manifest.Previous)}
+			ci.CompareDest = nil
+		}
+	}
+	fs.Infof(fdst, "Making snapshot %q", name)
+	err = sync.CopyDir(syncCtx, fsnapshot, fsrc, true)
+	if err != nil {
+		return fmt.Errorf("failed to make snapshot %q: %w", name, err)
+	}
+
+	err = writeManifest(ctx, fdst, fsnapshot, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest for snapshot %q: %w", name, err)
+	}
+
+	if !opt.pruning() {
+		return nil
+	}
+	complete[name] = true
+	return prune(ctx, fdst, append([]string{name}, snapshots...), complete, opt)
+}
+
+// writeManifest lists fsnapshot and writes the manifest to fdst
+func writeManifest(ctx context.Context, fdst, fsnapshot fs.Fs, manifest *Manifest) error {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(encodeManifest(ctx, pw, fsnapshot, manifest))
+	}()
+	_, err := operations.Rcat(ctx, fdst, manifest.Name+manifestSuffix, pr, timeNow(), nil)
+	// unblock encodeManifest if Rcat stopped reading early
+	_ = pr.Close()
+	return err
+}
+
+// encodeManifest lists fsnapshot writing manifest to out as indented
+// JSON.
+//
+// The entries are written as they are listed rather than being held
+// in memory, with the totals and the finish time after them.
+func encodeManifest(ctx context.Context, out io.Writer, fsnapshot fs.Fs, manifest *Manifest) error {
+	lsOpt := operations.ListJSONOpt{
+		Recurse:    true,
+		FilesOnly:  true,
+		NoMimeType: true,
+		ShowHash:   !fsnapshot.Features().SlowHash,
+	}
+	if hashType := fsnapshot.Hashes().GetOne(); lsOpt.ShowHash && hashType != 0 {
+		lsOpt.HashTypes = []string{hashType.String()}
+	}
+	w := bufio.NewWriter(out)
+	// writeFields writes the fields of the struct v without its braces
+	writeFields := func(v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "\t")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data[2 : len(data)-2])
+		return err
+	}
+	_, _ = w.WriteString("{\n")
+	err := writeFields(struct {
+		Name     string    `json:"name"`
+		Source   string    `json:"source"`
+		Previous string    `json:"previous,omitempty"`
+		Started  time.Time `json:"started"`
+	}{manifest.Name, manifest.Source, manifest.Previous, manifest.Started})
+	if err != nil {
+		return err
+	}
+	_, _ = w.WriteString(",\n\t\"entries\": [")
+	err = operations.ListJSON(ctx, fsnapshot, "", &lsOpt, func(item *operations.ListJSONItem) error {
+		data, err := json.MarshalIndent(item, "\t\t", "\t")
+		if err != nil {
+			return err
+		}
+		if manifest.Files > 0 {
+			_, _ = w.WriteString(",")
+		}
+		manifest.Files++
+		manifest.Bytes += item.Size
+		_, _ = w.WriteString("\n\t\t")
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil && !errors.Is(err, fs.ErrorDirNotFound) {
+		return err
+	}
+	if manifest.Files > 0 {
+		_, _ = w.WriteString("\n\t")
+	}
+	_, _ = w.WriteString("],\n")
+	manifest.Finished = timeNow().UTC()
+	err = writeFields(struct {
+		Files    int64     `json:"files"`
+		Bytes    int64     `json:"bytes"`
+		Finished time.Time `json:"finished"`
+	}{manifest.Files, manifest.Bytes, manifest.Finished})
+	if err != nil {
+		return err
+	}
+	_, _ = w.WriteString("\n}\n")
+	return w.Flush()
+}
+
+// keepSnapshots returns which of the snapshots, named in snapshotFormat
+// and sorted most recent first, opt says to keep.
+func keepSnapshots(names []string, opt *Options) []bool {
+	keep := make([]bool, len(names))
+	times := make([]time.Time, len(names))
+	for i, name := range names {
+		times[i], _ = time.Parse(snapshotFormat, name)
+	}
+	// keepBy keeps the most recent snapshot for each of the first n
+	// distinct values of key
+	keepBy := func(n int, key func(i int, t time.Time) string) {
+		lastKey := ""
+		for i, t := range times {
+			if n <= 0 {
+				break
+			}
+			k := key(i, t)
+			if k == lastKey {
+				continue
+			}
+			keep[i] = true
+			lastKey = k
+			n--
+		}
+	}
+	keepBy(opt.KeepLast, func(i int, t time.Time) string { return fmt.Sprint(i) })
+	keepBy(opt.KeepDaily, func(i int, t time.Time) string { return t.Format("2006-01-02") })
+	keepBy(opt.KeepWeekly, func(i int, t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%02d", year, week)
+	})
+	keepBy(opt.KeepMonthly, func(i int, t time.Time) string { return t.Format("2006-01") })
+	keepBy(opt.KeepYearly, func(i int, t time.Time) string { return t.Format("2006") })
+	if len(keep) > 0 {
+		// always keep the most recent snapshot
+		keep[0] = true
+	}
+	return keep
+}
+
+// prune deletes the snapshots and their manifests in fdst which opt
+// doesn't keep. snapshots should be sorted most recent first.
+//
+// Only the complete snapshots are considered by opt. The others are
+// from failed runs so are always deleted.
+//
+// It carries on if there is an error returning the last one.
+func prune(ctx context.Context, fdst fs.Fs, snapshots []string, complete map[string]bool, opt *Options) (lastErr error) {
+	var completeSnapshots []string
+	for _, name := range snapshots {
+		if complete[name] {
+			completeSnapshots = append(completeSnapshots, name)
+		}
+	}
+	keep := map[string]bool{}
+	for i, kept := range keepSnapshots(completeSnapshots, opt) {
+		if kept {
+			keep[completeSnapshots[i]] = true
+		}
+	}
+	for _, name := range snapshots {
+		if keep[name] {
+			fs.Debugf(fdst, "Keeping snapshot %q", name)
+			continue
+		}
+		if complete[name] {
+			fs.Infof(fdst, "Pruning snapshot %q", name)
+		} else {
+			fs.Infof(fdst, "Pruning incomplete snapshot %q", name)
+		}
+		err := operations.Purge(ctx, fdst, name)
+		if err != nil {
+			lastErr = fs.CountError(fmt.Errorf("failed to prune snapshot %q: %w", name, err))
+			fs.Errorf(fdst, "%v", lastErr)
+			continue
+		}
+		if !complete[name] {
+			continue
+		}
+		o, err := fdst.NewObject(ctx, name+manifestSuffix)
+		if errors.Is(err, fs.ErrorObjectNotFound) {
+			continue
+		}
+		if err == nil {
+			err = operations.DeleteFile(ctx, o)
+		}
+		if err != nil {
+			lastErr = fs.CountError(fmt.Errorf("failed to delete manifest of snapshot %q: %w", name, err))
+			fs.Errorf(fdst, "%v", lastErr)
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,159 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestKeepSnapshots(t *testing.T) {
+	names := []string{
+		"2023-03-01T120000Z",
+		"2023-02-28T120000Z",
+		"2023-02-28T060000Z",
+		"2023-02-27T120000Z",
+		"2023-02-20T120000Z",
+		"2023-01-15T120000Z",
+		"2022-12-31T120000Z",
+		"2022-06-01T120000Z",
+	}
+	for _, test := range []struct {
+		name string
+		opt  Options
+		want []bool
+	}{
+		{"None", Options{}, []bool{true, false, false, false, false, false, false, false}},
+		{"Last", Options{KeepLast: 3}, []bool{true, true, true, false, false, false, false, false}},
+		{"Daily", Options{KeepDaily: 3}, []bool{true, true, false, true, false, false, false, false}},
+		{"Weekly", Options{KeepWeekly: 2}, []bool{true, false, false, false, true, false, false, false}},
+		{"Monthly", Options{KeepMonthly: 3}, []bool{true, true, false, false, false, true, false, false}},
+		{"Yearly", Options{KeepYearly: 5}, []bool{true, false, false, false, false, false, true, false}},
+		{"Mixed", Options{KeepDaily: 2, KeepMonthly: 5}, []bool{true, true, false, false, false, true, true, true}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, keepSnapshots(names, &test.opt))
+		})
+	}
+	assert.Equal(t, []bool{}, keepSnapshots(nil, &Options{KeepLast: 1}))
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	oldTimeNow := timeNow
+	defer func() {
+		timeNow = oldTimeNow
+	}()
+	now := fstest.Time("2023-02-27T12:00:00Z")
+	timeNow = func() time.Time { return now }
+
+	// Use hardlinks for server-side copies in the destination
+	fdst, err := fs.NewFs(ctx, ":local,copy_is_hardlink:"+r.FremoteName)
+	require.NoError(t, err)
+
+	file1 := r.WriteFile("file1.txt", "unchanged", fstest.Time("2001-02-03T04:05:06Z"))
+	file2 := r.WriteFile("dir/file2.txt", "first version", fstest.Time("2001-02-03T04:05:06Z"))
+
+	// Make an unrelated directory which shouldn't be pruned
+	require.NoError(t, os.MkdirAll(filepath.Join(r.FremoteName, "potato"), 0777))
+
+	require.NoError(t, Snapshot(ctx, r.Flocal, fdst, &Options{}))
+
+	now = now.Add(24 * time.Hour)
+	file2 = r.WriteFile("dir/file2.txt", "second version", fstest.Time("2002-02-03T04:05:06Z"))
+	require.NoError(t, Snapshot(ctx, r.Flocal, fdst, &Options{}))
+
+	now = now.Add(time.Hour)
+	require.NoError(t, Snapshot(ctx, r.Flocal, fdst, &Options{KeepDaily: 2}))
+
+	snapshots, complete, err := listSnapshots(ctx, fdst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2023-02-28T130000Z", "2023-02-27T120000Z"}, snapshots)
+	assert.Equal(t, map[string]bool{"2023-02-28T130000Z": true, "2023-02-27T120000Z": true}, complete)
+
+	// Check the contents of the latest snapshot
+	fsnapshot, err := subFs(ctx, fdst, snapshots[0])
+	require.NoError(t, err)
+	fstest.CheckListingWithPrecision(t, fsnapshot, []fstest.Item{file1, file2}, []string{"dir"}, fs.GetModifyWindow(ctx, r.Flocal, fsnapshot))
+
+	// Unchanged files should be hardlinks to the first snapshot
+	info1, err := os.Stat(filepath.Join(r.FremoteName, snapshots[0], "file1.txt"))
+	require.NoError(t, err)
+	info2, err := os.Stat(filepath.Join(r.FremoteName, snapshots[1], "file1.txt"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(info1, info2))
+	info1, err = os.Stat(filepath.Join(r.FremoteName, snapshots[0], "dir", "file2.txt"))
+	require.NoError(t, err)
+	info2, err = os.Stat(filepath.Join(r.FremoteName, snapshots[1], "dir", "file2.txt"))
+	require.NoError(t, err)
+	assert.False(t, os.SameFile(info1, info2))
+
+	// Check the manifests - the pruned one should be gone
+	_, err = os.Stat(filepath.Join(r.FremoteName, "2023-02-28T120000Z.json"))
+	assert.True(t, os.IsNotExist(err))
+	o, err := fdst.NewObject(ctx, snapshots[0]+manifestSuffix)
+	require.NoError(t, err)
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	data, err := io.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, snapshots[0], manifest.Name)
+	assert.Equal(t, "2023-02-28T120000Z", manifest.Previous)
+	assert.Equal(t, int64(2), manifest.Files)
+	assert.Equal(t, int64(len("unchanged")+len("second version")), manifest.Bytes)
+	require.Len(t, manifest.Entries, 2)
+	assert.Equal(t, "file1.txt", manifest.Entries[0].Path)
+	assert.Equal(t, "dir/file2.txt", manifest.Entries[1].Path)
+	assert.Equal(t, file2.ModTime, manifest.Entries[1].ModTime.When)
+
+	// The unrelated directory should still be there
+	_, err = os.Stat(filepath.Join(r.FremoteName, "potato"))
+	assert.NoError(t, err)
+
+	// A snapshot without a manifest isn't used as the previous
+	// snapshot or kept when pruning
+	now = now.Add(time.Hour)
+	require.NoError(t, os.MkdirAll(filepath.Join(r.FremoteName, "2023-02-28T140000Z"), 0777))
+	now = now.Add(time.Hour)
+	require.NoError(t, Snapshot(ctx, r.Flocal, fdst, &Options{KeepLast: 2}))
+	snapshots, complete, err = listSnapshots(ctx, fdst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2023-02-28T150000Z", "2023-02-28T130000Z"}, snapshots)
+	assert.True(t, complete["2023-02-28T150000Z"])
+	o, err = fdst.NewObject(ctx, snapshots[0]+manifestSuffix)
+	require.NoError(t, err)
+	in, err = o.Open(ctx)
+	require.NoError(t, err)
+	data, err = io.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	manifest = Manifest{}
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, "2023-02-28T130000Z", manifest.Previous)
+	assert.Equal(t, int64(2), manifest.Files)
+	assert.Equal(t, now.UTC(), manifest.Finished)
+
+	// Snapshots must be made in time order
+	now = now.Add(-time.Hour)
+	err = Snapshot(ctx, r.Flocal, fdst, &Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not older than the new snapshot")
+}
@@ -3,8 +3,14 @@ package sync
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
 
 	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/sync"
@@ -13,12 +19,15 @@ import (
 
 var (
 	createEmptySrcDirs = false
+	planOut            = ""
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &createEmptySrcDirs, "create-empty-src-dirs", "", createEmptySrcDirs, "Create empty source dirs on destination after sync", "")
+	cmd.AddOutputFormatFlag(cmdFlags)
+	flags.StringVarP(cmdFlags, &planOut, "plan-out", "", planOut, "Write the changes the sync would make to this file instead of making them", "")
 }
 
 var commandDefinition = &cobra.Command{
@@ -57,6 +66,15 @@ destination that is inside the source directory.
 
 **Note**: Use the ` + "`-P`" + `/` + "`--progress`" + ` flag to view real-time transfer statistics
 
+` + cmd.OutputFormatHelp + `
+If ` + "`--plan-out plan.json`" + ` is supplied then the sync doesn't
+change anything. Instead the exact set of copies, deletes and renames
+it would do is written to ` + "`plan.json`" + ` which can be reviewed
+and then applied with [apply](/commands/rclone_apply/). The plan
+records the remotes by name so remotes with parameters in the name,
+such as connection strings, can't be used with it as the parameters
+may be credentials. Put them in the config file instead.
+
 **Note**: Use the ` + "`rclone dedupe`" + ` command to deal with "Duplicate object/directory found in source/destination - ignoring" errors.
 See [this forum post](https://forum.rclone.org/t/sync-not-clearing-duplicates/14372) for more info.
 `,
@@ -65,12 +83,41 @@ See [this forum post](https://forum.rclone.org/t/sync-not-clearing-duplicates/14
 	},
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
+		ctx, err := cmd.OutputContext(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
 		fsrc, srcFileName, fdst := cmd.NewFsSrcFileDst(args)
 		cmd.Run(true, true, command, func() error {
+			if planOut != "" {
+				if srcFileName != "" {
+					return errors.New("--plan-out can only be used when syncing directories")
+				}
+				return writePlan(ctx, fdst, fsrc, planOut)
+			}
 			if srcFileName == "" {
-				return sync.Sync(context.Background(), fdst, fsrc, createEmptySrcDirs)
+				return sync.Sync(ctx, fdst, fsrc, createEmptySrcDirs)
 			}
-			return operations.CopyFile(context.Background(), fdst, fsrc, srcFileName, srcFileName)
+			return operations.CopyFile(ctx, fdst, fsrc, srcFileName, srcFileName)
 		})
 	},
 }
+
+// writePlan works out the changes a sync would make and writes them
+// to fileName as JSON
+func writePlan(ctx context.Context, fdst, fsrc fs.Fs, fileName string) error {
+	plan, err := sync.MakePlan(ctx, fdst, fsrc, createEmptySrcDirs)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(plan, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(fileName, append(data, '\n'), 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	fs.Logf(nil, "Wrote plan with %d changes to %q", len(plan.Actions), fileName)
+	return nil
+}
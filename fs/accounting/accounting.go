@@ -10,6 +10,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rclone/rclone/fs/rc"
 
 	"github.com/rclone/rclone/fs"
@@ -61,7 +62,8 @@ type Account struct {
 	exit    chan struct{} // channel that will be closed when transfer is finished
 	withBuf bool          // is using a buffered in
 
-	tokenBucket buckets // per file bandwidth limiter (may be nil)
+	tokenBucket buckets            // per file bandwidth limiter (may be nil)
+	remoteBytes prometheus.Counter // per remote bytes transferred metric (may be nil)
 
 	values accountValues
 }
@@ -334,6 +336,9 @@ func (acc *Account) accountRead(n int) {
 	acc.values.mu.Unlock()
 
 	acc.stats.Bytes(int64(n))
+	if acc.remoteBytes != nil {
+		acc.remoteBytes.Add(float64(n))
+	}
 
 	TokenBucket.LimitBandwidth(TokenBucketSlotAccounting, n)
 	acc.limitPerFileBandwidth(n)
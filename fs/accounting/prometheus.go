@@ -4,10 +4,17 @@ import (
 	"context"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rclone/rclone/fs"
 )
 
 var namespace = "rclone_"
 
+// maxMetricsGroups is the maximum number of stats groups exported
+// with a group label. Only the most recently created groups are
+// exported so the number of series stays bounded however many jobs
+// are run.
+const maxMetricsGroups = 100
+
 // RcloneCollector is a Prometheus collector for Rclone
 type RcloneCollector struct {
 	ctx              context.Context
@@ -21,8 +28,36 @@ type RcloneCollector struct {
 	renames          *prometheus.Desc
 	fatalError       *prometheus.Desc
 	retryError       *prometheus.Desc
+
+	// per stats group
+	groupBytesTransferred *prometheus.Desc
+	groupTransferSpeed    *prometheus.Desc
+	groupNumOfErrors      *prometheus.Desc
+	groupNumOfCheckFiles  *prometheus.Desc
+	groupTransferredFiles *prometheus.Desc
+	groupTransferring     *prometheus.Desc
 }
 
+// Metrics kept per remote, labelled with the name of the remote in
+// the config file.
+//
+// These are updated as the transfers happen rather than being read
+// from the stats when collected.
+var (
+	remoteBytesTransferred = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: namespace + "remote_bytes_transferred_total",
+		Help: "Total bytes transferred from the remote",
+	}, []string{"remote"})
+	remoteTransferredFiles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: namespace + "remote_files_transferred_total",
+		Help: "Number of files transferred from the remote",
+	}, []string{"remote"})
+	remoteNumOfErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: namespace + "remote_errors_total",
+		Help: "Number of transfers from the remote which failed",
+	}, []string{"remote"})
+)
+
 // NewRcloneCollector make a new RcloneCollector
 func NewRcloneCollector(ctx context.Context) *RcloneCollector {
 	return &RcloneCollector{
@@ -67,6 +102,30 @@ func NewRcloneCollector(ctx context.Context) *RcloneCollector {
 			"Whether there has been an error that will be retried",
 			nil, nil,
 		),
+		groupBytesTransferred: prometheus.NewDesc(namespace+"group_bytes_transferred_total",
+			"Total transferred bytes in the stats group",
+			[]string{"group"}, nil,
+		),
+		groupTransferSpeed: prometheus.NewDesc(namespace+"group_speed",
+			"Average speed in bytes per second of the stats group",
+			[]string{"group"}, nil,
+		),
+		groupNumOfErrors: prometheus.NewDesc(namespace+"group_errors_total",
+			"Number of errors thrown in the stats group",
+			[]string{"group"}, nil,
+		),
+		groupNumOfCheckFiles: prometheus.NewDesc(namespace+"group_checked_files_total",
+			"Number of checked files in the stats group",
+			[]string{"group"}, nil,
+		),
+		groupTransferredFiles: prometheus.NewDesc(namespace+"group_files_transferred_total",
+			"Number of transferred files in the stats group",
+			[]string{"group"}, nil,
+		),
+		groupTransferring: prometheus.NewDesc(namespace+"group_transferring",
+			"Number of transfers in progress in the stats group",
+			[]string{"group"}, nil,
+		),
 	}
 }
 
@@ -82,6 +141,15 @@ func (c *RcloneCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.renames
 	ch <- c.fatalError
 	ch <- c.retryError
+	ch <- c.groupBytesTransferred
+	ch <- c.groupTransferSpeed
+	ch <- c.groupNumOfErrors
+	ch <- c.groupNumOfCheckFiles
+	ch <- c.groupTransferredFiles
+	ch <- c.groupTransferring
+	remoteBytesTransferred.Describe(ch)
+	remoteTransferredFiles.Describe(ch)
+	remoteNumOfErrors.Describe(ch)
 }
 
 // Collect is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
@@ -101,6 +169,44 @@ func (c *RcloneCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.retryError, prometheus.GaugeValue, bool2Float(s.retryError))
 
 	s.mu.RUnlock()
+
+	names := groups.names(c.ctx)
+	if len(names) > maxMetricsGroups {
+		names = names[len(names)-maxMetricsGroups:]
+	}
+	for _, group := range names {
+		s := groups.get(group)
+		if s == nil {
+			continue
+		}
+		transferring := s.transferring.count()
+		s.mu.RLock()
+		ch <- prometheus.MustNewConstMetric(c.groupBytesTransferred, prometheus.CounterValue, float64(s.bytes), group)
+		ch <- prometheus.MustNewConstMetric(c.groupTransferSpeed, prometheus.GaugeValue, s.speed(), group)
+		ch <- prometheus.MustNewConstMetric(c.groupNumOfErrors, prometheus.CounterValue, float64(s.errors), group)
+		ch <- prometheus.MustNewConstMetric(c.groupNumOfCheckFiles, prometheus.CounterValue, float64(s.checks), group)
+		ch <- prometheus.MustNewConstMetric(c.groupTransferredFiles, prometheus.CounterValue, float64(s.transfers), group)
+		ch <- prometheus.MustNewConstMetric(c.groupTransferring, prometheus.GaugeValue, float64(transferring), group)
+		s.mu.RUnlock()
+	}
+
+	remoteBytesTransferred.Collect(ch)
+	remoteTransferredFiles.Collect(ch)
+	remoteNumOfErrors.Collect(ch)
+}
+
+// objectRemoteName returns the name of the remote obj is on or "" if
+// not known
+func objectRemoteName(obj fs.DirEntry) string {
+	o, ok := obj.(fs.ObjectInfo)
+	if !ok {
+		return ""
+	}
+	f := o.Fs()
+	if f == nil {
+		return ""
+	}
+	return f.Name()
 }
 
 // bool2Float is a small function to convert a boolean into a float64 value that can be used for Prometheus
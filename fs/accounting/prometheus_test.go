@@ -0,0 +1,104 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRcloneCollectorGroups(t *testing.T) {
+	ctx := context.Background()
+	defer groups.delete("prometheus-test")
+	stats := StatsGroup(ctx, "prometheus-test")
+	stats.Bytes(42)
+	stats.Errors(2)
+
+	c := NewRcloneCollector(ctx)
+	expected := `
+# HELP rclone_group_bytes_transferred_total Total transferred bytes in the stats group
+# TYPE rclone_group_bytes_transferred_total counter
+rclone_group_bytes_transferred_total{group="prometheus-test"} 42
+# HELP rclone_group_errors_total Number of errors thrown in the stats group
+# TYPE rclone_group_errors_total counter
+rclone_group_errors_total{group="prometheus-test"} 2
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "rclone_group_bytes_transferred_total", "rclone_group_errors_total")
+	require.NoError(t, err)
+}
+
+func TestRcloneCollectorGroupsMax(t *testing.T) {
+	ctx := context.Background()
+	var names []string
+	for i := 0; i < maxMetricsGroups+10; i++ {
+		name := fmt.Sprintf("prometheus-max-%d", i)
+		names = append(names, name)
+		StatsGroup(ctx, name).Bytes(1)
+	}
+	defer func() {
+		for _, name := range names {
+			groups.delete(name)
+		}
+	}()
+
+	// Only the newest groups are exported
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewRcloneCollector(ctx))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	exported := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != "rclone_group_bytes_transferred_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				exported[label.GetValue()] = true
+			}
+		}
+	}
+	assert.LessOrEqual(t, len(exported), maxMetricsGroups)
+	assert.True(t, exported[names[len(names)-1]])
+	assert.False(t, exported[names[0]])
+}
+
+func TestRcloneCollectorRemotes(t *testing.T) {
+	ctx := context.Background()
+	f, err := mockfs.NewFs(ctx, "prometheusremote", "root", nil)
+	require.NoError(t, err)
+	stats := NewStats(ctx)
+
+	before := func(vec *prometheus.CounterVec) float64 {
+		return testutil.ToFloat64(vec.WithLabelValues("prometheusremote"))
+	}
+	bytesBefore := before(remoteBytesTransferred)
+	filesBefore := before(remoteTransferredFiles)
+	errorsBefore := before(remoteNumOfErrors)
+
+	// A successful transfer
+	src := object.NewStaticObjectInfo("file", time.Now(), 5, true, nil, f)
+	tr := stats.NewTransfer(src)
+	acc := tr.Account(ctx, io.NopCloser(bytes.NewBufferString("hello")))
+	_, err = io.ReadAll(acc)
+	require.NoError(t, err)
+	tr.Done(ctx, nil)
+
+	// A failed transfer
+	tr = stats.NewTransfer(src)
+	tr.Done(ctx, errors.New("boom"))
+
+	assert.Equal(t, 5.0, before(remoteBytesTransferred)-bytesBefore)
+	assert.Equal(t, 1.0, before(remoteTransferredFiles)-filesBefore)
+	assert.Equal(t, 1.0, before(remoteNumOfErrors)-errorsBefore)
+}
@@ -51,6 +51,8 @@ type StatsInfo struct {
 	deletes             int64
 	deletesSize         int64
 	deletedDirs         int64
+	verified            int64
+	unverified          int64
 	inProgress          *inProgress
 	startedTransfers    []*Transfer   // currently active transfers
 	oldTimeRanges       timeRanges    // a merged list of time ranges for the transfers
@@ -113,6 +115,8 @@ func (s *StatsInfo) RemoteStats() (out rc.Params, err error) {
 	out["deletes"] = s.deletes
 	out["deletedDirs"] = s.deletedDirs
 	out["renames"] = s.renames
+	out["verified"] = s.verified
+	out["unverified"] = s.unverified
 	out["elapsedTime"] = time.Since(s.startTime).Seconds()
 	out["serverSideCopies"] = s.serverSideCopies
 	out["serverSideCopyBytes"] = s.serverSideCopyBytes
@@ -459,6 +463,9 @@ func (s *StatsInfo) String() string {
 			_, _ = fmt.Fprintf(buf, "Transferred:   %10d / %d, %s\n",
 				s.transfers, ts.totalTransfers, percent(s.transfers, ts.totalTransfers))
 		}
+		if s.verified != 0 || s.unverified != 0 {
+			_, _ = fmt.Fprintf(buf, "Verified:      %10d (unverified %d)\n", s.verified, s.unverified)
+		}
 		if s.serverSideCopies != 0 || s.serverSideCopyBytes != 0 {
 			_, _ = fmt.Fprintf(buf, "Server Side Copies:%6d @ %s\n",
 				s.serverSideCopies, fs.SizeSuffix(s.serverSideCopyBytes).ByteUnit(),
@@ -656,6 +663,23 @@ func (s *StatsInfo) Renames(renames int64) int64 {
 	return s.renames
 }
 
+// Verified updates the stats for files verified after transfer
+func (s *StatsInfo) Verified(verified int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verified += verified
+	return s.verified
+}
+
+// Unverified updates the stats for files which couldn't be verified
+// after transfer
+func (s *StatsInfo) Unverified(unverified int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unverified += unverified
+	return s.unverified
+}
+
 // ResetCounters sets the counters (bytes, checks, errors, transfers, deletes, renames) to 0 and resets lastError, fatalError and retryError
 func (s *StatsInfo) ResetCounters() {
 	s.mu.Lock()
@@ -672,6 +696,8 @@ func (s *StatsInfo) ResetCounters() {
 	s.deletesSize = 0
 	s.deletedDirs = 0
 	s.renames = 0
+	s.verified = 0
+	s.unverified = 0
 	s.startedTransfers = nil
 	s.oldDuration = 0
 
@@ -763,7 +789,7 @@ func (s *StatsInfo) NewTransfer(obj fs.DirEntry) *Transfer {
 
 // NewTransferRemoteSize adds a transfer to the stats based on remote and size.
 func (s *StatsInfo) NewTransferRemoteSize(remote string, size int64) *Transfer {
-	tr := newTransferRemoteSize(s, remote, size, false, "")
+	tr := newTransferRemoteSize(s, remote, size, false, "", "")
 	s.transferring.add(tr)
 	s.startAverageLoop()
 	return tr
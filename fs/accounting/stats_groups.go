@@ -3,6 +3,7 @@ package accounting
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/rclone/rclone/fs/rc"
@@ -25,7 +26,7 @@ func init() {
 func rcListStats(ctx context.Context, in rc.Params) (rc.Params, error) {
 	out := make(rc.Params)
 
-	out["groups"] = groups.names()
+	out["groups"] = groups.names(ctx)
 
 	return out, nil
 }
@@ -107,6 +108,8 @@ Returns the following values:
 	"totalTransfers": total number of transfers in the group,
 	"transferTime" : total time spent on running jobs,
 	"transfers": number of transferred files,
+	"unverified": number of transferred files which failed or couldn't be verified with --verify,
+	"verified": number of transferred files verified with --verify,
 	"transferring": an array of currently active file transfers:
 		[
 			{
@@ -194,14 +197,14 @@ func rcResetStats(ctx context.Context, in rc.Params) (rc.Params, error) {
 	}
 
 	if group != "" {
-		stats := groups.get(group)
+		stats := groups.get(fs.InNamespace(ctx, group))
 		if stats == nil {
 			return rc.Params{}, fmt.Errorf("group %q not found", group)
 		}
 		stats.ResetErrors()
 		stats.ResetCounters()
 	} else {
-		groups.reset()
+		groups.reset(ctx)
 	}
 
 	return rc.Params{}, nil
@@ -231,7 +234,7 @@ func rcDeleteStats(ctx context.Context, in rc.Params) (rc.Params, error) {
 	}
 
 	if group != "" {
-		groups.delete(group)
+		groups.delete(fs.InNamespace(ctx, group))
 	}
 
 	return rc.Params{}, nil
@@ -272,16 +275,25 @@ func StatsGroupFromContext(ctx context.Context) (string, bool) {
 }
 
 // Stats gets stats by extracting group from context.
+//
+// If ctx has a namespace but no group then the global stats of the
+// namespace are returned.
 func Stats(ctx context.Context) *StatsInfo {
 	group, ok := StatsGroupFromContext(ctx)
 	if !ok {
-		return GlobalStats()
+		if fs.GetNamespace(ctx) == "" {
+			return GlobalStats()
+		}
+		group = globalStats
 	}
 	return StatsGroup(ctx, group)
 }
 
 // StatsGroup gets stats by group name.
+//
+// If ctx has a namespace then the group is looked up in that namespace.
 func StatsGroup(ctx context.Context, group string) *StatsInfo {
+	group = fs.InNamespace(ctx, group)
 	stats := groups.get(group)
 	if stats == nil {
 		return NewStatsGroup(ctx, group)
@@ -295,7 +307,10 @@ func GlobalStats() *StatsInfo {
 }
 
 // NewStatsGroup creates new stats under named group.
+//
+// If ctx has a namespace then the group is created in that namespace.
 func NewStatsGroup(ctx context.Context, group string) *StatsInfo {
+	group = fs.InNamespace(ctx, group)
 	stats := NewStats(ctx)
 	stats.group = group
 	groups.set(ctx, group, stats)
@@ -332,7 +347,7 @@ func (sg *statsGroups) set(ctx context.Context, group string, stats *StatsInfo)
 	}
 
 	// Exclude global stats from listing
-	if group != globalStats {
+	if !isGlobalStats(group) {
 		sg.order = append(sg.order, group)
 	}
 	sg.m[group] = stats
@@ -349,20 +364,39 @@ func (sg *statsGroups) get(group string) *StatsInfo {
 	return stats
 }
 
-func (sg *statsGroups) names() []string {
+// isGlobalStats returns true if group is the global stats of a
+// namespace or of everything.
+func isGlobalStats(group string) bool {
+	return group == globalStats || strings.HasSuffix(group, fs.NamespaceSeparator+globalStats)
+}
+
+// names returns the names of the groups which can be seen from ctx
+//
+// If ctx has a namespace then the names are returned without it.
+func (sg *statsGroups) names(ctx context.Context) []string {
 	sg.mu.Lock()
 	defer sg.mu.Unlock()
-	return sg.order
+	names := []string{}
+	for _, group := range sg.order {
+		if name, ok := fs.FromNamespace(ctx, group); ok {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
-// sum returns aggregate stats that contains summation of all groups.
+// sum returns aggregate stats that contains summation of all groups
+// which can be seen from ctx.
 func (sg *statsGroups) sum(ctx context.Context) *StatsInfo {
 	startTime := GlobalStats().startTime
 	sg.mu.Lock()
 	defer sg.mu.Unlock()
 
 	sum := NewStats(ctx)
-	for _, stats := range sg.m {
+	for group, stats := range sg.m {
+		if _, ok := fs.FromNamespace(ctx, group); !ok {
+			continue
+		}
 		stats.mu.RLock()
 		{
 			sum.bytes += stats.bytes
@@ -388,6 +422,8 @@ func (sg *statsGroups) sum(ctx context.Context) *StatsInfo {
 			sum.renameQueueSize += stats.renameQueueSize
 			sum.deletes += stats.deletes
 			sum.deletedDirs += stats.deletedDirs
+			sum.verified += stats.verified
+			sum.unverified += stats.unverified
 			sum.inProgress.merge(stats.inProgress)
 			sum.startedTransfers = append(sum.startedTransfers, stats.startedTransfers...)
 			sum.oldTimeRanges = append(sum.oldTimeRanges, stats.oldTimeRanges...)
@@ -402,17 +438,26 @@ func (sg *statsGroups) sum(ctx context.Context) *StatsInfo {
 	return sum
 }
 
-func (sg *statsGroups) reset() {
+// reset resets and removes all the groups which can be seen from ctx
+func (sg *statsGroups) reset(ctx context.Context) {
 	sg.mu.Lock()
 	defer sg.mu.Unlock()
 
-	for _, stats := range sg.m {
+	order := sg.order[:0]
+	for _, group := range sg.order {
+		if _, ok := fs.FromNamespace(ctx, group); !ok {
+			order = append(order, group)
+		}
+	}
+	sg.order = order
+	for group, stats := range sg.m {
+		if _, ok := fs.FromNamespace(ctx, group); !ok {
+			continue
+		}
 		stats.ResetErrors()
 		stats.ResetCounters()
+		delete(sg.m, group)
 	}
-
-	sg.m = make(map[string]*StatsInfo)
-	sg.order = nil
 }
 
 // delete removes all references to the group.
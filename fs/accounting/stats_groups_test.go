@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fstest/testy"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +29,7 @@ func TestStatsGroupOperations(t *testing.T) {
 		sg := newStatsGroups()
 		sg.set(ctx, "test", stats)
 		sg.set(ctx, "test1", stats)
-		if len(sg.m) != len(sg.names()) || len(sg.m) != 2 {
+		if len(sg.m) != len(sg.names(ctx)) || len(sg.m) != 2 {
 			t.Fatalf("Expected two stats got %d, %d", len(sg.m), len(sg.order))
 		}
 	})
@@ -86,7 +87,7 @@ func TestStatsGroupOperations(t *testing.T) {
 		if sg.get("test1") != nil {
 			t.Fatal("stats not deleted")
 		}
-		if len(sg.m) != len(sg.names()) || len(sg.m) != 1 {
+		if len(sg.m) != len(sg.names(ctx)) || len(sg.m) != 1 {
 			t.Fatalf("Expected two stats got %d, %d", len(sg.m), len(sg.order))
 		}
 	})
@@ -194,18 +195,50 @@ func TestStatsGroupOperations(t *testing.T) {
 		call := rc.Calls.Get("core/stats-delete")
 		require.NotNil(t, call)
 
-		assert.Equal(t, []string{"test-group"}, groups.names())
+		assert.Equal(t, []string{"test-group"}, groups.names(ctx))
 
 		_, err := call.Fn(ctx, rc.Params{"group": "test-group"})
 		require.NoError(t, err)
 
-		assert.Equal(t, []string{}, groups.names())
+		assert.Equal(t, []string{}, groups.names(ctx))
 
 		_, err = call.Fn(ctx, rc.Params{"group": "not-found"})
 		require.NoError(t, err)
 	})
 }
 
+func TestStatsGroupNamespace(t *testing.T) {
+	ctx := context.Background()
+	userCtx := fs.WithNamespace(ctx, "user")
+	otherCtx := fs.WithNamespace(ctx, "other")
+
+	userStats := StatsGroup(userCtx, "ns-group")
+	otherStats := StatsGroup(otherCtx, "ns-group")
+	defer groups.delete("user@@ns-group")
+	defer groups.delete("other@@ns-group")
+	assert.NotEqual(t, userStats, otherStats)
+	assert.Equal(t, userStats, groups.get("user@@ns-group"))
+	assert.Equal(t, userStats, Stats(WithStatsGroup(userCtx, "ns-group")))
+
+	// Global stats of a namespace are separate and not listed
+	userGlobal := Stats(userCtx)
+	defer groups.delete("user@@" + globalStats)
+	assert.NotEqual(t, GlobalStats(), userGlobal)
+	assert.Equal(t, []string{"ns-group"}, groups.names(userCtx))
+	assert.Contains(t, groups.names(ctx), "user@@ns-group")
+	assert.NotContains(t, groups.names(ctx), "user@@"+globalStats)
+
+	// Reset only affects the namespace
+	require.NoError(t, userStats.DeleteFile(ctx, 0))
+	require.NoError(t, otherStats.DeleteFile(ctx, 0))
+	require.NoError(t, otherStats.DeleteFile(ctx, 0))
+	assert.Equal(t, int64(1), groups.sum(userCtx).deletes)
+	groups.reset(userCtx)
+	assert.Equal(t, []string{}, groups.names(userCtx))
+	assert.Equal(t, []string{"ns-group"}, groups.names(otherCtx))
+	assert.Equal(t, int64(2), otherStats.deletes)
+}
+
 func percentDiff(start, end uint64) uint64 {
 	return (start - end) * 100 / start
 }
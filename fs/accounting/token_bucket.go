@@ -35,6 +35,8 @@ type tokenBucket struct {
 	prev       buckets
 	toggledOff bool
 	currLimit  fs.BwTimeSlot
+	remoteBw   fs.RemoteBwLimits  // limits for remotes set with --bwlimit-remote
+	remotes    map[string]buckets // buckets for each remote name made on first use
 }
 
 // Return true if limit is disabled
@@ -106,6 +108,18 @@ func (tb *tokenBucket) StartTokenBucket(ctx context.Context) {
 		tb.curr = newTokenBucket(tb.currLimit.Bandwidth)
 		fs.Infof(nil, "Starting bandwidth limiter at %v Byte/s", &tb.currLimit.Bandwidth)
 	}
+	tb.remoteBw = nil
+	tb.remotes = nil
+	for name, bandwidth := range ci.BwLimitRemote {
+		if !bandwidth.IsSet() {
+			continue
+		}
+		if tb.remoteBw == nil {
+			tb.remoteBw = make(fs.RemoteBwLimits, len(ci.BwLimitRemote))
+		}
+		tb.remoteBw[name] = bandwidth
+		fs.Infof(name, "Starting bandwidth limiter for remote at %v Byte/s", &bandwidth)
+	}
 
 	// Start the SIGUSR2 signal handler to toggle bandwidth.
 	// This function does nothing in windows systems.
@@ -176,6 +190,54 @@ func (tb *tokenBucket) LimitBandwidth(i TokenBucketSlot, n int) {
 	tb.mu.RUnlock()
 }
 
+// remoteBuckets returns the buckets for the remote called name making
+// them if necessary.
+//
+// Each namespace gets buckets of its own.
+func (tb *tokenBucket) remoteBuckets(name string) buckets {
+	tb.mu.RLock()
+	bs, ok := tb.remotes[name]
+	noLimits := len(tb.remoteBw) == 0
+	tb.mu.RUnlock()
+	if ok || noLimits {
+		return bs
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	bs, ok = tb.remotes[name]
+	if ok {
+		return bs
+	}
+	if bandwidth, ok := tb.remoteBw.Get(name); ok {
+		bs = newTokenBucket(bandwidth)
+	}
+	if tb.remotes == nil {
+		tb.remotes = map[string]buckets{}
+	}
+	tb.remotes[name] = bs
+	return bs
+}
+
+// LimitRemoteBandwidth sleeps for the correct amount of time for the
+// passage of n bytes to or from the remote called name according to
+// its --bwlimit-remote limit.
+//
+// This is as well as the global limit applied by LimitBandwidth.
+func (tb *tokenBucket) LimitRemoteBandwidth(name string, i TokenBucketSlot, n int) {
+	if name == "" {
+		return
+	}
+	bs := tb.remoteBuckets(name)
+
+	// Limit the transfer speed if required
+	if bs[i] != nil {
+		err := bs[i].WaitN(context.Background(), n)
+		if err != nil {
+			fs.Errorf(name, "Token bucket error: %v", err)
+		}
+	}
+}
+
 // SetBwLimit sets the current bandwidth limit
 func (tb *tokenBucket) SetBwLimit(bandwidth fs.BwPair) {
 	tb.mu.Lock()
@@ -232,7 +294,8 @@ func (tb *tokenBucket) rcBwlimit(ctx context.Context, in rc.Params) (out rc.Para
 // Remote control for the token bucket
 func init() {
 	rc.Add(rc.Call{
-		Path: "core/bwlimit",
+		Path:   "core/bwlimit",
+		Global: true,
 		Fn: func(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 			return TokenBucket.rcBwlimit(ctx, in)
 		},
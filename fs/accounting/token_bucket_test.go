@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,3 +91,31 @@ func TestRcBwLimit(t *testing.T) {
 	}, out)
 
 }
+
+func TestStartTokenBucketRemote(t *testing.T) {
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.BwLimitRemote = fs.RemoteBwLimits{
+		"nas":   {Tx: 1024 * 1024, Rx: -1},
+		"drive": {Tx: -1, Rx: -1},
+	}
+	var tb tokenBucket
+	tb.StartTokenBucket(ctx)
+	assert.Len(t, tb.remoteBw, 1)
+	nas := tb.remoteBuckets("nas")
+	require.NotNil(t, nas[TokenBucketSlotTransportTx])
+	assert.Equal(t, rate.Limit(1024*1024), nas[TokenBucketSlotTransportTx].Limit())
+	assert.Nil(t, nas[TokenBucketSlotTransportRx])
+	assert.Nil(t, tb.curr[TokenBucketSlotAccounting])
+	assert.True(t, nas == tb.remoteBuckets("nas"))
+	assert.Equal(t, buckets{}, tb.remoteBuckets("drive"))
+
+	// Each namespace gets its own buckets
+	userNas := tb.remoteBuckets("user" + fs.NamespaceSeparator + "nas")
+	require.NotNil(t, userNas[TokenBucketSlotTransportTx])
+	assert.False(t, nas[TokenBucketSlotTransportTx] == userNas[TokenBucketSlotTransportTx])
+
+	// These shouldn't block as they aren't limited
+	tb.LimitRemoteBandwidth("", TokenBucketSlotTransportTx, 1<<30)
+	tb.LimitRemoteBandwidth("drive", TokenBucketSlotTransportTx, 1<<30)
+	tb.LimitRemoteBandwidth("nas", TokenBucketSlotTransportRx, 1<<30)
+}
@@ -2,6 +2,7 @@ package accounting
 
 import (
 	"context"
+	"sync"
 
 	"github.com/rclone/rclone/fs"
 	"golang.org/x/time/rate"
@@ -9,24 +10,68 @@ import (
 
 var (
 	tpsBucket *rate.Limiter // for limiting number of http transactions per second
+
+	// for limiting transactions per remote with --tpslimit-remote
+	remoteTPSMu      sync.Mutex
+	remoteTPSLimits  fs.RemoteTPSLimits
+	remoteTPSBurst   int
+	remoteTPSBuckets map[string]*rate.Limiter // made on first use for each remote name
 )
 
 // StartLimitTPS starts the token bucket for transactions per second
 // limiting if necessary
 func StartLimitTPS(ctx context.Context) {
 	ci := fs.GetConfig(ctx)
+	tpsBurst := ci.TPSLimitBurst
+	if tpsBurst < 1 {
+		tpsBurst = 1
+	}
 	if ci.TPSLimit > 0 {
-		tpsBurst := ci.TPSLimitBurst
-		if tpsBurst < 1 {
-			tpsBurst = 1
-		}
 		tpsBucket = rate.NewLimiter(rate.Limit(ci.TPSLimit), tpsBurst)
 		fs.Infof(nil, "Starting transaction limiter: max %g transactions/s with burst %d", ci.TPSLimit, tpsBurst)
 	}
+	remoteTPSMu.Lock()
+	defer remoteTPSMu.Unlock()
+	remoteTPSLimits = ci.TPSLimitRemote
+	remoteTPSBurst = tpsBurst
+	remoteTPSBuckets = nil
+	for name, tpsLimit := range remoteTPSLimits {
+		fs.Infof(name, "Starting transaction limiter for remote: max %g transactions/s with burst %d", tpsLimit, tpsBurst)
+	}
+}
+
+// remoteTPSBucket returns the bucket for the remote called name or
+// nil if it isn't limited.
+//
+// Each namespace gets a bucket of its own.
+func remoteTPSBucket(name string) *rate.Limiter {
+	if name == "" {
+		return nil
+	}
+	remoteTPSMu.Lock()
+	defer remoteTPSMu.Unlock()
+	if len(remoteTPSLimits) == 0 {
+		return nil
+	}
+	bucket, ok := remoteTPSBuckets[name]
+	if ok {
+		return bucket
+	}
+	if tpsLimit, ok := remoteTPSLimits.Get(name); ok {
+		bucket = rate.NewLimiter(rate.Limit(tpsLimit), remoteTPSBurst)
+	}
+	if remoteTPSBuckets == nil {
+		remoteTPSBuckets = map[string]*rate.Limiter{}
+	}
+	remoteTPSBuckets[name] = bucket
+	return bucket
 }
 
 // LimitTPS limits the number of transactions per second if enabled.
 // It should be called once per transaction.
+//
+// If ctx has a remote name set with fs.WithRemoteName then the
+// --tpslimit-remote limit for that remote is applied too.
 func LimitTPS(ctx context.Context) {
 	if tpsBucket != nil {
 		tbErr := tpsBucket.Wait(ctx)
@@ -34,4 +79,11 @@ func LimitTPS(ctx context.Context) {
 			fs.Errorf(nil, "HTTP token bucket error: %v", tbErr)
 		}
 	}
+	name := fs.GetRemoteName(ctx)
+	if bucket := remoteTPSBucket(name); bucket != nil {
+		tbErr := bucket.Wait(ctx)
+		if tbErr != nil && tbErr != context.Canceled {
+			fs.Errorf(name, "HTTP token bucket error: %v", tbErr)
+		}
+	}
 }
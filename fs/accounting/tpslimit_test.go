@@ -37,3 +37,37 @@ func TestLimitTPS(t *testing.T) {
 		timeTransactions(100, 900*time.Millisecond, 5000*time.Millisecond)
 	})
 }
+
+func TestLimitTPSRemote(t *testing.T) {
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.TPSLimitRemote = fs.RemoteTPSLimits{"nas": 100.0}
+	StartLimitTPS(ctx)
+	assert.Nil(t, tpsBucket)
+	assert.NotNil(t, remoteTPSBucket("nas"))
+	assert.Nil(t, remoteTPSBucket("drive"))
+	defer func() {
+		remoteTPSLimits = nil
+		remoteTPSBuckets = nil
+	}()
+
+	// Each namespace gets its own bucket
+	userNas := remoteTPSBucket("user" + fs.NamespaceSeparator + "nas")
+	assert.NotNil(t, userNas)
+	assert.False(t, userNas == remoteTPSBucket("nas"))
+
+	timeTransactions := func(ctx context.Context, n int, minTime, maxTime time.Duration) {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			LimitTPS(ctx)
+		}
+		dt := time.Since(start)
+		assert.True(t, dt >= minTime && dt <= maxTime, "Expecting time between %v and %v, got %v", minTime, maxTime, dt)
+	}
+
+	// Other remotes aren't limited
+	timeTransactions(fs.WithRemoteName(ctx, "drive"), 100, 0*time.Millisecond, 100*time.Millisecond)
+	timeTransactions(ctx, 100, 0*time.Millisecond, 100*time.Millisecond)
+
+	// But nas is
+	timeTransactions(fs.WithRemoteName(ctx, "nas:"), 100, 900*time.Millisecond, 5000*time.Millisecond)
+}
@@ -51,6 +51,7 @@ type Transfer struct {
 	startedAt time.Time
 	checking  bool
 	what      string // what kind of transfer this is
+	fsName    string // name of the remote the object is on if known
 
 	// Protects all below
 	//
@@ -65,15 +66,15 @@ type Transfer struct {
 
 // newCheckingTransfer instantiates new checking of the object.
 func newCheckingTransfer(stats *StatsInfo, obj fs.DirEntry, what string) *Transfer {
-	return newTransferRemoteSize(stats, obj.Remote(), obj.Size(), true, what)
+	return newTransferRemoteSize(stats, obj.Remote(), obj.Size(), true, what, objectRemoteName(obj))
 }
 
 // newTransfer instantiates new transfer.
 func newTransfer(stats *StatsInfo, obj fs.DirEntry) *Transfer {
-	return newTransferRemoteSize(stats, obj.Remote(), obj.Size(), false, "")
+	return newTransferRemoteSize(stats, obj.Remote(), obj.Size(), false, "", objectRemoteName(obj))
 }
 
-func newTransferRemoteSize(stats *StatsInfo, remote string, size int64, checking bool, what string) *Transfer {
+func newTransferRemoteSize(stats *StatsInfo, remote string, size int64, checking bool, what string, fsName string) *Transfer {
 	tr := &Transfer{
 		stats:     stats,
 		remote:    remote,
@@ -81,6 +82,7 @@ func newTransferRemoteSize(stats *StatsInfo, remote string, size int64, checking
 		startedAt: time.Now(),
 		checking:  checking,
 		what:      what,
+		fsName:    fsName,
 	}
 	stats.AddTransfer(tr)
 	return tr
@@ -121,6 +123,13 @@ func (tr *Transfer) Done(ctx context.Context, err error) {
 		tr.stats.DoneChecking(tr.remote)
 	} else {
 		tr.stats.DoneTransferring(tr.remote, err == nil)
+		if tr.fsName != "" {
+			if err == nil {
+				remoteTransferredFiles.WithLabelValues(tr.fsName).Inc()
+			} else {
+				remoteNumOfErrors.WithLabelValues(tr.fsName).Inc()
+			}
+		}
 	}
 	tr.stats.PruneTransfers()
 }
@@ -146,6 +155,9 @@ func (tr *Transfer) Account(ctx context.Context, in io.ReadCloser) *Account {
 	tr.mu.Lock()
 	if tr.acc == nil {
 		tr.acc = newAccountSizeName(ctx, tr.stats, in, tr.size, tr.remote)
+		if tr.fsName != "" {
+			tr.acc.remoteBytes = remoteBytesTransferred.WithLabelValues(tr.fsName)
+		}
 	} else {
 		tr.acc.UpdateReader(ctx, in)
 	}
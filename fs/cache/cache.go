@@ -59,8 +59,14 @@ func addMapping(fsString, canonicalName string) {
 
 // GetFn gets an fs.Fs named fsString either from the cache or creates
 // it afresh with the create function
+//
+// If ctx has a namespace then fsString is looked up in that namespace.
 func GetFn(ctx context.Context, fsString string, create func(ctx context.Context, fsString string) (fs.Fs, error)) (f fs.Fs, err error) {
 	createOnFirstUse()
+	fsString, err = fs.NamespacePath(ctx, fsString)
+	if err != nil {
+		return nil, err
+	}
 	canonicalFsString := Canonicalize(fsString)
 	created := false
 	value, err := c.Get(canonicalFsString, func(canonicalFsString string) (f interface{}, ok bool, err error) {
@@ -133,9 +139,11 @@ func Get(ctx context.Context, fsString string) (f fs.Fs, err error) {
 	// If we are making a long lived backend which lives longer
 	// than this request, we want to disconnect it from the
 	// current context and in particular any WithCancel contexts,
-	// but we want to preserve the config embedded in the context.
+	// but we want to preserve the config and namespace embedded
+	// in the context.
 	newCtx := context.Background()
 	newCtx = fs.CopyConfig(newCtx, ctx)
+	newCtx = fs.CopyNamespace(newCtx, ctx)
 	newCtx = filter.CopyConfig(newCtx, ctx)
 	f, err = GetFn(newCtx, fsString, fs.NewFs)
 	if f == nil || (err != nil && err != fs.ErrorIsFile) {
@@ -35,6 +35,13 @@ var (
 	// implementation from the fs
 	ConfigFileHasSection = func(section string) bool { return false }
 
+	// Check the section in the config file can be used, e.g. that
+	// any encrypted secrets in it can be decrypted
+	//
+	// This is a function pointer to decouple the config
+	// implementation from the fs
+	ConfigFileCheck = func(section string) error { return nil }
+
 	// CountError counts an error.  If any errors have been
 	// counted then rclone will exit with a non zero error code.
 	//
@@ -80,6 +87,7 @@ type ConfigInfo struct {
 	MaxDepth                   int
 	IgnoreSize                 bool
 	IgnoreChecksum             bool
+	Verify                     bool // read back each file after transfer to check it
 	IgnoreCaseSync             bool
 	NoTraverse                 bool
 	CheckFirst                 bool
@@ -96,8 +104,12 @@ type ConfigInfo struct {
 	BufferSize                 SizeSuffix
 	BwLimit                    BwTimetable
 	BwLimitFile                BwTimetable
+	BwLimitRemote              RemoteBwLimits
 	TPSLimit                   float64
 	TPSLimitBurst              int
+	TPSLimitRemote             RemoteTPSLimits
+	MaxConnections             int
+	MaxConnectionsRemote       RemoteMaxConnections
 	BindAddr                   net.IP
 	DisableFeatures            []string
 	UserAgent                  string
@@ -107,6 +119,8 @@ type ConfigInfo struct {
 	StatsFileNameLength        int
 	AskPassword                bool
 	PasswordCommand            SpaceSepList
+	SecretsKeyFile             string
+	SecretsKeyCommand          SpaceSepList
 	UseServerModTime           bool
 	MaxTransfer                SizeSuffix
 	MaxDuration                time.Duration
@@ -150,6 +164,8 @@ type ConfigInfo struct {
 	Inplace                    bool // Download directly to destination file instead of atomic download to temp/rename
 	PartialSuffix              string
 	MetadataMapper             SpaceSepList
+	TraceEndpoint              string  // OTLP/HTTP endpoint to send traces to if set
+	TraceSampleRatio           float64 // fraction of traces to send
 }
 
 // NewConfig creates a new config with everything set to the default
@@ -195,6 +211,7 @@ func NewConfig() *ConfigInfo {
 	c.KvLockTime = 1 * time.Second
 	c.DefaultTime = Time(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
 	c.PartialSuffix = ".partial"
+	c.TraceSampleRatio = 1
 
 	// Perform a simple check for debug flags to enable debug logging during the flag initialization
 	for argIndex, arg := range os.Args {
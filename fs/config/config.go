@@ -106,6 +106,29 @@ type Storage interface {
 	Serialize() (string, error)
 }
 
+// StorageOpener makes a Storage from arg, the part of the config path
+// after "scheme:". Any errors should be returned by Storage.Load.
+type StorageOpener func(arg string) Storage
+
+// storageSchemes are the registered config path schemes
+var storageSchemes = map[string]StorageOpener{}
+
+// RegisterStorage registers opener to make the Storage for config
+// paths of the form "scheme:arg", eg "env:"
+func RegisterStorage(scheme string, opener StorageOpener) {
+	storageSchemes[scheme] = opener
+}
+
+// FindStorage returns the StorageOpener and its argument if path is
+// of the form "scheme:arg" for a registered scheme, or nil otherwise
+func FindStorage(path string) (opener StorageOpener, arg string) {
+	scheme, arg, ok := strings.Cut(path, ":")
+	if !ok {
+		return nil, ""
+	}
+	return storageSchemes[scheme], arg
+}
+
 // Global
 var (
 	// Password can be used to configure the random password generator
@@ -126,9 +149,10 @@ func init() {
 	fs.ConfigFileHasSection = func(section string) bool {
 		return LoadedData().HasSection(section)
 	}
+	fs.ConfigFileCheck = CheckSecrets
 	configPath = makeConfigPath()
 	cacheDir = makeCacheDir() // Has fallback to tempDir, so set that first
-	data = newDefaultStorage()
+	data = secretsStorage{newDefaultStorage()}
 }
 
 // Join directory with filename, and check if exists
@@ -327,6 +351,8 @@ func SetConfigPath(path string) (err error) {
 		cfgPath = ""
 	} else if filepath.Base(path) == noConfigFile {
 		cfgPath = ""
+	} else if opener, _ := FindStorage(path); opener != nil {
+		cfgPath = path
 	} else if err = file.IsReserved(path); err != nil {
 		return err
 	} else if cfgPath, err = filepath.Abs(path); err != nil {
@@ -342,6 +368,9 @@ func SetData(newData Storage) {
 	if configPath == "" {
 		return
 	}
+	if _, ok := newData.(secretsStorage); !ok {
+		newData = secretsStorage{newData}
+	}
 	data = newData
 	dataLoaded = false
 }
@@ -643,10 +672,19 @@ var matchEnv = regexp.MustCompile(`^RCLONE_CONFIG_(.*?)_TYPE=.*$`)
 // including any defined by environment variables.
 func FileSections() []string {
 	sections := LoadedData().GetSectionList()
+	found := make(map[string]struct{}, len(sections))
+	for _, section := range sections {
+		found[section] = struct{}{}
+	}
 	for _, item := range os.Environ() {
 		matches := matchEnv.FindStringSubmatch(item)
 		if len(matches) == 2 {
-			sections = append(sections, strings.ToLower(matches[1]))
+			section := strings.ToLower(matches[1])
+			// The config may be read from the environment already
+			if _, ok := found[section]; !ok {
+				found[section] = struct{}{}
+				sections = append(sections, section)
+			}
 		}
 	}
 	return sections
@@ -16,23 +16,54 @@ import (
 )
 
 // Install installs the config file handler
+//
+// The storage is chosen by NewStorage from the config path, and is
+// chosen again if the config path is changed, eg with config/setpath.
 func Install() {
-	config.SetData(&Storage{})
+	config.SetData(&pathStorage{})
+}
+
+// NewStorage returns the config.Storage for the config path passed in.
+//
+// This is chosen by the scheme of the path if it has a registered
+// one, eg "env:", otherwise by the file extension with ".yaml",
+// ".yml" and ".json" files being read as YAML and JSON and anything
+// else as an INI file.
+func NewStorage(path string) config.Storage {
+	if opener, arg := config.FindStorage(path); opener != nil {
+		return opener(arg)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return newMapFileStorage(path, formatYAML)
+	case ".json":
+		return newMapFileStorage(path, formatJSON)
+	}
+	return &Storage{path: path}
 }
 
 // Storage implements config.Storage for saving and loading config
 // data in a simple INI based file.
 type Storage struct {
-	mu sync.Mutex           // to protect the following variables
-	gc *goconfig.ConfigFile // config file loaded - not thread safe
-	fi os.FileInfo          // stat of the file when last loaded
+	path string               // path of the config file - if empty use the config path
+	mu   sync.Mutex           // to protect the following variables
+	gc   *goconfig.ConfigFile // config file loaded - not thread safe
+	fi   os.FileInfo          // stat of the file when last loaded
+}
+
+// getPath returns the path of the config file
+func (s *Storage) getPath() string {
+	if s.path != "" {
+		return s.path
+	}
+	return config.GetConfigPath()
 }
 
 // Check to see if we need to reload the config
 //
 // mu must be held when calling this
 func (s *Storage) _check() {
-	if configPath := config.GetConfigPath(); configPath != "" {
+	if configPath := s.getPath(); configPath != "" {
 		// Check to see if config file has changed since it was last loaded
 		fi, err := os.Stat(configPath)
 		if err == nil {
@@ -59,7 +90,7 @@ func (s *Storage) _load() (err error) {
 		}
 	}()
 
-	configPath := config.GetConfigPath()
+	configPath := s.getPath()
 	if configPath == "" {
 		return config.ErrorConfigFileNotFound
 	}
@@ -102,7 +133,7 @@ func (s *Storage) Save() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	configPath := config.GetConfigPath()
+	configPath := s.getPath()
 	if configPath == "" {
 		return fmt.Errorf("failed to save config file, path is empty")
 	}
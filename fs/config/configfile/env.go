@@ -0,0 +1,84 @@
+package configfile
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+)
+
+func init() {
+	config.RegisterStorage("env", func(arg string) config.Storage {
+		return &envStorage{}
+	})
+}
+
+// envPrefix is the prefix of environment variables holding config
+const envPrefix = "RCLONE_CONFIG_"
+
+// envStorage implements a read only config.Storage which reads the
+// config from environment variables only.
+//
+// A remote is defined by RCLONE_CONFIG_NAME_TYPE and its other keys by
+// RCLONE_CONFIG_NAME_KEY. The names of remotes and keys are lower
+// cased.
+//
+// Changes may be made to the config but they are only kept in memory.
+type envStorage struct {
+	mapStorage
+}
+
+// envSections reads the config from the environment variables in
+// environ which are in "KEY=value" form
+func envSections(environ []string) sections {
+	vars := map[string]string{}
+	var names []string
+	for _, item := range environ {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || !strings.HasPrefix(key, envPrefix) {
+			continue
+		}
+		key = key[len(envPrefix):]
+		vars[key] = value
+		if name := strings.TrimSuffix(key, "_TYPE"); name != key && name != "" {
+			names = append(names, name)
+		}
+	}
+	newSections := make(sections, len(names))
+	for key, value := range vars {
+		// Find the longest remote name which is a prefix of the key
+		var name string
+		for _, possibleName := range names {
+			if len(possibleName) > len(name) && strings.HasPrefix(key, possibleName+"_") {
+				name = possibleName
+			}
+		}
+		if name == "" {
+			continue
+		}
+		section := strings.ToLower(name)
+		keys, found := newSections[section]
+		if !found {
+			keys = map[string]string{}
+			newSections[section] = keys
+		}
+		keys[strings.ToLower(key[len(name)+1:])] = value
+	}
+	return newSections
+}
+
+// Load the config from the environment
+func (s *envStorage) Load() error {
+	s.setSections(envSections(os.Environ()))
+	return nil
+}
+
+// Save does nothing as the config is read only
+func (s *envStorage) Save() error {
+	fs.Logf(nil, "Not saving config as it is read from the environment")
+	return nil
+}
+
+// Check the interface is satisfied
+var _ config.Storage = (*envStorage)(nil)
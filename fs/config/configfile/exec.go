@@ -0,0 +1,95 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+)
+
+func init() {
+	config.RegisterStorage("exec", func(arg string) config.Storage {
+		return &execStorage{command: arg}
+	})
+}
+
+// execStorage implements a read only config.Storage which fetches
+// the config from an external helper program, for example one which
+// reads secrets from a secret manager.
+//
+// The helper is run with extra arguments to fetch the config
+//
+//	helper list       - print a JSON list of remote names
+//	helper get NAME   - print a JSON object with the config of NAME
+//
+// Changes may be made to the config but they are only kept in memory.
+type execStorage struct {
+	mapStorage
+	command string // the helper command line
+}
+
+// run the helper with args and decode its JSON output into result
+func (s *execStorage) run(result interface{}, args ...string) error {
+	var command fs.SpaceSepList
+	err := command.Set(s.command)
+	if err != nil {
+		return fmt.Errorf("failed to parse config helper command %q: %w", s.command, err)
+	}
+	if len(command) == 0 {
+		return errors.New("config helper command is empty - use exec:command")
+	}
+	command = append(command, args...)
+	cmd := exec.Command(command[0], command[1:]...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("config helper %q failed: %w", strings.Join(command, " "), err)
+	}
+	err = json.Unmarshal(stdout.Bytes(), result)
+	if err != nil {
+		return fmt.Errorf("config helper %q returned invalid JSON: %w", strings.Join(command, " "), err)
+	}
+	return nil
+}
+
+// Load the config by running the helper
+func (s *execStorage) Load() error {
+	s.setSections(nil)
+	var names []string
+	err := s.run(&names, "list")
+	if err != nil {
+		return err
+	}
+	newSections := make(sections, len(names))
+	for _, name := range names {
+		var keys map[string]string
+		err = s.run(&keys, "get", name)
+		if err != nil {
+			return err
+		}
+		if keys == nil {
+			keys = map[string]string{}
+		}
+		newSections[name] = keys
+	}
+	s.setSections(newSections)
+	return nil
+}
+
+// Save does nothing as the config is read only
+func (s *execStorage) Save() error {
+	fs.Logf(nil, "Not saving config as it is read from a helper program")
+	return nil
+}
+
+// Check the interface is satisfied
+var _ config.Storage = (*execStorage)(nil)
@@ -0,0 +1,185 @@
+package configfile
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+func init() {
+	config.RegisterStorage("layered", newLayeredStorage)
+}
+
+// layeredStorage implements config.Storage by merging a read only
+// base config with a writable overlay config file.
+//
+// It is made from a config path of the form "layered:OVERLAY,BASE"
+// where OVERLAY is the path of a config file and BASE is any config
+// path, eg "layered:/var/lib/rclone/tokens.conf,exec:vault-helper".
+//
+// Values are read from the overlay if present there, otherwise from
+// the base. All changes are written to the overlay, so, for example,
+// refreshed OAuth tokens are saved there. Keys deleted from the base
+// are recorded in the overlay by setting them to layeredDeleted.
+type layeredStorage struct {
+	err     error // error parsing the config path
+	overlay config.Storage
+	base    config.Storage
+}
+
+// layeredDeleted is the value set in the overlay for keys which have
+// been deleted from the base
+const layeredDeleted = "RCLONE_DELETED"
+
+// newLayeredStorage makes a layeredStorage from "OVERLAY,BASE"
+func newLayeredStorage(arg string) config.Storage {
+	// Use empty configs until they are parsed
+	s := &layeredStorage{
+		overlay: newMapFileStorage("", formatYAML),
+		base:    newMapFileStorage("", formatYAML),
+	}
+	overlayPath, basePath, ok := strings.Cut(arg, ",")
+	if !ok || overlayPath == "" || basePath == "" {
+		s.err = errors.New("layered config should be in the form layered:OVERLAY,BASE")
+		return s
+	}
+	overlayPath, err := filepath.Abs(overlayPath)
+	if err != nil {
+		s.err = fmt.Errorf("layered config overlay: %w", err)
+		return s
+	}
+	s.overlay = NewStorage(overlayPath)
+	s.base = NewStorage(basePath)
+	return s
+}
+
+// GetSectionList returns a slice of strings with names for all the
+// sections
+func (s *layeredStorage) GetSectionList() []string {
+	var list []string
+	for _, section := range mergeLists(s.overlay.GetSectionList(), s.base.GetSectionList()) {
+		if s.HasSection(section) {
+			list = append(list, section)
+		}
+	}
+	return list
+}
+
+// HasSection returns true if section exists in the config and all
+// its keys haven't been deleted
+func (s *layeredStorage) HasSection(section string) bool {
+	if !s.overlay.HasSection(section) {
+		return s.base.HasSection(section)
+	}
+	keys := mergeLists(s.overlay.GetKeyList(section), s.base.GetKeyList(section))
+	return len(keys) == 0 || len(s.GetKeyList(section)) > 0
+}
+
+// DeleteSection removes the named section from the overlay and marks
+// the keys of the section in the base as deleted
+func (s *layeredStorage) DeleteSection(section string) {
+	s.overlay.DeleteSection(section)
+	for _, key := range s.base.GetKeyList(section) {
+		s.overlay.SetValue(section, key, layeredDeleted)
+	}
+}
+
+// GetKeyList returns the keys in this section
+func (s *layeredStorage) GetKeyList(section string) []string {
+	var list []string
+	for _, key := range mergeLists(s.overlay.GetKeyList(section), s.base.GetKeyList(section)) {
+		if value, _ := s.overlay.GetValue(section, key); value != layeredDeleted {
+			list = append(list, key)
+		}
+	}
+	return list
+}
+
+// GetValue returns the key in section with a found flag
+func (s *layeredStorage) GetValue(section string, key string) (value string, found bool) {
+	value, found = s.overlay.GetValue(section, key)
+	if found {
+		if value == layeredDeleted {
+			return "", false
+		}
+		return value, found
+	}
+	return s.base.GetValue(section, key)
+}
+
+// SetValue sets the value under key in section in the overlay
+func (s *layeredStorage) SetValue(section string, key string, value string) {
+	s.overlay.SetValue(section, key, value)
+}
+
+// DeleteKey removes the key under section from the overlay and marks
+// it as deleted if it is in the base
+func (s *layeredStorage) DeleteKey(section string, key string) bool {
+	_, found := s.GetValue(section, key)
+	if _, inBase := s.base.GetValue(section, key); inBase {
+		s.overlay.SetValue(section, key, layeredDeleted)
+	} else {
+		s.overlay.DeleteKey(section, key)
+	}
+	return found
+}
+
+// Load the config from the base and the overlay
+func (s *layeredStorage) Load() error {
+	if s.err != nil {
+		return s.err
+	}
+	err := s.base.Load()
+	if err != nil && err != config.ErrorConfigFileNotFound {
+		return fmt.Errorf("layered config base: %w", err)
+	}
+	err = s.overlay.Load()
+	if err != nil && err != config.ErrorConfigFileNotFound {
+		return fmt.Errorf("layered config overlay: %w", err)
+	}
+	return nil
+}
+
+// Save the overlay config
+func (s *layeredStorage) Save() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.overlay.Save()
+}
+
+// Serialize the merged config into a string in INI format
+func (s *layeredStorage) Serialize() (string, error) {
+	merged := sections{}
+	for _, section := range s.GetSectionList() {
+		keys := map[string]string{}
+		for _, key := range s.GetKeyList(section) {
+			keys[key], _ = s.GetValue(section, key)
+		}
+		merged[section] = keys
+	}
+	return serializeINI(merged)
+}
+
+// mergeLists returns the sorted union of a and b
+func mergeLists(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	list := make([]string, 0, len(a)+len(b))
+	for _, items := range [][]string{a, b} {
+		for _, item := range items {
+			if _, found := seen[item]; !found {
+				seen[item] = struct{}{}
+				list = append(list, item)
+			}
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Check the interface is satisfied
+var _ config.Storage = (*layeredStorage)(nil)
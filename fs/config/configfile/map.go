@@ -0,0 +1,167 @@
+package configfile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Unknwon/goconfig" //nolint:misspell // Don't include misspell when running golangci-lint
+	"github.com/rclone/rclone/fs"
+)
+
+// sections is config indexed by section then key
+type sections map[string]map[string]string
+
+// mapStorage implements the parts of config.Storage which don't need
+// permanent storage on config held in memory. It is embedded in the
+// other storage types.
+type mapStorage struct {
+	mu       sync.RWMutex // to protect the following variables
+	sections sections
+}
+
+// setSections replaces the config with newSections
+func (s *mapStorage) setSections(newSections sections) {
+	if newSections == nil {
+		newSections = sections{}
+	}
+	s.mu.Lock()
+	s.sections = newSections
+	s.mu.Unlock()
+}
+
+// copySections returns a copy of the config
+func (s *mapStorage) copySections() sections {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	newSections := make(sections, len(s.sections))
+	for section, keys := range s.sections {
+		newKeys := make(map[string]string, len(keys))
+		for key, value := range keys {
+			newKeys[key] = value
+		}
+		newSections[section] = newKeys
+	}
+	return newSections
+}
+
+// GetSectionList returns a slice of strings with names for all the
+// sections
+func (s *mapStorage) GetSectionList() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]string, 0, len(s.sections))
+	for section := range s.sections {
+		list = append(list, section)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// HasSection returns true if section exists in the config
+func (s *mapStorage) HasSection(section string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.sections[section]
+	return found
+}
+
+// DeleteSection removes the named section and all config from the
+// config
+func (s *mapStorage) DeleteSection(section string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sections, section)
+}
+
+// GetKeyList returns the keys in this section
+func (s *mapStorage) GetKeyList(section string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := s.sections[section]
+	list := make([]string, 0, len(keys))
+	for key := range keys {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// GetValue returns the key in section with a found flag
+func (s *mapStorage) GetValue(section string, key string) (value string, found bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, found = s.sections[section][key]
+	return value, found
+}
+
+// SetValue sets the value under key in section
+func (s *mapStorage) SetValue(section string, key string, value string) {
+	if strings.HasPrefix(section, ":") {
+		fs.Logf(nil, "Can't save config %q for on the fly backend %q", key, section)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sections == nil {
+		s.sections = sections{}
+	}
+	keys, found := s.sections[section]
+	if !found {
+		keys = map[string]string{}
+		s.sections[section] = keys
+	}
+	keys[key] = value
+}
+
+// DeleteKey removes the key under section
+func (s *mapStorage) DeleteKey(section string, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys, found := s.sections[section]
+	if !found {
+		return false
+	}
+	if _, found = keys[key]; !found {
+		return false
+	}
+	delete(keys, key)
+	return true
+}
+
+// Serialize the config into a string in INI format
+func (s *mapStorage) Serialize() (string, error) {
+	return serializeINI(s.copySections())
+}
+
+// serializeINI returns the config in INI format as used by the
+// config file
+func serializeINI(config sections) (string, error) {
+	gc, err := goconfig.LoadFromReader(bytes.NewReader([]byte{}))
+	if err != nil {
+		return "", err
+	}
+	sectionList := make([]string, 0, len(config))
+	for section := range config {
+		sectionList = append(sectionList, section)
+	}
+	sort.Strings(sectionList)
+	for _, section := range sectionList {
+		keys := config[section]
+		keyList := make([]string, 0, len(keys))
+		for key := range keys {
+			keyList = append(keyList, key)
+		}
+		sort.Strings(keyList)
+		for _, key := range keyList {
+			gc.SetValue(section, key, keys[key])
+		}
+	}
+	var buf bytes.Buffer
+	if err := goconfig.SaveConfigData(gc, &buf); err != nil {
+		return "", fmt.Errorf("failed to serialize config: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,170 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/lib/file"
+	"gopkg.in/yaml.v2"
+)
+
+// mapFileFormat is the format of a file read by mapFileStorage
+type mapFileFormat int
+
+// Formats of mapFileStorage
+const (
+	formatYAML mapFileFormat = iota
+	formatJSON
+)
+
+// String returns the name of the format
+func (format mapFileFormat) String() string {
+	if format == formatJSON {
+		return "JSON"
+	}
+	return "YAML"
+}
+
+// mapFileStorage implements config.Storage for saving and loading
+// config in a YAML or JSON file with an object for each section, eg
+//
+//	myremote:
+//	  type: s3
+//	  provider: AWS
+type mapFileStorage struct {
+	mapStorage
+	path   string
+	format mapFileFormat
+}
+
+// newMapFileStorage makes a mapFileStorage for the file at path
+func newMapFileStorage(path string, format mapFileFormat) *mapFileStorage {
+	return &mapFileStorage{
+		path:   path,
+		format: format,
+	}
+}
+
+// Load the config from the file
+func (s *mapFileStorage) Load() error {
+	s.setSections(nil)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config.ErrorConfigFileNotFound
+		}
+		return err
+	}
+	newSections, err := s.decode(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v config file: %w", s.format, err)
+	}
+	s.setSections(newSections)
+	return nil
+}
+
+// decode the config from data
+func (s *mapFileStorage) decode(data []byte) (newSections sections, err error) {
+	if s.format == formatYAML {
+		err = yaml.UnmarshalStrict(data, &newSections)
+		return newSections, err
+	}
+	// Decode JSON allowing numbers and booleans as values
+	var raw map[string]map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+	newSections = make(sections, len(raw))
+	for section, keys := range raw {
+		newKeys := make(map[string]string, len(keys))
+		for key, value := range keys {
+			switch v := value.(type) {
+			case string:
+				newKeys[key] = v
+			case json.Number, bool:
+				newKeys[key] = fmt.Sprint(v)
+			case nil:
+				newKeys[key] = ""
+			default:
+				return nil, fmt.Errorf("value of %q in section %q must be a string, number or boolean", key, section)
+			}
+		}
+		newSections[section] = newKeys
+	}
+	return newSections, nil
+}
+
+// encode the config
+func (s *mapFileStorage) encode() ([]byte, error) {
+	config := s.copySections()
+	if s.format == formatYAML {
+		return yaml.Marshal(config)
+	}
+	data, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Save the config to the file
+//
+// This writes a temporary file and renames it over the old one so the
+// config is never left half written.
+func (s *mapFileStorage) Save() (err error) {
+	if s.path == "" {
+		return fmt.Errorf("failed to save config file, path is empty")
+	}
+	data, err := s.encode()
+	if err != nil {
+		return fmt.Errorf("failed to encode %v config file: %w", s.format, err)
+	}
+	configDir, configName := filepath.Split(s.path)
+	if configDir == "" {
+		configDir = "."
+	}
+	err = file.MkdirAll(configDir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.CreateTemp(configDir, configName)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for new config: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			fs.Errorf(nil, "Failed to remove temp file for new config: %v", err)
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return fmt.Errorf("failed to write new config: %w", err)
+	}
+	_ = f.Sync()
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to close config file: %w", err)
+	}
+	var fileMode os.FileMode = 0600
+	if info, err := os.Stat(s.path); err == nil {
+		fileMode = info.Mode()
+	}
+	attemptCopyGroup(s.path, f.Name())
+	if err = os.Chmod(f.Name(), fileMode); err != nil {
+		fs.Errorf(nil, "Failed to set permissions on config file: %v", err)
+	}
+	if err = os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to move newly written config from %s to final location: %v", f.Name(), err)
+	}
+	return nil
+}
+
+// Check the interface is satisfied
+var _ config.Storage = (*mapFileStorage)(nil)
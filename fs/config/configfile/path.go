@@ -0,0 +1,96 @@
+package configfile
+
+import (
+	"sync"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+)
+
+// pathStorage implements config.Storage with the Storage NewStorage
+// makes for the current config path.
+//
+// If the config path changes a new Storage is made for it and loaded,
+// so changing from an INI file to a YAML file, say, changes the format
+// too.
+type pathStorage struct {
+	mu      sync.Mutex
+	path    string         // config path storage was made for
+	storage config.Storage // storage for path - nil if not made yet
+}
+
+// get returns the Storage for the current config path
+func (s *pathStorage) get() config.Storage {
+	path := config.GetConfigPath()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.storage != nil && s.path == path {
+		return s.storage
+	}
+	changed := s.storage != nil
+	s.path = path
+	s.storage = NewStorage(path)
+	if changed {
+		fs.Debugf(nil, "Config path has changed - loading %q", path)
+		err := s.storage.Load()
+		if err != nil && err != config.ErrorConfigFileNotFound {
+			fs.Errorf(nil, "Failed to load config file %q: %v", path, err)
+		}
+	}
+	return s.storage
+}
+
+// GetSectionList returns a slice of strings with names for all the
+// sections
+func (s *pathStorage) GetSectionList() []string {
+	return s.get().GetSectionList()
+}
+
+// HasSection returns true if section exists in the config file
+func (s *pathStorage) HasSection(section string) bool {
+	return s.get().HasSection(section)
+}
+
+// DeleteSection removes the named section and all config from the
+// config file
+func (s *pathStorage) DeleteSection(section string) {
+	s.get().DeleteSection(section)
+}
+
+// GetKeyList returns the keys in this section
+func (s *pathStorage) GetKeyList(section string) []string {
+	return s.get().GetKeyList(section)
+}
+
+// GetValue returns the key in section with a found flag
+func (s *pathStorage) GetValue(section string, key string) (value string, found bool) {
+	return s.get().GetValue(section, key)
+}
+
+// SetValue sets the value under key in section
+func (s *pathStorage) SetValue(section string, key string, value string) {
+	s.get().SetValue(section, key, value)
+}
+
+// DeleteKey removes the key under section
+func (s *pathStorage) DeleteKey(section string, key string) bool {
+	return s.get().DeleteKey(section, key)
+}
+
+// Load the config from permanent storage
+func (s *pathStorage) Load() error {
+	return s.get().Load()
+}
+
+// Save the config to permanent storage
+func (s *pathStorage) Save() error {
+	return s.get().Save()
+}
+
+// Serialize the config into a string
+func (s *pathStorage) Serialize() (string, error) {
+	return s.get().Serialize()
+}
+
+// Check the interface is satisfied
+var _ config.Storage = (*pathStorage)(nil)
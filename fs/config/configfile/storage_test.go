@@ -0,0 +1,281 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/rclone/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStorage(t *testing.T) {
+	assert.IsType(t, &Storage{}, NewStorage("/path/to/rclone.conf"))
+	assert.IsType(t, &mapFileStorage{}, NewStorage("/path/to/rclone.yaml"))
+	assert.IsType(t, &mapFileStorage{}, NewStorage("/path/to/rclone.YML"))
+	assert.IsType(t, &mapFileStorage{}, NewStorage("/path/to/rclone.json"))
+	assert.IsType(t, &envStorage{}, NewStorage("env:"))
+	assert.IsType(t, &execStorage{}, NewStorage("exec:helper"))
+	assert.IsType(t, &layeredStorage{}, NewStorage("layered:overlay.conf,env:"))
+}
+
+func TestMapFileStorage(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name   string
+		format mapFileFormat
+		data   string
+	}{
+		{
+			name:   "rclone.yaml",
+			format: formatYAML,
+			data: `one:
+  type: number1
+  fruit: potato
+two:
+  type: number2
+  fruit: apple
+  count: 2
+  enabled: true
+`,
+		},
+		{
+			name:   "rclone.json",
+			format: formatJSON,
+			data: `{
+	"one": {"type": "number1", "fruit": "potato"},
+	"two": {"type": "number2", "fruit": "apple", "count": 2, "enabled": true}
+}`,
+		},
+	} {
+		t.Run(test.format.String(), func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			s := newMapFileStorage(path, test.format)
+			assert.Equal(t, config.ErrorConfigFileNotFound, s.Load())
+
+			require.NoError(t, os.WriteFile(path, []byte(test.data), 0600))
+			require.NoError(t, s.Load())
+			assert.Equal(t, []string{"one", "two"}, s.GetSectionList())
+			assert.Equal(t, []string{"count", "enabled", "fruit", "type"}, s.GetKeyList("two"))
+			value, found := s.GetValue("two", "count")
+			assert.True(t, found)
+			assert.Equal(t, "2", value)
+			value, found = s.GetValue("two", "enabled")
+			assert.True(t, found)
+			assert.Equal(t, "true", value)
+
+			s.SetValue("three", "type", "number3")
+			assert.True(t, s.DeleteKey("two", "count"))
+			assert.False(t, s.DeleteKey("two", "count"))
+			s.DeleteSection("one")
+			require.NoError(t, s.Save())
+
+			s2 := newMapFileStorage(path, test.format)
+			require.NoError(t, s2.Load())
+			assert.Equal(t, []string{"three", "two"}, s2.GetSectionList())
+			value, found = s2.GetValue("three", "type")
+			assert.True(t, found)
+			assert.Equal(t, "number3", value)
+			_, found = s2.GetValue("two", "count")
+			assert.False(t, found)
+
+			str, err := s2.Serialize()
+			require.NoError(t, err)
+			assert.Contains(t, str, "[three]\ntype = number3\n")
+
+			if runtime.GOOS != "windows" {
+				fi, err := os.Stat(path)
+				require.NoError(t, err)
+				assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+			}
+
+			require.NoError(t, os.WriteFile(path, []byte("potato"), 0600))
+			assert.Error(t, s.Load())
+		})
+	}
+}
+
+func TestEnvSections(t *testing.T) {
+	got := envSections([]string{
+		"HOME=/home/user",
+		"RCLONE_CONFIG_PASS=secret",
+		"RCLONE_CONFIG_MYS3_TYPE=s3",
+		"RCLONE_CONFIG_MYS3_ACCESS_KEY_ID=key",
+		"RCLONE_CONFIG_MYS3_BACKUP_TYPE=alias",
+		"RCLONE_CONFIG_MYS3_BACKUP_REMOTE=mys3:backup",
+		"RCLONE_CONFIG_EMPTY=",
+	})
+	assert.Equal(t, sections{
+		"mys3": {
+			"type":          "s3",
+			"access_key_id": "key",
+		},
+		"mys3_backup": {
+			"type":   "alias",
+			"remote": "mys3:backup",
+		},
+	}, got)
+}
+
+func TestEnvStorage(t *testing.T) {
+	t.Setenv("RCLONE_CONFIG_ENVTEST_TYPE", "local")
+	t.Setenv("RCLONE_CONFIG_ENVTEST_NOUNC", "true")
+	s := NewStorage("env:")
+	require.NoError(t, s.Load())
+	assert.True(t, s.HasSection("envtest"))
+	value, found := s.GetValue("envtest", "nounc")
+	assert.True(t, found)
+	assert.Equal(t, "true", value)
+
+	// Changes are kept in memory only
+	s.SetValue("envtest", "token", "abc")
+	require.NoError(t, s.Save())
+	value, _ = s.GetValue("envtest", "token")
+	assert.Equal(t, "abc", value)
+	require.NoError(t, s.Load())
+	_, found = s.GetValue("envtest", "token")
+	assert.False(t, found)
+}
+
+func TestExecStorage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell")
+	}
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "helper.sh")
+	require.NoError(t, os.WriteFile(helper, []byte(`case "$1 $2" in
+"list ") echo '["one", "two"]' ;;
+"get one") echo '{"type": "number1", "fruit": "potato"}' ;;
+"get two") echo '{"type": "number2"}' ;;
+*) echo "unknown command $*" >&2 ; exit 1 ;;
+esac
+`), 0600))
+
+	s := NewStorage("exec:sh " + helper)
+	require.NoError(t, s.Load())
+	assert.Equal(t, []string{"one", "two"}, s.GetSectionList())
+	value, found := s.GetValue("one", "fruit")
+	assert.True(t, found)
+	assert.Equal(t, "potato", value)
+	require.NoError(t, s.Save())
+
+	assert.Error(t, NewStorage("exec:").Load())
+	assert.Error(t, NewStorage("exec:sh "+helper+" extra").Load())
+	assert.Error(t, NewStorage("exec:"+filepath.Join(dir, "notfound")).Load())
+}
+
+func TestLayeredStorage(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overlay := filepath.Join(dir, "overlay.conf")
+	baseData := `one:
+  type: number1
+  token: old
+two:
+  type: number2
+`
+	require.NoError(t, os.WriteFile(base, []byte(baseData), 0600))
+
+	s := NewStorage("layered:" + overlay + "," + base)
+	require.NoError(t, s.Load())
+	assert.Equal(t, []string{"one", "two"}, s.GetSectionList())
+
+	// Write a value to the overlay
+	s.SetValue("one", "token", "new")
+	s.SetValue("three", "type", "number3")
+	require.NoError(t, s.Save())
+
+	// Check the base is unchanged
+	data, err := os.ReadFile(base)
+	require.NoError(t, err)
+	assert.Equal(t, baseData, string(data))
+
+	// Check the merged config after reading it again
+	s = NewStorage("layered:" + overlay + "," + base)
+	require.NoError(t, s.Load())
+	assert.Equal(t, []string{"one", "three", "two"}, s.GetSectionList())
+	assert.Equal(t, []string{"token", "type"}, s.GetKeyList("one"))
+	value, found := s.GetValue("one", "token")
+	assert.True(t, found)
+	assert.Equal(t, "new", value)
+	value, found = s.GetValue("one", "type")
+	assert.True(t, found)
+	assert.Equal(t, "number1", value)
+	assert.True(t, s.HasSection("two"))
+
+	str, err := s.Serialize()
+	require.NoError(t, err)
+	assert.Contains(t, str, "[one]\ntoken = new\ntype = number1\n")
+
+	// Delete a key and a section from the base
+	assert.True(t, s.DeleteKey("one", "token"))
+	assert.False(t, s.DeleteKey("one", "token"))
+	s.DeleteSection("two")
+	require.NoError(t, s.Save())
+
+	// Check the base is unchanged
+	data, err = os.ReadFile(base)
+	require.NoError(t, err)
+	assert.Equal(t, baseData, string(data))
+
+	// Check they stay deleted after reading it again
+	s = NewStorage("layered:" + overlay + "," + base)
+	require.NoError(t, s.Load())
+	assert.Equal(t, []string{"one", "three"}, s.GetSectionList())
+	assert.Equal(t, []string{"type"}, s.GetKeyList("one"))
+	_, found = s.GetValue("one", "token")
+	assert.False(t, found)
+	assert.False(t, s.HasSection("two"))
+	_, found = s.GetValue("two", "type")
+	assert.False(t, found)
+	str, err = s.Serialize()
+	require.NoError(t, err)
+	assert.NotContains(t, str, "two")
+	assert.NotContains(t, str, layeredDeleted)
+
+	// Check a deleted section can be made again
+	s.SetValue("two", "type", "number2")
+	assert.True(t, s.HasSection("two"))
+	assert.Equal(t, []string{"type"}, s.GetKeyList("two"))
+
+	assert.Error(t, NewStorage("layered:"+overlay).Load())
+}
+
+func TestPathStorage(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "rclone.conf")
+	yamlPath := filepath.Join(dir, "rclone.yaml")
+	require.NoError(t, os.WriteFile(confPath, []byte("[one]\ntype = number1\n"), 0600))
+	require.NoError(t, os.WriteFile(yamlPath, []byte("two:\n  type: number2\n"), 0600))
+	old := config.GetConfigPath()
+	defer func() {
+		assert.NoError(t, config.SetConfigPath(old))
+	}()
+
+	require.NoError(t, config.SetConfigPath(confPath))
+	s := &pathStorage{}
+	require.NoError(t, s.Load())
+	assert.IsType(t, &Storage{}, s.get())
+	assert.Equal(t, []string{"one"}, s.GetSectionList())
+
+	// Check changing the config path changes the format
+	require.NoError(t, config.SetConfigPath(yamlPath))
+	assert.IsType(t, &mapFileStorage{}, s.get())
+	assert.Equal(t, []string{"two"}, s.GetSectionList())
+	s.SetValue("two", "fruit", "apple")
+	require.NoError(t, s.Save())
+	data, err := os.ReadFile(yamlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "fruit: apple")
+}
+
+func TestSetConfigPathStorage(t *testing.T) {
+	old := config.GetConfigPath()
+	defer func() {
+		assert.NoError(t, config.SetConfigPath(old))
+	}()
+	require.NoError(t, config.SetConfigPath("exec:helper --flag"))
+	assert.Equal(t, "exec:helper --flag", config.GetConfigPath())
+}
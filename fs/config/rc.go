@@ -27,7 +27,16 @@ See the [config dump](/commands/rclone_config_dump/) command for more informatio
 
 // Return the config file dump
 func rcDump(ctx context.Context, in rc.Params) (out rc.Params, err error) {
-	return DumpRcBlob(), nil
+	if fs.GetNamespace(ctx) == "" {
+		return DumpRcBlob(), nil
+	}
+	dump := rc.Params{}
+	for _, section := range LoadedData().GetSectionList() {
+		if name, ok := fs.FromNamespace(ctx, section); ok {
+			dump[name] = DumpRcRemote(section)
+		}
+	}
+	return dump, nil
 }
 
 func init() {
@@ -52,7 +61,7 @@ func rcGet(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if err != nil {
 		return nil, err
 	}
-	return DumpRcRemote(name), nil
+	return DumpRcRemote(fs.InNamespace(ctx, name)), nil
 }
 
 func init() {
@@ -72,8 +81,19 @@ See the [listremotes](/commands/rclone_listremotes/) command for more informatio
 
 // Return the a list of remotes in the config file
 // including any defined by environment variables.
+//
+// If ctx has a namespace then only the remotes in it are returned.
 func rcListRemotes(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	remotes := FileSections()
+	if fs.GetNamespace(ctx) != "" {
+		namespaced := []string{}
+		for _, section := range remotes {
+			if name, ok := fs.FromNamespace(ctx, section); ok {
+				namespaced = append(namespaced, name)
+			}
+		}
+		remotes = namespaced
+	}
 	out = rc.Params{
 		"remotes": remotes,
 	}
@@ -145,6 +165,7 @@ func rcConfig(ctx context.Context, in rc.Params, what string) (out rc.Params, er
 	if err != nil {
 		return nil, err
 	}
+	name = fs.InNamespace(ctx, name)
 	parameters := rc.Params{}
 	err = in.GetStruct("parameters", &parameters)
 	if err != nil {
@@ -215,7 +236,7 @@ func rcDelete(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if err != nil {
 		return nil, err
 	}
-	DeleteRemote(name)
+	DeleteRemote(fs.InNamespace(ctx, name))
 	return nil, nil
 }
 
@@ -225,6 +246,7 @@ func init() {
 		Fn:           rcSetPath,
 		Title:        "Set the path of the config file",
 		AuthRequired: true,
+		Global:       true,
 		Help: `
 Parameters:
 
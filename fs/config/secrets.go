@@ -0,0 +1,340 @@
+package config
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// secretPrefix is the prefix of config values encrypted with the
+// secrets key
+const secretPrefix = "RCLONE_SECRET_V1:"
+
+// Sizes of the parts of an encrypted secret which is stored as
+// salt + nonce + secretbox
+const (
+	secretSaltSize  = 16
+	secretNonceSize = 24
+)
+
+// errNoSecretsKey is returned if a secret needs the secrets key and
+// it hasn't been supplied
+var errNoSecretsKey = errors.New("no secrets key - set --secrets-key-file or --secrets-key-command")
+
+// secretsKey makes the keys used to encrypt secrets from the secrets
+// password with scrypt.
+//
+// Each secret stores the salt its key was made with. Secrets written
+// by the same process share a salt so the key is only made once.
+type secretsKey struct {
+	password string
+	mu       sync.Mutex
+	salt     []byte               // salt for encrypting new secrets - nil if not made yet
+	keys     map[string]*[32]byte // keys made from the password by salt
+}
+
+var (
+	secretsKeyMu   sync.Mutex
+	secrets        *secretsKey // key for encrypting secrets - nil if not read yet
+	secretsKeyRead bool        // set if we've tried to read the key
+)
+
+// newSecretsKey makes a secretsKey from password
+func newSecretsKey(password string) *secretsKey {
+	return &secretsKey{
+		password: password,
+		keys:     map[string]*[32]byte{},
+	}
+}
+
+// key returns the key made from the password with salt
+func (k *secretsKey) key(salt []byte) (*[32]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key := k.keys[string(salt)]; key != nil {
+		return key, nil
+	}
+	keyBytes, err := scrypt.Key([]byte(k.password), salt, 32768, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make secrets key: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], keyBytes)
+	k.keys[string(salt)] = &key
+	return &key, nil
+}
+
+// newSalt returns the salt for encrypting new secrets making it if
+// necessary
+func (k *secretsKey) newSalt() ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.salt == nil {
+		salt := make([]byte, secretSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to make salt: %w", err)
+		}
+		k.salt = salt
+	}
+	return k.salt, nil
+}
+
+// encrypt value with the key
+func (k *secretsKey) encrypt(value string) (string, error) {
+	salt, err := k.newSalt()
+	if err != nil {
+		return "", err
+	}
+	key, err := k.key(salt)
+	if err != nil {
+		return "", err
+	}
+	var nonce [secretNonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", fmt.Errorf("failed to make nonce: %w", err)
+	}
+	out := append([]byte{}, salt...)
+	out = append(out, nonce[:]...)
+	out = secretbox.Seal(out, []byte(value), &nonce, key)
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// decrypt value which was encrypted with the key
+func (k *secretsKey) decrypt(value string) (string, error) {
+	box, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, secretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	if len(box) < secretSaltSize+secretNonceSize+secretbox.Overhead {
+		return "", errors.New("secret too short")
+	}
+	salt, box := box[:secretSaltSize], box[secretSaltSize:]
+	var nonce [secretNonceSize]byte
+	copy(nonce[:], box[:secretNonceSize])
+	key, err := k.key(salt)
+	if err != nil {
+		return "", err
+	}
+	out, ok := secretbox.Open(nil, box[secretNonceSize:], &nonce, key)
+	if !ok {
+		return "", errors.New("failed to decrypt secret - wrong secrets key?")
+	}
+	return string(out), nil
+}
+
+// SetSecretsPassword sets the password the key used to encrypt
+// passwords and tokens in the config is made from.
+func SetSecretsPassword(password string) error {
+	password, err := checkPassword(password)
+	if err != nil {
+		return err
+	}
+	secretsKeyMu.Lock()
+	secrets = newSecretsKey(password)
+	secretsKeyRead = true
+	secretsKeyMu.Unlock()
+	return nil
+}
+
+// ClearSecretsPassword clears the key used to encrypt passwords and
+// tokens so it will be read again from --secrets-key-file or
+// --secrets-key-command when next needed.
+func ClearSecretsPassword() {
+	secretsKeyMu.Lock()
+	secrets = nil
+	secretsKeyRead = false
+	secretsKeyMu.Unlock()
+}
+
+// getSecretsKey returns the key for encrypting secrets, reading it
+// from --secrets-key-file or --secrets-key-command if necessary.
+//
+// It returns a nil key if neither are set.
+func getSecretsKey() (*secretsKey, error) {
+	secretsKeyMu.Lock()
+	key, read := secrets, secretsKeyRead
+	secretsKeyMu.Unlock()
+	if read {
+		return key, nil
+	}
+	ci := fs.GetConfig(context.Background())
+	var password string
+	switch {
+	case ci.SecretsKeyFile != "":
+		data, err := os.ReadFile(ci.SecretsKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets key file: %w", err)
+		}
+		password = strings.TrimSpace(string(data))
+	case len(ci.SecretsKeyCommand) != 0:
+		var err error
+		password, err = runPasswordCommand("--secrets-key-command", ci.SecretsKeyCommand)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		secretsKeyMu.Lock()
+		secretsKeyRead = true
+		secretsKeyMu.Unlock()
+		return nil, nil
+	}
+	err := SetSecretsPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %w", err)
+	}
+	secretsKeyMu.Lock()
+	defer secretsKeyMu.Unlock()
+	return secrets, nil
+}
+
+// IsEncryptedSecret returns true if value has been encrypted with the
+// secrets key
+func IsEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, secretPrefix)
+}
+
+// isSecret returns true if key in section should be encrypted with
+// the secrets key.
+//
+// These are passwords, OAuth tokens and client secrets.
+func isSecret(storage Storage, section, key string) bool {
+	if key == ConfigToken || key == ConfigClientSecret {
+		return true
+	}
+	backendType, found := storage.GetValue(section, "type")
+	if !found {
+		return false
+	}
+	ri, err := fs.Find(backendType)
+	if err != nil {
+		return false
+	}
+	for _, opt := range ri.Options {
+		if opt.Name == key {
+			return opt.IsPassword
+		}
+	}
+	return false
+}
+
+// secretsStorage wraps a Storage to encrypt secrets when they are set
+// and decrypt them when they are read.
+//
+// Secrets are only encrypted if there is a secrets key and the whole
+// config isn't encrypted already.
+type secretsStorage struct {
+	Storage
+}
+
+// getValue returns the key in section with a found flag, decrypting
+// it if necessary, or an error if it couldn't be decrypted
+func (s secretsStorage) getValue(section string, key string) (value string, found bool, err error) {
+	value, found = s.Storage.GetValue(section, key)
+	if !found || !IsEncryptedSecret(value) {
+		return value, found, nil
+	}
+	k, err := getSecretsKey()
+	if err == nil && k == nil {
+		err = errNoSecretsKey
+	}
+	if err == nil {
+		value, err = k.decrypt(value)
+	}
+	if err != nil {
+		return "", found, fmt.Errorf("failed to decrypt config %q in %q: %w", key, section, err)
+	}
+	return value, found, nil
+}
+
+// GetValue returns the key in section with a found flag, decrypting
+// it if necessary.
+//
+// If it can't be decrypted the error is logged and an empty value is
+// returned. Use CheckSecrets to get the error.
+func (s secretsStorage) GetValue(section string, key string) (value string, found bool) {
+	value, found, err := s.getValue(section, key)
+	if err != nil {
+		fs.Errorf(nil, "%v", err)
+	}
+	return value, found
+}
+
+// SetValue sets the value under key in section, encrypting it if it
+// is a secret
+func (s secretsStorage) SetValue(section string, key string, value string) {
+	if value != "" && !IsEncryptedSecret(value) && len(configKey) == 0 && isSecret(s.Storage, section, key) {
+		k, err := getSecretsKey()
+		if err != nil {
+			fs.Errorf(nil, "Failed to read secrets key - saving config %q in %q unencrypted: %v", key, section, err)
+		} else if k != nil {
+			encrypted, err := k.encrypt(value)
+			if err != nil {
+				fs.Errorf(nil, "Failed to encrypt config %q in %q - saving it unencrypted: %v", key, section, err)
+			} else {
+				value = encrypted
+			}
+		}
+	}
+	s.Storage.SetValue(section, key, value)
+}
+
+// CheckSecrets returns an error if any of the encrypted secrets in
+// section of the config can't be decrypted.
+//
+// NewFs calls this so a remote with secrets which can't be decrypted
+// fails to be made rather than being used without them.
+func CheckSecrets(section string) error {
+	storage := LoadedData()
+	s, ok := storage.(secretsStorage)
+	if !ok {
+		return nil
+	}
+	for _, key := range s.GetKeyList(section) {
+		_, _, err := s.getValue(section, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncryptSecrets encrypts any unencrypted passwords and tokens in the
+// config with the secrets key and saves it.
+func EncryptSecrets() error {
+	k, err := getSecretsKey()
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		return errNoSecretsKey
+	}
+	if len(configKey) != 0 {
+		return errors.New("the config file is encrypted already")
+	}
+	storage := LoadedData()
+	for _, section := range storage.GetSectionList() {
+		// Don't lose any secrets which can't be decrypted
+		err = CheckSecrets(section)
+		if err != nil {
+			return err
+		}
+		for _, key := range storage.GetKeyList(section) {
+			value, found := storage.GetValue(section, key)
+			if found && value != "" && isSecret(storage, section, key) {
+				// Setting the value encrypts it
+				storage.SetValue(section, key, value)
+			}
+		}
+	}
+	return LoadedData().Save()
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	fs.Register(&fs.RegInfo{
+		Name: "secrets_test_remote",
+		Options: []fs.Option{{
+			Name: "user",
+		}, {
+			Name:       "pass",
+			IsPassword: true,
+		}},
+	})
+}
+
+func TestEncryptDecryptSecret(t *testing.T) {
+	key := newSecretsKey("secret key")
+	encrypted, err := key.encrypt("potato")
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedSecret(encrypted))
+	assert.NotContains(t, encrypted, "potato")
+
+	// Check a new nonce is used each time
+	encrypted2, err := key.encrypt("potato")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, encrypted2)
+
+	decrypted, err := key.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "potato", decrypted)
+
+	// Check a new key with the same password decrypts it using
+	// the salt stored in the secret but uses a different salt
+	key2 := newSecretsKey("secret key")
+	decrypted, err = key2.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "potato", decrypted)
+	encrypted3, err := key2.encrypt("potato")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted[:40], encrypted3[:40])
+
+	_, err = newSecretsKey("wrong key").decrypt(encrypted)
+	assert.Error(t, err)
+	_, err = key.decrypt(secretPrefix + "AAAA")
+	assert.Error(t, err)
+	_, err = key.decrypt(secretPrefix + "!!!")
+	assert.Error(t, err)
+}
+
+func TestSecretsStorage(t *testing.T) {
+	ClearSecretsPassword()
+	defer ClearSecretsPassword()
+	inner := newDefaultStorage()
+	s := secretsStorage{inner}
+
+	// Without a key secrets are stored as is
+	s.SetValue("remote", "type", "secrets_test_remote")
+	s.SetValue("remote", "pass", "obscured")
+	value, _ := inner.GetValue("remote", "pass")
+	assert.Equal(t, "obscured", value)
+
+	require.NoError(t, SetSecretsPassword("secret key"))
+	s.SetValue("remote", "user", "user")
+	s.SetValue("remote", "pass", "obscured")
+	s.SetValue("remote", "token", `{"access_token":"x"}`)
+
+	// Check only the secrets are encrypted in the storage
+	value, _ = inner.GetValue("remote", "user")
+	assert.Equal(t, "user", value)
+	value, _ = inner.GetValue("remote", "pass")
+	assert.True(t, IsEncryptedSecret(value))
+	value, _ = inner.GetValue("remote", "token")
+	assert.True(t, IsEncryptedSecret(value))
+
+	// Check they are decrypted when read
+	value, found := s.GetValue("remote", "pass")
+	assert.True(t, found)
+	assert.Equal(t, "obscured", value)
+	value, _ = s.GetValue("remote", "token")
+	assert.Equal(t, `{"access_token":"x"}`, value)
+
+	// Check the wrong key doesn't decrypt them
+	require.NoError(t, SetSecretsPassword("wrong key"))
+	value, found, err := s.getValue("remote", "pass")
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+	assert.ErrorContains(t, err, "wrong secrets key")
+	value, _ = s.GetValue("remote", "pass")
+	assert.Equal(t, "", value)
+
+	// Check no key doesn't decrypt them
+	ClearSecretsPassword()
+	_, _, err = s.getValue("remote", "pass")
+	assert.ErrorIs(t, err, errNoSecretsKey)
+}
+
+func TestSecretsKeyFile(t *testing.T) {
+	ClearSecretsPassword()
+	defer ClearSecretsPassword()
+	ctx := context.Background()
+	ci := fs.GetConfig(ctx)
+	oldSecretsKeyFile := ci.SecretsKeyFile
+	defer func() {
+		ci.SecretsKeyFile = oldSecretsKeyFile
+	}()
+
+	// No key configured
+	key, err := getSecretsKey()
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	path := filepath.Join(t.TempDir(), "secrets.key")
+	require.NoError(t, os.WriteFile(path, []byte("secret key\n"), 0600))
+	ci.SecretsKeyFile = path
+	ClearSecretsPassword()
+	key, err = getSecretsKey()
+	require.NoError(t, err)
+	assert.Equal(t, "secret key", key.password)
+
+	ci.SecretsKeyFile = filepath.Join(t.TempDir(), "notfound")
+	ClearSecretsPassword()
+	_, err = getSecretsKey()
+	assert.Error(t, err)
+}
+
+func TestSecretsKeyCommand(t *testing.T) {
+	ClearSecretsPassword()
+	defer ClearSecretsPassword()
+	ctx := context.Background()
+	ci := fs.GetConfig(ctx)
+	oldPasswordCommand, oldSecretsKeyCommand := ci.PasswordCommand, ci.SecretsKeyCommand
+	defer func() {
+		ci.PasswordCommand, ci.SecretsKeyCommand = oldPasswordCommand, oldSecretsKeyCommand
+	}()
+
+	// --password-command isn't used for the secrets key
+	ci.PasswordCommand = fs.SpaceSepList{"echo", "asdf"}
+	key, err := getSecretsKey()
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	ci.SecretsKeyCommand = fs.SpaceSepList{"echo", "secret key"}
+	ClearSecretsPassword()
+	key, err = getSecretsKey()
+	require.NoError(t, err)
+	assert.Equal(t, "secret key", key.password)
+}
+
+func TestCheckSecrets(t *testing.T) {
+	ClearSecretsPassword()
+	defer ClearSecretsPassword()
+	oldData, oldDataLoaded := data, dataLoaded
+	defer func() {
+		data, dataLoaded = oldData, oldDataLoaded
+	}()
+	s := secretsStorage{newDefaultStorage()}
+	data, dataLoaded = s, true
+
+	require.NoError(t, SetSecretsPassword("secret key"))
+	s.SetValue("remote", "type", "secrets_test_remote")
+	s.SetValue("remote", "pass", "obscured")
+	assert.NoError(t, CheckSecrets("remote"))
+	assert.NoError(t, CheckSecrets("notfound"))
+
+	require.NoError(t, SetSecretsPassword("wrong key"))
+	assert.ErrorContains(t, CheckSecrets("remote"), `failed to decrypt config "pass" in "remote"`)
+}
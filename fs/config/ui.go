@@ -640,6 +640,9 @@ func CopyRemote(name string) {
 func ShowConfigLocation() {
 	if configPath := GetConfigPath(); configPath == "" {
 		fmt.Println("Configuration is in memory only")
+	} else if opener, _ := FindStorage(configPath); opener != nil {
+		fmt.Println("Configuration is read from:")
+		fmt.Printf("%s\n", configPath)
 	} else {
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
 			fmt.Println("Configuration file doesn't exist, but rclone will use this path:")
@@ -34,6 +34,7 @@ type Features struct {
 	NoMultiThreading        bool // set if can't have multiplethreads on one download open
 	Overlay                 bool // this wraps one or more backends to add functionality
 	ChunkWriterDoesntSeek   bool // set if the chunk writer doesn't need to read the data more than once
+	CopyIsLink              bool // server-side copies are links to the source so use no extra space
 
 	// Purge all files in the directory specified
 	//
@@ -355,6 +356,7 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	ft.PartialUploads = ft.PartialUploads && mask.PartialUploads
 	ft.NoMultiThreading = ft.NoMultiThreading && mask.NoMultiThreading
 	// ft.Overlay = ft.Overlay && mask.Overlay don't propagate Overlay
+	ft.CopyIsLink = ft.CopyIsLink && mask.CopyIsLink
 
 	if mask.Purge == nil {
 		ft.Purge = nil
@@ -0,0 +1,434 @@
+// Per directory filter files
+
+package filter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// dirFilter is the rules read from the filter files in a directory
+// merged with those of its parent directories.
+type dirFilter struct {
+	dir       string     // the directory the rules were read from, "" for the root
+	fileRules rules      // rules for files below dir
+	dirRules  rules      // rules for directories below dir
+	parent    *dirFilter // the rules of the parent directories - may be nil
+}
+
+// match checks remote, a file or directory below df.dir, against the
+// rules of df and its parents starting with the deepest directory.
+//
+// It returns whether it should be included and whether any rule
+// matched.
+func (df *dirFilter) match(remote string, isDir bool) (include, matched bool) {
+	for ; df != nil; df = df.parent {
+		rel := remote
+		if df.dir != "" {
+			rel = strings.TrimPrefix(remote, df.dir+"/")
+		}
+		rs := &df.fileRules
+		if isDir {
+			rs = &df.dirRules
+			rel += "/"
+		}
+		include, matched = rs.match(rel)
+		if matched {
+			return include, true
+		}
+	}
+	return false, false
+}
+
+// dirFilterCacheTime is how long the Filter keeps the rules of a
+// directory which hasn't been listed again before reading its filter
+// files again
+const dirFilterCacheTime = time.Minute
+
+// dirFilterEntry is the dirFilter of a directory in the
+// dirFilterCache
+type dirFilterEntry struct {
+	df          *dirFilter // the rules for the directory, nil if none
+	parent      *dirFilter // the rules of the parent df was made with
+	fingerprint string     // fingerprints of the filter files read
+	read        time.Time  // when the filter files were last checked
+}
+
+// dirFilterCache holds the dirFilter for each directory listed
+type dirFilterCache struct {
+	src       fs.Fs         // Fs to read the filter files from if set
+	maxAge    time.Duration // how long to use an entry without checking its filter files - 0 for ever
+	mu        sync.Mutex
+	dirs      map[string]dirFilterEntry // keyed by Fs and dir
+	lastPrune time.Time                 // when expired entries were last removed
+}
+
+func newDirFilterCache(src fs.Fs, maxAge time.Duration) *dirFilterCache {
+	return &dirFilterCache{
+		src:       src,
+		maxAge:    maxAge,
+		dirs:      make(map[string]dirFilterEntry),
+		lastPrune: time.Now(),
+	}
+}
+
+// get the entry for key
+func (c *dirFilterCache) get(key string) (entry dirFilterEntry, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found = c.dirs[key]
+	return entry, found
+}
+
+// expired returns true if entry needs its filter files checking
+func (c *dirFilterCache) expired(entry dirFilterEntry, now time.Time) bool {
+	return c.maxAge > 0 && now.Sub(entry.read) > c.maxAge
+}
+
+// put the entry for key, removing any expired entries every maxAge
+func (c *dirFilterCache) put(key string, entry dirFilterEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirs[key] = entry
+	if c.maxAge > 0 && entry.read.Sub(c.lastPrune) > c.maxAge {
+		for key, entry := range c.dirs {
+			if c.expired(entry, c.lastPrune) {
+				delete(c.dirs, key)
+			}
+		}
+		c.lastPrune = entry.read
+	}
+}
+
+// dirFiltersMu protects Filter.dirFilters
+var dirFiltersMu sync.Mutex
+
+// getDirFilters returns the dirFilterCache of f, making it if
+// necessary
+func (f *Filter) getDirFilters() *dirFilterCache {
+	dirFiltersMu.Lock()
+	defer dirFiltersMu.Unlock()
+	if f.dirFilters == nil {
+		f.dirFilters = newDirFilterCache(nil, dirFilterCacheTime)
+	}
+	return f.dirFilters
+}
+
+// Context key for the dirFilterCache
+type dirFilterCacheContextKeyType struct{}
+
+var dirFilterCacheContextKey = dirFilterCacheContextKeyType{}
+
+// WithDirFilters returns a context which caches the rules read from
+// the filter files set with --filter-file-name for a traversal.
+//
+// If src is set the filter files are read from src rather than the
+// Fs being listed. This is used by march so the destination is
+// filtered with the filter files of the source.
+//
+// If ctx already has a cache then it is returned unchanged.
+func WithDirFilters(ctx context.Context, src fs.Fs) context.Context {
+	if getDirFilterCache(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dirFilterCacheContextKey, newDirFilterCache(src, 0))
+}
+
+// getDirFilterCache returns the cache set with WithDirFilters or nil
+func getDirFilterCache(ctx context.Context) *dirFilterCache {
+	cache, _ := ctx.Value(dirFilterCacheContextKey).(*dirFilterCache)
+	return cache
+}
+
+// UsesFilterFiles returns true if rules are read from filter files
+// in each directory with --filter-file-name.
+func (f *Filter) UsesFilterFiles() bool {
+	return len(f.Opt.FilterFileName) > 0
+}
+
+// DirFilter is the Filter to use for the entries of a directory. It
+// merges the rules read from the filter files in the directory and
+// its parents with the global rules.
+type DirFilter struct {
+	*Filter
+	df *dirFilter
+}
+
+// ForDir returns the DirFilter to use for entries, the unfiltered
+// listing of dir in fremote.
+//
+// If --filter-file-name isn't in use this returns a DirFilter using
+// just the global rules.
+//
+// The rules are cached in the context set up with WithDirFilters if
+// there is one, otherwise in f. The rules for dir are read again if
+// its filter files in entries have changed. Those cached in f for the
+// parent directories are read again if they are over a minute old and
+// the parent hasn't been listed since.
+func (f *Filter) ForDir(ctx context.Context, fremote fs.Fs, dir string, entries fs.DirEntries) (*DirFilter, error) {
+	if !f.UsesFilterFiles() {
+		return &DirFilter{Filter: f}, nil
+	}
+	cache := getDirFilterCache(ctx)
+	if cache == nil {
+		cache = f.getDirFilters()
+	}
+	if cache.src != nil && cache.src != fremote {
+		fremote, entries = cache.src, nil
+	}
+	df, err := f.dirFilter(ctx, cache, fremote, dir, entries)
+	if err != nil {
+		return nil, err
+	}
+	return &DirFilter{Filter: f, df: df}, nil
+}
+
+// dirFilter returns the dirFilter for dir, reading the filter files
+// from entries if set or from fremote otherwise.
+func (f *Filter) dirFilter(ctx context.Context, cache *dirFilterCache, fremote fs.Fs, dir string, entries fs.DirEntries) (*dirFilter, error) {
+	// Parents are normally listed first so are in the cache
+	var parent *dirFilter
+	if dir != "" {
+		parentDir := path.Dir(dir)
+		if parentDir == "." {
+			parentDir = ""
+		}
+		var err error
+		parent, err = f.dirFilter(ctx, cache, fremote, parentDir, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key := fs.ConfigString(fremote) + "\x00" + dir
+	now := time.Now()
+	entry, found := cache.get(key)
+	found = found && entry.parent == parent
+	if found && entries == nil && !cache.expired(entry, now) {
+		return entry.df, nil
+	}
+
+	// Find the filter files and only read them if they have changed
+	var filterFiles []fs.Object
+	var fingerprint strings.Builder
+	for _, name := range f.Opt.FilterFileName {
+		o, err := findFilterFile(ctx, fremote, dir, name, entries)
+		if err != nil {
+			return nil, err
+		}
+		if o == nil {
+			continue
+		}
+		filterFiles = append(filterFiles, o)
+		_, _ = fmt.Fprintf(&fingerprint, "%s,%d,%d\x00", o.Remote(), o.Size(), o.ModTime(ctx).UnixNano())
+	}
+	df := parent
+	if found && entry.fingerprint == fingerprint.String() {
+		df = entry.df
+	} else {
+		for _, o := range filterFiles {
+			var err error
+			df, err = f.readFilterFile(ctx, o, dir, df)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cache.put(key, dirFilterEntry{
+		df:          df,
+		parent:      parent,
+		fingerprint: fingerprint.String(),
+		read:        now,
+	})
+	return df, nil
+}
+
+// findFilterFile finds the filter file called name in dir returning
+// nil if it isn't found.
+//
+// If entries is nil then it is looked for in fremote.
+func findFilterFile(ctx context.Context, fremote fs.Fs, dir, name string, entries fs.DirEntries) (fs.Object, error) {
+	remote := path.Join(dir, name)
+	if entries == nil {
+		o, err := fremote.NewObject(ctx, remote)
+		if errors.Is(err, fs.ErrorObjectNotFound) || errors.Is(err, fs.ErrorIsDir) || errors.Is(err, fs.ErrorDirNotFound) {
+			return nil, nil
+		}
+		return o, err
+	}
+	for _, entry := range entries {
+		if o, ok := entry.(fs.Object); ok && o.Remote() == remote {
+			return o, nil
+		}
+	}
+	return nil, nil
+}
+
+// readFilterFile reads the rules in the filter file o in dir
+// returning a new dirFilter with parent as its parent.
+func (f *Filter) readFilterFile(ctx context.Context, o fs.Object, dir string, parent *dirFilter) (df *dirFilter, err error) {
+	df = &dirFilter{
+		dir:    dir,
+		parent: parent,
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open filter file: %w", err)
+	}
+	defer fs.CheckClose(in, &err)
+	var lines []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read filter file: %w", err)
+	}
+	if f.Opt.FilterFileGitignore {
+		err = df.addGitignore(lines, f.Opt.IgnoreCase)
+	} else {
+		err = df.addRules(lines, f.Opt.IgnoreCase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad filter file %q: %w", o.Remote(), err)
+	}
+	fs.Debugf(o, "Read %d file and %d directory rules from filter file", df.fileRules.len(), df.dirRules.len())
+	return df, nil
+}
+
+// addRules adds lines in the format of --filter-from to df
+func (df *dirFilter) addRules(lines []string, ignoreCase bool) error {
+	rulesFilter := &Filter{Opt: Opt{IgnoreCase: ignoreCase}}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		err := rulesFilter.AddRule(line)
+		if err != nil {
+			return err
+		}
+	}
+	df.fileRules = rulesFilter.fileRules
+	df.dirRules = rulesFilter.dirRules
+	return nil
+}
+
+// gitignoreRule is a rule parsed from a .gitignore file
+type gitignoreRule struct {
+	include bool   // negated with !
+	glob    string // rclone glob to match
+	dirOnly bool   // only match directories
+}
+
+// parseGitignore parses lines in .gitignore format
+func parseGitignore(lines []string) (gitRules []gitignoreRule) {
+	for _, line := range lines {
+		// Trailing spaces are ignored unless quoted with backslash
+		trimmed := strings.TrimRight(line, " \t")
+		if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+			trimmed += " "
+		}
+		line = trimmed
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var rule gitignoreRule
+		if line[0] == '!' {
+			rule.include = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		line = strings.TrimPrefix(line, "**/")
+		if line == "" {
+			continue
+		}
+		// A pattern with a slash in it is relative to the
+		// directory of the .gitignore, otherwise it matches at
+		// any level below it.
+		if strings.Contains(line, "/") && !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		rule.glob = line
+		gitRules = append(gitRules, rule)
+	}
+	return gitRules
+}
+
+// addGitignore adds lines in the format of .gitignore to df
+func (df *dirFilter) addGitignore(lines []string, ignoreCase bool) error {
+	gitRules := parseGitignore(lines)
+	// The last matching rule wins in .gitignore files whereas
+	// the first wins in rclone so add them in reverse order.
+	for i := len(gitRules) - 1; i >= 0; i-- {
+		rule := gitRules[i]
+		if !rule.dirOnly {
+			re, err := GlobToRegexp(rule.glob, ignoreCase)
+			if err != nil {
+				return err
+			}
+			df.fileRules.add(rule.include, re)
+		}
+		re, err := GlobToRegexp(rule.glob+"/", ignoreCase)
+		if err != nil {
+			return err
+		}
+		df.dirRules.add(rule.include, re)
+		if !rule.include {
+			// Exclude everything in an excluded directory
+			re, err = GlobToRegexp(rule.glob+"/**", ignoreCase)
+			if err != nil {
+				return err
+			}
+			df.fileRules.add(false, re)
+			df.dirRules.add(false, re)
+		}
+	}
+	return nil
+}
+
+// IncludeObject returns whether this object should be included into
+// the sync or not.
+//
+// If the rules of the filter files exclude the object it is excluded,
+// otherwise the global filters decide.
+func (d *DirFilter) IncludeObject(ctx context.Context, o fs.Object) bool {
+	if d.df != nil {
+		if include, matched := d.df.match(o.Remote(), false); matched && !include {
+			return false
+		}
+	}
+	return d.Filter.IncludeObject(ctx, o)
+}
+
+// IncludeDirectory returns a function which checks whether this
+// directory should be included in the sync or not.
+//
+// If the rules of the filter files exclude the directory it is
+// excluded, otherwise the global filters decide.
+func (d *DirFilter) IncludeDirectory(ctx context.Context, fremote fs.Fs) func(string) (bool, error) {
+	includeDirectory := d.Filter.IncludeDirectory(ctx, fremote)
+	if d.df == nil {
+		return includeDirectory
+	}
+	return func(remote string) (bool, error) {
+		if include, matched := d.df.match(strings.Trim(remote, "/"), true); matched && !include {
+			return false, nil
+		}
+		return includeDirectory(remote)
+	}
+}
@@ -0,0 +1,200 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitignore(t *testing.T) {
+	got := parseGitignore([]string{
+		"# comment",
+		"",
+		"*.log",
+		"!important.log",
+		"/build",
+		"docs/out/",
+		"**/tmp",
+		`\#hash`,
+		`\!bang`,
+		`space\ `,
+		"trailing   ",
+	})
+	assert.Equal(t, []gitignoreRule{
+		{glob: "*.log"},
+		{include: true, glob: "important.log"},
+		{glob: "/build"},
+		{glob: "/docs/out", dirOnly: true},
+		{glob: "tmp"},
+		{glob: "#hash"},
+		{glob: "!bang"},
+		{glob: `space\ `},
+		{glob: "trailing"},
+	}, got)
+}
+
+func TestDirFilterMatch(t *testing.T) {
+	root := &dirFilter{}
+	require.NoError(t, root.addRules([]string{
+		"# comment",
+		"- *.log",
+		"- /secret/",
+	}, false))
+	sub := &dirFilter{dir: "sub", parent: root}
+	require.NoError(t, sub.addGitignore([]string{
+		"*.tmp",
+		"!keep.log",
+		"cache/",
+	}, false))
+
+	for _, test := range []struct {
+		remote  string
+		isDir   bool
+		include bool
+		matched bool
+	}{
+		{"file.txt", false, false, false},
+		{"file.log", false, false, true},
+		{"dir/file.log", false, false, true},
+		{"secret", true, false, true},
+		{"secret/file.txt", false, false, true},
+		{"sub/keep.log", false, true, true},
+		{"sub/other.log", false, false, true},
+		{"sub/dir/keep.log", false, true, true},
+		{"sub/file.tmp", false, false, true},
+		{"file.tmp", false, false, false},
+		{"sub/cache", true, false, true},
+		{"sub/cache", false, false, false},
+		{"sub/dir/cache/file.txt", false, false, true},
+		{"sub/secret", true, false, false},
+	} {
+		df := root
+		if len(test.remote) > 4 && test.remote[:4] == "sub/" {
+			df = sub
+		}
+		include, matched := df.match(test.remote, test.isDir)
+		assert.Equal(t, test.include, include, test.remote)
+		assert.Equal(t, test.matched, matched, test.remote)
+	}
+}
+
+func TestForDir(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	f.Opt.FilterFileName = []string{".rcloneignore"}
+	require.NoError(t, f.Add(false, "*.bak"))
+
+	rootFilterFile := mockobject.New(".rcloneignore").WithContent([]byte("- *.log\n+ *.bak\n"), mockobject.SeekModeNone)
+	subFilterFile := mockobject.New("sub/.rcloneignore").WithContent([]byte("+ keep.log\n"), mockobject.SeekModeNone)
+
+	mockFs, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+	mockFs.(*mockfs.Fs).AddObject(rootFilterFile)
+
+	ctx = WithDirFilters(ctx, nil)
+	rootFilter, err := f.ForDir(ctx, mockFs, "", fs.DirEntries{rootFilterFile, mockobject.New("a.log")})
+	require.NoError(t, err)
+	assert.False(t, rootFilter.IncludeObject(ctx, mockobject.New("a.log")))
+	assert.True(t, rootFilter.IncludeObject(ctx, mockobject.New("a.txt")))
+	// The filter files can't include what the global rules exclude
+	assert.False(t, rootFilter.IncludeObject(ctx, mockobject.New("a.bak")))
+
+	subFilter, err := f.ForDir(ctx, mockFs, "sub", fs.DirEntries{subFilterFile})
+	require.NoError(t, err)
+	assert.True(t, subFilter.IncludeObject(ctx, mockobject.New("sub/keep.log")))
+	assert.False(t, subFilter.IncludeObject(ctx, mockobject.New("sub/other.log")))
+	assert.False(t, subFilter.IncludeObject(ctx, mockobject.New("sub/a.bak")))
+
+	// Without a cache in the context the parent filter files are
+	// read from the Fs
+	otherFilter, err := f.ForDir(context.Background(), mockFs, "other", fs.DirEntries{})
+	require.NoError(t, err)
+	assert.False(t, otherFilter.IncludeObject(ctx, mockobject.New("other/a.log")))
+	assert.True(t, otherFilter.IncludeObject(ctx, mockobject.New("other/a.txt")))
+
+	// Without --filter-file-name just the global rules are used
+	f.Opt.FilterFileName = nil
+	rootFilter, err = f.ForDir(ctx, mockFs, "", fs.DirEntries{rootFilterFile})
+	require.NoError(t, err)
+	assert.True(t, rootFilter.IncludeObject(ctx, mockobject.New("a.log")))
+	assert.False(t, rootFilter.IncludeObject(ctx, mockobject.New("a.bak")))
+}
+
+func TestForDirFilesFrom(t *testing.T) {
+	ctx := WithDirFilters(context.Background(), nil)
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	f.Opt.FilterFileName = []string{".rcloneignore"}
+	require.NoError(t, f.AddFile("a.log"))
+	require.NoError(t, f.AddFile("a.txt"))
+
+	filterFile := mockobject.New(".rcloneignore").WithContent([]byte("- *.log\n+ *\n"), mockobject.SeekModeNone)
+	mockFs, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+
+	// The filter files can only remove files from --files-from
+	dirFilter, err := f.ForDir(ctx, mockFs, "", fs.DirEntries{filterFile})
+	require.NoError(t, err)
+	assert.False(t, dirFilter.IncludeObject(ctx, mockobject.New("a.log")))
+	assert.True(t, dirFilter.IncludeObject(ctx, mockobject.New("a.txt")))
+	assert.False(t, dirFilter.IncludeObject(ctx, mockobject.New("b.txt")))
+}
+
+// countingFs counts the calls to NewObject
+type countingFs struct {
+	fs.Fs
+	newObjects int
+}
+
+func (f *countingFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	f.newObjects++
+	return f.Fs.NewObject(ctx, remote)
+}
+
+func TestForDirCache(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	f.Opt.FilterFileName = []string{".rcloneignore"}
+
+	rootFilterFile := mockobject.New(".rcloneignore").WithContent([]byte("- *.log\n"), mockobject.SeekModeNone)
+	mockFs, err := mockfs.NewFs(ctx, "mock", "/", nil)
+	require.NoError(t, err)
+	mockFs.(*mockfs.Fs).AddObject(rootFilterFile)
+	countFs := &countingFs{Fs: mockFs}
+
+	// Check the parents are only read once without a cache in
+	// the context
+	for i := 0; i < 3; i++ {
+		dirFilter, err := f.ForDir(ctx, countFs, "a/b", fs.DirEntries{})
+		require.NoError(t, err)
+		assert.False(t, dirFilter.IncludeObject(ctx, mockobject.New("a/b/file.log")))
+	}
+	assert.Equal(t, 2, countFs.newObjects)
+
+	// Check listing the root with a changed filter file is
+	// noticed by the directories below it
+	newRootFilterFile := mockobject.New(".rcloneignore").WithContent([]byte("- *.txt\n- *.tmp\n"), mockobject.SeekModeNone)
+	_, err = f.ForDir(ctx, countFs, "", fs.DirEntries{newRootFilterFile})
+	require.NoError(t, err)
+	dirFilter, err := f.ForDir(ctx, countFs, "a/b", fs.DirEntries{})
+	require.NoError(t, err)
+	assert.True(t, dirFilter.IncludeObject(ctx, mockobject.New("a/b/file.log")))
+	assert.False(t, dirFilter.IncludeObject(ctx, mockobject.New("a/b/file.txt")))
+	// Only "a" needed reading again as its parent changed
+	assert.Equal(t, 3, countFs.newObjects)
+
+	// Check the parents are read again when they expire
+	f.dirFilters.maxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, err = f.ForDir(ctx, countFs, "a/b", fs.DirEntries{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, countFs.newObjects)
+}
@@ -0,0 +1,661 @@
+// Filter expressions for --filter-expr
+
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// valueKind is the type of a value in a filter expression
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindInt
+	kindTime
+	kindDuration
+)
+
+// String returns the name of the kind for error messages
+func (k valueKind) String() string {
+	switch k {
+	case kindInt:
+		return "number"
+	case kindTime:
+		return "time"
+	case kindDuration:
+		return "duration"
+	}
+	return "string"
+}
+
+// exprValue is a value in a filter expression - which part is used
+// depends on the kind
+type exprValue struct {
+	s string
+	n int64 // also used for durations
+	t time.Time
+}
+
+// exprObject is the object a filter expression is evaluated against
+//
+// If o is set then the things the expression needs are read from it
+// as needed, otherwise just remote, size, modTime and metadata are
+// available.
+type exprObject struct {
+	ctx          context.Context
+	remote       string
+	size         int64
+	modTime      time.Time
+	haveModTime  bool
+	metadata     fs.Metadata
+	haveMetadata bool
+	o            fs.ObjectInfo // may be nil
+}
+
+// getModTime returns the modification time of the object
+func (x *exprObject) getModTime() time.Time {
+	if !x.haveModTime && x.o != nil {
+		x.modTime = x.o.ModTime(x.ctx)
+		x.haveModTime = true
+	}
+	return x.modTime
+}
+
+// getMetadata returns the metadata of the object
+func (x *exprObject) getMetadata() fs.Metadata {
+	if !x.haveMetadata && x.o != nil {
+		metadata, err := fs.GetMetadata(x.ctx, x.o)
+		if err != nil {
+			fs.Errorf(x.o, "Failed to read metadata: %v", err)
+		}
+		x.metadata = metadata
+		x.haveMetadata = true
+	}
+	return x.metadata
+}
+
+// getHash returns the hash of the object or "" if it isn't available
+func (x *exprObject) getHash(ht hash.Type) string {
+	if x.o == nil {
+		return ""
+	}
+	sum, err := x.o.Hash(x.ctx, ht)
+	if err != nil && !errors.Is(err, hash.ErrUnsupported) {
+		fs.Errorf(x.o, "Failed to read %v hash: %v", ht, err)
+	}
+	return sum
+}
+
+// operand is a value in a comparison
+type operand interface {
+	kind() valueKind
+	value(x *exprObject) exprValue
+}
+
+// fieldOperand reads a value from the object
+type fieldOperand struct {
+	name string
+	k    valueKind
+	get  func(x *exprObject) exprValue
+}
+
+func (f *fieldOperand) kind() valueKind               { return f.k }
+func (f *fieldOperand) value(x *exprObject) exprValue { return f.get(x) }
+
+// constOperand is a literal converted to the kind of the other side
+// of the comparison
+type constOperand struct {
+	k valueKind
+	v exprValue
+}
+
+func (c *constOperand) kind() valueKind               { return c.k }
+func (c *constOperand) value(x *exprObject) exprValue { return c.v }
+
+// literalOperand is a literal which hasn't been converted yet
+type literalOperand struct {
+	raw string
+}
+
+func (l *literalOperand) kind() valueKind               { return kindString }
+func (l *literalOperand) value(x *exprObject) exprValue { return exprValue{s: l.raw} }
+
+// convert the literal into kind k
+func (l *literalOperand) convert(k valueKind) (*constOperand, error) {
+	c := &constOperand{k: k}
+	switch k {
+	case kindString:
+		c.v.s = l.raw
+	case kindInt:
+		n, err := strconv.ParseInt(l.raw, 10, 64)
+		if err != nil {
+			var size fs.SizeSuffix
+			if err = size.Set(l.raw); err != nil {
+				return nil, fmt.Errorf("bad number %q: %w", l.raw, err)
+			}
+			n = int64(size)
+		}
+		c.v.n = n
+	case kindTime:
+		t, err := fs.ParseTime(l.raw)
+		if err != nil {
+			return nil, fmt.Errorf("bad time %q: %w", l.raw, err)
+		}
+		c.v.t = t
+	case kindDuration:
+		d, err := fs.ParseDuration(l.raw)
+		if err != nil {
+			return nil, fmt.Errorf("bad duration %q: %w", l.raw, err)
+		}
+		c.v.n = int64(d)
+	}
+	return c, nil
+}
+
+// stringField makes a string field
+func stringField(name string, get func(x *exprObject) string) *fieldOperand {
+	return &fieldOperand{name: name, k: kindString, get: func(x *exprObject) exprValue {
+		return exprValue{s: get(x)}
+	}}
+}
+
+// lookupField returns the field called name or nil if not found
+func lookupField(name string) (*fieldOperand, error) {
+	switch name {
+	case "path":
+		return stringField(name, func(x *exprObject) string { return x.remote }), nil
+	case "name":
+		return stringField(name, func(x *exprObject) string { return path.Base(x.remote) }), nil
+	case "dir":
+		return stringField(name, func(x *exprObject) string {
+			dir := path.Dir(x.remote)
+			if dir == "." {
+				dir = ""
+			}
+			return dir
+		}), nil
+	case "ext":
+		return stringField(name, func(x *exprObject) string { return path.Ext(x.remote) }), nil
+	case "mimetype":
+		return stringField(name, func(x *exprObject) string {
+			if x.o == nil {
+				return fs.MimeTypeFromName(x.remote)
+			}
+			return fs.MimeType(x.ctx, x.o)
+		}), nil
+	case "tier":
+		return stringField(name, func(x *exprObject) string {
+			if do, ok := x.o.(fs.GetTierer); ok {
+				return do.GetTier()
+			}
+			return ""
+		}), nil
+	case "id":
+		return stringField(name, func(x *exprObject) string {
+			if do, ok := x.o.(fs.IDer); ok {
+				return do.ID()
+			}
+			return ""
+		}), nil
+	case "size":
+		return &fieldOperand{name: name, k: kindInt, get: func(x *exprObject) exprValue {
+			return exprValue{n: x.size}
+		}}, nil
+	case "modtime":
+		return &fieldOperand{name: name, k: kindTime, get: func(x *exprObject) exprValue {
+			return exprValue{t: x.getModTime()}
+		}}, nil
+	case "age":
+		return &fieldOperand{name: name, k: kindDuration, get: func(x *exprObject) exprValue {
+			return exprValue{n: int64(time.Since(x.getModTime()))}
+		}}, nil
+	}
+	if strings.HasPrefix(name, "meta.") && len(name) > len("meta.") {
+		key := strings.ToLower(name[len("meta."):])
+		return stringField(name, func(x *exprObject) string { return x.getMetadata()[key] }), nil
+	}
+	if strings.HasPrefix(name, "hash.") {
+		var ht hash.Type
+		if err := ht.Set(name[len("hash."):]); err != nil {
+			return nil, err
+		}
+		return stringField(name, func(x *exprObject) string { return x.getHash(ht) }), nil
+	}
+	return nil, nil
+}
+
+// bucketOperand returns the hash bucket of the path of the object
+type bucketOperand struct {
+	n uint64
+}
+
+func (b *bucketOperand) kind() valueKind { return kindInt }
+
+// value returns a number from 0 to n-1 computed from the path of
+// the object so it is the same on every run and every machine.
+func (b *bucketOperand) value(x *exprObject) exprValue {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(x.remote))
+	return exprValue{n: int64(h.Sum64() % b.n)}
+}
+
+// exprNode is a node in the parsed expression which returns a boolean
+type exprNode interface {
+	eval(x *exprObject) bool
+}
+
+type andNode struct{ left, right exprNode }
+type orNode struct{ left, right exprNode }
+type notNode struct{ node exprNode }
+
+func (n *andNode) eval(x *exprObject) bool { return n.left.eval(x) && n.right.eval(x) }
+func (n *orNode) eval(x *exprObject) bool  { return n.left.eval(x) || n.right.eval(x) }
+func (n *notNode) eval(x *exprObject) bool { return !n.node.eval(x) }
+
+// compareNode compares two operands of the same kind
+type compareNode struct {
+	op          string
+	left, right operand
+	ignoreCase  bool
+}
+
+func (n *compareNode) eval(x *exprObject) bool {
+	l, r := n.left.value(x), n.right.value(x)
+	var cmp int
+	switch n.left.kind() {
+	case kindString:
+		if n.ignoreCase {
+			l.s, r.s = strings.ToLower(l.s), strings.ToLower(r.s)
+		}
+		cmp = strings.Compare(l.s, r.s)
+	case kindInt, kindDuration:
+		switch {
+		case l.n < r.n:
+			cmp = -1
+		case l.n > r.n:
+			cmp = 1
+		}
+	case kindTime:
+		switch {
+		case l.t.Before(r.t):
+			cmp = -1
+		case l.t.After(r.t):
+			cmp = 1
+		}
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// matchNode matches a string operand against a glob
+type matchNode struct {
+	negate bool
+	left   operand
+	re     *regexp.Regexp
+}
+
+func (n *matchNode) eval(x *exprObject) bool {
+	return n.re.MatchString(n.left.value(x).s) != n.negate
+}
+
+// expr is a parsed --filter-expr
+type expr struct {
+	text      string
+	root      exprNode
+	usesAttrs bool // set if the expression reads more than the path
+}
+
+// String returns the expression as passed in
+func (e *expr) String() string {
+	return e.text
+}
+
+// include returns whether x passes the expression
+func (e *expr) include(x *exprObject) bool {
+	return e.root.eval(x)
+}
+
+// token is a lexical token of a filter expression
+type token struct {
+	pos    int
+	text   string
+	quoted bool // a quoted string
+}
+
+// Characters which end a bare word
+const exprSpecials = "()!=<>~&|,\"'"
+
+// lexExpr splits s into tokens
+func lexExpr(s string) (tokens []token, err error) {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			var text strings.Builder
+			i++
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("unterminated string at position %d", start+1)
+				}
+				if s[i] == '\\' && i+1 < len(s) {
+					text.WriteByte(s[i+1])
+					i += 2
+					continue
+				}
+				if s[i] == c {
+					i++
+					break
+				}
+				text.WriteByte(s[i])
+				i++
+			}
+			tokens = append(tokens, token{pos: start, text: text.String(), quoted: true})
+		case strings.ContainsRune(exprSpecials, rune(c)):
+			text := string(c)
+			if i+1 < len(s) {
+				switch two := s[i : i+2]; two {
+				case "==", "!=", "<=", ">=", "!~", "&&", "||":
+					text = two
+				}
+			}
+			if text == "=" || text == "&" || text == "|" {
+				return nil, fmt.Errorf("unexpected %q at position %d", text, i+1)
+			}
+			tokens = append(tokens, token{pos: i, text: text})
+			i += len(text)
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune(exprSpecials, rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, token{pos: start, text: s[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser for filter expressions
+type exprParser struct {
+	tokens     []token
+	i          int
+	ignoreCase bool
+	usesAttrs  bool // set if a field other than a path field was parsed
+}
+
+// peek returns the text of the next token if it isn't quoted or ""
+func (p *exprParser) peek() string {
+	if p.i >= len(p.tokens) || p.tokens[p.i].quoted {
+		return ""
+	}
+	return p.tokens[p.i].text
+}
+
+// errorf returns an error at the current position
+func (p *exprParser) errorf(format string, a ...any) error {
+	where := "at end of expression"
+	if p.i < len(p.tokens) {
+		where = fmt.Sprintf("at position %d", p.tokens[p.i].pos+1)
+	}
+	return fmt.Errorf(format+" "+where, a...)
+}
+
+// expect consumes the next token which must be text
+func (p *exprParser) expect(text string) error {
+	if p.peek() != text {
+		return p.errorf("expecting %q", text)
+	}
+	p.i++
+	return nil
+}
+
+// parseOr parses: and { ("||" | "or") and }
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" || p.peek() == "or" {
+		p.i++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary { ("&&" | "and") unary }
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" || p.peek() == "and" {
+		p.i++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: ("!" | "not") unary | "(" or ")" | comparison
+func (p *exprParser) parseUnary() (exprNode, error) {
+	switch p.peek() {
+	case "!", "not":
+		p.i++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: node}, nil
+	case "(":
+		p.i++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: operand op operand
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "~", "!~":
+		p.i++
+	default:
+		return nil, p.errorf("expecting comparison operator")
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	_, leftLiteral := left.(*literalOperand)
+	rightLiteral, isRightLiteral := right.(*literalOperand)
+	if op == "~" || op == "!~" {
+		if leftLiteral || !isRightLiteral || left.kind() != kindString {
+			return nil, fmt.Errorf("%q needs a string field on the left and a pattern on the right", op)
+		}
+		re, err := GlobToRegexp("/"+rightLiteral.raw, p.ignoreCase)
+		if err != nil {
+			return nil, err
+		}
+		return &matchNode{negate: op == "!~", left: left, re: re}, nil
+	}
+	switch {
+	case leftLiteral && isRightLiteral:
+		return nil, fmt.Errorf("comparison %q needs a field on one side", op)
+	case leftLiteral:
+		left, err = left.(*literalOperand).convert(right.kind())
+	case isRightLiteral:
+		right, err = rightLiteral.convert(left.kind())
+	case left.kind() != right.kind():
+		err = fmt.Errorf("can't compare %v with %v", left.kind(), right.kind())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op: op, left: left, right: right, ignoreCase: p.ignoreCase}, nil
+}
+
+// parseOperand parses: field | function "(" args ")" | literal
+func (p *exprParser) parseOperand() (operand, error) {
+	if p.i >= len(p.tokens) {
+		return nil, p.errorf("expecting field or value")
+	}
+	tok := p.tokens[p.i]
+	if tok.quoted {
+		p.i++
+		return &literalOperand{raw: tok.text}, nil
+	}
+	if tok.text == "" || strings.ContainsAny(tok.text[:1], exprSpecials) {
+		return nil, p.errorf("expecting field or value")
+	}
+	p.i++
+	if p.peek() == "(" {
+		return p.parseFunction(tok.text)
+	}
+	field, err := lookupField(tok.text)
+	if err != nil {
+		return nil, err
+	}
+	if field != nil {
+		if !isPathField(field.name) {
+			p.usesAttrs = true
+		}
+		return field, nil
+	}
+	return &literalOperand{raw: tok.text}, nil
+}
+
+// parseFunction parses the arguments of the function name
+func (p *exprParser) parseFunction(name string) (operand, error) {
+	if name != "bucket" {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	p.i++ // skip "("
+	arg := p.peek()
+	n, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || n == 0 {
+		return nil, p.errorf("bucket needs a positive number of buckets")
+	}
+	p.i++
+	if err = p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &bucketOperand{n: n}, nil
+}
+
+// newExpr parses the filter expression s
+func newExpr(s string, ignoreCase bool) (*expr, error) {
+	tokens, err := lexExpr(s)
+	if err != nil {
+		return nil, fmt.Errorf("bad filter expression %q: %w", s, err)
+	}
+	p := &exprParser{tokens: tokens, ignoreCase: ignoreCase}
+	root, err := p.parseOr()
+	if err == nil && p.i < len(p.tokens) {
+		err = p.errorf("unexpected %q", p.tokens[p.i].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad filter expression %q: %w", s, err)
+	}
+	return &expr{text: s, root: root, usesAttrs: p.usesAttrs}, nil
+}
+
+// isPathField returns true if the field called name only depends on
+// the path of the file
+func isPathField(name string) bool {
+	switch name {
+	case "path", "name", "dir", "ext":
+		return true
+	}
+	return false
+}
+
+// UsesAttributes returns true if any of the filter expressions read
+// attributes of the files other than their paths, such as size,
+// modtime, metadata, tier or hashes.
+//
+// These can't be used with sync as the attributes of a changed file
+// on the destination differ from the source so the destination file
+// can be excluded when the source is included and vice versa, which
+// makes sync delete files it shouldn't.
+func (f *Filter) UsesAttributes() bool {
+	for _, e := range f.exprs {
+		if e.usesAttrs {
+			return true
+		}
+	}
+	return false
+}
+
+// includeExprs returns whether the object passes all the filter
+// expressions.
+//
+// o may be nil in which case only remote, size, modTime and metadata
+// are available to the expressions.
+func (f *Filter) includeExprs(ctx context.Context, remote string, size int64, modTime time.Time, metadata fs.Metadata, o fs.ObjectInfo) bool {
+	if len(f.exprs) == 0 {
+		return true
+	}
+	x := &exprObject{
+		ctx:          ctx,
+		remote:       remote,
+		size:         size,
+		modTime:      modTime,
+		haveModTime:  o == nil || !f.ModTimeFrom.IsZero() || !f.ModTimeTo.IsZero(),
+		metadata:     metadata,
+		haveMetadata: o == nil || f.metaRules.len() > 0,
+		o:            o,
+	}
+	for _, e := range f.exprs {
+		if !e.include(x) {
+			return false
+		}
+	}
+	return true
+}
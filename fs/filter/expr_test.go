@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExprErrors(t *testing.T) {
+	for _, test := range []struct {
+		in  string
+		err string
+	}{
+		{"", "expecting field or value at end of expression"},
+		{"size", "expecting comparison operator at end of expression"},
+		{"size > ", "expecting field or value at end of expression"},
+		{"size = 1", `unexpected "=" at position 6`},
+		{"size > 1 size", `unexpected "size" at position 10`},
+		{"(size > 1", `expecting ")" at end of expression`},
+		{`name == "abc`, "unterminated string at position 9"},
+		{"1 == 2", `comparison "==" needs a field on one side`},
+		{"size > potato", `bad number "potato"`},
+		{"modtime > potato", `bad time "potato"`},
+		{"age > potato", `bad duration "potato"`},
+		{"size == name", "can't compare number with string"},
+		{"size ~ 1*", `"~" needs a string field on the left and a pattern on the right`},
+		{"hash.potato == 1", "unknown hash type"},
+		{"potato(1) == 1", `unknown function "potato"`},
+		{"bucket(0) == 1", "bucket needs a positive number of buckets at position 8"},
+	} {
+		_, err := newExpr(test.in, false)
+		require.Error(t, err, test.in)
+		assert.Contains(t, err.Error(), test.err, test.in)
+	}
+}
+
+func TestExprInclude(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	o := object.NewStaticObjectInfo("dir/photo.JPG", now.Add(-48*time.Hour), 2*1024*1024, true, map[hash.Type]string{
+		hash.MD5: "2e1f3b8c5d7a9f0b1c2d3e4f5a6b7c8d",
+	}, nil).WithMetadata(fs.Metadata{
+		"content-type": "image/jpeg",
+		"tier":         "STANDARD",
+	}).WithMimeType("image/jpeg")
+
+	for _, test := range []struct {
+		in         string
+		ignoreCase bool
+		want       bool
+	}{
+		{"size > 1M", false, true},
+		{"size >= 2M && size <= 2M", false, true},
+		{"size < 1M", false, false},
+		{"size == 2097152", false, true},
+		{"name == photo.JPG", false, true},
+		{"name == photo.jpg", false, false},
+		{"name == photo.jpg", true, true},
+		{"dir == dir and ext == .JPG", false, true},
+		{`path ~ "dir/*.JPG"`, false, true},
+		{`path ~ "*.JPG"`, false, false},
+		{`path ~ "**.JPG"`, false, true},
+		{`path !~ "**.JPG"`, false, false},
+		{`mimetype ~ "image/*"`, false, true},
+		{`mimetype ~ "image/*" and meta.tier == STANDARD`, false, true},
+		{`meta.Content-Type == "image/jpeg"`, false, true},
+		{`meta.missing == ""`, false, true},
+		{"hash.md5 ~ '[0-3]*'", false, true},
+		{"hash.md5 ~ '[4-9a-f]*'", false, false},
+		{"hash.sha1 == ''", false, true},
+		{"age > 1d && age < 3d", false, true},
+		{"modtime < 1d", false, true},
+		{"modtime > 2000-01-01", false, true},
+		{"2000-01-01 < modtime", false, true},
+		{"not size > 1M", false, false},
+		{"!(size > 1M) || name == photo.JPG", false, true},
+		{"size > 1M and (name == a or name == b)", false, false},
+		{"size < 1M or name == a or name == photo.JPG", false, true},
+		{"bucket(1) == 0", false, true},
+		{"bucket(1) != 0", false, false},
+	} {
+		e, err := newExpr(test.in, test.ignoreCase)
+		require.NoError(t, err, test.in)
+		x := &exprObject{ctx: ctx, remote: o.Remote(), size: o.Size(), o: o}
+		assert.Equal(t, test.want, e.include(x), test.in)
+	}
+}
+
+func TestExprBucket(t *testing.T) {
+	e, err := newExpr("bucket(4)", false)
+	require.Error(t, err)
+	assert.Nil(t, e)
+
+	// Every path should be in exactly one bucket
+	var exprs []*expr
+	for _, s := range []string{"bucket(4) == 0", "bucket(4) == 1", "bucket(4) == 2", "bucket(4) == 3"} {
+		e, err := newExpr(s, false)
+		require.NoError(t, err)
+		exprs = append(exprs, e)
+	}
+	counts := make([]int, len(exprs))
+	for _, remote := range []string{"a", "b", "c", "dir/d", "dir/e", "f.txt", "g.jpg", "h/i/j"} {
+		n := 0
+		for i, e := range exprs {
+			if e.include(&exprObject{remote: remote}) {
+				counts[i]++
+				n++
+			}
+		}
+		assert.Equal(t, 1, n, remote)
+	}
+	// Check it is stable
+	assert.Equal(t, []int{2, 1, 2, 3}, counts)
+}
+
+func TestFilterExpr(t *testing.T) {
+	ctx := context.Background()
+	opt := DefaultOpt
+	opt.FilterExpr = []string{"size > 10", `name !~ "*.bak"`}
+	f, err := NewFilter(&opt)
+	require.NoError(t, err)
+	assert.False(t, f.InActive())
+	assert.Contains(t, f.DumpFilters(), "--- Filter expressions ---\nsize > 10\nname !~ \"*.bak\"")
+
+	now := time.Now()
+	assert.True(t, f.Include("file.txt", 100, now, nil))
+	assert.False(t, f.Include("file.txt", 1, now, nil))
+	assert.False(t, f.Include("file.bak", 100, now, nil))
+
+	o := object.NewStaticObjectInfo("file.txt", now, 100, true, nil, nil)
+	assert.True(t, f.includeExprs(ctx, o.Remote(), o.Size(), time.Time{}, nil, o))
+
+	opt.FilterExpr = []string{"size >"}
+	_, err = NewFilter(&opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad filter expression")
+}
@@ -21,17 +21,21 @@ var globalConfig = mustNewFilter(nil)
 
 // Opt configures the filter
 type Opt struct {
-	DeleteExcluded bool
-	RulesOpt       // embedded so we don't change the JSON API
-	ExcludeFile    []string
-	FilesFrom      []string
-	FilesFromRaw   []string
-	MetaRules      RulesOpt
-	MinAge         fs.Duration
-	MaxAge         fs.Duration
-	MinSize        fs.SizeSuffix
-	MaxSize        fs.SizeSuffix
-	IgnoreCase     bool
+	DeleteExcluded      bool
+	RulesOpt            // embedded so we don't change the JSON API
+	ExcludeFile         []string
+	FilterFileName      []string
+	FilterFileGitignore bool
+	FilesFrom           []string
+	FilesFromRaw        []string
+	MetaRules           RulesOpt
+	FilterExpr          []string
+	FilterExprSync      bool
+	MinAge              fs.Duration
+	MaxAge              fs.Duration
+	MinSize             fs.SizeSuffix
+	MaxSize             fs.SizeSuffix
+	IgnoreCase          bool
 }
 
 // DefaultOpt is the default config for the filter
@@ -53,8 +57,10 @@ type Filter struct {
 	fileRules   rules
 	dirRules    rules
 	metaRules   rules
-	files       FilesMap // files if filesFrom
-	dirs        FilesMap // dirs from filesFrom
+	exprs       []*expr
+	files       FilesMap        // files if filesFrom
+	dirs        FilesMap        // dirs from filesFrom
+	dirFilters  *dirFilterCache // rules read with --filter-file-name - use getDirFilters
 }
 
 // NewFilter parses the command line options and creates a Filter
@@ -92,6 +98,14 @@ func NewFilter(opt *Opt) (f *Filter, err error) {
 		return nil, err
 	}
 
+	for _, s := range f.Opt.FilterExpr {
+		e, err := newExpr(s, f.Opt.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+		f.exprs = append(f.exprs, e)
+	}
+
 	inActive := f.InActive()
 
 	for _, rule := range f.Opt.FilesFrom {
@@ -254,7 +268,9 @@ func (f *Filter) InActive() bool {
 		f.fileRules.len() == 0 &&
 		f.dirRules.len() == 0 &&
 		f.metaRules.len() == 0 &&
-		len(f.Opt.ExcludeFile) == 0)
+		len(f.exprs) == 0 &&
+		len(f.Opt.ExcludeFile) == 0 &&
+		len(f.Opt.FilterFileName) == 0)
 }
 
 // IncludeRemote returns whether this remote passes the filter rules.
@@ -332,11 +348,29 @@ func (f *Filter) DirContainsExcludeFile(ctx context.Context, fremote fs.Fs, remo
 // Include returns whether this object should be included into the
 // sync or not
 func (f *Filter) Include(remote string, size int64, modTime time.Time, metadata fs.Metadata) bool {
+	return f.include(context.Background(), remote, size, modTime, metadata, nil)
+}
+
+// include returns whether this object should be included into the
+// sync or not. o may be nil.
+func (f *Filter) include(ctx context.Context, remote string, size int64, modTime time.Time, metadata fs.Metadata, o fs.ObjectInfo) bool {
 	// filesFrom takes precedence
 	if f.files != nil {
 		_, include := f.files[remote]
 		return include
 	}
+	if !f.includeWithoutRules(size, modTime, metadata) {
+		return false
+	}
+	if !f.IncludeRemote(remote) {
+		return false
+	}
+	return f.includeExprs(ctx, remote, size, modTime, metadata, o)
+}
+
+// includeWithoutRules returns whether this object passes the filters
+// other than the file name rules.
+func (f *Filter) includeWithoutRules(size int64, modTime time.Time, metadata fs.Metadata) bool {
 	if !f.ModTimeFrom.IsZero() && modTime.Before(f.ModTimeFrom) {
 		return false
 	}
@@ -363,21 +397,25 @@ func (f *Filter) Include(remote string, size int64, modTime time.Time, metadata
 			return false
 		}
 	}
-	return f.IncludeRemote(remote)
+	return true
 }
 
 // IncludeObject returns whether this object should be included into
 // the sync or not. This is a convenience function to avoid calling
 // o.ModTime(), which is an expensive operation.
 func (f *Filter) IncludeObject(ctx context.Context, o fs.Object) bool {
-	var modTime time.Time
+	modTime, metadata := f.objectInfo(ctx, o)
+	return f.include(ctx, o.Remote(), o.Size(), modTime, metadata, o)
+}
 
+// objectInfo reads the modification time and metadata of o if the
+// filters need them.
+func (f *Filter) objectInfo(ctx context.Context, o fs.Object) (modTime time.Time, metadata fs.Metadata) {
 	if !f.ModTimeFrom.IsZero() || !f.ModTimeTo.IsZero() {
 		modTime = o.ModTime(ctx)
 	} else {
 		modTime = time.Unix(0, 0)
 	}
-	var metadata fs.Metadata
 	if f.metaRules.len() > 0 {
 		var err error
 		metadata, err = fs.GetMetadata(ctx, o)
@@ -387,7 +425,7 @@ func (f *Filter) IncludeObject(ctx context.Context, o fs.Object) bool {
 		}
 
 	}
-	return f.Include(o.Remote(), o.Size(), modTime, metadata)
+	return modTime, metadata
 }
 
 // DumpFilters dumps the filters in textual form, 1 per line
@@ -413,6 +451,12 @@ func (f *Filter) DumpFilters() string {
 			rules = append(rules, metaRule.String())
 		}
 	}
+	if len(f.exprs) > 0 {
+		rules = append(rules, "--- Filter expressions ---")
+		for _, e := range f.exprs {
+			rules = append(rules, e.String())
+		}
+	}
 	return strings.Join(rules, "\n")
 }
 
@@ -472,6 +516,9 @@ func (f *Filter) MakeListR(ctx context.Context, NewObject func(ctx context.Conte
 //
 // This is used in deciding whether to walk directories or use ListR
 func (f *Filter) UsesDirectoryFilters() bool {
+	if f.UsesFilterFiles() {
+		return true
+	}
 	if len(f.dirRules.rules) == 0 {
 		return false
 	}
@@ -51,7 +51,11 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.DeleteExcluded, "delete-excluded", "", false, "Delete files on dest excluded from sync", "Filter")
 	AddRuleFlags(flagSet, &Opt.RulesOpt, "file", "")
 	AddRuleFlags(flagSet, &Opt.MetaRules, "metadata", "metadata-")
+	flags.StringArrayVarP(flagSet, &Opt.FilterExpr, "filter-expr", "", nil, "Only transfer files matching this expression on name, size, modtime, metadata, hashes", "Filter")
+	flags.BoolVarP(flagSet, &Opt.FilterExprSync, "filter-expr-sync", "", false, "Allow sync with --filter-expr on file attributes other than the path", "Filter")
 	flags.StringArrayVarP(flagSet, &Opt.ExcludeFile, "exclude-if-present", "", nil, "Exclude directories if filename is present", "Filter")
+	flags.StringArrayVarP(flagSet, &Opt.FilterFileName, "filter-file-name", "", nil, "Read filter rules for each directory from files with this name", "Filter")
+	flags.BoolVarP(flagSet, &Opt.FilterFileGitignore, "filter-file-gitignore", "", false, "Read the files set with --filter-file-name as .gitignore files", "Filter")
 	flags.StringArrayVarP(flagSet, &Opt.FilesFrom, "files-from", "", nil, "Read list of source-file names from file (use - to read from stdin)", "Filter")
 	flags.StringArrayVarP(flagSet, &Opt.FilesFromRaw, "files-from-raw", "", nil, "Read list of source-file names from file without any processing of lines (use - to read from stdin)", "Filter")
 	flags.FVarP(flagSet, &Opt.MinAge, "min-age", "", "Only transfer files older than this in s or suffix ms|s|m|h|d|w|M|y", "Filter")
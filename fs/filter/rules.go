@@ -88,6 +88,17 @@ func (rs *rules) len() int {
 	return len(rs.rules)
 }
 
+// match returns whether this remote passes the filter rules and
+// whether any rule matched it.
+func (rs *rules) match(remote string) (include, matched bool) {
+	for _, rule := range rs.rules {
+		if rule.Match(remote) {
+			return rule.Include, true
+		}
+	}
+	return false, false
+}
+
 // include returns whether this remote passes the filter rules.
 func (rs *rules) include(remote string) bool {
 	for _, rule := range rs.rules {
@@ -19,6 +19,7 @@ type Dialer struct {
 	net.Dialer
 	timeout time.Duration
 	tclass  int
+	remote  string // name of the remote for --bwlimit-remote
 }
 
 // NewDialer creates a Dialer structure with Timeout, Keepalive,
@@ -32,6 +33,7 @@ func NewDialer(ctx context.Context) *Dialer {
 		},
 		timeout: ci.Timeout,
 		tclass:  int(ci.TrafficClass),
+		remote:  fs.GetRemoteName(ctx),
 	}
 	if ci.BindAddr != nil {
 		dialer.Dialer.LocalAddr = &net.TCPAddr{IP: ci.BindAddr}
@@ -87,6 +89,7 @@ func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.
 	t := &timeoutConn{
 		Conn:    c,
 		timeout: d.timeout,
+		remote:  d.remote,
 	}
 	return t, t.nudgeDeadline()
 }
@@ -95,6 +98,7 @@ func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.
 type timeoutConn struct {
 	net.Conn
 	timeout time.Duration
+	remote  string
 }
 
 // Nudge the deadline for an idle timeout on by c.timeout if non-zero
@@ -110,6 +114,7 @@ func (c *timeoutConn) Read(b []byte) (n int, err error) {
 	// Ideally we would LimitBandwidth(len(b)) here and replace tokens we didn't use
 	n, err = c.Conn.Read(b)
 	accounting.TokenBucket.LimitBandwidth(accounting.TokenBucketSlotTransportRx, n)
+	accounting.TokenBucket.LimitRemoteBandwidth(c.remote, accounting.TokenBucketSlotTransportRx, n)
 	if err == nil && n > 0 && c.timeout > 0 {
 		err = c.nudgeDeadline()
 	}
@@ -119,6 +124,7 @@ func (c *timeoutConn) Read(b []byte) (n int, err error) {
 // Write bytes with rate limiting and idle timeouts
 func (c *timeoutConn) Write(b []byte) (n int, err error) {
 	accounting.TokenBucket.LimitBandwidth(accounting.TokenBucketSlotTransportTx, len(b))
+	accounting.TokenBucket.LimitRemoteBandwidth(c.remote, accounting.TokenBucketSlotTransportTx, len(b))
 	n, err = c.Conn.Write(b)
 	if err == nil && n > 0 && c.timeout > 0 {
 		err = c.nudgeDeadline()
@@ -17,7 +17,9 @@ import (
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/tracing"
 	"github.com/rclone/rclone/lib/structs"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"golang.org/x/net/publicsuffix"
 )
 
@@ -29,6 +31,9 @@ const (
 var (
 	transport    http.RoundTripper
 	noTransport  = new(sync.Once)
+	remoteMu     sync.Mutex
+	remotes      = map[string]http.RoundTripper{} // transports for remotes with their own limits
+	noRemoteName = new(sync.Once)                 // for logging clients made without a remote name
 	cookieJar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	logMutex     sync.Mutex
 )
@@ -37,6 +42,10 @@ var (
 // Should only be used for testing.
 func ResetTransport() {
 	noTransport = new(sync.Once)
+	remoteMu.Lock()
+	remotes = map[string]http.RoundTripper{}
+	noRemoteName = new(sync.Once)
+	remoteMu.Unlock()
 }
 
 // NewTransportCustom returns an http.RoundTripper with the correct timeouts.
@@ -114,13 +123,48 @@ func NewTransportCustom(ctx context.Context, customize func(*http.Transport)) ht
 	}
 
 	// Wrap that http.Transport in our own transport
-	return newTransport(ci, t)
+	return newTransport(ci, fs.GetRemoteName(ctx), t)
+}
+
+// hasRemoteLimits returns true if the remote called name has its own
+// bandwidth or transaction limits.
+func hasRemoteLimits(ci *fs.ConfigInfo, name string) bool {
+	_, bwLimited := ci.BwLimitRemote.Get(name)
+	_, tpsLimited := ci.TPSLimitRemote.Get(name)
+	return bwLimited || tpsLimited
 }
 
 // NewTransport returns an http.RoundTripper with the correct timeouts
+//
+// This is shared between all the remotes, except those with their
+// own limits set with --bwlimit-remote or --tpslimit-remote which
+// get a transport each. Remotes in different namespaces get
+// different transports so they don't share their limits.
+//
+// If ctx doesn't have a remote name set with fs.WithRemoteName then
+// the shared transport is returned and the limits of the remote it
+// is being used for won't apply to it.
 func NewTransport(ctx context.Context) http.RoundTripper {
+	ci := fs.GetConfig(ctx)
+	name := fs.GetRemoteName(ctx)
+	if name != "" && hasRemoteLimits(ci, name) {
+		remoteMu.Lock()
+		defer remoteMu.Unlock()
+		t, ok := remotes[name]
+		if !ok {
+			t = NewTransportCustom(ctx, nil)
+			remotes[name] = t
+		}
+		return t
+	}
+	if name == "" && (len(ci.BwLimitRemote) != 0 || len(ci.TPSLimitRemote) != 0) {
+		noRemoteName.Do(func() {
+			fs.Infof(nil, "Making an HTTP client without a remote name - --bwlimit-remote and --tpslimit-remote won't apply to it")
+		})
+	}
 	(*noTransport).Do(func() {
-		transport = NewTransportCustom(ctx, nil)
+		// The shared transport doesn't have the limits of any one remote
+		transport = NewTransportCustom(fs.WithRemoteName(ctx, ""), nil)
 	})
 	return transport
 }
@@ -148,17 +192,19 @@ type Transport struct {
 	userAgent     string
 	headers       []*fs.HTTPOption
 	metrics       *Metrics
+	remote        string // name of the remote for --tpslimit-remote
 }
 
 // newTransport wraps the http.Transport passed in and logs all
 // roundtrips including the body if logBody is set.
-func newTransport(ci *fs.ConfigInfo, transport *http.Transport) *Transport {
+func newTransport(ci *fs.ConfigInfo, remote string, transport *http.Transport) *Transport {
 	return &Transport{
 		Transport: transport,
 		dump:      ci.Dump,
 		userAgent: ci.UserAgent,
 		headers:   ci.Headers,
 		metrics:   DefaultMetrics,
+		remote:    remote,
 	}
 }
 
@@ -247,10 +293,39 @@ func cleanAuths(buf []byte) []byte {
 	return buf
 }
 
+// traceRequest starts a span for req returning the request with the
+// context of the span.
+func traceRequest(req *http.Request) (*http.Request, *tracing.Span) {
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = ""
+	ctx, span := tracing.StartSpan(req.Context(), "HTTP "+req.Method,
+		semconv.HTTPMethod(req.Method),
+		semconv.HTTPURL(u.String()),
+		semconv.NetPeerName(req.URL.Hostname()),
+	)
+	return req.WithContext(ctx), span
+}
+
 // RoundTrip implements the RoundTripper interface.
 func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	// Limit transactions per second if required
-	accounting.LimitTPS(req.Context())
+	if t.remote != "" {
+		accounting.LimitTPS(fs.WithRemoteName(req.Context(), t.remote))
+	} else {
+		accounting.LimitTPS(req.Context())
+	}
+	// Trace the request if required
+	if tracing.Enabled() {
+		var span *tracing.Span
+		req, span = traceRequest(req)
+		defer func() {
+			if resp != nil {
+				span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+			}
+			span.End(err)
+		}()
+	}
 	// Force user agent
 	req.Header.Set("User-Agent", t.userAgent)
 	// Set user defined headers
@@ -275,7 +350,9 @@ func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error
 		logMutex.Unlock()
 	}
 	// Do round trip
+	start := time.Now()
 	resp, err = t.Transport.RoundTrip(req)
+	duration := time.Since(start)
 	// Logf response
 	if t.dump&(fs.DumpHeaders|fs.DumpBodies|fs.DumpAuth|fs.DumpRequests|fs.DumpResponses) != 0 {
 		logMutex.Lock()
@@ -291,7 +368,7 @@ func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error
 		logMutex.Unlock()
 	}
 	// Update metrics
-	t.metrics.onResponse(req, resp)
+	t.metrics.onResponse(req, resp, duration)
 
 	if err == nil {
 		checkServerTime(req, resp)
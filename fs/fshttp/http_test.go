@@ -1,8 +1,10 @@
 package fshttp
 
 import (
+	"context"
 	"testing"
 
+	"github.com/rclone/rclone/fs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,3 +47,27 @@ func TestCleanAuths(t *testing.T) {
 		assert.Equal(t, test.want, got, test.in)
 	}
 }
+
+func TestNewTransportRemote(t *testing.T) {
+	ResetTransport()
+	defer ResetTransport()
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.TPSLimitRemote = fs.RemoteTPSLimits{"nas": 10}
+
+	// Remotes without limits share the transport
+	shared := NewTransport(ctx)
+	assert.Equal(t, "", shared.(*Transport).remote)
+	assert.True(t, shared == NewTransport(fs.WithRemoteName(ctx, "drive")))
+
+	// Remotes with limits get their own
+	nas := NewTransport(fs.WithRemoteName(ctx, "nas"))
+	assert.False(t, shared == nas)
+	assert.Equal(t, "nas", nas.(*Transport).remote)
+	assert.True(t, nas == NewTransport(fs.WithRemoteName(ctx, "nas{AbCdE}")))
+
+	// Namespaces don't share the transport of a remote
+	userNas := NewTransport(fs.WithRemoteName(ctx, "user"+fs.NamespaceSeparator+"nas"))
+	assert.False(t, shared == userNas)
+	assert.False(t, nas == userNas)
+	assert.Equal(t, "user"+fs.NamespaceSeparator+"nas", userNas.(*Transport).remote)
+}
@@ -33,7 +33,15 @@ func DirSorted(ctx context.Context, f fs.Fs, includeAll bool, dir string) (entri
 		fs.Debugf(dir, "Excluded")
 		return nil, nil
 	}
-	return filterAndSortDir(ctx, entries, includeAll, dir, fi.IncludeObject, fi.IncludeDirectory(ctx, f))
+	if includeAll {
+		return filterAndSortDir(ctx, entries, includeAll, dir, fi.IncludeObject, fi.IncludeDirectory(ctx, f))
+	}
+	// Merge in the rules from any filter files in the directory
+	dirFilter, err := fi.ForDir(ctx, f, dir, entries)
+	if err != nil {
+		return nil, err
+	}
+	return filterAndSortDir(ctx, entries, includeAll, dir, dirFilter.IncludeObject, dirFilter.IncludeDirectory(ctx, f))
 }
 
 // filter (if required) and check the entries, then sort them
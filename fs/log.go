@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -65,6 +68,58 @@ var LogPrint = func(level LogLevel, text string) {
 	_ = log.Output(4, text)
 }
 
+// LogHook is a function which is called with every message logged
+//
+// ctx is the context the message was logged with by one of the Ctx
+// logging functions such as InfofCtx, or context.Background() if it
+// was logged without one.
+//
+// text has the object the message is about prepended if there is
+// one.
+type LogHook func(ctx context.Context, level LogLevel, text string)
+
+var (
+	logHooksMu sync.RWMutex
+	logHooks   []*LogHook
+)
+
+// AddLogHook adds a function which will be called with every message
+// logged. It returns a function to remove the hook.
+//
+// Messages are only logged if they are at or above the --log-level so
+// that applies to the hooks too.
+func AddLogHook(hook LogHook) (remove func()) {
+	logHooksMu.Lock()
+	defer logHooksMu.Unlock()
+	logHooks = append(logHooks, &hook)
+	return func() {
+		logHooksMu.Lock()
+		defer logHooksMu.Unlock()
+		for i, h := range logHooks {
+			if h == &hook {
+				logHooks = append(logHooks[:i:i], logHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// callLogHooks calls any log hooks with the message
+func callLogHooks(ctx context.Context, level LogLevel, o interface{}, text string) {
+	logHooksMu.RLock()
+	hooks := logHooks
+	logHooksMu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	if o != nil {
+		text = fmt.Sprintf("%v: %s", o, text)
+	}
+	for _, hook := range hooks {
+		(*hook)(ctx, level, text)
+	}
+}
+
 // LogValueItem describes keyed item for a JSON log entry
 type LogValueItem struct {
 	key    string
@@ -104,9 +159,42 @@ func (j LogValueItem) String() string {
 	return fmt.Sprint(j.value)
 }
 
+// LogExtraFieldsFn returns any extra key, value pairs to add to a
+// message logged with ctx, for example trace IDs.
+type LogExtraFieldsFn func(ctx context.Context) []string
+
+// logExtraFields is set with SetLogExtraFields
+var logExtraFields atomic.Pointer[LogExtraFieldsFn]
+
+// SetLogExtraFields sets fn to be called for each message logged to
+// return any extra fields to add to it. Set fn to nil to remove it.
+//
+// This is safe to call while messages are being logged.
+func SetLogExtraFields(fn LogExtraFieldsFn) {
+	if fn == nil {
+		logExtraFields.Store(nil)
+		return
+	}
+	logExtraFields.Store(&fn)
+}
+
 // LogPrintf produces a log string from the arguments passed in
 func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
+	LogPrintfCtx(context.Background(), level, o, text, args...)
+}
+
+// LogPrintfCtx produces a log string from the arguments passed in
+//
+// ctx is passed to the log hooks so they can tell which operation
+// the message came from.
+func LogPrintfCtx(ctx context.Context, level LogLevel, o interface{}, text string, args ...interface{}) {
 	out := fmt.Sprintf(text, args...)
+	callLogHooks(ctx, level, o, out)
+
+	var extra []string
+	if fn := logExtraFields.Load(); fn != nil {
+		extra = (*fn)(ctx)
+	}
 
 	if GetConfig(context.TODO()).UseJSONLog {
 		fields := logrus.Fields{}
@@ -116,6 +204,9 @@ func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{})
 				"objectType": fmt.Sprintf("%T", o),
 			}
 		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			fields[extra[i]] = extra[i+1]
+		}
 		for _, arg := range args {
 			if item, ok := arg.(LogValueItem); ok {
 				fields[item.key] = item.value
@@ -139,6 +230,12 @@ func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{})
 		if o != nil {
 			out = fmt.Sprintf("%v: %s", o, out)
 		}
+		if len(extra) > 0 {
+			out = strings.TrimRight(out, "\n")
+			for i := 0; i+1 < len(extra); i += 2 {
+				out += " " + extra[i] + "=" + extra[i+1]
+			}
+		}
 		LogPrint(level, out)
 	}
 }
@@ -146,7 +243,7 @@ func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{})
 // LogLevelPrintf writes logs at the given level
 func LogLevelPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
 	if GetConfig(context.TODO()).LogLevel >= level {
-		LogPrintf(level, o, text, args...)
+		LogPrintfCtx(context.Background(), level, o, text, args...)
 	}
 }
 
@@ -154,7 +251,7 @@ func LogLevelPrintf(level LogLevel, o interface{}, text string, args ...interfac
 // should always be seen by the user.
 func Errorf(o interface{}, text string, args ...interface{}) {
 	if GetConfig(context.TODO()).LogLevel >= LogLevelError {
-		LogPrintf(LogLevelError, o, text, args...)
+		LogPrintfCtx(context.Background(), LogLevelError, o, text, args...)
 	}
 }
 
@@ -165,7 +262,7 @@ func Errorf(o interface{}, text string, args ...interface{}) {
 // out with the -q flag.
 func Logf(o interface{}, text string, args ...interface{}) {
 	if GetConfig(context.TODO()).LogLevel >= LogLevelNotice {
-		LogPrintf(LogLevelNotice, o, text, args...)
+		LogPrintfCtx(context.Background(), LogLevelNotice, o, text, args...)
 	}
 }
 
@@ -174,7 +271,7 @@ func Logf(o interface{}, text string, args ...interface{}) {
 // appear with the -v flag.
 func Infof(o interface{}, text string, args ...interface{}) {
 	if GetConfig(context.TODO()).LogLevel >= LogLevelInfo {
-		LogPrintf(LogLevelInfo, o, text, args...)
+		LogPrintfCtx(context.Background(), LogLevelInfo, o, text, args...)
 	}
 }
 
@@ -182,7 +279,39 @@ func Infof(o interface{}, text string, args ...interface{}) {
 // debug only.  The user must have to specify -vv to see this.
 func Debugf(o interface{}, text string, args ...interface{}) {
 	if GetConfig(context.TODO()).LogLevel >= LogLevelDebug {
-		LogPrintf(LogLevelDebug, o, text, args...)
+		LogPrintfCtx(context.Background(), LogLevelDebug, o, text, args...)
+	}
+}
+
+// ErrorfCtx is like Errorf but uses the log level in the config of ctx
+// and passes ctx to the log hooks
+func ErrorfCtx(ctx context.Context, o interface{}, text string, args ...interface{}) {
+	if GetConfig(ctx).LogLevel >= LogLevelError {
+		LogPrintfCtx(ctx, LogLevelError, o, text, args...)
+	}
+}
+
+// LogfCtx is like Logf but uses the log level in the config of ctx
+// and passes ctx to the log hooks
+func LogfCtx(ctx context.Context, o interface{}, text string, args ...interface{}) {
+	if GetConfig(ctx).LogLevel >= LogLevelNotice {
+		LogPrintfCtx(ctx, LogLevelNotice, o, text, args...)
+	}
+}
+
+// InfofCtx is like Infof but uses the log level in the config of ctx
+// and passes ctx to the log hooks
+func InfofCtx(ctx context.Context, o interface{}, text string, args ...interface{}) {
+	if GetConfig(ctx).LogLevel >= LogLevelInfo {
+		LogPrintfCtx(ctx, LogLevelInfo, o, text, args...)
+	}
+}
+
+// DebugfCtx is like Debugf but uses the log level in the config of ctx
+// and passes ctx to the log hooks
+func DebugfCtx(ctx context.Context, o interface{}, text string, args ...interface{}) {
+	if GetConfig(ctx).LogLevel >= LogLevelDebug {
+		LogPrintfCtx(ctx, LogLevelDebug, o, text, args...)
 	}
 }
 
@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -93,3 +94,25 @@ func TestLogLevelUnmarshalJSON(t *testing.T) {
 		assert.Equal(t, test.want, logLevel, test.in)
 	}
 }
+
+func TestLogCtxLevel(t *testing.T) {
+	var got []string
+	remove := AddLogHook(func(ctx context.Context, level LogLevel, text string) {
+		got = append(got, text)
+	})
+	defer remove()
+
+	// The global log level hides INFO messages
+	ci := GetConfig(context.Background())
+	oldLogLevel := ci.LogLevel
+	ci.LogLevel = LogLevelNotice
+	defer func() { ci.LogLevel = oldLogLevel }()
+	InfofCtx(context.Background(), nil, "hidden")
+
+	// But the log level in the config of ctx shows them
+	ctx, ctxCi := AddConfig(context.Background())
+	ctxCi.LogLevel = LogLevelInfo
+	InfofCtx(ctx, nil, "shown")
+	DebugfCtx(ctx, nil, "hidden")
+	assert.Equal(t, []string{"shown"}, got)
+}
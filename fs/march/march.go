@@ -13,7 +13,9 @@ import (
 	"github.com/rclone/rclone/fs/dirtree"
 	"github.com/rclone/rclone/fs/filter"
 	"github.com/rclone/rclone/fs/list"
+	"github.com/rclone/rclone/fs/tracing"
 	"github.com/rclone/rclone/fs/walk"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/text/unicode/norm"
 )
 
@@ -52,6 +54,10 @@ type Marcher interface {
 // Note: this will flag filter-aware backends on the source side
 func (m *March) init(ctx context.Context) {
 	ci := fs.GetConfig(ctx)
+	// Filter the source and destination with the filter files of the source
+	if filter.GetConfig(ctx).UsesFilterFiles() {
+		m.Ctx = filter.WithDirFilters(m.Ctx, m.Fsrc)
+	}
 	m.srcListDir = m.makeListDir(ctx, m.Fsrc, m.SrcIncludeAll)
 	if !m.NoTraverse {
 		m.dstListDir = m.makeListDir(ctx, m.Fdst, m.DstIncludeAll)
@@ -83,11 +89,18 @@ type listDirFn func(dir string) (entries fs.DirEntries, err error)
 func (m *March) makeListDir(ctx context.Context, f fs.Fs, includeAll bool) listDirFn {
 	ci := fs.GetConfig(ctx)
 	fi := filter.GetConfig(ctx)
-	if !(ci.UseListR && f.Features().ListR != nil) && // !--fast-list active and
+	if !(ci.UseListR && f.Features().ListR != nil && !fi.UsesFilterFiles()) && // !--fast-list active (which can't be used with --filter-file-name) and
 		!(ci.NoTraverse && fi.HaveFilesFrom()) { // !(--files-from and --no-traverse)
 		return func(dir string) (entries fs.DirEntries, err error) {
 			dirCtx := filter.SetUseFilter(m.Ctx, f.Features().FilterAware && !includeAll) // make filter-aware backends constrain List
-			return list.DirSorted(dirCtx, f, includeAll, dir)
+			dirCtx, span := tracing.StartSpan(dirCtx, "march.List",
+				attribute.String("fs", fs.ConfigString(f)),
+				attribute.String("dir", dir),
+			)
+			entries, err = list.DirSorted(dirCtx, f, includeAll, dir)
+			span.SetAttributes(attribute.Int("entries", len(entries)))
+			span.End(err)
+			return entries, err
 		}
 	}
 
@@ -104,7 +117,12 @@ func (m *March) makeListDir(ctx context.Context, f fs.Fs, includeAll bool) listD
 		defer mu.Unlock()
 		if !started {
 			dirCtx := filter.SetUseFilter(m.Ctx, f.Features().FilterAware && !includeAll) // make filter-aware backends constrain List
+			dirCtx, span := tracing.StartSpan(dirCtx, "march.ListR",
+				attribute.String("fs", fs.ConfigString(f)),
+				attribute.String("dir", m.Dir),
+			)
 			dirs, dirsErr = walk.NewDirTree(dirCtx, f, m.Dir, includeAll, ci.MaxDepth)
+			span.End(dirsErr)
 			started = true
 		}
 		if dirsErr != nil {
@@ -0,0 +1,165 @@
+// Config namespaces
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rclone/rclone/fs/fspath"
+)
+
+// NamespaceSeparator separates the namespace from the name in the
+// config section names and stats group names of a namespace.
+//
+// So the remote "remote" in the namespace "user" is stored in the
+// config file under the section "user@@remote".
+const NamespaceSeparator = "@@"
+
+type namespaceContextKeyType struct{}
+
+// Context key for the namespace
+var namespaceContextKey = namespaceContextKeyType{}
+
+type namespaceLocalContextKeyType struct{}
+
+// Context key set if local paths may be used in the namespace
+var namespaceLocalContextKey = namespaceLocalContextKeyType{}
+
+// ErrorNamespaceLocal is returned when a local path, the local backend
+// or an on the fly backend is used in a namespace which doesn't allow
+// them.
+var ErrorNamespaceLocal = errors.New("local paths and on the fly backends can't be used in a namespace")
+
+// WithNamespace returns a copy of ctx with the namespace set.
+//
+// Remotes, Fs created with NewFs and stats groups used with this
+// context are isolated from those of other namespaces. This is used
+// to give each user of "rclone rcd --rc-multi-user" their own config.
+//
+// Local paths, remotes using the local backend and on the fly backends
+// such as ":s3:" can't be used in the namespace unless allowed with
+// WithNamespaceLocal as they give access to the files and credentials
+// of the host.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	if namespace == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, namespaceContextKey, namespace)
+}
+
+// GetNamespace returns the namespace set in ctx or "" if none
+func GetNamespace(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	namespace, _ := ctx.Value(namespaceContextKey).(string)
+	return namespace
+}
+
+// WithNamespaceLocal returns a copy of ctx which allows local paths,
+// the local backend and on the fly backends to be used in the
+// namespace set with WithNamespace.
+func WithNamespaceLocal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, namespaceLocalContextKey, true)
+}
+
+// namespaceLocal returns true if ctx has no namespace or if local
+// paths may be used in its namespace.
+func namespaceLocal(ctx context.Context) bool {
+	if GetNamespace(ctx) == "" {
+		return true
+	}
+	allowed, _ := ctx.Value(namespaceLocalContextKey).(bool)
+	return allowed
+}
+
+// CopyNamespace copies the namespace (if any) from srcCtx into dstCtx
+// returning the new context.
+func CopyNamespace(dstCtx, srcCtx context.Context) context.Context {
+	dstCtx = WithNamespace(dstCtx, GetNamespace(srcCtx))
+	if GetNamespace(srcCtx) != "" && namespaceLocal(srcCtx) {
+		dstCtx = WithNamespaceLocal(dstCtx)
+	}
+	return dstCtx
+}
+
+// CheckNamespace returns an error if namespace can't be used as a
+// namespace. It must be a valid remote name and not contain "@" as
+// remote names may start with "@" which would make
+// namespace+NamespaceSeparator+name ambiguous.
+func CheckNamespace(namespace string) error {
+	if strings.Contains(namespace, "@") {
+		return fmt.Errorf("namespace %q must not contain %q", namespace, "@")
+	}
+	return fspath.CheckConfigName(namespace)
+}
+
+// CheckNamespaceBackend returns ErrorNamespaceLocal if the backend
+// called fsName can't be used in the namespace in ctx.
+func CheckNamespaceBackend(ctx context.Context, fsName string) error {
+	if fsName == "local" && !namespaceLocal(ctx) {
+		return ErrorNamespaceLocal
+	}
+	return nil
+}
+
+// InNamespace returns name qualified with the namespace in ctx.
+//
+// If there is no namespace or name is already qualified it is
+// returned unchanged.
+func InNamespace(ctx context.Context, name string) string {
+	namespace := GetNamespace(ctx)
+	if namespace == "" {
+		return name
+	}
+	prefix := namespace + NamespaceSeparator
+	if strings.HasPrefix(name, prefix) {
+		return name
+	}
+	return prefix + name
+}
+
+// FromNamespace returns name with the namespace in ctx removed and
+// true if name is in that namespace, or false if it isn't.
+//
+// If there is no namespace it returns name unchanged and true.
+func FromNamespace(ctx context.Context, name string) (string, bool) {
+	namespace := GetNamespace(ctx)
+	if namespace == "" {
+		return name, true
+	}
+	prefix := namespace + NamespaceSeparator
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	return name[len(prefix):], true
+}
+
+// NamespacePath returns the remote path, as passed to NewFs, with its
+// remote name qualified with the namespace in ctx.
+//
+// Local paths and on the fly backends such as ":s3:bucket" are
+// returned unchanged if allowed with WithNamespaceLocal, otherwise
+// ErrorNamespaceLocal is returned.
+func NamespacePath(ctx context.Context, path string) (string, error) {
+	if GetNamespace(ctx) == "" {
+		return path, nil
+	}
+	parsed, err := fspath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Name == "" || strings.HasPrefix(parsed.Name, ":") {
+		if !namespaceLocal(ctx) {
+			return "", fmt.Errorf("%q: %w", path, ErrorNamespaceLocal)
+		}
+		return path, nil
+	}
+	if !strings.HasPrefix(path, parsed.Name) {
+		return "", fmt.Errorf("can't find remote name in %q", path)
+	}
+	return InNamespace(ctx, parsed.Name) + path[len(parsed.Name):], nil
+}
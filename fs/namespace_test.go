@@ -0,0 +1,96 @@
+package fs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", GetNamespace(ctx))
+	assert.Equal(t, ctx, WithNamespace(ctx, ""))
+
+	nsCtx := WithNamespace(ctx, "user")
+	assert.Equal(t, "user", GetNamespace(nsCtx))
+	assert.Equal(t, "user", GetNamespace(CopyNamespace(ctx, nsCtx)))
+	assert.Equal(t, "", GetNamespace(CopyNamespace(ctx, ctx)))
+
+	assert.Equal(t, "remote", InNamespace(ctx, "remote"))
+	assert.Equal(t, "user@@remote", InNamespace(nsCtx, "remote"))
+	assert.Equal(t, "user@@remote", InNamespace(nsCtx, "user@@remote"))
+
+	name, ok := FromNamespace(ctx, "other@@remote")
+	assert.True(t, ok)
+	assert.Equal(t, "other@@remote", name)
+	name, ok = FromNamespace(nsCtx, "user@@remote")
+	assert.True(t, ok)
+	assert.Equal(t, "remote", name)
+	_, ok = FromNamespace(nsCtx, "other@@remote")
+	assert.False(t, ok)
+	_, ok = FromNamespace(nsCtx, "remote")
+	assert.False(t, ok)
+}
+
+func TestCheckNamespace(t *testing.T) {
+	assert.NoError(t, CheckNamespace("user"))
+	assert.NoError(t, CheckNamespace("user.name"))
+	assert.Error(t, CheckNamespace(""))
+	assert.Error(t, CheckNamespace("user@@name"))
+	assert.Error(t, CheckNamespace("user@"))
+	assert.Error(t, CheckNamespace("user@example.com"))
+	assert.Error(t, CheckNamespace("user/name"))
+}
+
+func TestNamespacePath(t *testing.T) {
+	ctx := context.Background()
+	nsCtx := WithNamespace(ctx, "user")
+	localCtx := WithNamespaceLocal(nsCtx)
+	for _, test := range []struct {
+		in    string
+		want  string
+		local bool
+	}{
+		{"remote:", "user@@remote:", false},
+		{"remote:path/to/dir", "user@@remote:path/to/dir", false},
+		{"remote,option=true:path", "user@@remote,option=true:path", false},
+		{"user@@remote:path", "user@@remote:path", false},
+		{"@remote:path", "user@@@remote:path", false},
+		{":s3:bucket", ":s3:bucket", true},
+		{":s3,env_auth=true:bucket", ":s3,env_auth=true:bucket", true},
+		{"/local/path", "/local/path", true},
+		{"relative/path", "relative/path", true},
+	} {
+		got, err := NamespacePath(ctx, test.in)
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.in, got, test.in)
+
+		got, err = NamespacePath(localCtx, test.in)
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+
+		got, err = NamespacePath(nsCtx, test.in)
+		if test.local {
+			assert.ErrorIs(t, err, ErrorNamespaceLocal, test.in)
+		} else {
+			assert.NoError(t, err, test.in)
+			assert.Equal(t, test.want, got, test.in)
+		}
+	}
+}
+
+func TestNamespaceLocal(t *testing.T) {
+	ctx := context.Background()
+	nsCtx := WithNamespace(ctx, "user")
+	localCtx := WithNamespaceLocal(nsCtx)
+
+	assert.NoError(t, CheckNamespaceBackend(ctx, "local"))
+	assert.ErrorIs(t, CheckNamespaceBackend(nsCtx, "local"), ErrorNamespaceLocal)
+	assert.NoError(t, CheckNamespaceBackend(nsCtx, "s3"))
+	assert.NoError(t, CheckNamespaceBackend(localCtx, "local"))
+
+	// CopyNamespace keeps whether local paths are allowed
+	assert.ErrorIs(t, CheckNamespaceBackend(CopyNamespace(ctx, nsCtx), "local"), ErrorNamespaceLocal)
+	assert.NoError(t, CheckNamespaceBackend(CopyNamespace(ctx, localCtx), "local"))
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/base64"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,7 +31,14 @@ var (
 //
 // On Windows avoid single character remote names as they can be mixed
 // up with drive letters.
+//
+// If ctx has a namespace (see WithNamespace) then the remote is
+// looked up in that namespace.
 func NewFs(ctx context.Context, path string) (Fs, error) {
+	path, err := NamespacePath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
 	Debugf(nil, "Creating backend with remote %q", path)
 	if ConfigFileHasSection(path) {
 		Logf(nil, "%q refers to a local folder, use %q to refer to your remote or %q to hide this warning", path, path+":", "./"+path)
@@ -39,6 +47,14 @@ func NewFs(ctx context.Context, path string) (Fs, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = ConfigFileCheck(configName)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", path, err)
+	}
+	err = CheckNamespaceBackend(ctx, fsInfo.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", path, err)
+	}
 	overridden := fsInfo.Options.Overridden(config)
 	if len(overridden) > 0 {
 		extraConfig := overridden.String()
@@ -64,6 +80,8 @@ func NewFs(ctx context.Context, path string) (Fs, error) {
 		overriddenConfig[suffix] = extraConfig
 		overriddenConfigMu.Unlock()
 	}
+	// Let the backend find the limits set for this remote
+	ctx = WithRemoteName(ctx, configName)
 	f, err := fsInfo.NewFs(ctx, configName, fsPath, config)
 	if f != nil && (err == nil || err == ErrorIsFile) {
 		addReverse(f, fsInfo)
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -43,6 +44,8 @@ type CheckOpt struct {
 	Match        io.Writer // matching files
 	Differ       io.Writer // differing files
 	Error        io.Writer // files with errors of some kind
+	JSON         bool      // write a JSON Result for each file rather than its name
+	differReason string    // Reason to give when Check finds files differ
 }
 
 // checkMarch is used to march over two Fses in the same way as
@@ -59,12 +62,56 @@ type checkMarch struct {
 	opt             CheckOpt
 }
 
+// sigilAction maps the sigils in the combined log to Result actions
+var sigilAction = map[rune]ResultAction{
+	'=': ResultMatch,
+	'-': ResultMissingOnSrc,
+	'+': ResultMissingOnDst,
+	'*': ResultDiffer,
+	'!': ResultError,
+}
+
 // report outputs the fileName to out if required and to the combined log
 func (c *checkMarch) report(o fs.DirEntry, out io.Writer, sigil rune) {
-	c.reportFilename(o.String(), out, sigil)
+	var result *Result
+	if c.opt.JSON {
+		obj, _ := o.(fs.ObjectInfo)
+		if sigil == '-' {
+			result = NewResult(o.String(), nil, obj)
+		} else {
+			result = NewResult(o.String(), obj, nil)
+		}
+		result.Done(sigilAction[sigil], "", nil)
+	}
+	c.reportResult(result, o.String(), out, sigil)
 }
 
 func (c *checkMarch) reportFilename(filename string, out io.Writer, sigil rune) {
+	var result *Result
+	if c.opt.JSON {
+		result = NewResult(filename, nil, nil)
+		result.Done(sigilAction[sigil], "", nil)
+	}
+	c.reportResult(result, filename, out, sigil)
+}
+
+// reportResult outputs the fileName, or the result if outputting
+// JSON, to out if required and to the combined log
+func (c *checkMarch) reportResult(result *Result, filename string, out io.Writer, sigil rune) {
+	if c.opt.JSON {
+		data, err := json.Marshal(result)
+		if err != nil {
+			fs.Errorf(filename, "Failed to marshal result: %v", err)
+			return
+		}
+		if out != nil {
+			syncFprintf(out, "%s\n", data)
+		}
+		if c.opt.Combined != nil && c.opt.Combined != out {
+			syncFprintf(c.opt.Combined, "%s\n", data)
+		}
+		return
+	}
 	if out != nil {
 		syncFprintf(out, "%s\n", filename)
 	}
@@ -151,20 +198,43 @@ func (c *checkMarch) Match(ctx context.Context, dst, src fs.DirEntry) (recurse b
 					<-c.tokens // get the token back to free up a slot
 					c.wg.Done()
 				}()
+				var result *Result
+				if c.opt.JSON {
+					result = NewResult(src.String(), srcX, dstX)
+				}
 				differ, noHash, err := c.checkIdentical(ctx, dstX, srcX)
+				if result != nil && err == nil && c.opt.differReason != ReasonContents {
+					result.SetHashes(ctx, srcX, dstX)
+				}
 				if err != nil {
 					fs.Errorf(src, "%v", err)
 					_ = fs.CountError(err)
-					c.report(src, c.opt.Error, '!')
+					if result != nil {
+						result.Done(ResultError, "", err)
+					}
+					c.reportResult(result, src.String(), c.opt.Error, '!')
 				} else if differ {
 					c.differences.Add(1)
 					err := errors.New("files differ")
 					// the checkFn has already logged the reason
 					_ = fs.CountError(err)
-					c.report(src, c.opt.Differ, '*')
+					if result != nil {
+						reason := c.opt.differReason
+						if reason == "" {
+							reason = ReasonHash
+						}
+						if sizeDiffers(ctx, srcX, dstX) {
+							reason = ReasonSize
+						}
+						result.Done(ResultDiffer, reason, nil)
+					}
+					c.reportResult(result, src.String(), c.opt.Differ, '*')
 				} else {
 					c.matches.Add(1)
-					c.report(src, c.opt.Match, '=')
+					if result != nil {
+						result.Done(ResultMatch, "", nil)
+					}
+					c.reportResult(result, src.String(), c.opt.Match, '=')
 					if noHash {
 						c.noHashes.Add(1)
 						fs.Debugf(dstX, "OK - could not check hash")
@@ -271,6 +341,7 @@ func (c *checkMarch) reportResults(ctx context.Context, err error) error {
 // Check the files in fsrc and fdst according to Size and hash
 func Check(ctx context.Context, opt *CheckOpt) error {
 	optCopy := *opt
+	optCopy.differReason = ReasonHash
 	optCopy.Check = func(ctx context.Context, dst, src fs.Object) (differ bool, noHash bool, err error) {
 		same, ht, err := CheckHashes(ctx, src, dst)
 		if err != nil {
@@ -365,6 +436,7 @@ func checkIdenticalDownload(ctx context.Context, dst, src fs.Object) (differ boo
 // and the actual contents of the files.
 func CheckDownload(ctx context.Context, opt *CheckOpt) error {
 	optCopy := *opt
+	optCopy.differReason = ReasonContents
 	optCopy.Check = func(ctx context.Context, a, b fs.Object) (differ bool, noHash bool, err error) {
 		differ, err = CheckIdenticalDownload(ctx, a, b)
 		if err != nil {
@@ -503,26 +575,42 @@ func (c *checkMarch) checkSum(ctx context.Context, obj fs.Object, download bool,
 
 // matchSum sums up the results of hashsum matching for an object
 func (c *checkMarch) matchSum(ctx context.Context, sumHash, objHash string, obj fs.Object, err error, hashType hash.Type) {
+	var result *Result
+	if c.opt.JSON {
+		result = NewResult(obj.String(), nil, obj)
+		result.HashType = hashType.String()
+		result.SrcHash = sumHash
+		result.DstHash = objHash
+	}
+	done := func(action ResultAction, reason string, err error) {
+		if result != nil {
+			result.Done(action, reason, err)
+		}
+	}
 	switch {
 	case err != nil:
 		_ = fs.CountError(err)
 		fs.Errorf(obj, "Failed to calculate hash: %v", err)
-		c.report(obj, c.opt.Error, '!')
+		done(ResultError, "", err)
+		c.reportResult(result, obj.String(), c.opt.Error, '!')
 	case sumHash == "":
 		err = errors.New("duplicate file")
 		_ = fs.CountError(err)
 		fs.Errorf(obj, "%v", err)
-		c.report(obj, c.opt.Error, '!')
+		done(ResultError, "", err)
+		c.reportResult(result, obj.String(), c.opt.Error, '!')
 	case objHash == "":
 		fs.Debugf(nil, "%v = %s (sum)", hashType, sumHash)
 		fs.Debugf(obj, "%v - could not check hash (%v)", hashType, c.opt.Fdst)
 		c.noHashes.Add(1)
 		c.matches.Add(1)
-		c.report(obj, c.opt.Match, '=')
+		done(ResultMatch, "", nil)
+		c.reportResult(result, obj.String(), c.opt.Match, '=')
 	case objHash == sumHash:
 		fs.Debugf(obj, "%v = %s OK", hashType, sumHash)
 		c.matches.Add(1)
-		c.report(obj, c.opt.Match, '=')
+		done(ResultMatch, "", nil)
+		c.reportResult(result, obj.String(), c.opt.Match, '=')
 	default:
 		err = errors.New("files differ")
 		_ = fs.CountError(err)
@@ -530,7 +618,8 @@ func (c *checkMarch) matchSum(ctx context.Context, sumHash, objHash string, obj
 		fs.Debugf(obj, "%v = %s (%v)", hashType, objHash, c.opt.Fdst)
 		fs.Errorf(obj, "%v", err)
 		c.differences.Add(1)
-		c.report(obj, c.opt.Differ, '*')
+		done(ResultDiffer, ReasonHash, nil)
+		c.reportResult(result, obj.String(), c.opt.Differ, '*')
 	}
 }
 
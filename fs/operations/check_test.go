@@ -3,6 +3,7 @@ package operations_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -214,6 +215,57 @@ func TestCheckSizeOnly(t *testing.T) {
 	TestCheck(t)
 }
 
+func TestCheckJSON(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	r.WriteBoth(ctx, "match", "identical", t1)
+	r.WriteFile("differ", "source version", t1)
+	r.WriteObject(ctx, "differ", "destination", t1)
+	r.WriteFile("srconly", "only in source", t1)
+	r.WriteObject(ctx, "dstonly", "only in destination", t1)
+
+	var combined, differ bytes.Buffer
+	opt := operations.CheckOpt{
+		Fdst:     r.Fremote,
+		Fsrc:     r.Flocal,
+		Combined: &combined,
+		Differ:   &differ,
+		JSON:     true,
+	}
+	err := operations.Check(ctx, &opt)
+	require.Error(t, err)
+
+	results := map[string]operations.Result{}
+	for _, line := range strings.Split(strings.TrimSpace(combined.String()), "\n") {
+		var result operations.Result
+		require.NoError(t, json.Unmarshal([]byte(line), &result), line)
+		results[result.Path] = result
+	}
+	require.Equal(t, 4, len(results))
+
+	assert.Equal(t, operations.ResultMatch, results["match"].Action)
+	require.NotNil(t, results["match"].SrcSize)
+	assert.Equal(t, int64(9), *results["match"].SrcSize)
+	require.NotNil(t, results["match"].DstSize)
+	assert.Equal(t, int64(9), *results["match"].DstSize)
+
+	assert.Equal(t, operations.ResultDiffer, results["differ"].Action)
+	assert.Equal(t, operations.ReasonSize, results["differ"].Reason)
+
+	assert.Equal(t, operations.ResultMissingOnDst, results["srconly"].Action)
+	assert.NotNil(t, results["srconly"].SrcSize)
+	assert.Nil(t, results["srconly"].DstSize)
+
+	assert.Equal(t, operations.ResultMissingOnSrc, results["dstonly"].Action)
+	assert.Nil(t, results["dstonly"].SrcSize)
+	assert.NotNil(t, results["dstonly"].DstSize)
+
+	// The differ report should just have the one record
+	var result operations.Result
+	require.NoError(t, json.Unmarshal(differ.Bytes(), &result))
+	assert.Equal(t, "differ", result.Path)
+}
+
 func TestCheckEqualReaders(t *testing.T) {
 	b65a := make([]byte, 65*1024)
 	b65b := make([]byte, 65*1024)
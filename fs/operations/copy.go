@@ -18,9 +18,11 @@ import (
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/tracing"
 	"github.com/rclone/rclone/lib/atexit"
 	"github.com/rclone/rclone/lib/pacer"
 	"github.com/rclone/rclone/lib/random"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // State of the copy
@@ -38,6 +40,8 @@ type copy struct {
 	tr            *accounting.Transfer // accounting for the transfer
 	inplace       bool                 // set if we are updating inplace and not using a partial name
 	remoteForCopy string               // the name used for the transfer, either remote or remote+".partial"
+	verifyHash    hash.Type            // hash to use for --verify
+	srcHasher     *hash.MultiHasher    // hash of the source made while copying for --verify, may be nil
 }
 
 // Used to remove a failed copy
@@ -254,6 +258,7 @@ func (c *copy) manualCopy(ctx context.Context) (actionTaken string, newDst fs.Ob
 	if err != nil {
 		return actionTaken, nil, fmt.Errorf("failed to open source object: %w", err)
 	}
+	in = c.hashWhileReading(in)
 
 	// Note that c.rcat and c.updateOrPut close in
 	if c.src.Size() == -1 {
@@ -279,6 +284,30 @@ func (c *copy) verify(ctx context.Context, newDst fs.Object) (err error) {
 	return nil
 }
 
+// Verify the copy by reading it back for --verify
+//
+// This updates the verified and unverified stats unless the
+// verification failed, in which case it returns an error wrapping
+// errVerifyFailed and the copy should be retried.
+func (c *copy) verifyCopy(ctx context.Context, newDst fs.Object) error {
+	if newDst == nil {
+		fs.Logf(c.src, "Can't verify copy as the backend didn't return the new object")
+		accounting.Stats(ctx).Unverified(1)
+		return nil
+	}
+	err := c.verifyReadBack(ctx, newDst)
+	if errors.Is(err, errVerifyFailed) {
+		return err
+	}
+	if err != nil {
+		fs.ErrorfCtx(ctx, newDst, "Can't verify copy: %v", err)
+		accounting.Stats(ctx).Unverified(1)
+		return nil
+	}
+	accounting.Stats(ctx).Verified(1)
+	return nil
+}
+
 // copy src object to dst or f if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
@@ -295,6 +324,7 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 		}
 
 		// Try server side copy
+		c.srcHasher = nil
 		actionTaken, newDst, err = c.serverSideCopy(ctx)
 
 		// If can't server-side copy, do it manually
@@ -307,6 +337,22 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 			break
 		}
 
+		// Read back the copy to check it if required
+		if err == nil && c.ci.Verify {
+			err = c.verifyCopy(ctx, newDst)
+			if errors.Is(err, errVerifyFailed) {
+				fs.ErrorfCtx(ctx, c.src, "%v - retrying", err)
+				c.removeFailedCopy(ctx, newDst)
+				newDst = nil
+				if c.dst != nil && c.inplace {
+					// the file being updated has gone
+					c.dst = nil
+					c.doUpdate = false
+				}
+				err = fserrors.RetryError(err)
+			}
+		}
+
 		// Retry if err returned a retry error
 		retry = false
 		if fserrors.IsRetryError(err) || fserrors.ShouldRetry(err) {
@@ -323,8 +369,11 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 		}
 	}
 	if err != nil {
+		if c.ci.Verify && errors.Is(err, errVerifyFailed) {
+			accounting.Stats(ctx).Unverified(1)
+		}
 		err = fs.CountError(err)
-		fs.Errorf(c.src, "Failed to copy: %v", err)
+		fs.ErrorfCtx(ctx, c.src, "Failed to copy: %v", err)
 		if !c.inplace {
 			c.removeFailedPartialCopy(ctx, c.f, c.remoteForCopy)
 		}
@@ -334,7 +383,7 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 	// Verify the copy
 	err = c.verify(ctx, newDst)
 	if err != nil {
-		fs.Errorf(newDst, "%v", err)
+		fs.ErrorfCtx(ctx, newDst, "%v", err)
 		err = fs.CountError(err)
 		c.removeFailedCopy(ctx, newDst)
 		return nil, err
@@ -344,7 +393,7 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 	if !c.inplace && c.remoteForCopy != c.remote {
 		movedNewDst, err := c.dstFeatures.Move(ctx, newDst, c.remote)
 		if err != nil {
-			fs.Errorf(newDst, "partial file rename failed: %v", err)
+			fs.ErrorfCtx(ctx, newDst, "partial file rename failed: %v", err)
 			err = fs.CountError(err)
 			c.removeFailedCopy(ctx, newDst)
 			return nil, err
@@ -357,7 +406,7 @@ func (c *copy) copy(ctx context.Context) (newDst fs.Object, err error) {
 	if newDst != nil && c.src.String() != newDst.String() {
 		actionTaken = fmt.Sprintf("%s to: %s", actionTaken, newDst.String())
 	}
-	fs.Infof(c.src, "%s%s", actionTaken, fs.LogValueHide("size", fs.SizeSuffix(c.src.Size())))
+	fs.InfofCtx(ctx, c.src, "%s%s", actionTaken, fs.LogValueHide("size", fs.SizeSuffix(c.src.Size())))
 
 	return newDst, nil
 }
@@ -373,6 +422,14 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 	defer func() {
 		tr.Done(ctx, err)
 	}()
+	ctx, span := tracing.StartSpan(ctx, "operations.Copy",
+		attribute.String("remote", remote),
+		attribute.Int64("size", src.Size()),
+		attribute.String("dst", fs.ConfigString(f)),
+	)
+	defer func() {
+		span.End(err)
+	}()
 	if SkipDestructive(ctx, src, "copy") {
 		in := tr.Account(ctx, nil)
 		in.DryRun(src.Size())
@@ -390,6 +447,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 		doUpdate:    dst != nil,
 	}
 	c.hashType, c.hashOption = CommonHash(ctx, f, src.Fs())
+	c.verifyHash = verifyHashType(f, src.Fs())
 	if c.dst != nil {
 		c.remote = c.dst.Remote()
 	}
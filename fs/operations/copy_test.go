@@ -121,6 +121,37 @@ func TestCopyFile(t *testing.T) {
 	r.CheckRemoteItems(t, file2)
 }
 
+// Test a Result is written for single files copied and moved
+func TestCopyFileResults(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	var results []operations.Result
+	ctx = operations.WithResultWriter(ctx, operations.NewResultWriterFn(func(result *operations.Result) {
+		results = append(results, *result)
+	}))
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	r.CheckLocalItems(t, file1)
+
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, "file2", "file1")
+	require.NoError(t, err)
+	err = operations.CopyFile(ctx, r.Fremote, r.Flocal, "file2", "file1")
+	require.NoError(t, err)
+	err = operations.MoveFile(ctx, r.Fremote, r.Flocal, "file3", "file1")
+	require.NoError(t, err)
+
+	require.Equal(t, 3, len(results))
+	assert.Equal(t, "file2", results[0].Path)
+	assert.Equal(t, operations.ResultCopied, results[0].Action)
+	assert.Equal(t, operations.ReasonNew, results[0].Reason)
+	assert.Equal(t, operations.ResultSkipped, results[1].Action)
+	assert.Equal(t, operations.ReasonUnchanged, results[1].Reason)
+	assert.Equal(t, "file3", results[2].Path)
+	assert.Equal(t, operations.ResultMoved, results[2].Action)
+	require.NotNil(t, results[2].SrcSize)
+	assert.Equal(t, int64(14), *results[2].SrcSize)
+}
+
 func TestCopyFileBackupDir(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -453,3 +484,21 @@ func TestCopyFileMaxTransfer(t *testing.T) {
 	r.CheckLocalItems(t, file1, file2, file3, file4)
 	r.CheckRemoteItems(t, file1, file4)
 }
+
+func TestCopyVerify(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	ci.Verify = true
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	r.CheckLocalItems(t, file1)
+
+	accounting.GlobalStats().ResetCounters()
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1)
+
+	assert.Equal(t, int64(1), accounting.GlobalStats().Verified(0))
+	assert.Equal(t, int64(0), accounting.GlobalStats().Unverified(0))
+}
@@ -2,6 +2,10 @@ package operations_test
 
 import (
 	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -278,3 +282,99 @@ func TestMergeDirs(t *testing.T) {
 	assert.Equal(t, 0, len(objs))
 	assert.Equal(t, "dupe1", dirs[0].Remote())
 }
+
+func TestDedupeReport(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	if r.Flocal.Hashes().Overlap(r.Fremote.Hashes()).GetOne() == hash.None {
+		t.Skip("Can't run this test without a common hash")
+	}
+
+	r.WriteFile("one.txt", "This is one", t1)
+	r.WriteFile("empty.txt", "", t1)
+	r.WriteObject(ctx, "dir/one.txt", "This is one", t2)
+	r.WriteObject(ctx, "one-copy.txt", "This is one", t3)
+	r.WriteObject(ctx, "two.txt", "This is two", t1)
+	r.WriteObject(ctx, "two-copy.txt", "This is two", t1)
+	r.WriteObject(ctx, "three.txt", "This is three", t1)
+	r.WriteObject(ctx, "empty.txt", "", t1)
+
+	report, err := operations.DedupeReport(ctx, []fs.Fs{r.Flocal, r.Fremote}, hash.None)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(8), report.Files)
+	assert.Equal(t, int64(3), report.Duplicates)
+	assert.Equal(t, int64(3*11), report.Wasted)
+	require.Equal(t, 2, len(report.Groups))
+
+	one := report.Groups[0]
+	assert.Equal(t, int64(11), one.Size)
+	assert.Equal(t, int64(2*11), one.Wasted)
+	require.Equal(t, 3, len(one.Files))
+	assert.Equal(t, "one.txt", one.Files[0].Path)
+	assert.Equal(t, fs.ConfigString(r.Flocal)+"/one.txt", one.Files[0].Remote)
+
+	two := report.Groups[1]
+	assert.Equal(t, int64(11), two.Wasted)
+	require.Equal(t, 2, len(two.Files))
+}
+
+func TestDedupeLink(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	f, err := fs.NewFs(ctx, ":local,copy_is_hardlink:"+dir)
+	require.NoError(t, err)
+	require.True(t, f.Features().CopyIsLink)
+
+	contents := "This is one"
+	for _, remote := range []string{"one.txt", "dir/one.txt", "one-copy.txt"} {
+		_, err = operations.Rcat(ctx, f, remote, io.NopCloser(strings.NewReader(contents)), t1, nil)
+		require.NoError(t, err)
+	}
+
+	report, err := operations.DedupeReport(ctx, []fs.Fs{f}, hash.None)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Groups))
+	keep := report.Groups[0].Files[0].Path
+
+	replaced, saved, err := operations.DedupeLink(ctx, report)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), replaced)
+	assert.Equal(t, int64(2*len(contents)), saved)
+
+	keepInfo, err := os.Stat(filepath.Join(dir, keep))
+	require.NoError(t, err)
+	for _, file := range report.Groups[0].Files[1:] {
+		info, err := os.Stat(filepath.Join(dir, file.Path))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(keepInfo, info), file.Path)
+	}
+
+	// The hard links aren't duplicates any more, even when the
+	// roots overlap so the files are found twice
+	fsub, err := fs.NewFs(ctx, ":local,copy_is_hardlink:"+filepath.Join(dir, "dir"))
+	require.NoError(t, err)
+	report, err = operations.DedupeReport(ctx, []fs.Fs{f, fsub}, hash.None)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), report.Files)
+	assert.Equal(t, int64(0), report.Duplicates)
+	assert.Equal(t, 0, len(report.Groups))
+
+	// Duplicates found through overlapping roots are only linked once
+	_, err = operations.Rcat(ctx, f, "dir/two.txt", io.NopCloser(strings.NewReader("This is two")), t1, nil)
+	require.NoError(t, err)
+	_, err = operations.Rcat(ctx, f, "two.txt", io.NopCloser(strings.NewReader("This is two")), t1, nil)
+	require.NoError(t, err)
+	report, err = operations.DedupeReport(ctx, []fs.Fs{f, fsub}, hash.None)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Groups))
+	require.Equal(t, 2, len(report.Groups[0].Files))
+	replaced, _, err = operations.DedupeLink(ctx, report)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replaced)
+	for _, remote := range []string{"dir/two.txt", "two.txt"} {
+		data, err := os.ReadFile(filepath.Join(dir, remote))
+		require.NoError(t, err)
+		assert.Equal(t, "This is two", string(data))
+	}
+}
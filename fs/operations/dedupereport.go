@@ -0,0 +1,281 @@
+// Find duplicate files by hash across remotes
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/walk"
+	"github.com/rclone/rclone/lib/random"
+)
+
+// DuplicateFile is a file found by DedupeReport
+type DuplicateFile struct {
+	Remote  string    `json:"remote"`  // path of the file including the remote, e.g. "drive:dir/file.txt"
+	Path    string    `json:"path"`    // path of the file relative to the root it was found in
+	ModTime time.Time `json:"modTime"` // modification time of the file
+	f       fs.Fs
+	o       fs.Object
+}
+
+// DuplicateGroup is a group of files with identical contents
+type DuplicateGroup struct {
+	Hash   string           `json:"hash"`   // the hash of the files
+	Size   int64            `json:"size"`   // size of each file
+	Wasted int64            `json:"wasted"` // space used by all but the first file
+	Files  []*DuplicateFile `json:"files"`  // the files - the first is the one kept by DedupeLink
+}
+
+// DuplicateReport describes the duplicate files found in one or more
+// remotes
+type DuplicateReport struct {
+	HashType   string            `json:"hashType"`   // hash used to find duplicates
+	Files      int64             `json:"files"`      // number of files examined
+	Bytes      int64             `json:"bytes"`      // total size of files examined
+	Duplicates int64             `json:"duplicates"` // number of files which are copies of another
+	Wasted     int64             `json:"wasted"`     // space used by the duplicates
+	Groups     []*DuplicateGroup `json:"groups"`     // groups of identical files, most wasted space first
+}
+
+// sameFiler is implemented by objects which can tell if another
+// object is the same file, for example a hard link to it
+type sameFiler interface {
+	SameFile(other fs.Object) bool
+}
+
+// isSameFile returns true if a and b are the same file
+func isSameFile(a, b fs.Object) bool {
+	do, ok := a.(sameFiler)
+	return ok && do.SameFile(b)
+}
+
+// commonHash returns ht if it is set and supported by all of fses,
+// otherwise a hash supported by all of them.
+func commonHash(fses []fs.Fs, ht hash.Type) (hash.Type, error) {
+	common := hash.Supported()
+	for _, f := range fses {
+		common = common.Overlap(f.Hashes())
+	}
+	if ht == hash.None {
+		ht = common.GetOne()
+		if ht == hash.None {
+			return ht, errors.New("no hash type is supported by all the remotes")
+		}
+		return ht, nil
+	}
+	if !common.Contains(ht) {
+		return ht, fmt.Errorf("%v hash isn't supported by all the remotes", ht)
+	}
+	return ht, nil
+}
+
+// DedupeReport finds files with identical contents in fses using
+// the hash ht. If ht is hash.None then a hash supported by all of
+// fses is chosen.
+//
+// Files are identical if they have the same size and hash. Empty
+// files are ignored. Files found more than once, because the roots
+// overlap, and files which are already hard links to another file in
+// their group are only reported once.
+func DedupeReport(ctx context.Context, fses []fs.Fs, ht hash.Type) (report *DuplicateReport, err error) {
+	ci := fs.GetConfig(ctx)
+	ht, err = commonHash(fses, ht)
+	if err != nil {
+		return nil, err
+	}
+	fs.Infof(nil, "Looking for duplicate files using %v hashes", ht)
+
+	type key struct {
+		hash string
+		size int64
+	}
+	report = &DuplicateReport{HashType: ht.String()}
+	groups := map[key]*DuplicateGroup{}
+	seen := map[string]struct{}{}
+	for _, f := range fses {
+		err = walk.ListR(ctx, f, "", false, ci.MaxDepth, walk.ListObjects, func(entries fs.DirEntries) error {
+			entries.ForObject(func(o fs.Object) {
+				// The same file is found twice if the roots overlap
+				id := f.Name() + ":" + path.Join(f.Root(), o.Remote())
+				if _, found := seen[id]; found {
+					return
+				}
+				seen[id] = struct{}{}
+
+				tr := accounting.Stats(ctx).NewCheckingTransfer(o, "hashing")
+				defer tr.Done(ctx, nil)
+
+				report.Files++
+				report.Bytes += o.Size()
+				if o.Size() <= 0 {
+					return
+				}
+				sum, err := o.Hash(ctx, ht)
+				if err != nil {
+					fs.Errorf(o, "Failed to hash: %v", err)
+					return
+				}
+				if sum == "" {
+					fs.Debugf(o, "No %v hash - skipping", ht)
+					return
+				}
+				k := key{hash: sum, size: o.Size()}
+				group := groups[k]
+				if group == nil {
+					group = &DuplicateGroup{Hash: sum, Size: o.Size()}
+					groups[k] = group
+				}
+				for _, file := range group.Files {
+					if isSameFile(file.o, o) {
+						fs.Debugf(o, "Already a link to %q - skipping", file.Remote)
+						return
+					}
+				}
+				group.Files = append(group.Files, &DuplicateFile{
+					Remote:  fspath.JoinRootPath(fs.ConfigString(f), o.Remote()),
+					Path:    o.Remote(),
+					ModTime: o.ModTime(ctx),
+					f:       f,
+					o:       o,
+				})
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %v: %w", f, err)
+		}
+	}
+
+	for _, group := range groups {
+		if len(group.Files) <= 1 {
+			continue
+		}
+		duplicates := int64(len(group.Files) - 1)
+		group.Wasted = duplicates * group.Size
+		report.Duplicates += duplicates
+		report.Wasted += group.Wasted
+		report.Groups = append(report.Groups, group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Wasted != b.Wasted {
+			return a.Wasted > b.Wasted
+		}
+		return a.Hash < b.Hash
+	})
+	fs.Infof(nil, "Found %d files in %d groups of duplicates wasting %v", report.Duplicates, len(report.Groups), fs.SizeSuffix(report.Wasted))
+	return report, nil
+}
+
+// dedupeLinkMethod returns how a duplicate in fdup can be replaced
+// with a link to a file in fkeep, or "" if it can't be.
+func dedupeLinkMethod(fkeep, fdup fs.Fs) string {
+	if !SameConfig(fkeep, fdup) {
+		return ""
+	}
+	if fdup.Features().CopyIsLink && fdup.Features().Copy != nil {
+		return "hardlink"
+	}
+	if fkeep.Features().Command == nil {
+		return ""
+	}
+	fsInfo, _, _, _, err := fs.ConfigFs(fs.ConfigStringFull(fkeep))
+	if err != nil {
+		return ""
+	}
+	for _, command := range fsInfo.CommandHelp {
+		if command.Name == "shortcut" {
+			return "shortcut"
+		}
+	}
+	return ""
+}
+
+// DedupeLink replaces the duplicates in each group of the report with
+// links to the first file in the group where the backend supports it.
+//
+// Hard links are used on backends whose server-side copies are hard
+// links, e.g. local with --local-copy-is-hardlink, and shortcuts on
+// backends with a "shortcut" command, e.g. drive. Duplicates on other
+// backends, or on a different remote from the first file, are left
+// alone.
+//
+// The duplicate is only removed once the link has been made so if
+// making the link fails the duplicate is left alone.
+//
+// It returns the number of files replaced and the space saved.
+func DedupeLink(ctx context.Context, report *DuplicateReport) (replaced int64, saved int64, err error) {
+	for _, group := range report.Groups {
+		keep := group.Files[0]
+		fkeep := keep.f
+		for _, dup := range group.Files[1:] {
+			fdup := dup.f
+			method := dedupeLinkMethod(fkeep, fdup)
+			if method == "" {
+				fs.Debugf(dup.o, "Can't replace with a link to %q", keep.Remote)
+				continue
+			}
+			if SkipDestructive(ctx, dup.o, "replace with "+method+" to "+keep.Remote) {
+				continue
+			}
+			switch method {
+			case "hardlink":
+				// Copy replaces the duplicate with the hard link
+				_, err = fdup.Features().Copy(ctx, keep.o, dup.Path)
+			case "shortcut":
+				err = dedupeShortcut(ctx, keep, dup)
+			}
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(dup.o, "Failed to replace with %s to %q: %v", method, keep.Remote, err)
+				continue
+			}
+			fs.Infof(dup.o, "Replaced with %s to %q", method, keep.Remote)
+			replaced++
+			saved += group.Size
+		}
+	}
+	return replaced, saved, nil
+}
+
+// dedupeShortcut replaces dup with a shortcut to keep.
+//
+// The shortcut is made with a temporary name then renamed over dup
+// once dup has been deleted so the duplicate is only removed if the
+// shortcut could be made.
+func dedupeShortcut(ctx context.Context, keep, dup *DuplicateFile) error {
+	fdup := dup.f
+	tmpPath := dup.Path + ".rclone-dedupe-" + random.String(8)
+	// the target remote makes the shortcut path relative to fdup
+	_, err := keep.f.Features().Command(ctx, "shortcut", []string{keep.Path, tmpPath}, map[string]string{
+		"target": fs.ConfigStringFull(fdup),
+	})
+	if err != nil {
+		return err
+	}
+	tmp, err := fdup.NewObject(ctx, tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to find shortcut %q: %w", tmpPath, err)
+	}
+	err = DeleteFile(ctx, dup.o)
+	if err != nil {
+		if removeErr := DeleteFile(ctx, tmp); removeErr != nil {
+			fs.Errorf(tmp, "Failed to remove shortcut: %v", removeErr)
+		}
+		return err
+	}
+	_, err = Move(ctx, fdup, nil, dup.Path, tmp)
+	if err != nil {
+		return fmt.Errorf("failed to rename shortcut %q: %w", tmpPath, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package operations
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path"
@@ -46,6 +47,22 @@ func (t Timestamp) MarshalJSON() (out []byte, err error) {
 	return []byte(`"` + t.When.Format(t.Format) + `"`), nil
 }
 
+// UnmarshalJSON turns JSON into a Timestamp
+func (t *Timestamp) UnmarshalJSON(in []byte) error {
+	var s string
+	err := json.Unmarshal(in, &s)
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		t.When = time.Time{}
+		return nil
+	}
+	t.When, err = time.Parse(time.RFC3339Nano, s)
+	t.Format = time.RFC3339Nano
+	return err
+}
+
 // Returns a time format for the given precision
 func formatForPrecision(precision time.Duration) string {
 	switch {
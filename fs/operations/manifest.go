@@ -0,0 +1,553 @@
+// Checksum manifests stored in each directory of a remote
+
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/walk"
+	"golang.org/x/sync/errgroup"
+)
+
+// Formats of manifest files
+const (
+	ManifestGNU = "gnu" // "hash  name" lines as written by md5sum - one manifest per hash
+	ManifestBSD = "bsd" // "HASH (name) = hash" lines - all the hashes in one manifest
+	ManifestSFV = "sfv" // "name hash" lines in Simple File Verification format - CRC32 only
+)
+
+// DefaultManifestName is the name manifests are given, without their
+// extension, if none is set.
+const DefaultManifestName = "checksums"
+
+// ManifestOpt contains options for WriteManifests
+type ManifestOpt struct {
+	Name     string      // name of the manifests without extension - DefaultManifestName if empty
+	Format   string      // format of the manifests - ManifestGNU if empty
+	Hashes   []hash.Type // hashes to put in the manifests
+	Download bool        // read the files to hash them rather than asking the backend
+}
+
+// manifestName returns the name of the manifest for ht in format.
+//
+// GNU manifests are named after their hash, e.g. checksums.md5, and
+// the others after their format, e.g. checksums.sfv.
+func manifestName(name, format string, ht hash.Type) string {
+	if name == "" {
+		name = DefaultManifestName
+	}
+	if format == ManifestGNU {
+		return name + "." + ht.String()
+	}
+	return name + "." + format
+}
+
+// parseManifestName returns the format of the manifest with leaf
+// name leaf and for GNU manifests its hash. It returns ok false if
+// leaf isn't a manifest called name.
+func parseManifestName(name, leaf string) (format string, ht hash.Type, ok bool) {
+	if name == "" {
+		name = DefaultManifestName
+	}
+	if !strings.HasPrefix(leaf, name+".") {
+		return "", hash.None, false
+	}
+	ext := leaf[len(name)+1:]
+	switch ext {
+	case ManifestBSD, ManifestSFV:
+		return ext, hash.None, true
+	}
+	for _, ht := range hash.Supported().Array() {
+		if ext == ht.String() {
+			return ManifestGNU, ht, true
+		}
+	}
+	return "", hash.None, false
+}
+
+// hashObject returns the hashes of o of the types given.
+//
+// Unless download is set the hashes the backend supports are read
+// from it. The others are calculated by reading o once.
+//
+// Local files are always read once as the local backend would
+// otherwise read them again for each hash.
+func hashObject(ctx context.Context, o fs.Object, types []hash.Type, download bool) (sums map[hash.Type]string, err error) {
+	sums = make(map[hash.Type]string, len(types))
+	if len(types) > 1 && o.Fs().Features().IsLocal {
+		download = true
+	}
+	var toRead hash.Set
+	for _, ht := range types {
+		if !download && o.Fs().Hashes().Contains(ht) {
+			sum, err := o.Hash(ctx, ht)
+			if err != nil {
+				return nil, err
+			}
+			if sum != "" {
+				sums[ht] = sum
+				continue
+			}
+		}
+		toRead.Add(ht)
+	}
+	if toRead.Count() == 0 {
+		return sums, nil
+	}
+	var in io.ReadCloser
+	in, err = Open(ctx, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open: %w", err)
+	}
+	tr := accounting.Stats(ctx).NewTransfer(o)
+	in = tr.Account(ctx, in).WithBuffer() // account and buffer the transfer
+	defer func() {
+		tr.Done(ctx, err) // will close the stream
+	}()
+	hasher, err := hash.NewMultiHasherTypes(toRead)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(hasher, in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+	for ht, sum := range hasher.Sums() {
+		sums[ht] = sum
+	}
+	return sums, nil
+}
+
+// manifestEntry is a file in a manifest and its hashes
+type manifestEntry struct {
+	name string
+	sums map[hash.Type]string
+}
+
+// manifest is the contents of a manifest being made
+type manifest struct {
+	buf   bytes.Buffer
+	files map[string]struct{} // names of the files with a hash in the manifest
+}
+
+// formatManifests returns the manifests for entries keyed on their
+// names.
+func formatManifests(opt *ManifestOpt, entries []manifestEntry) map[string]*manifest {
+	manifests := map[string]*manifest{}
+	buf := func(ht hash.Type, entryName string) *bytes.Buffer {
+		name := manifestName(opt.Name, opt.Format, ht)
+		m := manifests[name]
+		if m == nil {
+			m = &manifest{files: map[string]struct{}{}}
+			if opt.Format == ManifestSFV {
+				_, _ = fmt.Fprintf(&m.buf, "; Generated by rclone %s\n", fs.Version)
+			}
+			manifests[name] = m
+		}
+		m.files[entryName] = struct{}{}
+		return &m.buf
+	}
+	for _, entry := range entries {
+		for _, ht := range opt.Hashes {
+			sum := entry.sums[ht]
+			if sum == "" {
+				continue
+			}
+			switch opt.Format {
+			case ManifestBSD:
+				_, _ = fmt.Fprintf(buf(ht, entry.name), "%s (%s) = %s\n", strings.ToUpper(ht.String()), entry.name, sum)
+			case ManifestSFV:
+				_, _ = fmt.Fprintf(buf(ht, entry.name), "%s %s\n", entry.name, strings.ToUpper(sum))
+			default:
+				_, _ = fmt.Fprintf(buf(ht, entry.name), "%s  %s\n", sum, entry.name)
+			}
+		}
+	}
+	return manifests
+}
+
+// WriteManifests writes a manifest into each directory of f with the
+// hashes of the files in that directory.
+//
+// Directories with no files don't get a manifest. If any of the files
+// in a directory can't be hashed its manifests aren't written so an
+// existing manifest isn't replaced with an incomplete one. The error
+// is counted and returned.
+func WriteManifests(ctx context.Context, f fs.Fs, opt *ManifestOpt) error {
+	ci := fs.GetConfig(ctx)
+	fi := filter.GetConfig(ctx)
+	o := *opt
+	opt = &o
+	if opt.Format == "" {
+		opt.Format = ManifestGNU
+	}
+	switch opt.Format {
+	case ManifestGNU, ManifestBSD:
+	case ManifestSFV:
+		if len(opt.Hashes) == 0 {
+			opt.Hashes = []hash.Type{hash.CRC32}
+		}
+		if len(opt.Hashes) != 1 || opt.Hashes[0] != hash.CRC32 {
+			return errors.New("sfv manifests can only contain crc32 hashes")
+		}
+	default:
+		return fmt.Errorf("unknown manifest format %q", opt.Format)
+	}
+	if len(opt.Hashes) == 0 {
+		return errors.New("no hashes to write to manifests")
+	}
+	concurrency := ci.Checkers
+	if opt.Download {
+		concurrency = ci.Transfers
+	}
+	var (
+		mu       sync.Mutex
+		lastErr  error
+		nWritten int
+	)
+	err := walk.Walk(ctx, f, "", true, ci.MaxDepth, func(dir string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(dir, "Failed to list: %v", err)
+			lastErr = err
+			return nil
+		}
+		var objs []fs.Object
+		for _, entry := range entries {
+			obj, ok := entry.(fs.Object)
+			if !ok {
+				continue
+			}
+			if _, _, isManifest := parseManifestName(opt.Name, path.Base(obj.Remote())); isManifest {
+				continue
+			}
+			if !fi.IncludeObject(ctx, obj) {
+				continue
+			}
+			objs = append(objs, obj)
+		}
+		if len(objs) == 0 {
+			return nil
+		}
+
+		// Hash the files in parallel
+		manifestEntries := make([]manifestEntry, len(objs))
+		var nFailed int
+		var g errgroup.Group
+		g.SetLimit(concurrency)
+		for i, obj := range objs {
+			i, obj := i, obj
+			g.Go(func() error {
+				tr := accounting.Stats(ctx).NewCheckingTransfer(obj, "hashing")
+				sums, err := hashObject(ctx, obj, opt.Hashes, opt.Download)
+				tr.Done(ctx, err)
+				if err != nil {
+					err = fs.CountError(err)
+					fs.Errorf(obj, "Failed to hash: %v", err)
+					mu.Lock()
+					lastErr = err
+					nFailed++
+					mu.Unlock()
+					return nil
+				}
+				manifestEntries[i] = manifestEntry{name: path.Base(obj.Remote()), sums: sums}
+				return nil
+			})
+		}
+		_ = g.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if nFailed > 0 {
+			fs.Errorf(dir, "Not writing manifests as %d of %d files failed to hash", nFailed, len(objs))
+			return nil
+		}
+
+		manifests := formatManifests(opt, manifestEntries)
+		names := make([]string, 0, len(manifests))
+		for name := range manifests {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			remote := path.Join(dir, name)
+			if SkipDestructive(ctx, remote, "write manifest") {
+				continue
+			}
+			m := manifests[name]
+			data := m.buf.Bytes()
+			_, err := RcatSize(ctx, f, remote, io.NopCloser(bytes.NewReader(data)), int64(len(data)), time.Now(), nil)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(remote, "Failed to write manifest: %v", err)
+				lastErr = err
+				continue
+			}
+			if missing := len(objs) - len(m.files); missing > 0 {
+				fs.Logf(remote, "%d files left out of manifest as the hash wasn't available", missing)
+			}
+			fs.Infof(remote, "Wrote manifest with %d files", len(m.files))
+			nWritten++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fs.Logf(f, "Wrote %d manifests", nWritten)
+	return lastErr
+}
+
+// regular expressions for parsing manifest lines
+var (
+	manifestBSDLine = regexp.MustCompile(`^([\w-]+) \((.+)\) = ([^ ]+)$`)
+	manifestGNULine = regexp.MustCompile(`^([^ ]+) [ *](.+)$`)
+	manifestSFVLine = regexp.MustCompile(`^(.+) ([0-9a-fA-F]{8})$`)
+)
+
+// ManifestSums are the hashes read from the manifests in a directory
+// keyed on file name
+type ManifestSums map[string]map[hash.Type]string
+
+// ParseManifest reads the manifest o in format adding its hashes to
+// sums. For GNU manifests ht is the hash they contain.
+func ParseManifest(ctx context.Context, o fs.Object, format string, ht hash.Type, sums ManifestSums) (err error) {
+	in, err := Open(ctx, o)
+	if err != nil {
+		return err
+	}
+	defer fs.CheckClose(in, &err)
+
+	const maxWarn = 3
+	numWarn := 0
+	warn := func(lineNo int, what string) {
+		numWarn++
+		if numWarn <= maxWarn {
+			fs.Logf(o, "%s on manifest line %d", what, lineNo)
+		}
+	}
+
+	scanner := bufio.NewScanner(in)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		var (
+			name, sum string
+			lineHash  = ht
+		)
+		switch format {
+		case ManifestBSD:
+			fields := manifestBSDLine.FindStringSubmatch(line)
+			if fields == nil {
+				warn(lineNo, "improperly formatted checksum")
+				continue
+			}
+			if err := lineHash.Set(fields[1]); err != nil {
+				warn(lineNo, "unknown hash "+fields[1])
+				continue
+			}
+			name, sum = fields[2], fields[3]
+		case ManifestSFV:
+			if strings.HasPrefix(line, ";") {
+				continue
+			}
+			fields := manifestSFVLine.FindStringSubmatch(line)
+			if fields == nil {
+				warn(lineNo, "improperly formatted checksum")
+				continue
+			}
+			name, sum, lineHash = fields[1], fields[2], hash.CRC32
+		default:
+			fields := manifestGNULine.FindStringSubmatch(line)
+			if fields == nil {
+				warn(lineNo, "improperly formatted checksum")
+				continue
+			}
+			sum, name = fields[1], fields[2]
+		}
+		if sums[name] == nil {
+			sums[name] = map[hash.Type]string{}
+		}
+		if sums[name][lineHash] != "" {
+			warn(lineNo, "duplicate file")
+			continue
+		}
+		// We've standardised on lower case checksums in rclone internals.
+		sums[name][lineHash] = strings.ToLower(sum)
+	}
+	if numWarn > maxWarn {
+		fs.Logf(o, "%d warning(s) suppressed...", numWarn-maxWarn)
+	}
+	return scanner.Err()
+}
+
+// CheckManifests checks the files in each directory of f against the
+// manifests called name in that directory written by WriteManifests
+// or other tools.
+//
+// The directories are checked one at a time as they are listed and
+// each file is read at most once however many hashes are in the
+// manifests. Files not in a manifest are reported as missing on the
+// source and files in a manifest which don't exist as missing on the
+// destination.
+func CheckManifests(ctx context.Context, f fs.Fs, name string, opt *CheckOpt, download bool) error {
+	ci := fs.GetConfig(ctx)
+	fi := filter.GetConfig(ctx)
+	var options CheckOpt
+	if opt != nil {
+		options = *opt
+	}
+	// Like CheckSum the manifests take the place of Fsrc
+	options.Fsrc = nil
+	options.Fdst = f
+	concurrency := ci.Checkers
+	if download {
+		concurrency = ci.Transfers
+	}
+	c := &checkMarch{
+		tokens: make(chan struct{}, concurrency),
+		opt:    options,
+	}
+	var (
+		lastErr    error
+		nManifests int
+	)
+	err := walk.Walk(ctx, f, "", true, ci.MaxDepth, func(dir string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(dir, "Failed to list: %v", err)
+			lastErr = err
+			return nil
+		}
+
+		// Read the manifests in this directory first
+		sums := ManifestSums{}
+		var objs []fs.Object
+		for _, entry := range entries {
+			obj, ok := entry.(fs.Object)
+			if !ok {
+				continue
+			}
+			format, ht, isManifest := parseManifestName(name, path.Base(obj.Remote()))
+			if !isManifest {
+				if fi.IncludeObject(ctx, obj) {
+					objs = append(objs, obj)
+				}
+				continue
+			}
+			err := ParseManifest(ctx, obj, format, ht, sums)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(obj, "Failed to read manifest: %v", err)
+				lastErr = err
+				continue
+			}
+			nManifests++
+		}
+
+		// Then check the files against them
+		for _, obj := range objs {
+			c.checkManifestSums(ctx, obj, download, sums[path.Base(obj.Remote())])
+			delete(sums, path.Base(obj.Remote()))
+		}
+
+		// Anything left is missing
+		missing := make([]string, 0, len(sums))
+		for leaf := range sums {
+			missing = append(missing, path.Join(dir, leaf))
+		}
+		sort.Strings(missing)
+		for _, remote := range missing {
+			if !fi.IncludeRemote(remote) {
+				continue
+			}
+			err := fmt.Errorf("file not in %v", f)
+			fs.Errorf(remote, "%v", err)
+			_ = fs.CountError(err)
+			c.differences.Add(1)
+			c.dstFilesMissing.Add(1)
+			c.reportFilename(remote, c.opt.MissingOnDst, '+')
+		}
+		return nil
+	})
+	c.wg.Wait() // wait for background go-routines
+	if err == nil {
+		err = lastErr
+	}
+	fs.Infof(f, "Read %d manifests", nManifests)
+	return c.reportResults(ctx, err)
+}
+
+// checkManifestSums checks obj against the hashes for it from the
+// manifests, which is nil if it isn't in any.
+func (c *checkMarch) checkManifestSums(ctx context.Context, obj fs.Object, download bool, sums map[hash.Type]string) {
+	if len(sums) == 0 {
+		if c.opt.OneWay {
+			return
+		}
+		err := errors.New("sum not found")
+		_ = fs.CountError(err)
+		fs.Errorf(obj, "%v", err)
+		c.differences.Add(1)
+		c.srcFilesMissing.Add(1)
+		c.report(obj, c.opt.MissingOnSrc, '-')
+		return
+	}
+	types := make([]hash.Type, 0, len(sums))
+	for ht := range sums {
+		types = append(types, ht)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	c.wg.Add(1)
+	c.tokens <- struct{}{} // put a token to limit concurrency
+	go func() {
+		defer func() {
+			<-c.tokens // get the token back to free up a slot
+			c.wg.Done()
+		}()
+		tr := accounting.Stats(ctx).NewCheckingTransfer(obj, "hashing")
+		objSums, err := hashObject(ctx, obj, types, download)
+		tr.Done(ctx, err)
+		if err != nil {
+			c.matchSum(ctx, sums[types[0]], "", obj, err, types[0])
+			return
+		}
+		// Report the first hash which differs, or failing that
+		// the first which could be checked
+		ht := types[0]
+		checked := false
+		for _, t := range types {
+			objSum := objSums[t]
+			if objSum == "" {
+				continue
+			}
+			if objSum != sums[t] {
+				ht = t
+				break
+			}
+			if !checked {
+				ht = t
+				checked = true
+			}
+		}
+		c.matchSum(ctx, sums[ht], objSums[ht], obj, nil, ht)
+	}()
+}
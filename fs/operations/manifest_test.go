@@ -0,0 +1,117 @@
+package operations_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// read the object at remote in f returning its contents
+func readRemote(ctx context.Context, t *testing.T, f fs.Fs, remote string) string {
+	o, err := f.NewObject(ctx, remote)
+	require.NoError(t, err)
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	data, err := io.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	return string(data)
+}
+
+// check the manifests in f returning the sorted combined report
+func checkManifests(ctx context.Context, t *testing.T, f fs.Fs, wantErr bool) []string {
+	var combined bytes.Buffer
+	err := operations.CheckManifests(ctx, f, "", &operations.CheckOpt{Combined: &combined}, false)
+	if wantErr {
+		assert.Error(t, err)
+	} else {
+		assert.NoError(t, err)
+	}
+	lines := strings.Split(strings.TrimSpace(combined.String()), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestManifests(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		format   string
+		hashes   []hash.Type
+		manifest string
+		want     string
+	}{
+		{
+			format:   operations.ManifestGNU,
+			hashes:   []hash.Type{hash.MD5, hash.SHA1},
+			manifest: "checksums.sha1",
+			want:     "0a0a9f2a6772942557ab5355d76af442f8f65e01  a.txt\n",
+		},
+		{
+			format:   operations.ManifestBSD,
+			hashes:   []hash.Type{hash.MD5, hash.SHA1},
+			manifest: "checksums.bsd",
+			want: "MD5 (a.txt) = 65a8e27d8879283831b664bd8b7f0ad4\n" +
+				"SHA1 (a.txt) = 0a0a9f2a6772942557ab5355d76af442f8f65e01\n",
+		},
+		{
+			format:   operations.ManifestSFV,
+			manifest: "checksums.sfv",
+			want:     "; Generated by rclone " + fs.Version + "\na.txt EC4AC3D0\n",
+		},
+	} {
+		t.Run(test.format, func(t *testing.T) {
+			r := fstest.NewRun(t)
+			r.WriteObject(ctx, "a.txt", "Hello, World!", t1)
+			r.WriteObject(ctx, "dir/b.txt", "I am the walrus", t1)
+
+			err := operations.WriteManifests(ctx, r.Fremote, &operations.ManifestOpt{
+				Format:   test.format,
+				Hashes:   test.hashes,
+				Download: true,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.want, readRemote(ctx, t, r.Fremote, test.manifest))
+
+			// Writing them again doesn't include the manifests
+			err = operations.WriteManifests(ctx, r.Fremote, &operations.ManifestOpt{
+				Format:   test.format,
+				Hashes:   test.hashes,
+				Download: true,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.want, readRemote(ctx, t, r.Fremote, test.manifest))
+
+			assert.Equal(t, []string{"= a.txt", "= dir/b.txt"}, checkManifests(ctx, t, r.Fremote, false))
+
+			// Change, add and remove files
+			r.WriteObject(ctx, "dir/b.txt", "I am the WALRUS", t1)
+			r.WriteObject(ctx, "c.txt", "new", t1)
+			o, err := r.Fremote.NewObject(ctx, "a.txt")
+			require.NoError(t, err)
+			require.NoError(t, o.Remove(ctx))
+
+			assert.Equal(t, []string{"* dir/b.txt", "+ a.txt", "- c.txt"}, checkManifests(ctx, t, r.Fremote, true))
+		})
+	}
+}
+
+func TestWriteManifestsErrors(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	err := operations.WriteManifests(ctx, r.Fremote, &operations.ManifestOpt{Format: "potato", Hashes: []hash.Type{hash.MD5}})
+	assert.ErrorContains(t, err, "unknown manifest format")
+	err = operations.WriteManifests(ctx, r.Fremote, &operations.ManifestOpt{Format: operations.ManifestSFV, Hashes: []hash.Type{hash.MD5}})
+	assert.ErrorContains(t, err, "crc32")
+	err = operations.WriteManifests(ctx, r.Fremote, &operations.ManifestOpt{})
+	assert.ErrorContains(t, err, "no hashes")
+}
@@ -271,9 +271,9 @@ func equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object, opt equalOpt)
 				return false
 			} else if err != nil {
 				err = fs.CountError(err)
-				fs.Errorf(dst, "Failed to set modification time: %v", err)
+				fs.ErrorfCtx(ctx, dst, "Failed to set modification time: %v", err)
 			} else {
-				fs.Infof(src, "Updated modification time in destination")
+				fs.InfofCtx(ctx, src, "Updated modification time in destination")
 			}
 		}
 	}
@@ -363,9 +363,9 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 		switch err {
 		case nil:
 			if newDst != nil && src.String() != newDst.String() {
-				fs.Infof(src, "Moved (server-side) to: %s", newDst.String())
+				fs.InfofCtx(ctx, src, "Moved (server-side) to: %s", newDst.String())
 			} else {
-				fs.Infof(src, "Moved (server-side)")
+				fs.InfofCtx(ctx, src, "Moved (server-side)")
 			}
 			in.ServerSideMoveEnd(newDst.Size()) // account the bytes for the server-side transfer
 			_ = in.Close()
@@ -375,7 +375,7 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 			_ = in.Close()
 		default:
 			err = fs.CountError(err)
-			fs.Errorf(src, "Couldn't move: %v", err)
+			fs.ErrorfCtx(ctx, src, "Couldn't move: %v", err)
 			_ = in.Close()
 			return newDst, err
 		}
@@ -383,7 +383,7 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 	// Move not found or didn't work so copy dst <- src
 	newDst, err = Copy(ctx, fdst, dst, remote, src)
 	if err != nil {
-		fs.Errorf(src, "Not deleting source as copy failed: %v", err)
+		fs.ErrorfCtx(ctx, src, "Not deleting source as copy failed: %v", err)
 		return newDst, err
 	}
 	// Delete src if no error on copy
@@ -458,10 +458,10 @@ func DeleteFileWithBackupDir(ctx context.Context, dst fs.Object, backupDir fs.Fs
 		err = dst.Remove(ctx)
 	}
 	if err != nil {
-		fs.Errorf(dst, "Couldn't %s: %v", action, err)
+		fs.ErrorfCtx(ctx, dst, "Couldn't %s: %v", action, err)
 		err = fs.CountError(err)
 	} else if !skip {
-		fs.Infof(dst, actioned)
+		fs.InfofCtx(ctx, dst, actioned)
 	}
 	return err
 }
@@ -485,16 +485,25 @@ func DeleteFilesWithBackupDir(ctx context.Context, toBeDeleted fs.ObjectsChan, b
 	wg.Add(ci.Checkers)
 	var errorCount atomic.Int32
 	var fatalErrorCount atomic.Int32
+	results := GetResultWriter(ctx)
 
 	for i := 0; i < ci.Checkers; i++ {
 		go func() {
 			defer wg.Done()
 			for dst := range toBeDeleted {
+				var result *Result
+				if results != nil {
+					result = NewResult(dst.Remote(), nil, dst)
+				}
 				err := DeleteFileWithBackupDir(ctx, dst, backupDir)
+				if result != nil {
+					result.Done(ResultDeleted, "", err)
+					results.Write(result)
+				}
 				if err != nil {
 					errorCount.Add(1)
 					if fserrors.IsFatalError(err) {
-						fs.Errorf(dst, "Got fatal error on delete: %s", err)
+						fs.ErrorfCtx(ctx, dst, "Got fatal error on delete: %s", err)
 						fatalErrorCount.Add(1)
 						return
 					}
@@ -933,7 +942,7 @@ func TryRmdir(ctx context.Context, f fs.Fs, dir string) error {
 	if SkipDestructive(ctx, fs.LogDirName(f, dir), "remove directory") {
 		return nil
 	}
-	fs.Infof(fs.LogDirName(f, dir), "Removing directory")
+	fs.InfofCtx(ctx, fs.LogDirName(f, dir), "Removing directory")
 	return f.Rmdir(ctx, dir)
 }
 
@@ -1318,7 +1327,7 @@ func Rmdirs(ctx context.Context, f fs.Fs, dir string, leaveRoot bool) error {
 				err := TryRmdir(gCtx, f, dir)
 				if err != nil {
 					err = fs.CountError(err)
-					fs.Errorf(dir, "Failed to rmdir: %v", err)
+					fs.ErrorfCtx(ctx, dir, "Failed to rmdir: %v", err)
 					errMu.Lock()
 					lastError = err
 					errCount += 1
@@ -1473,20 +1482,27 @@ func CompareOrCopyDest(ctx context.Context, fdst fs.Fs, dst, src fs.Object, Comp
 // Returns a flag which indicates whether the file needs to be
 // transferred or not.
 func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
+	needTransfer, _ := NeedTransferReason(ctx, dst, src)
+	return needTransfer
+}
+
+// NeedTransferReason is like NeedTransfer but also returns the reason
+// for the decision as one of the Reason constants.
+func NeedTransferReason(ctx context.Context, dst, src fs.Object) (needTransfer bool, reason string) {
 	ci := fs.GetConfig(ctx)
 	if dst == nil {
 		fs.Debugf(src, "Need to transfer - File not found at Destination")
-		return true
+		return true, ReasonNew
 	}
 	// If we should ignore existing files, don't transfer
 	if ci.IgnoreExisting {
 		fs.Debugf(src, "Destination exists, skipping")
-		return false
+		return false, ReasonExists
 	}
 	// If we should upload unconditionally
 	if ci.IgnoreTimes {
 		fs.Debugf(src, "Transferring unconditionally as --ignore-times is in use")
-		return true
+		return true, ReasonIgnoreTimes
 	}
 	// If UpdateOlder is in effect, skip if dst is newer than src
 	if ci.UpdateOlder {
@@ -1504,33 +1520,37 @@ func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
 		switch {
 		case dt >= modifyWindow:
 			fs.Debugf(src, "Destination is newer than source, skipping")
-			return false
+			return false, ReasonNewer
 		case dt <= -modifyWindow:
 			// force --checksum on for the check and do update modtimes by default
 			opt := defaultEqualOpt(ctx)
 			opt.forceModTimeMatch = true
 			if equal(ctx, src, dst, opt) {
 				fs.Debugf(src, "Unchanged skipping")
-				return false
+				return false, ReasonUnchanged
 			}
+			return true, ReasonModTime
 		default:
 			// Do a size only compare unless --checksum is set
 			opt := defaultEqualOpt(ctx)
 			opt.sizeOnly = !ci.CheckSum
 			if equal(ctx, src, dst, opt) {
 				fs.Debugf(src, "Destination mod time is within %v of source and files identical, skipping", modifyWindow)
-				return false
+				return false, ReasonUnchanged
 			}
 			fs.Debugf(src, "Destination mod time is within %v of source but files differ, transferring", modifyWindow)
-		}
-	} else {
-		// Check to see if changed or not
-		if Equal(ctx, src, dst) {
-			fs.Debugf(src, "Unchanged skipping")
-			return false
+			if sizeDiffers(ctx, src, dst) {
+				return true, ReasonSize
+			}
+			return true, ReasonHash
 		}
 	}
-	return true
+	// Check to see if changed or not
+	if Equal(ctx, src, dst) {
+		fs.Debugf(src, "Unchanged skipping")
+		return false, ReasonUnchanged
+	}
+	return true, differReason(ctx, src, dst)
 }
 
 // RcatSize reads data from the Reader until EOF and uploads it to a file on remote.
@@ -1762,7 +1782,22 @@ func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName str
 			return err
 		}
 	}
-	needTransfer := NeedTransfer(ctx, dstObj, srcObj)
+	needTransfer, reason := NeedTransferReason(ctx, dstObj, srcObj)
+
+	// Write a Result for the file if required
+	var result *Result
+	results := GetResultWriter(ctx)
+	if results != nil {
+		result = NewResult(dstFileName, srcObj, dstObj)
+	}
+	action := ResultSkipped
+	defer func() {
+		if result != nil {
+			result.Done(action, reason, err)
+			results.Write(result)
+		}
+	}()
+
 	if needTransfer {
 		NoNeedTransfer, err := CompareOrCopyDest(ctx, fdst, dstObj, srcObj, copyDestDir, backupDir)
 		if err != nil {
@@ -1770,9 +1805,18 @@ func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName str
 		}
 		if NoNeedTransfer {
 			needTransfer = false
+			if len(ci.CompareDest) > 0 {
+				reason = ReasonCompareDest
+			} else {
+				action, reason = ResultCopied, ReasonCopyDest
+			}
 		}
 	}
 	if needTransfer {
+		action = ResultCopied
+		if !cp {
+			action = ResultMoved
+		}
 		// If destination already exists, then we must move it into --backup-dir if required
 		if dstObj != nil && backupDir != nil {
 			err = MoveBackupDir(ctx, backupDir, dstObj)
@@ -1783,8 +1827,15 @@ func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName str
 			dstObj = nil
 		}
 
-		_, err = Op(ctx, fdst, dstObj, dstFileName, srcObj)
+		var newDst fs.Object
+		newDst, err = Op(ctx, fdst, dstObj, dstFileName, srcObj)
+		if result != nil && err == nil && ci.CheckSum && newDst != nil {
+			result.SetHashes(ctx, srcObj, newDst)
+		}
 	} else {
+		if result != nil && ci.CheckSum && action == ResultSkipped && reason != ReasonCompareDest {
+			result.SetHashes(ctx, srcObj, dstObj)
+		}
 		if !cp {
 			if ci.IgnoreExisting {
 				fs.Debugf(srcObj, "Not removing source file as destination file exists and --ignore-existing is set")
@@ -3,14 +3,19 @@
 package operations
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/object"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSizeDiffers(t *testing.T) {
@@ -41,3 +46,70 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+// memoryFs without hashes so copies have to be read back to verify
+type noHashMemoryFs struct {
+	fs.Fs
+}
+
+func (noHashMemoryFs) Hashes() hash.Set { return hash.Set(hash.None) }
+
+func TestVerifyReadBack(t *testing.T) {
+	ctx := context.Background()
+	ci := fs.GetConfig(ctx)
+	when := time.Now()
+	src := object.NewMemoryObject("a", when, []byte("contents"))
+	c := &copy{
+		f:          noHashMemoryFs{object.MemoryFs},
+		src:        src,
+		ci:         ci,
+		verifyHash: verifyHashType(noHashMemoryFs{object.MemoryFs}, object.MemoryFs),
+	}
+	assert.Equal(t, hash.MD5, c.verifyHash)
+
+	// Identical copy
+	err := c.verifyReadBack(ctx, object.NewMemoryObject("a", when, []byte("contents")))
+	assert.NoError(t, err)
+
+	// Corrupted copy
+	err = c.verifyReadBack(ctx, object.NewMemoryObject("a", when, []byte("CONTENTS")))
+	assert.True(t, errors.Is(err, errVerifyFailed), err)
+
+	// The source is only hashed while copying with --verify
+	_ = c.hashWhileReading(io.NopCloser(bytes.NewBufferString("CONTENTS")))
+	assert.Nil(t, c.srcHasher)
+
+	// Using the hash made while copying
+	ci.Verify = true
+	defer func() { ci.Verify = false }()
+	in := c.hashWhileReading(io.NopCloser(bytes.NewBufferString("CONTENTS")))
+	_, err = io.Copy(io.Discard, in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	err = c.verifyReadBack(ctx, object.NewMemoryObject("a", when, []byte("contents")))
+	assert.True(t, errors.Is(err, errVerifyFailed), err)
+}
+
+func TestFormatManifests(t *testing.T) {
+	opt := &ManifestOpt{Format: ManifestGNU, Hashes: []hash.Type{hash.MD5, hash.SHA1}}
+	manifests := formatManifests(opt, []manifestEntry{
+		{name: "a.txt", sums: map[hash.Type]string{hash.MD5: "aaaa", hash.SHA1: "1111"}},
+		{name: "b.txt", sums: map[hash.Type]string{hash.MD5: "bbbb"}},
+	})
+	require.Len(t, manifests, 2)
+	md5 := manifests[DefaultManifestName+".md5"]
+	assert.Equal(t, "aaaa  a.txt\nbbbb  b.txt\n", md5.buf.String())
+	assert.Len(t, md5.files, 2)
+	sha1 := manifests[DefaultManifestName+".sha1"]
+	assert.Equal(t, "1111  a.txt\n", sha1.buf.String())
+	assert.Len(t, sha1.files, 1)
+
+	opt = &ManifestOpt{Format: ManifestBSD, Hashes: []hash.Type{hash.MD5, hash.SHA1}}
+	manifests = formatManifests(opt, []manifestEntry{
+		{name: "a.txt", sums: map[hash.Type]string{hash.MD5: "aaaa", hash.SHA1: "1111"}},
+	})
+	require.Len(t, manifests, 1)
+	bsd := manifests[DefaultManifestName+".bsd"]
+	assert.Equal(t, "MD5 (a.txt) = aaaa\nSHA1 (a.txt) = 1111\n", bsd.buf.String())
+	assert.Len(t, bsd.files, 1)
+}
@@ -653,9 +653,10 @@ func rcBackend(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 // to a circular dependency on config.
 func init() {
 	rc.Add(rc.Call{
-		Path:  "core/du",
-		Fn:    rcDu,
-		Title: "Returns disk usage of a locally attached disk.",
+		Path:   "core/du",
+		Global: true,
+		Fn:     rcDu,
+		Title:  "Returns disk usage of a locally attached disk.",
 		Help: `
 This returns the disk usage for the local directory passed in as dir.
 
@@ -0,0 +1,204 @@
+// Structured records of what happened to each file
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// ResultAction describes what happened to a file
+type ResultAction string
+
+// ResultAction values
+const (
+	ResultCopied       ResultAction = "copied"         // file was copied to the destination
+	ResultMoved        ResultAction = "moved"          // file was moved to the destination
+	ResultDeleted      ResultAction = "deleted"        // file was deleted
+	ResultSkipped      ResultAction = "skipped"        // file didn't need transferring
+	ResultMatch        ResultAction = "match"          // file was identical in source and destination
+	ResultDiffer       ResultAction = "differ"         // file was different in source and destination
+	ResultMissingOnSrc ResultAction = "missing-on-src" // file was only in the destination
+	ResultMissingOnDst ResultAction = "missing-on-dst" // file was only in the source
+	ResultError        ResultAction = "error"          // there was an error processing the file
+)
+
+// Reasons for a ResultAction
+const (
+	ReasonNew         = "new"          // file isn't in the destination
+	ReasonSize        = "size"         // sizes differ
+	ReasonModTime     = "modtime"      // modification times differ
+	ReasonHash        = "hash"         // hashes differ
+	ReasonContents    = "contents"     // contents differ when downloaded
+	ReasonIgnoreTimes = "ignore-times" // --ignore-times is in use
+	ReasonUnchanged   = "unchanged"    // file is the same in source and destination
+	ReasonExists      = "exists"       // --ignore-existing is in use and file exists
+	ReasonNewer       = "newer"        // --update is in use and destination is newer
+	ReasonCompareDest = "compare-dest" // file found with --compare-dest
+	ReasonCopyDest    = "copy-dest"    // file copied from --copy-dest
+	ReasonRenamed     = "renamed"      // file renamed in the destination with --track-renames
+)
+
+// Result is a record of what happened to a single file in a sync or
+// check which can be written as JSON.
+type Result struct {
+	Path     string       `json:"path"`               // path of the file relative to the root
+	Action   ResultAction `json:"action"`             // what happened to the file
+	Reason   string       `json:"reason,omitempty"`   // why it happened - one of the Reason constants
+	SrcSize  *int64       `json:"srcSize,omitempty"`  // size of the source, if present
+	DstSize  *int64       `json:"dstSize,omitempty"`  // size of the destination, if present
+	HashType string       `json:"hashType,omitempty"` // type of SrcHash and DstHash
+	SrcHash  string       `json:"srcHash,omitempty"`  // hash of the source, if known
+	DstHash  string       `json:"dstHash,omitempty"`  // hash of the destination, if known
+	Error    string       `json:"error,omitempty"`    // error if Action is "error"
+	Start    time.Time    `json:"start"`              // time processing the file started
+	Elapsed  float64      `json:"elapsed"`            // time taken in seconds
+	src      fs.ObjectInfo
+	dst      fs.ObjectInfo
+}
+
+// NewResult starts a Result for path with the src and dst which may
+// be nil.
+func NewResult(path string, src, dst fs.ObjectInfo) *Result {
+	r := &Result{
+		Path:  path,
+		Start: time.Now(),
+		src:   src,
+		dst:   dst,
+	}
+	if src != nil {
+		size := src.Size()
+		r.SrcSize = &size
+	}
+	if dst != nil {
+		size := dst.Size()
+		r.DstSize = &size
+	}
+	return r
+}
+
+// Src returns the source the Result was made with, which may be nil
+func (r *Result) Src() fs.ObjectInfo {
+	return r.src
+}
+
+// Dst returns the destination the Result was made with, which may be
+// nil. For renames this is the file before it was renamed.
+func (r *Result) Dst() fs.ObjectInfo {
+	return r.dst
+}
+
+// SetHashes reads the hashes of src and dst, which may be nil, using
+// a hash type they have in common.
+func (r *Result) SetHashes(ctx context.Context, src, dst fs.ObjectInfo) {
+	common := hash.Supported()
+	for _, o := range []fs.ObjectInfo{src, dst} {
+		if o != nil {
+			common = common.Overlap(o.Fs().Hashes())
+		}
+	}
+	ht := common.GetOne()
+	if ht == hash.None {
+		return
+	}
+	r.HashType = ht.String()
+	if src != nil {
+		r.SrcHash, _ = src.Hash(ctx, ht)
+	}
+	if dst != nil {
+		r.DstHash, _ = dst.Hash(ctx, ht)
+	}
+}
+
+// Done finishes the Result with the action and reason given.
+//
+// If err is not nil then the action is set to ResultError.
+func (r *Result) Done(action ResultAction, reason string, err error) {
+	r.Action = action
+	r.Reason = reason
+	if err != nil {
+		r.Action = ResultError
+		r.Error = err.Error()
+	}
+	r.Elapsed = time.Since(r.Start).Seconds()
+}
+
+// ResultWriter writes Results to an io.Writer as JSON, one per line.
+//
+// It is safe for concurrent use.
+type ResultWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	fn  func(r *Result)
+}
+
+// NewResultWriter makes a ResultWriter which writes to out
+func NewResultWriter(out io.Writer) *ResultWriter {
+	return &ResultWriter{out: out}
+}
+
+// NewResultWriterFn makes a ResultWriter which calls fn with each
+// Result instead of writing it.
+//
+// Calls to fn are serialized.
+func NewResultWriterFn(fn func(r *Result)) *ResultWriter {
+	return &ResultWriter{fn: fn}
+}
+
+// Write writes r as a single line of JSON
+func (w *ResultWriter) Write(r *Result) {
+	if w.fn != nil {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.fn(r)
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		fs.Errorf(r.Path, "Failed to marshal result: %v", err)
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	syncFprintf(w.out, "%s\n", data)
+}
+
+type resultWriterKey struct{}
+
+// WithResultWriter returns a new context with w attached so that
+// sync operations write a Result for each file to it.
+func WithResultWriter(ctx context.Context, w *ResultWriter) context.Context {
+	return context.WithValue(ctx, resultWriterKey{}, w)
+}
+
+// GetResultWriter returns the ResultWriter attached to ctx or nil
+// if there isn't one.
+func GetResultWriter(ctx context.Context) *ResultWriter {
+	w, _ := ctx.Value(resultWriterKey{}).(*ResultWriter)
+	return w
+}
+
+// differReason returns the Reason why src and dst which are known
+// to differ are different.
+func differReason(ctx context.Context, src fs.ObjectInfo, dst fs.Object) string {
+	ci := fs.GetConfig(ctx)
+	if sizeDiffers(ctx, src, dst) {
+		return ReasonSize
+	}
+	if !ci.CheckSum && !ci.SizeOnly {
+		modifyWindow := fs.GetModifyWindow(ctx, src.Fs(), dst.Fs())
+		if modifyWindow != fs.ModTimeNotSupported {
+			dt := dst.ModTime(ctx).Sub(src.ModTime(ctx))
+			if dt >= modifyWindow || dt <= -modifyWindow {
+				return ReasonModTime
+			}
+		}
+	}
+	return ReasonHash
+}
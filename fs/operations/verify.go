@@ -0,0 +1,147 @@
+// Implement --verify which reads back files after copying them
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// errVerifyFailed is returned when a copy doesn't match the source
+// when it is read back
+var errVerifyFailed = errors.New("verify failed")
+
+// verifyHashType returns the hash to use to verify copies from fsrc
+// to fdst.
+//
+// This is a hash fdst supports if possible so the backend can be
+// asked for it rather than reading the file back, otherwise one fsrc
+// supports so the source doesn't need reading again.
+func verifyHashType(fdst, fsrc fs.Info) hash.Type {
+	if ht := fdst.Hashes().GetOne(); ht != hash.None {
+		return ht
+	}
+	if ht := fsrc.Hashes().GetOne(); ht != hash.None {
+		return ht
+	}
+	return hash.MD5
+}
+
+// hashingReader hashes the data read through it
+type hashingReader struct {
+	in     io.ReadCloser
+	hasher *hash.MultiHasher
+}
+
+// Read bytes passing them to the hasher
+func (r *hashingReader) Read(p []byte) (n int, err error) {
+	n, err = r.in.Read(p)
+	_, _ = r.hasher.Write(p[:n])
+	return n, err
+}
+
+// Close the underlying reader
+func (r *hashingReader) Close() error {
+	return r.in.Close()
+}
+
+// hashWhileReading wraps in so the source is hashed as it is copied
+// if --verify is in use.
+func (c *copy) hashWhileReading(in io.ReadCloser) io.ReadCloser {
+	c.srcHasher = nil
+	if !c.ci.Verify {
+		return in
+	}
+	hasher, err := hash.NewMultiHasherTypes(hash.NewHashSet(c.verifyHash))
+	if err != nil {
+		fs.Debugf(c.src, "Can't hash source while copying: %v", err)
+		return in
+	}
+	c.srcHasher = hasher
+	return &hashingReader{in: in, hasher: hasher}
+}
+
+// readHash reads all of o and returns its hash of type ht
+func readHash(ctx context.Context, o fs.Object, ht hash.Type) (sum string, err error) {
+	tr := accounting.Stats(ctx).NewCheckingTransfer(o, "verifying")
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	var options []fs.OpenOption
+	for _, option := range fs.GetConfig(ctx).DownloadHeaders {
+		options = append(options, option)
+	}
+	in, err := Open(ctx, o, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to open: %w", err)
+	}
+	defer fs.CheckClose(in, &err)
+	hasher, err := hash.NewMultiHasherTypes(hash.NewHashSet(ht))
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(hasher, in)
+	if err != nil {
+		return "", fmt.Errorf("failed to read: %w", err)
+	}
+	return hasher.SumString(ht, false)
+}
+
+// verifySrcSum returns the hash of the source, using the one computed
+// while copying if possible.
+func (c *copy) verifySrcSum(ctx context.Context) (string, error) {
+	if c.srcHasher != nil && (c.src.Size() < 0 || c.srcHasher.Size() == c.src.Size()) {
+		return c.srcHasher.SumString(c.verifyHash, false)
+	}
+	if c.src.Fs().Hashes().Contains(c.verifyHash) {
+		sum, err := c.src.Hash(ctx, c.verifyHash)
+		if err != nil || sum != "" {
+			return sum, err
+		}
+	}
+	return readHash(ctx, c.src, c.verifyHash)
+}
+
+// verifyDstSum returns the hash of newDst, asking the backend for a
+// fresh copy of the object if it supports the hash, otherwise reading
+// it back.
+func (c *copy) verifyDstSum(ctx context.Context, newDst fs.Object) (string, error) {
+	if c.f.Hashes().Contains(c.verifyHash) {
+		fresh, err := c.f.NewObject(ctx, newDst.Remote())
+		if err != nil {
+			return "", fmt.Errorf("failed to find copy: %w", err)
+		}
+		sum, err := fresh.Hash(ctx, c.verifyHash)
+		if err != nil || sum != "" {
+			return sum, err
+		}
+	}
+	return readHash(ctx, newDst, c.verifyHash)
+}
+
+// verifyReadBack checks newDst has the same contents as the source
+// by comparing hashes of the source with hashes read back from the
+// destination.
+//
+// It returns an error wrapping errVerifyFailed if they differ.
+func (c *copy) verifyReadBack(ctx context.Context, newDst fs.Object) error {
+	srcSum, err := c.verifySrcSum(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read %v hash of source to verify: %w", c.verifyHash, err)
+	}
+	dstSum, err := c.verifyDstSum(ctx, newDst)
+	if err != nil {
+		return fmt.Errorf("failed to read %v hash of copy to verify: %w", c.verifyHash, err)
+	}
+	if srcSum != dstSum {
+		return fmt.Errorf("%w: %v hash differ %q vs %q", errVerifyFailed, c.verifyHash, srcSum, dstSum)
+	}
+	fs.Debugf(newDst, "Verified %v hash %q", c.verifyHash, dstSum)
+	return nil
+}
@@ -4,6 +4,7 @@ package fs
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/rclone/rclone/fs/fserrors"
@@ -20,24 +21,67 @@ type logCalculator struct {
 }
 
 // NewPacer creates a Pacer for the given Fs and Calculator.
+//
+// The metrics of the pacer are labelled with the remote name NewFs
+// sets in ctx.
 func NewPacer(ctx context.Context, c pacer.Calculator) *Pacer {
 	ci := GetConfig(ctx)
 	retries := ci.LowLevelRetries
 	if retries <= 0 {
 		retries = 1
 	}
+	options := []pacer.Option{
+		pacer.InvokerOption(pacerInvoker),
+		// pacer.MaxConnectionsOption(ci.Checkers+ci.Transfers),
+		pacer.RetriesOption(retries),
+		pacer.CalculatorOption(c),
+		pacer.RemoteOption(GetRemoteName(ctx)),
+	}
+	if td := remoteConnectionTokens(ctx); td != nil {
+		options = append(options, pacer.TokenDispenserOption(td))
+	}
 	p := &Pacer{
-		Pacer: pacer.New(
-			pacer.InvokerOption(pacerInvoker),
-			// pacer.MaxConnectionsOption(ci.Checkers+ci.Transfers),
-			pacer.RetriesOption(retries),
-			pacer.CalculatorOption(c),
-		),
+		Pacer: pacer.New(options...),
 	}
 	p.SetCalculator(c)
 	return p
 }
 
+// Tokens limiting the simultaneous API calls of each remote with
+// --max-connections. These are kept per namespace.
+var (
+	remoteConnTokensMu sync.Mutex
+	remoteConnTokens   = map[string]*pacer.TokenDispenser{}
+)
+
+// remoteConnectionTokens returns the tokens shared by all the pacers
+// of the remote in ctx or nil if it has no limit.
+//
+// The limit comes from --max-connections-remote or --max-connections
+func remoteConnectionTokens(ctx context.Context) *pacer.TokenDispenser {
+	name := GetRemoteName(ctx)
+	if name == "" {
+		return nil
+	}
+	ci := GetConfig(ctx)
+	n, ok := ci.MaxConnectionsRemote.Get(name)
+	if !ok {
+		n = ci.MaxConnections
+	}
+	if n <= 0 {
+		return nil
+	}
+	remoteConnTokensMu.Lock()
+	defer remoteConnTokensMu.Unlock()
+	td := remoteConnTokens[name]
+	if td == nil {
+		Debugf(name, "Limiting to %d simultaneous API calls", n)
+		td = pacer.NewTokenDispenser(n)
+		remoteConnTokens[name] = td
+	}
+	return td
+}
+
 func (d *logCalculator) Calculate(state pacer.State) time.Duration {
 	oldSleepTime := state.SleepTime
 	newSleepTime := d.Calculator.Calculate(state)
@@ -90,6 +90,7 @@ func GetFsAndRemote(ctx context.Context, in Params) (f fs.Fs, remote string, err
 func init() {
 	Add(Call{
 		Path:         "fscache/clear",
+		Global:       true,
 		Fn:           rcCacheClear,
 		Title:        "Clear the Fs cache.",
 		AuthRequired: true,
@@ -113,6 +114,7 @@ func rcCacheClear(ctx context.Context, in Params) (out Params, err error) {
 func init() {
 	Add(Call{
 		Path:         "fscache/entries",
+		Global:       true,
 		Fn:           rcCacheEntries,
 		Title:        "Returns the number of entries in the fs cache.",
 		AuthRequired: true,
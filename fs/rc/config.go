@@ -109,9 +109,10 @@ func rcOptionsLocal(ctx context.Context, in Params) (out Params, err error) {
 
 func init() {
 	Add(Call{
-		Path:  "options/set",
-		Fn:    rcOptionsSet,
-		Title: "Set an option",
+		Path:   "options/set",
+		Global: true,
+		Fn:     rcOptionsSet,
+		Title:  "Set an option",
 		Help: `Parameters:
 
 - option block name containing an object with
@@ -242,9 +242,10 @@ func rcObscure(ctx context.Context, in Params) (out Params, err error) {
 
 func init() {
 	Add(Call{
-		Path:  "core/quit",
-		Fn:    rcQuit,
-		Title: "Terminates the app.",
+		Path:   "core/quit",
+		Global: true,
+		Fn:     rcQuit,
+		Title:  "Terminates the app.",
 		Help: `
 (Optional) Pass an exit code to be used for terminating the app:
 - exitCode - int
@@ -275,9 +276,10 @@ func rcQuit(ctx context.Context, in Params) (out Params, err error) {
 
 func init() {
 	Add(Call{
-		Path:  "debug/set-mutex-profile-fraction",
-		Fn:    rcSetMutexProfileFraction,
-		Title: "Set runtime.SetMutexProfileFraction for mutex profiling.",
+		Path:   "debug/set-mutex-profile-fraction",
+		Global: true,
+		Fn:     rcSetMutexProfileFraction,
+		Title:  "Set runtime.SetMutexProfileFraction for mutex profiling.",
 		Help: `
 SetMutexProfileFraction controls the fraction of mutex contention
 events that are reported in the mutex profile. On average 1/rate
@@ -314,9 +316,10 @@ func rcSetMutexProfileFraction(ctx context.Context, in Params) (out Params, err
 
 func init() {
 	Add(Call{
-		Path:  "debug/set-block-profile-rate",
-		Fn:    rcSetBlockProfileRate,
-		Title: "Set runtime.SetBlockProfileRate for blocking profiling.",
+		Path:   "debug/set-block-profile-rate",
+		Global: true,
+		Fn:     rcSetBlockProfileRate,
+		Title:  "Set runtime.SetBlockProfileRate for blocking profiling.",
 		Help: `
 SetBlockProfileRate controls the fraction of goroutine blocking events
 that are reported in the blocking profile. The profiler aims to sample
@@ -347,9 +350,10 @@ func rcSetBlockProfileRate(ctx context.Context, in Params) (out Params, err erro
 
 func init() {
 	Add(Call{
-		Path:  "debug/set-soft-memory-limit",
-		Fn:    rcSetSoftMemoryLimit,
-		Title: "Call runtime/debug.SetMemoryLimit for setting a soft memory limit for the runtime.",
+		Path:   "debug/set-soft-memory-limit",
+		Global: true,
+		Fn:     rcSetSoftMemoryLimit,
+		Title:  "Call runtime/debug.SetMemoryLimit for setting a soft memory limit for the runtime.",
 		Help: `
 SetMemoryLimit provides the runtime with a soft memory limit.
 
@@ -398,9 +402,10 @@ func rcSetSoftMemoryLimit(ctx context.Context, in Params) (out Params, err error
 
 func init() {
 	Add(Call{
-		Path:  "debug/set-gc-percent",
-		Fn:    rcSetGCPercent,
-		Title: "Call runtime/debug.SetGCPercent for setting the garbage collection target percentage.",
+		Path:   "debug/set-gc-percent",
+		Global: true,
+		Fn:     rcSetGCPercent,
+		Title:  "Call runtime/debug.SetGCPercent for setting the garbage collection target percentage.",
 		Help: `
 SetGCPercent sets the garbage collection target percentage: a collection is triggered
 when the ratio of freshly allocated data to live data remaining after the previous collection
@@ -434,6 +439,7 @@ func rcSetGCPercent(ctx context.Context, in Params) (out Params, err error) {
 func init() {
 	Add(Call{
 		Path:          "core/command",
+		Global:        true,
 		AuthRequired:  true,
 		Fn:            rcRunCommand,
 		NeedsRequest:  true,
@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression
+//
+// Each field is a bitmap of the values which match.
+type cronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// set if the day of month or day of week field was "*"
+	domStar bool
+	dowStar bool
+	// if set run at this interval instead
+	every time.Duration
+}
+
+// cronField describes the range of a field of a cron expression
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names []string // names for the values starting at min if any
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronDow    = cronField{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// cron expressions which can be used instead of the 5 fields
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a cron expression
+//
+// This is the standard 5 field "minute hour day-of-month month
+// day-of-week" form with lists, ranges, steps and names for months and
+// days, one of the descriptors @yearly, @monthly, @weekly, @daily,
+// @hourly or "@every DURATION".
+func parseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, fmt.Errorf("bad cron %q: %w", spec, err)
+		}
+		if every < time.Second {
+			return nil, fmt.Errorf("bad cron %q: interval must be at least 1s", spec)
+		}
+		return &cronSchedule{every: every}, nil
+	}
+	if expanded, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("bad cron %q: need 5 fields but found %d", spec, len(fields))
+	}
+	var c cronSchedule
+	var err error
+	for i, field := range []struct {
+		bits *uint64
+		desc cronField
+	}{
+		{&c.minute, cronMinute},
+		{&c.hour, cronHour},
+		{&c.dom, cronDom},
+		{&c.month, cronMonth},
+		{&c.dow, cronDow},
+	} {
+		*field.bits, err = parseCronField(fields[i], field.desc)
+		if err != nil {
+			return nil, fmt.Errorf("bad cron %q: %w", spec, err)
+		}
+	}
+	// Sunday can be 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return &c, nil
+}
+
+// parseCronValue parses a single value of a field
+func parseCronValue(s string, field cronField) (int, error) {
+	lower := strings.ToLower(s)
+	for i, name := range field.names {
+		if lower == name {
+			return field.min + i, nil
+		}
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad %s %q", field.name, s)
+	}
+	if value < field.min || value > field.max {
+		return 0, fmt.Errorf("%s %d out of range %d-%d", field.name, value, field.min, field.max)
+	}
+	return value, nil
+}
+
+// parseCronField parses one field of a cron expression into a bitmap
+func parseCronField(s string, field cronField) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		rangeStr, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("bad step %q in %s", stepStr, field.name)
+			}
+		}
+		var start, end int
+		switch {
+		case rangeStr == "*":
+			start, end = field.min, field.max
+		case strings.Contains(rangeStr, "-"):
+			startStr, endStr, _ := strings.Cut(rangeStr, "-")
+			if start, err = parseCronValue(startStr, field); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(endStr, field); err != nil {
+				return 0, err
+			}
+			if end < start {
+				return 0, fmt.Errorf("bad range %q in %s", rangeStr, field.name)
+			}
+		default:
+			if start, err = parseCronValue(rangeStr, field); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = field.max
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	if bits == 0 {
+		return 0, errors.New("empty " + field.name)
+	}
+	return bits, nil
+}
+
+// dayMatches returns true if the day of t matches the schedule
+//
+// As with cron, if both day of month and day of week are restricted
+// then either matching is enough.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next returns the first time after t that the schedule fires or the
+// zero time if there isn't one in the next 5 years
+func (c *cronSchedule) next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Add(c.every).Truncate(time.Second)
+	}
+	loc := t.Location()
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond())).Truncate(time.Second)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
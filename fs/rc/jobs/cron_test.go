@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * potato *",
+		"@every potato",
+		"@every 1ms",
+	} {
+		_, err := parseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday
+	start := time.Date(2023, 11, 15, 10, 30, 15, 500, time.UTC)
+	for _, test := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2023, 11, 15, 10, 31, 0, 0, time.UTC)},
+		{"30 * * * *", time.Date(2023, 11, 15, 11, 30, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2023, 11, 15, 10, 40, 0, 0, time.UTC)},
+		{"0,45 10-12 * * *", time.Date(2023, 11, 15, 10, 45, 0, 0, time.UTC)},
+		{"15 2 * * *", time.Date(2023, 11, 16, 2, 15, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2023, 11, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2023, 11, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2023, 11, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * fri", time.Date(2023, 11, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 feb *", time.Time{}},
+		{"@hourly", time.Date(2023, 11, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, 11, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2023, 11, 19, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h30m", time.Date(2023, 11, 15, 12, 0, 15, 0, time.UTC)},
+	} {
+		c, err := parseCron(test.spec)
+		require.NoError(t, err, test.spec)
+		assert.Equal(t, test.want, c.next(start), test.spec)
+	}
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+)
+
+// Types of Event
+const (
+	EventStart  = "start"
+	EventFinish = "finish"
+)
+
+// Event describes a job starting or finishing
+type Event struct {
+	Type      string    `json:"type"` // EventStart or EventFinish
+	ID        int64     `json:"id"`
+	Group     string    `json:"group"`
+	Call      string    `json:"call,omitempty"`
+	Schedule  string    `json:"schedule,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Finished  bool      `json:"finished"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error"`
+	Duration  float64   `json:"duration"`
+}
+
+var (
+	eventListenersMu sync.RWMutex
+	eventListeners   []*func(Event)
+)
+
+// OnEvent adds a listener which will be called when any job starts or
+// finishes. It returns a function to remove the listener.
+//
+// The listener is called synchronously so should not block.
+func OnEvent(fn func(Event)) (remove func()) {
+	eventListenersMu.Lock()
+	defer eventListenersMu.Unlock()
+	eventListeners = append(eventListeners, &fn)
+	return func() {
+		eventListenersMu.Lock()
+		defer eventListenersMu.Unlock()
+		for i, listener := range eventListeners {
+			if listener == &fn {
+				eventListeners = append(eventListeners[:i:i], eventListeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// sendEvent tells the listeners about the state of job
+func sendEvent(eventType string, job *Job) {
+	eventListenersMu.RLock()
+	listeners := eventListeners
+	eventListenersMu.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+	job.mu.Lock()
+	event := Event{
+		Type:      eventType,
+		ID:        job.ID,
+		Group:     job.Group,
+		Call:      job.call,
+		Schedule:  job.schedule,
+		StartTime: job.StartTime,
+		EndTime:   job.EndTime,
+		Finished:  job.Finished,
+		Success:   job.Success,
+		Error:     job.Error,
+		Duration:  job.Duration,
+	}
+	job.mu.Unlock()
+	for _, listener := range listeners {
+		(*listener)(event)
+	}
+}
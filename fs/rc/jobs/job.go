@@ -39,6 +39,13 @@ type Job struct {
 	Stop      func()    `json:"-"`
 	listeners []*func()
 
+	input     rc.Params // the parameters the job was started with
+	call      string    // the rc call the job is running if known
+	schedule  string    // the name of the schedule which started the job if any
+	stats     rc.Params // the stats of the job when it finished if stored
+	log       *jobLog   // the log messages of the job - may be nil
+	namespace string    // the config namespace the job was started in if any
+
 	// realErr is the Error before printing it as a string, it's used to return
 	// the real error to the upper application layers while still printing the
 	// string error message.
@@ -74,12 +81,6 @@ func (job *Job) finish(out rc.Params, err error) {
 	running.kickExpire() // make sure this job gets expired
 }
 
-func (job *Job) addListener(fn *func()) {
-	job.mu.Lock()
-	defer job.mu.Unlock()
-	job.listeners = append(job.listeners, fn)
-}
-
 func (job *Job) removeListener(fn *func()) {
 	job.mu.Lock()
 	defer job.mu.Unlock()
@@ -94,14 +95,51 @@ func (job *Job) removeListener(fn *func()) {
 // OnFinish adds listener to job that will be triggered when job is finished.
 // It returns a function to cancel listening.
 func (job *Job) OnFinish(fn func()) func() {
-	if job.Finished {
+	job.mu.Lock()
+	finished := job.Finished
+	if !finished {
+		job.listeners = append(job.listeners, &fn)
+	}
+	job.mu.Unlock()
+	if finished {
 		fn()
-	} else {
-		job.addListener(&fn)
 	}
 	return func() { job.removeListener(&fn) }
 }
 
+// record returns the state of the job for storing
+func (job *Job) record() *jobRecord {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return &jobRecord{
+		ID:        job.ID,
+		ExecuteID: executeID,
+		Group:     job.Group,
+		Namespace: job.namespace,
+		Call:      job.call,
+		Schedule:  job.schedule,
+		StartTime: job.StartTime,
+		EndTime:   job.EndTime,
+		Error:     job.Error,
+		Finished:  job.Finished,
+		Success:   job.Success,
+		Duration:  job.Duration,
+		Input:     job.input,
+		Output:    job.Output,
+		Stats:     job.stats,
+		Logs:      job.log.all(),
+	}
+}
+
+// inNamespace returns true if something in namespace can be seen
+// from ctx.
+//
+// Everything can be seen from a ctx without a namespace.
+func inNamespace(ctx context.Context, namespace string) bool {
+	ctxNamespace := fs.GetNamespace(ctx)
+	return ctxNamespace == "" || ctxNamespace == namespace
+}
+
 // run the job until completion writing the return status
 func (job *Job) run(ctx context.Context, fn rc.Func, in rc.Params) {
 	defer func() {
@@ -118,6 +156,9 @@ type Jobs struct {
 	jobs          map[int64]*Job
 	opt           *rc.Options
 	expireRunning bool
+	store         *store     // if set, jobs are persisted here
+	limits        *limits    // concurrency limits of the groups
+	scheduler     *scheduler // jobs to run periodically
 }
 
 var (
@@ -128,10 +169,13 @@ var (
 
 // newJobs makes a new Jobs structure
 func newJobs() *Jobs {
-	return &Jobs{
-		jobs: map[int64]*Job{},
-		opt:  &rc.DefaultOpt,
+	jobs := &Jobs{
+		jobs:   map[int64]*Job{},
+		opt:    &rc.DefaultOpt,
+		limits: newLimits(),
 	}
+	jobs.scheduler = newScheduler(jobs)
+	return jobs
 }
 
 // SetOpt sets the options when they are known
@@ -168,6 +212,9 @@ func (jobs *Jobs) Expire() {
 		}
 		job.mu.Unlock()
 	}
+	if jobs.store != nil && jobs.opt.JobHistoryMaxAge > 0 {
+		go jobs.store.prune(now.Add(-jobs.opt.JobHistoryMaxAge))
+	}
 	if len(jobs.jobs) != 0 {
 		time.AfterFunc(jobs.opt.JobExpireInterval, jobs.Expire)
 		jobs.expireRunning = true
@@ -194,6 +241,16 @@ func (jobs *Jobs) Get(ID int64) *Job {
 	return jobs.jobs[ID]
 }
 
+// getIn gets the job with ID if it can be seen from ctx or nil if
+// it doesn't exist or can't be seen.
+func (jobs *Jobs) getIn(ctx context.Context, ID int64) *Job {
+	job := jobs.Get(ID)
+	if job == nil || !inNamespace(ctx, job.namespace) {
+		return nil
+	}
+	return job
+}
+
 // Check to see if the group is set
 func getGroup(ctx context.Context, in rc.Params, id int64) (context.Context, string, error) {
 	group, err := in.GetString("_group")
@@ -216,8 +273,9 @@ func getAsync(ctx context.Context, in rc.Params) (context.Context, bool, error)
 	}
 	delete(in, "_async") // remove the async parameter after parsing
 	if isAsync {
-		// unlink this job from the current context
-		ctx = context.Background()
+		// unlink this job from the current context keeping
+		// only its namespace
+		ctx = fs.CopyNamespace(context.Background(), ctx)
 	}
 	return ctx, isAsync, nil
 }
@@ -263,8 +321,27 @@ type jobKeyType struct{}
 var jobKey = jobKeyType{}
 
 // NewJob creates a Job and executes it, possibly in the background if _async is set
+//
+// As the rc call fn is part of isn't known its input is treated as
+// needing authorisation when stored. Use NewCallJob if it is known.
 func (jobs *Jobs) NewJob(ctx context.Context, fn rc.Func, in rc.Params) (job *Job, out rc.Params, err error) {
+	return jobs.newJob(ctx, &rc.Call{Fn: fn, AuthRequired: true}, in, "")
+}
+
+// NewCallJob creates a Job running call and executes it, possibly in
+// the background if _async is set
+func (jobs *Jobs) NewCallJob(ctx context.Context, call *rc.Call, in rc.Params) (job *Job, out rc.Params, err error) {
+	return jobs.newJob(ctx, call, in, "")
+}
+
+// newJob creates a Job running the rc call and executes it, possibly
+// in the background if _async is set.
+//
+// schedule is the name of the schedule starting the job if any.
+func (jobs *Jobs) newJob(ctx context.Context, call *rc.Call, in rc.Params, schedule string) (job *Job, out rc.Params, err error) {
 	id := jobID.Add(1)
+	fn := call.Fn
+	input := redactInput(call, in)
 	in = in.Copy() // copy input so we can change it
 
 	ctx, isAsync, err := getAsync(ctx, in)
@@ -298,34 +375,99 @@ func (jobs *Jobs) NewJob(ctx context.Context, fn rc.Func, in rc.Params) (job *Jo
 		Group:     group,
 		StartTime: time.Now(),
 		Stop:      stop,
+		input:     input,
+		call:      call.Path,
+		schedule:  schedule,
+		log:       newJobLog(jobs.opt.JobLogLines),
+		namespace: fs.GetNamespace(ctx),
 	}
 
 	jobs.mu.Lock()
 	jobs.jobs[job.ID] = job
 	jobs.mu.Unlock()
+	jobs.saveJob(job)
+	sendEvent(EventStart, job)
 
 	// Add the job to the context
 	ctx = context.WithValue(ctx, jobKey, job)
 
+	// Run the job when there is space in its group
+	run := func() {
+		defer job.log.close()
+		release, err := jobs.limits.acquire(ctx, group)
+		if err != nil {
+			job.finish(nil, err)
+		} else {
+			job.run(ctx, fn, in)
+			release()
+		}
+		if jobs.store != nil {
+			stats, err := accounting.Stats(ctx).RemoteStats()
+			if err == nil {
+				job.mu.Lock()
+				job.stats = stats
+				job.mu.Unlock()
+			}
+		}
+		jobs.saveJob(job)
+		sendEvent(EventFinish, job)
+	}
+
 	if isAsync {
-		go job.run(ctx, fn, in)
+		go run()
 		out = make(rc.Params)
 		out["jobid"] = job.ID
 		err = nil
 	} else {
-		job.run(ctx, fn, in)
+		run()
 		out = job.Output
 		err = job.realErr
 	}
 	return job, out, err
 }
 
+// saveJob writes the job to the store if there is one
+func (jobs *Jobs) saveJob(job *Job) {
+	if jobs.store == nil {
+		return
+	}
+	err := jobs.store.saveJob(job.record())
+	if err != nil {
+		fs.Errorf(nil, "%v", err)
+	}
+}
+
+// getRecord returns the state of job ID from memory or from the store
+// returning nil if it wasn't found or can't be seen from ctx.
+func (jobs *Jobs) getRecord(ctx context.Context, ID int64) (*jobRecord, error) {
+	if job := jobs.Get(ID); job != nil {
+		if !inNamespace(ctx, job.namespace) {
+			return nil, nil
+		}
+		return job.record(), nil
+	}
+	if jobs.store == nil {
+		return nil, nil
+	}
+	record, err := jobs.store.loadJob(ID)
+	if err != nil || record == nil || !inNamespace(ctx, record.Namespace) {
+		return nil, err
+	}
+	return record, nil
+}
+
 // NewJob creates a Job and executes it on the global job queue,
 // possibly in the background if _async is set
 func NewJob(ctx context.Context, fn rc.Func, in rc.Params) (job *Job, out rc.Params, err error) {
 	return running.NewJob(ctx, fn, in)
 }
 
+// NewCallJob creates a Job running call and executes it on the global
+// job queue, possibly in the background if _async is set
+func NewCallJob(ctx context.Context, call *rc.Call, in rc.Params) (job *Job, out rc.Params, err error) {
+	return running.NewCallJob(ctx, call, in)
+}
+
 // OnFinish adds listener to jobid that will be triggered when job is finished.
 // It returns a function to cancel listening.
 func OnFinish(jobID int64, fn func()) (func(), error) {
@@ -372,6 +514,10 @@ Results:
 - success - boolean - true for success false otherwise
 - output - output of the job as would have been returned if called synchronously
 - progress - output of the progress related to the underlying job
+
+If --rc-job-store is set then the status of jobs which have expired
+is read from the job store. These have the same fields as the results
+of job/history.
 `,
 	})
 }
@@ -382,9 +528,9 @@ func rcJobStatus(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if err != nil {
 		return nil, err
 	}
-	job := running.Get(jobID)
+	job := running.getIn(ctx, jobID)
 	if job == nil {
-		return nil, errors.New("job not found")
+		return rcStoredJobStatus(ctx, jobID)
 	}
 	job.mu.Lock()
 	defer job.mu.Unlock()
@@ -396,6 +542,27 @@ func rcJobStatus(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+// Returns the status of a job which is no longer in memory
+func rcStoredJobStatus(ctx context.Context, jobID int64) (out rc.Params, err error) {
+	if running.store == nil {
+		return nil, errors.New("job not found")
+	}
+	record, err := running.getRecord(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors.New("job not found")
+	}
+	record.Logs = nil // read these with job/logs
+	out = make(rc.Params)
+	err = rc.Reshape(&out, record)
+	if err != nil {
+		return nil, fmt.Errorf("reshape failed in job status: %w", err)
+	}
+	return out, nil
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:  "job/list",
@@ -414,7 +581,13 @@ Results:
 // Returns list of job ids.
 func rcJobList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out = make(rc.Params)
-	out["jobids"] = running.IDs()
+	jobIDs := []int64{}
+	for _, ID := range running.IDs() {
+		if running.getIn(ctx, ID) != nil {
+			jobIDs = append(jobIDs, ID)
+		}
+	}
+	out["jobids"] = jobIDs
 	out["executeId"] = executeID
 	return out, nil
 }
@@ -437,7 +610,7 @@ func rcJobStop(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	if err != nil {
 		return nil, err
 	}
-	job := running.Get(jobID)
+	job := running.getIn(ctx, jobID)
 	if job == nil {
 		return nil, errors.New("job not found")
 	}
@@ -469,7 +642,7 @@ func rcGroupStop(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	running.mu.RLock()
 	defer running.mu.RUnlock()
 	for _, job := range running.jobs {
-		if job.Group == group {
+		if job.Group == group && inNamespace(ctx, job.namespace) {
 			job.mu.Lock()
 			job.Stop()
 			job.mu.Unlock()
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -537,6 +539,34 @@ func TestOnFinish(t *testing.T) {
 	}
 }
 
+// Check listeners added while the job is finishing are called exactly
+// once - run with -race
+func TestOnFinishWhileFinishing(t *testing.T) {
+	jobID.Store(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	job, _, err := NewJob(ctx, ctxParmFn(ctx, false), rc.Params{"_async": true})
+	require.NoError(t, err)
+
+	const n = 100
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			job.OnFinish(func() {
+				calls.Add(1)
+				wg.Done()
+			})
+		}()
+		if i == n/2 {
+			cancel()
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, int32(n), calls.Load())
+}
+
 func TestOnFinishAlreadyFinished(t *testing.T) {
 	jobID.Store(0)
 	done := make(chan struct{})
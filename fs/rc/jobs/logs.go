@@ -0,0 +1,279 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// The log messages of a job are captured by the log hook finding the
+// job in the context the message was logged with. The context of the
+// job is passed to everything the job calls so this captures the
+// messages logged with fs.InfofCtx and friends. These are used for the
+// outcome of each file operation, e.g. a file being copied or
+// deleted or failing to be, and the errors which stop a sync. Other
+// messages, for example from backends, are only in the global log.
+
+func init() {
+	fs.AddLogHook(logHook)
+}
+
+// logHook adds the message to the log of the job which logged it if any
+func logHook(ctx context.Context, level fs.LogLevel, text string) {
+	job, ok := GetJob(ctx)
+	if ok && job.log != nil {
+		job.log.add(level, text)
+	}
+}
+
+// maxLogWait is the longest job/logs will wait for messages so a
+// call can't tie up the rc server indefinitely
+const maxLogWait = time.Minute
+
+// logLine is a message logged by a job
+type logLine struct {
+	Seq   int64     `json:"seq"`
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Text  string    `json:"text"`
+}
+
+// jobLog keeps the last messages logged by a job
+type jobLog struct {
+	mu       sync.Mutex
+	maxLines int
+	lines    []logLine
+	seq      int64         // Seq of the last line added
+	changed  chan struct{} // closed when a line is added or the log is closed
+	closed   bool          // set when the job has finished
+}
+
+// newJobLog makes a log which keeps maxLines lines or returns nil if
+// maxLines is 0 or less
+func newJobLog(maxLines int) *jobLog {
+	if maxLines <= 0 {
+		return nil
+	}
+	return &jobLog{
+		maxLines: maxLines,
+		changed:  make(chan struct{}),
+	}
+}
+
+// add a line to the log
+func (l *jobLog) add(level fs.LogLevel, text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	l.lines = append(l.lines, logLine{
+		Seq:   l.seq,
+		Time:  time.Now(),
+		Level: level.String(),
+		Text:  text,
+	})
+	// Trim the lines occasionally rather than on every add
+	if len(l.lines) >= 2*l.maxLines {
+		n := copy(l.lines, l.lines[len(l.lines)-l.maxLines:])
+		l.lines = l.lines[:n]
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// close the log when the job has finished
+func (l *jobLog) close() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.closed {
+		l.closed = true
+		close(l.changed)
+	}
+}
+
+// _all returns the lines kept
+//
+// Call with the lock held.
+func (l *jobLog) _all() []logLine {
+	if len(l.lines) > l.maxLines {
+		return l.lines[len(l.lines)-l.maxLines:]
+	}
+	return l.lines
+}
+
+// all returns a copy of the lines kept
+func (l *jobLog) all() []logLine {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]logLine(nil), l._all()...)
+}
+
+// get returns the lines which match the filter, waiting up to wait
+// for some if there aren't any and the job hasn't finished.
+func (l *jobLog) get(ctx context.Context, filter logFilter, wait time.Duration) (lines []logLine, last int64) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		l.mu.Lock()
+		lines = filter.apply(l._all())
+		last, closed, changed := l.seq, l.closed, l.changed
+		l.mu.Unlock()
+		if len(lines) > 0 || closed || wait <= 0 {
+			return lines, last
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return lines, last
+		case <-ctx.Done():
+			return lines, last
+		}
+	}
+}
+
+// logFilter selects log lines
+type logFilter struct {
+	level fs.LogLevel // only lines at this level or more severe
+	since int64       // only lines with a Seq greater than this
+	tail  int         // only the last tail lines if > 0
+}
+
+// apply the filter returning a copy of the lines which match
+func (filter logFilter) apply(lines []logLine) (out []logLine) {
+	out = []logLine{}
+	for _, line := range lines {
+		if line.Seq <= filter.since {
+			continue
+		}
+		var level fs.LogLevel
+		if level.Set(line.Level) == nil && level > filter.level {
+			continue
+		}
+		out = append(out, line)
+	}
+	if filter.tail > 0 && len(out) > filter.tail {
+		out = out[len(out)-filter.tail:]
+	}
+	return out
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "job/logs",
+		Fn:    rcJobLogs,
+		Title: "Reads the log messages of the job ID",
+		Help: `Parameters:
+
+- jobid - id of the job (integer).
+- level - only return messages at this level or more severe, e.g. "INFO" (string, optional, default "DEBUG")
+- since - only return messages with a seq greater than this (integer, optional)
+- tail - only return the last this many messages (integer, optional)
+- wait - if there are no messages to return and the job is running,
+  wait up to this long for some, e.g. "30s", at most "1m" (duration, optional)
+
+This returns the messages logged with the context of the job. These
+say what happened to each file the job operated on, e.g. whether it
+was copied, moved or deleted or failed to be, and the errors which
+stopped the job. Other messages, such as debug messages and those
+logged by backends, are only in the global log. Only messages at or
+above the --log-level of the job are logged so to see INFO messages
+rclone must be run with -v or the job started with
+_config={"LogLevel": "INFO"}.
+
+The last --rc-job-log-lines messages of each job are kept. If
+--rc-job-store is set then these are stored with the job and can be
+read after the job has finished.
+
+To follow the log of a running job, call this repeatedly with since
+set to the last returned and wait set.
+
+Results:
+
+- lines - array of messages with
+    - seq - sequence number of the message, starting from 1
+    - time - time the message was logged
+    - level - level of the message, e.g. "ERROR"
+    - text - the message
+- last - seq of the last message logged by the job
+- finished - boolean whether the job has finished or not
+`,
+	})
+}
+
+// Returns the log of a job
+func rcJobLogs(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	jobID, err := in.GetInt64("jobid")
+	if err != nil {
+		return nil, err
+	}
+	filter := logFilter{level: fs.LogLevelDebug}
+	levelString, err := in.GetString("level")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if levelString != "" {
+		err = filter.level.Set(levelString)
+		if err != nil {
+			return nil, rc.NewErrParamInvalid(err)
+		}
+	}
+	filter.since, err = in.GetInt64("since")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	tail, err := in.GetInt64("tail")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	filter.tail = int(tail)
+	wait, err := in.GetDuration("wait")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if wait > maxLogWait {
+		wait = maxLogWait
+	}
+
+	var (
+		lines    []logLine
+		last     int64
+		finished bool
+	)
+	if job := running.getIn(ctx, jobID); job != nil {
+		if job.log == nil {
+			return nil, errors.New("job logs are disabled with --rc-job-log-lines 0")
+		}
+		lines, last = job.log.get(ctx, filter, wait)
+		job.mu.Lock()
+		finished = job.Finished
+		job.mu.Unlock()
+	} else {
+		record, err := running.getRecord(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if record == nil {
+			return nil, errors.New("job not found")
+		}
+		lines = filter.apply(record.Logs)
+		if n := len(record.Logs); n > 0 {
+			last = record.Logs[n-1].Seq
+		}
+		finished = record.Finished
+	}
+	out = rc.Params{
+		"lines":    lines,
+		"last":     last,
+		"finished": finished,
+	}
+	return out, nil
+}
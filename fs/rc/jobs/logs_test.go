@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobLogTrim(t *testing.T) {
+	assert.Nil(t, newJobLog(0))
+	l := newJobLog(3)
+	for i := 1; i <= 10; i++ {
+		l.add(fs.LogLevelNotice, fmt.Sprint(i))
+	}
+	lines := l.all()
+	require.Equal(t, 3, len(lines))
+	assert.Equal(t, int64(8), lines[0].Seq)
+	assert.Equal(t, "10", lines[2].Text)
+	assert.True(t, len(l.lines) < 6)
+}
+
+func TestLogFilter(t *testing.T) {
+	lines := []logLine{
+		{Seq: 1, Level: "ERROR", Text: "one"},
+		{Seq: 2, Level: "NOTICE", Text: "two"},
+		{Seq: 3, Level: "DEBUG", Text: "three"},
+		{Seq: 4, Level: "ERROR", Text: "four"},
+	}
+	texts := func(lines []logLine) (out []string) {
+		out = []string{}
+		for _, line := range lines {
+			out = append(out, line.Text)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"one", "two", "three", "four"}, texts(logFilter{level: fs.LogLevelDebug}.apply(lines)))
+	assert.Equal(t, []string{"one", "four"}, texts(logFilter{level: fs.LogLevelError}.apply(lines)))
+	assert.Equal(t, []string{"three", "four"}, texts(logFilter{level: fs.LogLevelDebug, since: 2}.apply(lines)))
+	assert.Equal(t, []string{"four"}, texts(logFilter{level: fs.LogLevelDebug, tail: 1}.apply(lines)))
+}
+
+func TestJobLogs(t *testing.T) {
+	ctx := context.Background()
+	start := make(chan struct{})
+	fn := func(ctx context.Context, in rc.Params) (rc.Params, error) {
+		<-start
+		name := in["name"].(string)
+		fs.LogfCtx(ctx, nil, "%s: first", name)
+		// Messages logged without the context of the job aren't captured
+		fs.Logf(nil, "%s: without context", name)
+		// Check goroutines started by the job are captured too
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fs.ErrorfCtx(ctx, nil, "%s: from goroutine", name)
+		}()
+		wg.Wait()
+		return rc.Params{}, nil
+	}
+	job1, _, err := NewJob(ctx, fn, rc.Params{"_async": true, "name": "job1"})
+	require.NoError(t, err)
+	job2, _, err := NewJob(ctx, fn, rc.Params{"_async": true, "name": "job2"})
+	require.NoError(t, err)
+
+	// Wait for messages while the jobs are running
+	call := rc.Calls.Get("job/logs")
+	require.NotNil(t, call)
+	var out rc.Params
+	done := make(chan struct{})
+	go func() {
+		out, err = call.Fn(ctx, rc.Params{"jobid": job1.ID, "wait": "10s"})
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(start)
+	<-done
+	require.NoError(t, err)
+	lines := out["lines"].([]logLine)
+	require.NotEqual(t, 0, len(lines))
+	assert.Equal(t, "job1: first", lines[0].Text)
+	<-waitFinished(job1)
+	<-waitFinished(job2)
+
+	for _, job := range []*Job{job1, job2} {
+		name := fmt.Sprintf("job%d", job.ID-job1.ID+1)
+		out, err = call.Fn(ctx, rc.Params{"jobid": job.ID})
+		require.NoError(t, err)
+		lines = out["lines"].([]logLine)
+		require.Equal(t, 2, len(lines))
+		assert.Equal(t, name+": first", lines[0].Text)
+		assert.Equal(t, "NOTICE", lines[0].Level)
+		assert.Equal(t, name+": from goroutine", lines[1].Text)
+		assert.Equal(t, "ERROR", lines[1].Level)
+		assert.Equal(t, int64(2), out["last"])
+		assert.Equal(t, true, out["finished"])
+
+		out, err = call.Fn(ctx, rc.Params{"jobid": job.ID, "level": "error"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(out["lines"].([]logLine)))
+
+		// Check waiting returns straight away when the job has finished
+		out, err = call.Fn(ctx, rc.Params{"jobid": job.ID, "since": 2, "wait": "1h"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(out["lines"].([]logLine)))
+	}
+
+	_, err = call.Fn(ctx, rc.Params{"jobid": job1.ID, "level": "potato"})
+	assert.Error(t, err)
+	_, err = call.Fn(ctx, rc.Params{"jobid": 123456789})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"regexp"
+
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// redactedValue replaces the values which aren't kept
+const redactedValue = "XXX"
+
+// secretKey matches the names of parameters which may hold secrets
+var secretKey = regexp.MustCompile(`(?i)pass|secret|token|key|credential|auth`)
+
+// keptKeys are the parameters of calls needing authorisation which
+// are kept as they only control how the job is run
+var keptKeys = map[string]bool{
+	"_async":  true,
+	"_group":  true,
+	"_config": true,
+	"_filter": true,
+}
+
+// redactInput returns a copy of the input of call suitable for storing
+// and for showing in job/status and job/history.
+//
+// The parameters of calls which need authorisation, such as
+// config/create and core/command, may contain passwords and
+// credentials in connection strings so only their names are kept.
+// Parameters of other calls which look like they hold secrets are
+// redacted too.
+func redactInput(call *rc.Call, in rc.Params) rc.Params {
+	return redactParams(in, call.AuthRequired)
+}
+
+// redactParams returns a copy of in with secrets redacted, or all the
+// values apart from keptKeys if all is set.
+func redactParams(in rc.Params, all bool) rc.Params {
+	out := make(rc.Params, len(in))
+	for key, value := range in {
+		switch {
+		case key == "_request" || key == "_response":
+			// added by the rc server for the call
+		case secretKey.MatchString(key), all && !keptKeys[key]:
+			out[key] = redactedValue
+		default:
+			out[key] = redactValue(value)
+		}
+	}
+	return out
+}
+
+// redactValue redacts the secrets in value if it is a map
+func redactValue(value interface{}) interface{} {
+	switch x := value.(type) {
+	case rc.Params:
+		return redactParams(x, false)
+	case map[string]interface{}:
+		return redactParams(x, false)
+	}
+	return value
+}
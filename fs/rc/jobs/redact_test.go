@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactInput(t *testing.T) {
+	in := rc.Params{
+		"fs":       ":s3,secret_access_key=secret:bucket",
+		"remote":   "file.txt",
+		"_async":   true,
+		"_config":  rc.Params{"Transfers": 8, "BearerToken": "token"},
+		"_request": "request",
+		"opt": map[string]interface{}{
+			"pass":  "secret",
+			"count": 1,
+		},
+	}
+
+	// Calls needing authorisation only keep the names
+	got := redactInput(&rc.Call{AuthRequired: true}, in)
+	assert.Equal(t, rc.Params{
+		"fs":      "XXX",
+		"remote":  "XXX",
+		"_async":  true,
+		"_config": rc.Params{"Transfers": 8, "BearerToken": "XXX"},
+		"opt":     "XXX",
+	}, got)
+
+	// Other calls only have the secrets removed
+	got = redactInput(&rc.Call{}, in)
+	assert.Equal(t, rc.Params{
+		"fs":      ":s3,secret_access_key=secret:bucket",
+		"remote":  "file.txt",
+		"_async":  true,
+		"_config": rc.Params{"Transfers": 8, "BearerToken": "XXX"},
+		"opt": rc.Params{
+			"pass":  "XXX",
+			"count": 1,
+		},
+	}, got)
+
+	// The input isn't changed
+	assert.Equal(t, "secret", in["opt"].(map[string]interface{})["pass"])
+}
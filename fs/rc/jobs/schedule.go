@@ -0,0 +1,635 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// limit is the concurrency limit of a group of jobs
+type limit struct {
+	concurrency int             // max number of jobs to run at once - 0 for unlimited
+	running     int             // number of jobs running
+	waiters     []chan struct{} // jobs waiting to run in order
+}
+
+// limits controls how many jobs run at once in each group
+type limits struct {
+	mu     sync.Mutex
+	groups map[string]*limit
+}
+
+// newLimits makes a new limits with no groups limited
+func newLimits() *limits {
+	return &limits{
+		groups: map[string]*limit{},
+	}
+}
+
+// acquire waits until a job may run in group or ctx is cancelled
+//
+// If it returns no error then release must be called when the job has
+// finished.
+func (l *limits) acquire(ctx context.Context, group string) (release func(), err error) {
+	release = func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lim := l.groups[group]
+		lim.running--
+		l._wake(group, lim)
+	}
+	l.mu.Lock()
+	lim := l.groups[group]
+	if lim == nil {
+		lim = &limit{}
+		l.groups[group] = lim
+	}
+	if lim.concurrency <= 0 || lim.running < lim.concurrency {
+		lim.running++
+		l.mu.Unlock()
+		return release, nil
+	}
+	wait := make(chan struct{})
+	lim.waiters = append(lim.waiters, wait)
+	concurrency := lim.concurrency
+	l.mu.Unlock()
+	fs.Debugf(nil, "Job waiting for one of the %d jobs in group %q to finish", concurrency, group)
+	select {
+	case <-wait:
+		return release, nil
+	case <-ctx.Done():
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, waiter := range lim.waiters {
+		if waiter == wait {
+			lim.waiters = append(lim.waiters[:i], lim.waiters[i+1:]...)
+			return nil, ctx.Err()
+		}
+	}
+	// We were woken as ctx was cancelled so give the slot back
+	lim.running--
+	l._wake(group, lim)
+	return nil, ctx.Err()
+}
+
+// _wake starts as many waiting jobs as the limit allows and removes
+// the limit if it is no longer needed.
+//
+// Call with the lock held.
+func (l *limits) _wake(group string, lim *limit) {
+	for len(lim.waiters) > 0 && (lim.concurrency <= 0 || lim.running < lim.concurrency) {
+		close(lim.waiters[0])
+		lim.waiters = lim.waiters[1:]
+		lim.running++
+	}
+	if lim.concurrency <= 0 && lim.running == 0 && len(lim.waiters) == 0 {
+		delete(l.groups, group)
+	}
+}
+
+// set the max number of jobs running at once in group - 0 for unlimited
+func (l *limits) set(group string, concurrency int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim := l.groups[group]
+	if lim == nil {
+		lim = &limit{}
+		l.groups[group] = lim
+	}
+	lim.concurrency = concurrency
+	l._wake(group, lim)
+}
+
+// get the concurrency limits of the groups which have them
+func (l *limits) get() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := map[string]int{}
+	for group, lim := range l.groups {
+		if lim.concurrency > 0 {
+			out[group] = lim.concurrency
+		}
+	}
+	return out
+}
+
+// schedule describes an rc call run periodically
+type schedule struct {
+	Name      string    `json:"name"`      // name of the schedule
+	Cron      string    `json:"cron"`      // cron expression saying when to run
+	Command   string    `json:"command"`   // rc command to run
+	Params    rc.Params `json:"params"`    // parameters for the command
+	Group     string    `json:"group"`     // group to run the jobs in
+	Next      time.Time `json:"next"`      // time of the next run
+	LastJobID int64     `json:"lastJobId"` // ID of the last job started or 0
+
+	cron  *cronSchedule
+	timer *time.Timer
+}
+
+// scheduler runs scheduled jobs
+type scheduler struct {
+	mu        sync.Mutex
+	jobs      *Jobs
+	schedules map[string]*schedule
+}
+
+// newScheduler makes a scheduler starting jobs in jobs
+func newScheduler(jobs *Jobs) *scheduler {
+	return &scheduler{
+		jobs:      jobs,
+		schedules: map[string]*schedule{},
+	}
+}
+
+// add the schedule replacing any with the same name returning the
+// time it will next run
+func (s *scheduler) add(sch *schedule) (next time.Time, err error) {
+	if sch.Name == "" {
+		return next, errors.New("schedule needs a name")
+	}
+	sch.cron, err = parseCron(sch.Cron)
+	if err != nil {
+		return next, err
+	}
+	call := rc.Calls.Get(sch.Command)
+	if call == nil {
+		return next, fmt.Errorf("couldn't find command %q", sch.Command)
+	}
+	if call.NeedsRequest || call.NeedsResponse {
+		return next, fmt.Errorf("command %q can't be scheduled", sch.Command)
+	}
+	if sch.Params == nil {
+		sch.Params = rc.Params{}
+	}
+	if sch.Group == "" {
+		sch.Group = sch.Name
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old := s.schedules[sch.Name]; old != nil {
+		old._stop()
+	}
+	s.schedules[sch.Name] = sch
+	s._arm(sch, time.Now())
+	return sch.Next, nil
+}
+
+// remove the schedule called name
+func (s *scheduler) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sch := s.schedules[name]
+	if sch == nil {
+		return errors.New("schedule not found")
+	}
+	sch._stop()
+	delete(s.schedules, name)
+	return nil
+}
+
+// list returns copies of the schedules sorted by name
+func (s *scheduler) list() (out []*schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out = []*schedule{}
+	for _, sch := range s.schedules {
+		schCopy := *sch
+		out = append(out, &schCopy)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// _stop the timer of the schedule
+//
+// Call with the scheduler lock held.
+func (sch *schedule) _stop() {
+	if sch.timer != nil {
+		sch.timer.Stop()
+	}
+}
+
+// _arm sets the timer to run sch at its next time after now
+//
+// Call with the lock held.
+func (s *scheduler) _arm(sch *schedule, now time.Time) {
+	sch.Next = sch.cron.next(now)
+	if sch.Next.IsZero() {
+		sch.timer = nil
+		return
+	}
+	sch.timer = time.AfterFunc(sch.Next.Sub(now), func() {
+		s.run(sch)
+	})
+}
+
+// run starts the job for sch and arms the timer for the next one
+func (s *scheduler) run(sch *schedule) {
+	s.mu.Lock()
+	if s.schedules[sch.Name] != sch {
+		// schedule was removed or replaced
+		s.mu.Unlock()
+		return
+	}
+	s._arm(sch, time.Now())
+	in := sch.Params.Copy()
+	in["_async"] = true
+	in["_group"] = sch.Group
+	s.mu.Unlock()
+
+	call := rc.Calls.Get(sch.Command)
+	if call == nil {
+		fs.Errorf(nil, "Schedule %q: couldn't find command %q", sch.Name, sch.Command)
+		return
+	}
+	fs.Infof(nil, "Schedule %q: starting %q", sch.Name, sch.Command)
+	job, _, err := s.jobs.newJob(context.Background(), call, in, sch.Name)
+	if err != nil {
+		fs.Errorf(nil, "Schedule %q: failed to start %q: %v", sch.Name, sch.Command, err)
+		return
+	}
+	s.mu.Lock()
+	sch.LastJobID = job.ID
+	s.mu.Unlock()
+	s.jobs.saveState()
+}
+
+// saveState writes the schedules and concurrency limits to the store
+// if there is one
+func (jobs *Jobs) saveState() {
+	if jobs.store == nil {
+		return
+	}
+	err := jobs.store.saveState(&jobsState{
+		Schedules:   jobs.scheduler.list(),
+		Concurrency: jobs.limits.get(),
+	})
+	if err != nil {
+		fs.Errorf(nil, "%v", err)
+	}
+}
+
+// OpenStore stores the jobs, their history and schedules in dir and
+// loads any stored there already.
+//
+// This should be called before any jobs are started.
+func OpenStore(dir string) error {
+	return running.openStore(dir)
+}
+
+// openStore stores the jobs in dir and loads the state from it
+func (jobs *Jobs) openStore(dir string) error {
+	st, err := newStore(dir)
+	if err != nil {
+		return err
+	}
+	records, err := st.loadJobs()
+	if err != nil {
+		return err
+	}
+	var maxID int64
+	for _, record := range records {
+		if record.ID > maxID {
+			maxID = record.ID
+		}
+		if !record.Finished {
+			record.Finished = true
+			record.EndTime = time.Now()
+			record.Duration = record.EndTime.Sub(record.StartTime).Seconds()
+			record.Error = "job interrupted: rclone stopped before it finished"
+			err = st.saveJob(record)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Carry on numbering jobs after the stored ones
+	for {
+		ID := jobID.Load()
+		if ID >= maxID || jobID.CompareAndSwap(ID, maxID) {
+			break
+		}
+	}
+	state, err := st.loadState()
+	if err != nil {
+		return err
+	}
+	jobs.store = st
+	for group, concurrency := range state.Concurrency {
+		jobs.limits.set(group, concurrency)
+	}
+	for _, sch := range state.Schedules {
+		_, err = jobs.scheduler.add(sch)
+		if err != nil {
+			fs.Errorf(nil, "Failed to load schedule %q: %v", sch.Name, err)
+		}
+	}
+	if jobs.opt.JobHistoryMaxAge > 0 {
+		st.prune(time.Now().Add(-jobs.opt.JobHistoryMaxAge))
+	}
+	return nil
+}
+
+// history returns the records of the jobs in memory and in the store
+// which can be seen from ctx newest first
+func (jobs *Jobs) history(ctx context.Context) (records []*jobRecord, err error) {
+	byID := map[int64]*jobRecord{}
+	if jobs.store != nil {
+		for _, record := range jobs.store.records() {
+			byID[record.ID] = record
+		}
+	}
+	// Jobs in memory are more up to date than the stored ones
+	for _, ID := range jobs.IDs() {
+		if job := jobs.Get(ID); job != nil {
+			byID[ID] = job.record()
+		}
+	}
+	records = make([]*jobRecord, 0, len(byID))
+	for _, record := range byID {
+		if !inNamespace(ctx, record.Namespace) {
+			continue
+		}
+		record.Logs = nil // read these with job/logs
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID > records[j].ID })
+	return records, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "job/schedule",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           rcJobSchedule,
+		Title:        "Run an rc command periodically",
+		Help: `Parameters:
+
+- name - name of the schedule (string)
+- cron - when to run the command (string)
+- command - the rc command to run, e.g. "sync/sync" (string)
+- params - parameters for the command (object, optional)
+- group - group to run the jobs in (string, optional, default is the name)
+
+This starts the command as an async job each time the cron expression
+matches. If a schedule with the same name exists then it is replaced.
+
+The cron expression is in the standard 5 field "minute hour
+day-of-month month day-of-week" form, e.g. "30 2 * * 1-5" to run at
+02:30 local time on weekdays. Fields may contain lists, ranges, steps
+and the names of months and days, e.g. "0 */4 * jan-jun mon,fri". The
+descriptors "@yearly", "@monthly", "@weekly", "@daily" and "@hourly"
+and "@every DURATION", e.g. "@every 1h30m", may be used instead.
+
+The jobs started are recorded in job/history with the name of the
+schedule. To stop the jobs of a schedule overlapping, set the
+concurrency of its group to 1 with job/concurrency.
+
+If --rc-job-store is set then the schedules are stored and will be run
+when rclone is restarted.
+
+Results:
+
+- name - name of the schedule
+- next - time the command will next run
+`,
+	})
+}
+
+// Schedules a job
+func rcJobSchedule(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	sch := &schedule{}
+	sch.Name, err = in.GetString("name")
+	if err != nil {
+		return nil, err
+	}
+	sch.Cron, err = in.GetString("cron")
+	if err != nil {
+		return nil, err
+	}
+	sch.Command, err = in.GetString("command")
+	if err != nil {
+		return nil, err
+	}
+	err = in.GetStructMissingOK("params", &sch.Params)
+	if err != nil {
+		return nil, err
+	}
+	sch.Group, err = in.GetString("group")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	next, err := running.scheduler.add(sch)
+	if err != nil {
+		return nil, err
+	}
+	running.saveState()
+	out = rc.Params{
+		"name": sch.Name,
+		"next": next,
+	}
+	return out, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "job/unschedule",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           rcJobUnschedule,
+		Title:        "Remove a schedule made with job/schedule",
+		Help: `Parameters:
+
+- name - name of the schedule (string)
+
+This stops new jobs being started by the schedule. It doesn't stop
+any jobs already running.
+`,
+	})
+}
+
+// Removes a schedule
+func rcJobUnschedule(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	name, err := in.GetString("name")
+	if err != nil {
+		return nil, err
+	}
+	err = running.scheduler.remove(name)
+	if err != nil {
+		return nil, err
+	}
+	running.saveState()
+	return rc.Params{}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "job/schedules",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           rcJobSchedules,
+		Title:        "List the schedules made with job/schedule",
+		Help: `Parameters: None.
+
+Results:
+
+- schedules - array of schedules sorted by name with
+    - name - name of the schedule
+    - cron - when the command runs
+    - command - the rc command to run
+    - params - parameters for the command
+    - group - group the jobs run in
+    - next - time the command will next run
+    - lastJobId - id of the last job started or 0 if none
+`,
+	})
+}
+
+// Lists the schedules
+func rcJobSchedules(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	out = rc.Params{
+		"schedules": running.scheduler.list(),
+	}
+	return out, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "job/concurrency",
+		Global:       true,
+		AuthRequired: true,
+		Fn:           rcJobConcurrency,
+		Title:        "Set or read the concurrency limits of groups of jobs",
+		Help: `Parameters:
+
+- group - name of the group (string, optional)
+- concurrency - max number of jobs in the group to run at once, 0 for unlimited (integer, optional)
+
+If group and concurrency are set then this limits the number of jobs
+in the group which run at once. Further jobs in the group wait until
+one of the running jobs has finished. Jobs waiting to run can be
+stopped with job/stop as normal.
+
+If --rc-job-store is set then the limits are stored and will be used
+when rclone is restarted.
+
+Results:
+
+- concurrency - object with the limit of each group which has one
+`,
+	})
+}
+
+// Sets or reads the concurrency limits
+func rcJobConcurrency(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	group, err := in.GetString("group")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	concurrency, err := in.GetInt64("concurrency")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if group == "" {
+			return nil, errors.New("need group to set concurrency")
+		}
+		if concurrency < 0 {
+			return nil, errors.New("concurrency must be 0 or more")
+		}
+		running.limits.set(group, int(concurrency))
+		running.saveState()
+	}
+	out = rc.Params{
+		"concurrency": running.limits.get(),
+	}
+	return out, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "job/history",
+		Fn:    rcJobHistory,
+		Title: "Lists past and present jobs",
+		Help: `Parameters:
+
+- group - only show jobs in this group (string, optional)
+- schedule - only show jobs started by this schedule (string, optional)
+- since - only show jobs started at or after this time, e.g. "2023-11-01T00:00:00Z" (string, optional)
+- limit - max number of jobs to return, default 100 (integer, optional)
+
+This lists the jobs in memory, and if --rc-job-store is set, the jobs
+in the job store which are kept for --rc-job-history-max-age.
+
+Results:
+
+- jobs - array of jobs newest first with the fields of job/status and
+    - executeId - id of the rclone which ran the job
+    - call - the rc command the job ran if known
+    - schedule - name of the schedule which started the job if any
+    - input - the parameters the job was started with - the values of
+      the parameters of calls needing authorisation and of parameters
+      which look like secrets, such as "password", are replaced with
+      "XXX"
+    - stats - the stats of the job when it finished if --rc-job-store is set
+`,
+	})
+}
+
+// Lists the job history
+func rcJobHistory(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	group, err := in.GetString("group")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	scheduleName, err := in.GetString("schedule")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	var since time.Time
+	sinceString, err := in.GetString("since")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if sinceString != "" {
+		since, err = time.Parse(time.RFC3339Nano, sinceString)
+		if err != nil {
+			return nil, rc.NewErrParamInvalid(fmt.Errorf("bad since time: %w", err))
+		}
+	}
+	limit, err := in.GetInt64("limit")
+	if rc.IsErrParamNotFound(err) {
+		limit = 100
+	} else if err != nil {
+		return nil, err
+	}
+	records, err := running.history(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobs := []*jobRecord{}
+	for _, record := range records {
+		if int64(len(jobs)) >= limit {
+			break
+		}
+		if (group != "" && record.Group != group) ||
+			(scheduleName != "" && record.Schedule != scheduleName) ||
+			record.StartTime.Before(since) {
+			continue
+		}
+		jobs = append(jobs, record)
+	}
+	out = rc.Params{
+		"jobs": jobs,
+	}
+	return out, nil
+}
@@ -0,0 +1,279 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	l := newLimits()
+
+	// Unlimited groups are removed when idle
+	release, err := l.acquire(ctx, "a")
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, 0, len(l.groups))
+
+	l.set("a", 1)
+	assert.Equal(t, map[string]int{"a": 1}, l.get())
+	release, err = l.acquire(ctx, "a")
+	require.NoError(t, err)
+
+	// Second job must wait for the first
+	var started atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		release2, err := l.acquire(ctx, "a")
+		assert.NoError(t, err)
+		started.Store(true)
+		release2()
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, started.Load())
+	release()
+	<-done
+	assert.True(t, started.Load())
+
+	// Waiting jobs can be cancelled
+	release, err = l.acquire(ctx, "a")
+	require.NoError(t, err)
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = l.acquire(cancelCtx, "a")
+	assert.Equal(t, context.Canceled, err)
+	release()
+
+	// Removing the limit starts the waiting jobs
+	l.set("a", 0)
+	assert.Equal(t, map[string]int{}, l.get())
+	assert.Equal(t, 0, len(l.groups))
+}
+
+func TestJobsConcurrency(t *testing.T) {
+	ctx := context.Background()
+	jobs := newJobs()
+	jobs.limits.set("limited", 1)
+	var running, maxRunning atomic.Int32
+	fn := func(ctx context.Context, in rc.Params) (rc.Params, error) {
+		n := running.Add(1)
+		if n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return rc.Params{}, nil
+	}
+	var started []*Job
+	for i := 0; i < 3; i++ {
+		job, _, err := jobs.NewJob(ctx, fn, rc.Params{"_async": true, "_group": "limited"})
+		require.NoError(t, err)
+		started = append(started, job)
+	}
+	for _, job := range started {
+		for {
+			job.mu.Lock()
+			finished := job.Finished
+			job.mu.Unlock()
+			if finished {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestJobStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	jobs := newJobs()
+	require.NoError(t, jobs.openStore(dir))
+
+	job, _, err := jobs.newJob(ctx, &rc.Call{Path: "rc/noop", Fn: rcNoop}, rc.Params{"potato": 1, "password": "secret"}, "")
+	require.NoError(t, err)
+	require.True(t, job.Finished)
+
+	// Check the record is stored
+	record, err := jobs.store.loadJob(job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, job.ID, record.ID)
+	assert.Equal(t, "rc/noop", record.Call)
+	assert.True(t, record.Finished)
+	assert.True(t, record.Success)
+	assert.Equal(t, float64(1), record.Input["potato"])
+	assert.Equal(t, "XXX", record.Input["password"])
+	assert.NotNil(t, record.Stats)
+
+	// Store a job which didn't finish
+	interrupted := &jobRecord{ID: job.ID + 1, StartTime: time.Now()}
+	require.NoError(t, jobs.store.saveJob(interrupted))
+
+	// Check a new Jobs loads the history and marks the job as interrupted
+	jobs = newJobs()
+	require.NoError(t, jobs.openStore(dir))
+	records, err := jobs.history(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(records))
+	assert.Equal(t, job.ID+1, records[0].ID)
+	assert.True(t, records[0].Finished)
+	assert.False(t, records[0].Success)
+	assert.Contains(t, records[0].Error, "interrupted")
+	assert.Equal(t, job.ID, records[1].ID)
+	assert.True(t, jobID.Load() >= job.ID+1)
+
+	// Check old jobs are pruned
+	oldTime := time.Now().Add(-time.Hour)
+	record.EndTime = oldTime
+	require.NoError(t, jobs.store.saveJob(record))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, jobFileName(job.ID)), oldTime, oldTime))
+	jobs.store.prune(time.Now().Add(-time.Minute))
+	records, err = jobs.history(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, job.ID+1, records[0].ID)
+}
+
+func TestJobScheduler(t *testing.T) {
+	dir := t.TempDir()
+	jobs := newJobs()
+	require.NoError(t, jobs.openStore(dir))
+
+	_, err := jobs.scheduler.add(&schedule{Name: "bad", Cron: "potato", Command: "rc/noop"})
+	assert.Error(t, err)
+	_, err = jobs.scheduler.add(&schedule{Name: "bad", Cron: "@daily", Command: "not/found"})
+	assert.Error(t, err)
+
+	sch := &schedule{
+		Name:    "test",
+		Cron:    "@daily",
+		Command: "rc/noop",
+		Params:  rc.Params{"potato": "1"},
+	}
+	next, err := jobs.scheduler.add(sch)
+	require.NoError(t, err)
+	assert.True(t, next.After(time.Now()))
+	jobs.limits.set("test", 1)
+	jobs.saveState()
+
+	// Run the schedule now
+	jobs.scheduler.run(sch)
+	list := jobs.scheduler.list()
+	require.Equal(t, 1, len(list))
+	assert.Equal(t, "test", list[0].Group)
+	jobID := list[0].LastJobID
+	require.NotEqual(t, int64(0), jobID)
+	job := jobs.Get(jobID)
+	require.NotNil(t, job)
+	<-waitFinished(job)
+	record, err := jobs.getRecord(context.Background(), jobID)
+	require.NoError(t, err)
+	assert.Equal(t, "test", record.Schedule)
+	assert.Equal(t, "test", record.Group)
+	assert.Equal(t, "1", record.Output["potato"])
+
+	// Check the schedules and limits are loaded again
+	jobs2 := newJobs()
+	require.NoError(t, jobs2.openStore(dir))
+	list = jobs2.scheduler.list()
+	require.Equal(t, 1, len(list))
+	assert.Equal(t, "rc/noop", list[0].Command)
+	assert.Equal(t, jobID, list[0].LastJobID)
+	assert.Equal(t, map[string]int{"test": 1}, jobs2.limits.get())
+
+	require.NoError(t, jobs2.scheduler.remove("test"))
+	assert.Error(t, jobs2.scheduler.remove("test"))
+	require.NoError(t, jobs.scheduler.remove("test"))
+}
+
+// waitFinished returns a channel which is closed when job finishes
+func waitFinished(job *Job) <-chan struct{} {
+	done := make(chan struct{})
+	job.OnFinish(func() { close(done) })
+	return done
+}
+
+func TestRcJobSchedule(t *testing.T) {
+	ctx := context.Background()
+	call := rc.Calls.Get("job/schedule")
+	require.NotNil(t, call)
+	out, err := call.Fn(ctx, rc.Params{
+		"name":    "rctest",
+		"cron":    "0 3 * * *",
+		"command": "rc/noop",
+		"params":  rc.Params{"a": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "rctest", out["name"])
+	next := out["next"].(time.Time)
+	assert.Equal(t, 3, next.Hour())
+
+	call = rc.Calls.Get("job/schedules")
+	require.NotNil(t, call)
+	out, err = call.Fn(ctx, rc.Params{})
+	require.NoError(t, err)
+	schedules := out["schedules"].([]*schedule)
+	require.Equal(t, 1, len(schedules))
+	assert.Equal(t, "rctest", schedules[0].Name)
+
+	call = rc.Calls.Get("job/unschedule")
+	require.NotNil(t, call)
+	_, err = call.Fn(ctx, rc.Params{"name": "rctest"})
+	require.NoError(t, err)
+	_, err = call.Fn(ctx, rc.Params{"name": "rctest"})
+	assert.Error(t, err)
+}
+
+func TestRcJobConcurrency(t *testing.T) {
+	ctx := context.Background()
+	call := rc.Calls.Get("job/concurrency")
+	require.NotNil(t, call)
+	_, err := call.Fn(ctx, rc.Params{"concurrency": 1})
+	assert.Error(t, err)
+	out, err := call.Fn(ctx, rc.Params{"group": "rcgroup", "concurrency": 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"rcgroup": 2}, out["concurrency"])
+	out, err = call.Fn(ctx, rc.Params{"group": "rcgroup", "concurrency": 0})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{}, out["concurrency"])
+}
+
+func TestRcJobHistory(t *testing.T) {
+	ctx := context.Background()
+	job, _, err := NewJob(ctx, rcNoop, rc.Params{"_group": "historytest"})
+	require.NoError(t, err)
+
+	call := rc.Calls.Get("job/history")
+	require.NotNil(t, call)
+	out, err := call.Fn(ctx, rc.Params{"group": "historytest"})
+	require.NoError(t, err)
+	records := out["jobs"].([]*jobRecord)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, job.ID, records[0].ID)
+
+	out, err = call.Fn(ctx, rc.Params{"group": "historytest", "since": time.Now().Add(time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(out["jobs"].([]*jobRecord)))
+
+	out, err = call.Fn(ctx, rc.Params{"limit": 0})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(out["jobs"].([]*jobRecord)))
+
+	_, err = call.Fn(ctx, rc.Params{"since": "potato"})
+	assert.Error(t, err)
+}
+
+// rcNoop runs the rc/noop call
+func rcNoop(ctx context.Context, in rc.Params) (rc.Params, error) {
+	return rc.Calls.Get("rc/noop").Fn(ctx, in)
+}
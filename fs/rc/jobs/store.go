@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/file"
+)
+
+// jobRecord is the stored state of a job
+//
+// It has the same fields as the output of job/status with the input,
+// stats, logs and the origin of the job added.
+type jobRecord struct {
+	ID        int64     `json:"id"`
+	ExecuteID string    `json:"executeId"`
+	Group     string    `json:"group"`
+	Namespace string    `json:"namespace,omitempty"`
+	Call      string    `json:"call,omitempty"`
+	Schedule  string    `json:"schedule,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Error     string    `json:"error"`
+	Finished  bool      `json:"finished"`
+	Success   bool      `json:"success"`
+	Duration  float64   `json:"duration"`
+	Input     rc.Params `json:"input"`
+	Output    rc.Params `json:"output"`
+	Stats     rc.Params `json:"stats,omitempty"`
+	Logs      []logLine `json:"logs,omitempty"`
+}
+
+// jobsState is the stored state of the schedules and the concurrency
+// limits
+type jobsState struct {
+	Schedules   []*schedule    `json:"schedules"`
+	Concurrency map[string]int `json:"concurrency"`
+}
+
+// store persists jobs and the scheduler state in a directory
+//
+// Each job is stored in "ID.json" and the state in "state.json".
+//
+// An index of the stored jobs without their logs is kept in memory so
+// the history can be read without reading every file.
+type store struct {
+	mu    sync.Mutex
+	dir   string
+	index map[int64]*jobRecord // stored jobs without their logs
+}
+
+// stateFileName is the file the scheduler state is kept in
+const stateFileName = "state.json"
+
+// newStore opens the store in dir, creating it if necessary
+func newStore(dir string) (*store, error) {
+	err := file.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make job store directory: %w", err)
+	}
+	return &store{dir: dir, index: map[int64]*jobRecord{}}, nil
+}
+
+// write data to name atomically
+func (s *store) write(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.dir, name)
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// read name into v
+func (s *store) read(name string, v interface{}) error {
+	s.mu.Lock()
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jobFileName returns the file name of job ID
+func jobFileName(ID int64) string {
+	return strconv.FormatInt(ID, 10) + ".json"
+}
+
+// saveJob writes the record of a job
+func (s *store) saveJob(record *jobRecord) error {
+	err := s.write(jobFileName(record.ID), record)
+	if err != nil {
+		return fmt.Errorf("failed to store job %d: %w", record.ID, err)
+	}
+	s.addIndex(record)
+	return nil
+}
+
+// addIndex adds record to the index without its logs
+func (s *store) addIndex(record *jobRecord) {
+	indexed := *record
+	indexed.Logs = nil
+	s.mu.Lock()
+	s.index[record.ID] = &indexed
+	s.mu.Unlock()
+}
+
+// records returns copies of the indexed jobs without their logs
+func (s *store) records() (records []*jobRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records = make([]*jobRecord, 0, len(s.index))
+	for _, record := range s.index {
+		indexed := *record
+		records = append(records, &indexed)
+	}
+	return records
+}
+
+// loadJob reads the record of job ID returning nil if it wasn't found
+func (s *store) loadJob(ID int64) (*jobRecord, error) {
+	var record jobRecord
+	err := s.read(jobFileName(ID), &record)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read stored job %d: %w", ID, err)
+	}
+	return &record, nil
+}
+
+// jobIDs returns the IDs of the stored jobs in increasing order
+func (s *store) jobIDs() ([]int64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job store: %w", err)
+	}
+	IDs := []int64{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".json") || name == stateFileName {
+			continue
+		}
+		ID, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		IDs = append(IDs, ID)
+	}
+	sort.Slice(IDs, func(i, j int) bool { return IDs[i] < IDs[j] })
+	return IDs, nil
+}
+
+// loadJobs reads all the stored jobs in increasing order of ID and
+// indexes them
+func (s *store) loadJobs() (records []*jobRecord, err error) {
+	IDs, err := s.jobIDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, ID := range IDs {
+		record, err := s.loadJob(ID)
+		if err != nil {
+			fs.Errorf(nil, "Ignoring stored job: %v", err)
+			continue
+		}
+		if record != nil {
+			records = append(records, record)
+			s.addIndex(record)
+		}
+	}
+	return records, nil
+}
+
+// prune removes jobs which finished before cutoff
+func (s *store) prune(cutoff time.Time) {
+	IDs, err := s.jobIDs()
+	if err != nil {
+		fs.Errorf(nil, "Failed to prune job store: %v", err)
+		return
+	}
+	for _, ID := range IDs {
+		path := filepath.Join(s.dir, jobFileName(ID))
+		// The record is written for the last time when the job
+		// finishes so only read it if it is old enough.
+		fi, err := os.Stat(path)
+		if err != nil || !fi.ModTime().Before(cutoff) {
+			continue
+		}
+		record, err := s.loadJob(ID)
+		if err != nil || record == nil || !record.Finished || !record.EndTime.Before(cutoff) {
+			continue
+		}
+		s.mu.Lock()
+		err = os.Remove(path)
+		delete(s.index, ID)
+		s.mu.Unlock()
+		if err != nil && !os.IsNotExist(err) {
+			fs.Errorf(nil, "Failed to remove stored job %d: %v", ID, err)
+		}
+	}
+}
+
+// saveState writes the scheduler state
+func (s *store) saveState(state *jobsState) error {
+	err := s.write(stateFileName, state)
+	if err != nil {
+		return fmt.Errorf("failed to store job schedules: %w", err)
+	}
+	return nil
+}
+
+// loadState reads the scheduler state returning an empty one if there
+// isn't one stored
+func (s *store) loadState() (*jobsState, error) {
+	var state jobsState
+	err := s.read(stateFileName, &state)
+	if errors.Is(err, os.ErrNotExist) {
+		return &state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read stored job schedules: %w", err)
+	}
+	return &state, nil
+}
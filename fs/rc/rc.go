@@ -33,6 +33,11 @@ type Options struct {
 	EnableMetrics       bool   // set to disable prometheus metrics on /metrics
 	JobExpireDuration   time.Duration
 	JobExpireInterval   time.Duration
+	JobStore            bool          // set to store jobs, their history and schedules in the cache directory
+	JobHistoryMaxAge    time.Duration // remove stored jobs older than this
+	JobLogLines         int           // number of log lines to keep for each job
+	MultiUser           bool          // set to give each authenticated user their own config namespace
+	MultiUserAllowLocal bool          // set to allow users of MultiUser to use local paths and on the fly backends
 }
 
 // DefaultOpt is the default values used for Options
@@ -43,6 +48,8 @@ var DefaultOpt = Options{
 	Enabled:           false,
 	JobExpireDuration: 60 * time.Second,
 	JobExpireInterval: 10 * time.Second,
+	JobHistoryMaxAge:  7 * 24 * time.Hour,
+	JobLogLines:       1000,
 }
 
 func init() {
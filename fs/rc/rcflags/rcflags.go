@@ -30,6 +30,11 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.EnableMetrics, "rc-enable-metrics", "", false, "Enable prometheus metrics on /metrics", "RC")
 	flags.DurationVarP(flagSet, &Opt.JobExpireDuration, "rc-job-expire-duration", "", Opt.JobExpireDuration, "Expire finished async jobs older than this value", "RC")
 	flags.DurationVarP(flagSet, &Opt.JobExpireInterval, "rc-job-expire-interval", "", Opt.JobExpireInterval, "Interval to check for expired async jobs", "RC")
+	flags.BoolVarP(flagSet, &Opt.JobStore, "rc-job-store", "", false, "Store jobs, their history and schedules in the cache directory", "RC")
+	flags.DurationVarP(flagSet, &Opt.JobHistoryMaxAge, "rc-job-history-max-age", "", Opt.JobHistoryMaxAge, "Remove stored jobs older than this from the history", "RC")
+	flags.IntVarP(flagSet, &Opt.JobLogLines, "rc-job-log-lines", "", Opt.JobLogLines, "Number of log lines to keep for each job", "RC")
+	flags.BoolVarP(flagSet, &Opt.MultiUser, "rc-multi-user", "", false, "Give each authenticated user their own remotes, Fs cache and stats", "RC")
+	flags.BoolVarP(flagSet, &Opt.MultiUserAllowLocal, "rc-multi-user-allow-local", "", false, "Allow users of --rc-multi-user to use local paths and on the fly backends", "RC")
 	Opt.HTTP.AddFlagsPrefix(flagSet, FlagPrefix)
 	Opt.Auth.AddFlagsPrefix(flagSet, FlagPrefix)
 	Opt.Template.AddFlagsPrefix(flagSet, FlagPrefix)
@@ -0,0 +1,222 @@
+package rcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/jobs"
+)
+
+// Names of the events streamed by /events
+const (
+	eventStats = "stats"
+	eventJob   = "job"
+	eventLog   = "log"
+)
+
+const (
+	// eventBufferSize is the number of events which can be queued
+	// for a client before they are dropped
+	eventBufferSize = 1024
+	// eventKeepAlive is how often a comment is sent to keep the
+	// connection open if nothing else is sent
+	eventKeepAlive = 30 * time.Second
+	// eventMinInterval is the shortest interval stats can be sent
+	eventMinInterval = 100 * time.Millisecond
+)
+
+// eventLogLine is a log message sent by /events
+type eventLogLine struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Text  string    `json:"text"`
+	JobID int64     `json:"jobid,omitempty"`
+	Group string    `json:"group,omitempty"`
+}
+
+// sseEvent is an event waiting to be sent
+type sseEvent struct {
+	name string
+	data interface{}
+}
+
+// eventsOptions are the parameters of /events
+type eventsOptions struct {
+	group    string          // only send events for this group if set
+	events   map[string]bool // names of the events to send
+	interval time.Duration   // how often to send stats
+	level    fs.LogLevel     // only send log messages at or above this level
+}
+
+// parseEventsOptions reads the eventsOptions from the URL query of r
+func parseEventsOptions(r *http.Request) (opt eventsOptions, err error) {
+	query := r.URL.Query()
+	opt = eventsOptions{
+		group: query.Get("group"),
+		events: map[string]bool{
+			eventStats: true,
+			eventJob:   true,
+			eventLog:   true,
+		},
+		interval: time.Second,
+		level:    fs.LogLevelDebug,
+	}
+	if events := query.Get("events"); events != "" {
+		opt.events = map[string]bool{}
+		for _, name := range strings.Split(events, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case eventStats, eventJob, eventLog:
+				opt.events[name] = true
+			default:
+				return opt, fmt.Errorf("unknown event %q", name)
+			}
+		}
+	}
+	if interval := query.Get("interval"); interval != "" {
+		var d fs.Duration
+		err = d.Set(interval)
+		if err != nil {
+			return opt, fmt.Errorf("bad interval: %w", err)
+		}
+		opt.interval = time.Duration(d)
+		if opt.interval < eventMinInterval {
+			opt.interval = eventMinInterval
+		}
+	}
+	if level := query.Get("level"); level != "" {
+		err = opt.level.Set(level)
+		if err != nil {
+			return opt, fmt.Errorf("bad level: %w", err)
+		}
+	}
+	return opt, nil
+}
+
+// handleEvents streams stats, job events and log messages as server
+// sent events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	const path = "events"
+	ctx := r.Context()
+	// This shows log messages so needs the same auth as
+	// calls which access remotes
+	if !s.opt.NoAuth && !s.server.UsingAuth() {
+		writeError(path, nil, w, errors.New("authentication must be set up on the rc server to use /events or the --rc-no-auth flag must be in use"), http.StatusForbidden)
+		return
+	}
+	// The events of all users are streamed so this isn't allowed
+	// with --rc-multi-user
+	if s.opt.MultiUser {
+		writeError(path, nil, w, errors.New("/events can't be used with --rc-multi-user"), http.StatusForbidden)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(path, nil, w, errors.New("streaming not supported"), http.StatusInternalServerError)
+		return
+	}
+	opt, err := parseEventsOptions(r)
+	if err != nil {
+		writeError(path, nil, w, rc.NewErrParamInvalid(err), http.StatusBadRequest)
+		return
+	}
+
+	// Queue the events without blocking the job or the logger
+	events := make(chan sseEvent, eventBufferSize)
+	queue := func(event sseEvent) {
+		select {
+		case events <- event:
+		default:
+			// drop the event if the client isn't keeping up
+		}
+	}
+	if opt.events[eventJob] {
+		remove := jobs.OnEvent(func(event jobs.Event) {
+			if opt.group == "" || event.Group == opt.group {
+				queue(sseEvent{name: eventJob, data: event})
+			}
+		})
+		defer remove()
+	}
+	if opt.events[eventLog] {
+		remove := fs.AddLogHook(func(ctx context.Context, level fs.LogLevel, text string) {
+			if level > opt.level {
+				return
+			}
+			line := eventLogLine{
+				Time:  time.Now(),
+				Level: level.String(),
+				Text:  text,
+			}
+			if job, ok := jobs.GetJob(ctx); ok {
+				line.JobID = job.ID
+				line.Group = job.Group
+			}
+			if opt.group == "" || line.Group == opt.group {
+				queue(sseEvent{name: eventLog, data: line})
+			}
+		})
+		defer remove()
+	}
+	var statsTick <-chan time.Time
+	if opt.events[eventStats] {
+		ticker := time.NewTicker(opt.interval)
+		defer ticker.Stop()
+		statsTick = ticker.C
+	}
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// write an event returning false if the client has gone away
+	write := func(event sseEvent) bool {
+		data, err := json.Marshal(event.data)
+		if err != nil {
+			fs.Errorf(nil, "rc: events: failed to encode %s event: %v", event.name, err)
+			return true
+		}
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+		if err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	statsCall := rc.Calls.Get("core/stats")
+	for {
+		var event sseEvent
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-events:
+		case <-statsTick:
+			stats, err := statsCall.Fn(ctx, rc.Params{"group": opt.group})
+			if err != nil {
+				continue
+			}
+			event = sseEvent{name: eventStats, data: stats}
+		case <-keepAlive.C:
+			_, err := fmt.Fprint(w, ": keepalive\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+			continue
+		}
+		if !write(event) {
+			return
+		}
+	}
+}
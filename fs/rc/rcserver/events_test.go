@@ -0,0 +1,187 @@
+package rcserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventsOptions(t *testing.T) {
+	r, err := http.NewRequest("GET", "http://1.2.3.4/events", nil)
+	require.NoError(t, err)
+	opt, err := parseEventsOptions(r)
+	require.NoError(t, err)
+	assert.Equal(t, "", opt.group)
+	assert.Equal(t, map[string]bool{"stats": true, "job": true, "log": true}, opt.events)
+	assert.Equal(t, time.Second, opt.interval)
+	assert.Equal(t, fs.LogLevelDebug, opt.level)
+
+	r, err = http.NewRequest("GET", "http://1.2.3.4/events?group=g&events=job,%20log&interval=10ms&level=info", nil)
+	require.NoError(t, err)
+	opt, err = parseEventsOptions(r)
+	require.NoError(t, err)
+	assert.Equal(t, "g", opt.group)
+	assert.Equal(t, map[string]bool{"job": true, "log": true}, opt.events)
+	assert.Equal(t, eventMinInterval, opt.interval)
+	assert.Equal(t, fs.LogLevelInfo, opt.level)
+
+	for _, query := range []string{"events=potato", "interval=potato", "level=potato"} {
+		r, err = http.NewRequest("GET", "http://1.2.3.4/events?"+query, nil)
+		require.NoError(t, err)
+		_, err = parseEventsOptions(r)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestEventsErrors(t *testing.T) {
+	tests := []testRun{{
+		Name:     "noauth",
+		URL:      "events",
+		Status:   http.StatusForbidden,
+		Contains: regexp.MustCompile(`authentication must be set up`),
+	}}
+	opt := newTestOpt()
+	opt.NoAuth = false
+	testServer(t, tests, &opt)
+
+	tests = []testRun{{
+		Name:     "badparam",
+		URL:      "events?events=potato",
+		Status:   http.StatusBadRequest,
+		Contains: regexp.MustCompile(`unknown event`),
+	}}
+	opt = newTestOpt()
+	opt.NoAuth = true
+	testServer(t, tests, &opt)
+}
+
+// read server sent events from the scanner into the channel
+func readEvents(scanner *bufio.Scanner, out chan<- [2]string) {
+	defer close(out)
+	var name string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = line[len("event: "):]
+		case strings.HasPrefix(line, "data: "):
+			out <- [2]string{name, line[len("data: "):]}
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ctx := context.Background()
+	opt := newTestOpt()
+	opt.NoAuth = true
+	rcServer, err := newServer(ctx, &opt, http.NewServeMux())
+	require.NoError(t, err)
+	require.NoError(t, rcServer.Serve())
+	defer func() {
+		require.NoError(t, rcServer.Shutdown())
+		rcServer.Wait()
+	}()
+	testURL := rcServer.server.URLs()[0]
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, "GET", testURL+"events?events=job,log&group=eventstest", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	events := make(chan [2]string)
+	go readEvents(bufio.NewScanner(resp.Body), events)
+
+	// A job not in the group which shouldn't be seen
+	_, _, err = jobs.NewJob(ctx, func(ctx context.Context, in rc.Params) (rc.Params, error) {
+		fs.LogfCtx(ctx, nil, "other job")
+		return rc.Params{}, nil
+	}, rc.Params{"_group": "other"})
+	require.NoError(t, err)
+
+	job, _, err := jobs.NewJob(ctx, func(ctx context.Context, in rc.Params) (rc.Params, error) {
+		fs.LogfCtx(ctx, nil, "hello from job")
+		return rc.Params{}, nil
+	}, rc.Params{"_group": "eventstest"})
+	require.NoError(t, err)
+
+	var got []string
+	timeout := time.After(10 * time.Second)
+	for len(got) < 3 {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "stream closed")
+			switch event[0] {
+			case eventJob:
+				var jobEvent jobs.Event
+				require.NoError(t, json.Unmarshal([]byte(event[1]), &jobEvent))
+				assert.Equal(t, job.ID, jobEvent.ID)
+				assert.Equal(t, "eventstest", jobEvent.Group)
+				got = append(got, "job "+jobEvent.Type)
+			case eventLog:
+				var line eventLogLine
+				require.NoError(t, json.Unmarshal([]byte(event[1]), &line))
+				assert.Equal(t, job.ID, line.JobID)
+				assert.Equal(t, "NOTICE", line.Level)
+				got = append(got, "log "+line.Text)
+			default:
+				t.Fatalf("unexpected event %q", event[0])
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for events - got %v", got)
+		}
+	}
+	assert.Equal(t, []string{"job start", "log hello from job", "job finish"}, got)
+}
+
+func TestEventsStats(t *testing.T) {
+	ctx := context.Background()
+	opt := newTestOpt()
+	opt.NoAuth = true
+	rcServer, err := newServer(ctx, &opt, http.NewServeMux())
+	require.NoError(t, err)
+	require.NoError(t, rcServer.Serve())
+	defer func() {
+		require.NoError(t, rcServer.Shutdown())
+		rcServer.Wait()
+	}()
+	testURL := rcServer.server.URLs()[0]
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, "GET", testURL+"events?events=stats&interval=100ms", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	events := make(chan [2]string)
+	go readEvents(bufio.NewScanner(resp.Body), events)
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "stream closed")
+		assert.Equal(t, eventStats, event[0])
+		var stats rc.Params
+		require.NoError(t, json.Unmarshal([]byte(event[1]), &stats))
+		assert.Contains(t, stats, "bytes")
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for stats")
+	}
+}
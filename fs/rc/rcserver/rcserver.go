@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -32,6 +33,7 @@ import (
 	"github.com/rclone/rclone/fs/rc/webgui"
 	libhttp "github.com/rclone/rclone/lib/http"
 	"github.com/rclone/rclone/lib/http/serve"
+	"github.com/rclone/rclone/lib/pacer"
 	"github.com/rclone/rclone/lib/random"
 	"github.com/skratchdot/open-golang/open"
 )
@@ -48,6 +50,12 @@ func init() {
 	}
 	fshttp.DefaultMetrics = m
 
+	pm := pacer.NewMetrics("rclone")
+	for _, c := range pm.Collectors() {
+		prometheus.MustRegister(c)
+	}
+	pacer.DefaultMetrics = pm
+
 	promHandler = promhttp.Handler()
 }
 
@@ -56,6 +64,12 @@ func init() {
 // If the server wasn't configured the *Server returned may be nil
 func Start(ctx context.Context, opt *rc.Options) (*Server, error) {
 	jobs.SetOpt(opt) // set the defaults for jobs
+	if opt.JobStore {
+		err := jobs.OpenStore(filepath.Join(config.GetCacheDir(), "rc-jobs"))
+		if err != nil {
+			return nil, err
+		}
+	}
 	if opt.Enabled {
 		// Serve on the DefaultServeMux so can have global registrations appear
 		s, err := newServer(ctx, opt, http.DefaultServeMux)
@@ -136,6 +150,9 @@ func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) (*Serve
 	if err != nil {
 		return nil, fmt.Errorf("failed to init server: %w", err)
 	}
+	if opt.MultiUser && !s.server.UsingAuth() {
+		return nil, errors.New("--rc-multi-user needs authentication to be set up on the rc server")
+	}
 
 	router := s.server.Router()
 	router.Use(
@@ -146,6 +163,9 @@ func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) (*Serve
 	// Add the debug handler which is installed in the default mux
 	router.Handle("/debug/*", mux)
 
+	// Stream events to the client
+	router.Get("/events", s.handleEvents)
+
 	// FIXME split these up into individual functions
 	router.Get("/*", s.handler)
 	router.Head("/*", s.handler)
@@ -269,6 +289,20 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string)
 		return
 	}
 
+	// Give each user their own namespace if required
+	if s.opt.MultiUser {
+		if call.Global {
+			writeError(path, in, w, fmt.Errorf("%q can't be used with --rc-multi-user", path), http.StatusForbidden)
+			return
+		}
+		var err error
+		ctx, err = s.userNamespace(r)
+		if err != nil {
+			writeError(path, in, w, err, http.StatusForbidden)
+			return
+		}
+	}
+
 	inOrig := in.Copy()
 
 	if call.NeedsRequest {
@@ -281,7 +315,7 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string)
 	}
 
 	fs.Debugf(nil, "rc: %q: with parameters %+v", path, in)
-	job, out, err := jobs.NewJob(ctx, call.Fn, in)
+	job, out, err := jobs.NewCallJob(ctx, call, in)
 	if job != nil {
 		w.Header().Add("x-rclone-jobid", fmt.Sprintf("%d", job.ID))
 	}
@@ -302,12 +336,44 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string)
 	}
 }
 
+// userNamespace returns the context of r with the namespace of the
+// authenticated user set for --rc-multi-user.
+func (s *Server) userNamespace(r *http.Request) (context.Context, error) {
+	user, ok := libhttp.CtxGetUser(r.Context())
+	if !ok || user == "" {
+		return nil, errors.New("an authenticated user is required with --rc-multi-user")
+	}
+	err := fs.CheckNamespace(user)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user name for --rc-multi-user: %w", err)
+	}
+	ctx := fs.WithNamespace(r.Context(), user)
+	if s.opt.MultiUserAllowLocal {
+		ctx = fs.WithNamespaceLocal(ctx)
+	}
+	return ctx, nil
+}
+
 func (s *Server) handleOptions(w http.ResponseWriter, r *http.Request, path string) {
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *Server) serveRoot(w http.ResponseWriter, r *http.Request) {
 	remotes := config.FileSections()
+	if s.opt.MultiUser {
+		ctx, err := s.userNamespace(r)
+		if err != nil {
+			writeError("", nil, w, err, http.StatusForbidden)
+			return
+		}
+		namespaced := []string{}
+		for _, remote := range remotes {
+			if name, ok := fs.FromNamespace(ctx, remote); ok {
+				namespaced = append(namespaced, name)
+			}
+		}
+		remotes = namespaced
+	}
 	sort.Strings(remotes)
 	directory := serve.NewDirectory("", s.server.HTMLTemplate())
 	directory.Name = "List of all rclone remotes."
@@ -324,7 +390,16 @@ func (s *Server) serveRoot(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) serveRemote(w http.ResponseWriter, r *http.Request, path string, fsName string) {
-	f, err := cache.Get(s.ctx, fsName)
+	ctx := s.ctx
+	if s.opt.MultiUser {
+		userCtx, err := s.userNamespace(r)
+		if err != nil {
+			writeError(path, nil, w, err, http.StatusForbidden)
+			return
+		}
+		ctx = fs.CopyNamespace(ctx, userCtx)
+	}
+	f, err := cache.Get(ctx, fsName)
 	if err != nil {
 		writeError(path, nil, w, fmt.Errorf("failed to make Fs: %w", err), http.StatusInternalServerError)
 		return
@@ -14,9 +14,12 @@ import (
 	"time"
 
 	_ "github.com/rclone/rclone/backend/local"
+	_ "github.com/rclone/rclone/backend/memory"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/config/configfile"
+	_ "github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -750,6 +753,103 @@ func TestWithUserPass(t *testing.T) {
 	testServer(t, tests, &opt)
 }
 
+func TestMultiUser(t *testing.T) {
+	tests := []testRun{{
+		Name:        "create",
+		URL:         "config/create",
+		Method:      "POST",
+		Body:        `{"name":"multiUserRemote","type":"memory","parameters":{}}`,
+		ContentType: "application/json",
+		Status:      http.StatusOK,
+		Expected:    "{}\n",
+		User:        "user",
+		Pass:        "pass",
+	}, {
+		Name:        "listremotes",
+		URL:         "config/listremotes",
+		Method:      "POST",
+		Body:        `{}`,
+		ContentType: "application/json",
+		Status:      http.StatusOK,
+		Expected: `{
+	"remotes": [
+		"multiUserRemote"
+	]
+}
+`,
+		User: "user",
+		Pass: "pass",
+	}, {
+		Name:        "global",
+		URL:         "core/quit",
+		Method:      "POST",
+		Body:        `{}`,
+		ContentType: "application/json",
+		Status:      http.StatusForbidden,
+		Expected: `{
+	"error": "\"core/quit\" can't be used with --rc-multi-user",
+	"input": {},
+	"path": "core/quit",
+	"status": 403
+}
+`,
+		User: "user",
+		Pass: "pass",
+	}, {
+		Name:        "local",
+		URL:         "operations/list",
+		Method:      "POST",
+		Body:        `{"fs":"/","remote":""}`,
+		ContentType: "application/json",
+		Status:      http.StatusInternalServerError,
+		Contains:    regexp.MustCompile(`local paths and on the fly backends can't be used in a namespace`),
+		User:        "user",
+		Pass:        "pass",
+	}, {
+		Name:        "onthefly",
+		URL:         "operations/list",
+		Method:      "POST",
+		Body:        `{"fs":":local:/","remote":""}`,
+		ContentType: "application/json",
+		Status:      http.StatusInternalServerError,
+		Contains:    regexp.MustCompile(`local paths and on the fly backends can't be used in a namespace`),
+		User:        "user",
+		Pass:        "pass",
+	}, {
+		Name:        "remote",
+		URL:         "operations/list",
+		Method:      "POST",
+		Body:        `{"fs":"multiUserRemote:","remote":""}`,
+		ContentType: "application/json",
+		Status:      http.StatusOK,
+		Expected:    "{\n\t\"list\": []\n}\n",
+		User:        "user",
+		Pass:        "pass",
+	}, {
+		Name:        "delete",
+		URL:         "config/delete",
+		Method:      "POST",
+		Body:        `{"name":"multiUserRemote"}`,
+		ContentType: "application/json",
+		Status:      http.StatusOK,
+		Expected:    "{}\n",
+		User:        "user",
+		Pass:        "pass",
+	}}
+	opt := newTestOpt()
+	opt.MultiUser = true
+	opt.Auth.BasicUser = "user"
+	opt.Auth.BasicPass = "pass"
+	testServer(t, tests, &opt)
+	assert.False(t, config.LoadedData().HasSection("user"+fs.NamespaceSeparator+"multiUserRemote"))
+
+	// Check auth is required
+	opt = newTestOpt()
+	opt.MultiUser = true
+	_, err := newServer(context.Background(), &opt, http.NewServeMux())
+	assert.ErrorContains(t, err, "--rc-multi-user needs authentication")
+}
+
 func TestRCAsync(t *testing.T) {
 	tests := []testRun{{
 		Name:        "ok",
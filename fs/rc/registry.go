@@ -22,6 +22,7 @@ type Call struct {
 	Help          string // multi-line markdown formatted help
 	NeedsRequest  bool   // if set then this call will be passed the original request object as _request
 	NeedsResponse bool   // if set then this call will be passed the original response object as _response
+	Global        bool   // if set then this call affects all users so is disabled with --rc-multi-user
 }
 
 // Registry holds the list of all the registered remote control functions
@@ -13,6 +13,7 @@ import (
 func init() {
 	rc.Add(rc.Call{
 		Path:         "pluginsctl/listTestPlugins",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           rcListTestPlugins,
 		Title:        "Show currently loaded test plugins",
@@ -42,6 +43,7 @@ func rcListTestPlugins(_ context.Context, _ rc.Params) (out rc.Params, err error
 func init() {
 	rc.Add(rc.Call{
 		Path:         "pluginsctl/removeTestPlugin",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           rcRemoveTestPlugin,
 		Title:        "Remove  a test plugin",
@@ -76,6 +78,7 @@ func rcRemoveTestPlugin(_ context.Context, in rc.Params) (out rc.Params, err err
 func init() {
 	rc.Add(rc.Call{
 		Path:         "pluginsctl/addPlugin",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           rcAddPlugin,
 		Title:        "Add a plugin using url",
@@ -186,6 +189,7 @@ func rcAddPlugin(_ context.Context, in rc.Params) (out rc.Params, err error) {
 func init() {
 	rc.Add(rc.Call{
 		Path:         "pluginsctl/listPlugins",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           rcGetPlugins,
 		Title:        "Get the list of currently loaded plugins",
@@ -221,6 +225,7 @@ func rcGetPlugins(_ context.Context, _ rc.Params) (out rc.Params, err error) {
 func init() {
 	rc.Add(rc.Call{
 		Path:         "pluginsctl/removePlugin",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           rcRemovePlugin,
 		Title:        "Remove a loaded plugin",
@@ -257,6 +262,7 @@ func rcRemovePlugin(_ context.Context, in rc.Params) (out rc.Params, err error)
 func init() {
 	rc.Add(rc.Call{
 		Path:         "pluginsctl/getPluginsForType",
+		Global:       true,
 		AuthRequired: true,
 		Fn:           rcGetPluginsForType,
 		Title:        "Get plugins with type criteria",
@@ -0,0 +1,277 @@
+// Limits which apply to named remotes
+
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type remoteNameContextKeyType struct{}
+
+// Context key for the remote name
+var remoteNameContextKey = remoteNameContextKeyType{}
+
+// WithRemoteName returns a copy of ctx which records that it is being
+// used to make or use the remote called name.
+//
+// NewFs sets this on the context passed to the backend so the HTTP
+// transport, the dialer and the pacer can find the limits set for
+// the remote with --bwlimit-remote, --tpslimit-remote and
+// --max-connections-remote.
+//
+// The name is stored without the suffix added for overridden config
+// so all the Fs made from the same remote share its limits. Any
+// namespace is kept so the users of a multi-user server get a limit
+// each rather than sharing one.
+func WithRemoteName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, remoteNameContextKey, remoteLimitName(name))
+}
+
+// GetRemoteName returns the remote name set in ctx or "" if none
+func GetRemoteName(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(remoteNameContextKey).(string)
+	return name
+}
+
+// remoteLimitName returns the name the limits for the remote called
+// name are kept under.
+func remoteLimitName(name string) string {
+	if i := strings.IndexRune(name, '{'); i > 0 {
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, ":")
+}
+
+// remoteFlagName returns the name the flags set the limits for the
+// remote called name under, which is the name without any namespace.
+func remoteFlagName(name string) string {
+	name = remoteLimitName(name)
+	if i := strings.LastIndex(name, NamespaceSeparator); i >= 0 {
+		name = name[i+len(NamespaceSeparator):]
+	}
+	return name
+}
+
+// parseRemoteValues parses a comma separated list of remote=value
+// items calling fn for each one.
+//
+// The remote may be written with or without its trailing ":" so
+// "nas=10M" and "nas:=10M" are the same.
+func parseRemoteValues(s string, fn func(name, value string) error) error {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		equals := strings.IndexRune(item, '=')
+		if equals < 0 {
+			return fmt.Errorf("%q should be in the form remote=value", item)
+		}
+		name := remoteFlagName(strings.TrimSpace(item[:equals]))
+		if name == "" {
+			return fmt.Errorf("%q needs a remote name", item)
+		}
+		err := fn(name, strings.TrimSpace(item[equals+1:]))
+		if err != nil {
+			return fmt.Errorf("bad value for remote %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// formatRemoteValues formats values as a sorted comma separated list
+// of remote=value items.
+func formatRemoteValues(values map[string]string) string {
+	items := make([]string, 0, len(values))
+	for name, value := range values {
+		items = append(items, name+"="+value)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// unmarshalRemoteValues unmarshals a JSON string and sets it with set
+func unmarshalRemoteValues(in []byte, set func(string) error) error {
+	var s string
+	err := json.Unmarshal(in, &s)
+	if err != nil {
+		return err
+	}
+	return set(s)
+}
+
+// RemoteBwLimits holds the upload and download bandwidth limits for
+// named remotes as set with --bwlimit-remote
+type RemoteBwLimits map[string]BwPair
+
+// String returns a printable representation of RemoteBwLimits
+func (x RemoteBwLimits) String() string {
+	values := make(map[string]string, len(x))
+	for name, bw := range x {
+		values[name] = bw.String()
+	}
+	return formatRemoteValues(values)
+}
+
+// Set the limits from a string of comma separated remote=bandwidth
+// items where bandwidth is SizeSuffix or SizeSuffix:SizeSuffix (for
+// upload:download bandwidth).
+//
+// Calling Set again adds to the limits rather than replacing them.
+func (x *RemoteBwLimits) Set(s string) error {
+	return parseRemoteValues(s, func(name, value string) error {
+		var bw BwPair
+		err := bw.Set(value)
+		if err != nil {
+			return err
+		}
+		if *x == nil {
+			*x = RemoteBwLimits{}
+		}
+		(*x)[name] = bw
+		return nil
+	})
+}
+
+// Get returns the limits for the remote called name which may be
+// namespaced as returned by GetRemoteName
+func (x RemoteBwLimits) Get(name string) (bw BwPair, ok bool) {
+	bw, ok = x[remoteFlagName(name)]
+	return bw, ok
+}
+
+// Type of the value
+func (x RemoteBwLimits) Type() string {
+	return "RemoteBwLimits"
+}
+
+// UnmarshalJSON unmarshals a string value replacing the limits
+func (x *RemoteBwLimits) UnmarshalJSON(in []byte) error {
+	*x = nil
+	return unmarshalRemoteValues(in, x.Set)
+}
+
+// MarshalJSON marshals as a string value
+func (x RemoteBwLimits) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// RemoteTPSLimits holds the transactions per second limits for named
+// remotes as set with --tpslimit-remote
+type RemoteTPSLimits map[string]float64
+
+// String returns a printable representation of RemoteTPSLimits
+func (x RemoteTPSLimits) String() string {
+	values := make(map[string]string, len(x))
+	for name, tps := range x {
+		values[name] = strconv.FormatFloat(tps, 'g', -1, 64)
+	}
+	return formatRemoteValues(values)
+}
+
+// Set the limits from a string of comma separated remote=tps items
+//
+// Calling Set again adds to the limits rather than replacing them.
+func (x *RemoteTPSLimits) Set(s string) error {
+	return parseRemoteValues(s, func(name, value string) error {
+		tps, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if tps <= 0 {
+			return fmt.Errorf("transactions per second must be positive: %v", tps)
+		}
+		if *x == nil {
+			*x = RemoteTPSLimits{}
+		}
+		(*x)[name] = tps
+		return nil
+	})
+}
+
+// Get returns the limit for the remote called name which may be
+// namespaced as returned by GetRemoteName
+func (x RemoteTPSLimits) Get(name string) (tps float64, ok bool) {
+	tps, ok = x[remoteFlagName(name)]
+	return tps, ok
+}
+
+// Type of the value
+func (x RemoteTPSLimits) Type() string {
+	return "RemoteTPSLimits"
+}
+
+// UnmarshalJSON unmarshals a string value replacing the limits
+func (x *RemoteTPSLimits) UnmarshalJSON(in []byte) error {
+	*x = nil
+	return unmarshalRemoteValues(in, x.Set)
+}
+
+// MarshalJSON marshals as a string value
+func (x RemoteTPSLimits) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// RemoteMaxConnections holds the maximum number of simultaneous API
+// calls for named remotes as set with --max-connections-remote
+type RemoteMaxConnections map[string]int
+
+// String returns a printable representation of RemoteMaxConnections
+func (x RemoteMaxConnections) String() string {
+	values := make(map[string]string, len(x))
+	for name, n := range x {
+		values[name] = strconv.Itoa(n)
+	}
+	return formatRemoteValues(values)
+}
+
+// Set the limits from a string of comma separated remote=calls items
+//
+// Calling Set again adds to the limits rather than replacing them.
+func (x *RemoteMaxConnections) Set(s string) error {
+	return parseRemoteValues(s, func(name, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("simultaneous API calls must not be negative: %d", n)
+		}
+		if *x == nil {
+			*x = RemoteMaxConnections{}
+		}
+		(*x)[name] = n
+		return nil
+	})
+}
+
+// Get returns the limit for the remote called name which may be
+// namespaced as returned by GetRemoteName
+func (x RemoteMaxConnections) Get(name string) (n int, ok bool) {
+	n, ok = x[remoteFlagName(name)]
+	return n, ok
+}
+
+// Type of the value
+func (x RemoteMaxConnections) Type() string {
+	return "RemoteMaxConnections"
+}
+
+// UnmarshalJSON unmarshals a string value replacing the limits
+func (x *RemoteMaxConnections) UnmarshalJSON(in []byte) error {
+	*x = nil
+	return unmarshalRemoteValues(in, x.Set)
+}
+
+// MarshalJSON marshals as a string value
+func (x RemoteMaxConnections) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
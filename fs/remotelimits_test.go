@@ -0,0 +1,134 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Check it satisfies the interfaces
+var (
+	_ flagger   = (*RemoteBwLimits)(nil)
+	_ flaggerNP = RemoteBwLimits{}
+	_ flagger   = (*RemoteTPSLimits)(nil)
+	_ flaggerNP = RemoteTPSLimits{}
+	_ flagger   = (*RemoteMaxConnections)(nil)
+	_ flaggerNP = RemoteMaxConnections{}
+)
+
+func TestRemoteName(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", GetRemoteName(ctx))
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"nas", "nas"},
+		{"nas:", "nas"},
+		{"nas{AbCdE}", "nas"},
+		{"user" + NamespaceSeparator + "nas", "user" + NamespaceSeparator + "nas"},
+		{"user" + NamespaceSeparator + "nas{AbCdE}", "user" + NamespaceSeparator + "nas"},
+		{":s3", ":s3"},
+	} {
+		assert.Equal(t, test.want, GetRemoteName(WithRemoteName(ctx, test.in)), test.in)
+	}
+}
+
+func TestRemoteBwLimitsSet(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want RemoteBwLimits
+		err  bool
+		out  string
+	}{
+		{"", nil, false, ""},
+		{"nas", nil, true, ""},
+		{"=10M", nil, true, ""},
+		{"nas=bad", nil, true, ""},
+		{"nas=10M", RemoteBwLimits{"nas": {Tx: 10 * 1024 * 1024, Rx: 10 * 1024 * 1024}}, false, "nas=10Mi"},
+		{"nas:=10M", RemoteBwLimits{"nas": {Tx: 10 * 1024 * 1024, Rx: 10 * 1024 * 1024}}, false, "nas=10Mi"},
+		{"nas=10M:1M, drive: = 100k", RemoteBwLimits{
+			"nas":   {Tx: 10 * 1024 * 1024, Rx: 1024 * 1024},
+			"drive": {Tx: 100 * 1024, Rx: 100 * 1024},
+		}, false, "drive=100Ki,nas=10Mi:1Mi"},
+	} {
+		var limits RemoteBwLimits
+		err := limits.Set(test.in)
+		if test.err {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, limits, test.in)
+		assert.Equal(t, test.out, limits.String(), test.in)
+	}
+
+	// Set adds to the limits
+	var limits RemoteBwLimits
+	require.NoError(t, limits.Set("nas=10M"))
+	require.NoError(t, limits.Set("drive=1M"))
+	assert.Equal(t, "drive=1Mi,nas=10Mi", limits.String())
+}
+
+func TestRemoteTPSLimitsSet(t *testing.T) {
+	var limits RemoteTPSLimits
+	assert.Error(t, limits.Set("nas=bad"))
+	assert.Error(t, limits.Set("nas=0"))
+	require.NoError(t, limits.Set("nas:=10,drive=0.5"))
+	assert.Equal(t, RemoteTPSLimits{"nas": 10, "drive": 0.5}, limits)
+	assert.Equal(t, "drive=0.5,nas=10", limits.String())
+}
+
+func TestRemoteMaxConnectionsSet(t *testing.T) {
+	var limits RemoteMaxConnections
+	assert.Error(t, limits.Set("nas=bad"))
+	assert.Error(t, limits.Set("nas=-1"))
+	require.NoError(t, limits.Set("nas:=4,drive=0"))
+	assert.Equal(t, RemoteMaxConnections{"nas": 4, "drive": 0}, limits)
+	assert.Equal(t, "drive=0,nas=4", limits.String())
+}
+
+func TestRemoteLimitsGet(t *testing.T) {
+	ctx := context.Background()
+	nas := GetRemoteName(WithRemoteName(ctx, "user"+NamespaceSeparator+"nas{AbCdE}:"))
+
+	// Limits set for a remote apply to it in every namespace
+	bw := RemoteBwLimits{"nas": {Tx: 1024, Rx: 2048}}
+	got, ok := bw.Get(nas)
+	assert.True(t, ok)
+	assert.Equal(t, BwPair{Tx: 1024, Rx: 2048}, got)
+	_, ok = bw.Get("drive")
+	assert.False(t, ok)
+
+	tps, ok := RemoteTPSLimits{"nas": 10}.Get(nas)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, tps)
+
+	n, ok := RemoteMaxConnections{"nas": 4}.Get(nas)
+	assert.True(t, ok)
+	assert.Equal(t, 4, n)
+}
+
+func TestRemoteLimitsJSON(t *testing.T) {
+	limits := RemoteBwLimits{"nas": {Tx: 1024, Rx: 2048}}
+	out, err := json.Marshal(limits)
+	require.NoError(t, err)
+	assert.Equal(t, `"nas=1Ki:2Ki"`, string(out))
+
+	// Unmarshal replaces the limits
+	got := RemoteBwLimits{"drive": {Tx: 1, Rx: 1}}
+	require.NoError(t, json.Unmarshal(out, &got))
+	assert.Equal(t, limits, got)
+
+	var tps RemoteTPSLimits
+	require.NoError(t, json.Unmarshal([]byte(`"nas=10"`), &tps))
+	assert.Equal(t, RemoteTPSLimits{"nas": 10}, tps)
+
+	var conns RemoteMaxConnections
+	require.NoError(t, json.Unmarshal([]byte(`"nas=4"`), &conns))
+	assert.Equal(t, RemoteMaxConnections{"nas": 4}, conns)
+}
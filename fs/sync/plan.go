@@ -0,0 +1,330 @@
+// Compute a sync as a plan which can be applied later
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/operations"
+	"golang.org/x/sync/errgroup"
+)
+
+// Actions in a Plan
+const (
+	PlanCopy   = "copy"   // copy Path from the source to the destination
+	PlanDelete = "delete" // delete Path from the destination
+	PlanRename = "rename" // rename OldPath to Path in the destination
+)
+
+// PlanAction is a single change to the destination in a Plan
+type PlanAction struct {
+	Action              string `json:"action"`                        // one of the Plan constants
+	Path                string `json:"path"`                          // path of the file in the destination and the source
+	OldPath             string `json:"oldPath,omitempty"`             // path in the destination being renamed from
+	Size                int64  `json:"size"`                          // size of the file being copied, deleted or renamed
+	SrcFingerprint      string `json:"srcFingerprint,omitempty"`      // fingerprint of the source for copies
+	DstFingerprint      string `json:"dstFingerprint,omitempty"`      // fingerprint of the destination being overwritten, deleted or renamed
+	CopyDest            string `json:"copyDest,omitempty"`            // remote the file is server-side copied from if found with --copy-dest
+	CopyDestFingerprint string `json:"copyDestFingerprint,omitempty"` // fingerprint of the file in CopyDest
+}
+
+// Plan is the set of changes a sync would make to the destination
+type Plan struct {
+	Source      string        `json:"source"`      // source remote
+	Destination string        `json:"destination"` // destination remote
+	Created     time.Time     `json:"created"`     // time the plan was made
+	Actions     []*PlanAction `json:"actions"`     // changes to make - renames, then copies, then deletes
+}
+
+// order to apply the actions in
+var planActionOrder = map[string]int{
+	PlanRename: 0,
+	PlanCopy:   1,
+	PlanDelete: 2,
+}
+
+// MakePlan works out what Sync would do to make fdst the same as fsrc
+// without changing anything and returns it as a Plan.
+func MakePlan(ctx context.Context, fdst, fsrc fs.Fs, createEmptySrcDirs bool) (*Plan, error) {
+	for _, f := range []fs.Fs{fsrc, fdst} {
+		if err := checkPlanRemote(f); err != nil {
+			return nil, err
+		}
+	}
+	plan := &Plan{
+		Source:      fs.ConfigString(fsrc),
+		Destination: fs.ConfigString(fdst),
+		Created:     time.Now(),
+		Actions:     []*PlanAction{},
+	}
+	ctx, ci := fs.AddConfig(ctx)
+	ci.DryRun = true
+	var copyDest []fs.Fs
+	if len(ci.CopyDest) > 0 {
+		var err error
+		copyDest, err = operations.GetCopyDest(ctx, fdst)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var failed []string
+	results := operations.NewResultWriterFn(func(r *operations.Result) {
+		var action *PlanAction
+		switch r.Action {
+		case operations.ResultCopied:
+			if dst, ok := r.Dst().(fs.Object); ok && r.Reason == operations.ReasonCopyDest && operations.Equal(ctx, r.Src(), dst) {
+				// --copy-dest found the destination is unchanged
+				break
+			}
+			action = &PlanAction{
+				Action:         PlanCopy,
+				Path:           r.Path,
+				Size:           r.Src().Size(),
+				SrcFingerprint: fs.Fingerprint(ctx, r.Src(), true),
+			}
+			if r.Dst() != nil {
+				action.DstFingerprint = fs.Fingerprint(ctx, r.Dst(), true)
+			}
+			if r.Reason == operations.ReasonCopyDest {
+				planCopyDest(ctx, action, copyDest, r.Src())
+			}
+		case operations.ResultDeleted:
+			action = &PlanAction{
+				Action:         PlanDelete,
+				Path:           r.Path,
+				Size:           r.Dst().Size(),
+				DstFingerprint: fs.Fingerprint(ctx, r.Dst(), true),
+			}
+		case operations.ResultMoved:
+			action = &PlanAction{
+				Action:         PlanRename,
+				Path:           r.Path,
+				OldPath:        r.Dst().Remote(),
+				Size:           r.Dst().Size(),
+				DstFingerprint: fs.Fingerprint(ctx, r.Dst(), true),
+			}
+		case operations.ResultError:
+			failed = append(failed, r.Path)
+		}
+		if action != nil {
+			plan.Actions = append(plan.Actions, action)
+		}
+	})
+	ctx = operations.WithResultWriter(ctx, results)
+	err := Sync(ctx, fdst, fsrc, createEmptySrcDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make plan: %w", err)
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("failed to make plan: errors with %d files, e.g. %q", len(failed), failed[0])
+	}
+	sort.Slice(plan.Actions, func(i, j int) bool {
+		a, b := plan.Actions[i], plan.Actions[j]
+		if a.Action != b.Action {
+			return planActionOrder[a.Action] < planActionOrder[b.Action]
+		}
+		return a.Path < b.Path
+	})
+	fs.Infof(fdst, "Plan has %d changes", len(plan.Actions))
+	return plan, nil
+}
+
+// checkPlanRemote returns an error if f can't be written to a plan.
+//
+// Remotes with parameters in their names, such as on the fly remotes
+// or connection strings, can't be written without writing those
+// parameters, which may be credentials, to the plan.
+func checkPlanRemote(f fs.Fs) error {
+	if fs.ConfigString(f) != fs.ConfigStringFull(f) {
+		return fmt.Errorf("can't make a plan for %q as its parameters would be written to the plan - put them in the config file instead", fs.ConfigString(f))
+	}
+	return nil
+}
+
+// planCopyDest records in action the first of copyDest which has a
+// copy of src so it can be server-side copied from there again when
+// the plan is applied.
+//
+// If it can't be found the file is copied from the source instead.
+func planCopyDest(ctx context.Context, action *PlanAction, copyDest []fs.Fs, src fs.ObjectInfo) {
+	for _, f := range copyDest {
+		o, err := f.NewObject(ctx, action.Path)
+		if err != nil {
+			continue
+		}
+		if operations.Equal(ctx, src, o) {
+			if err := checkPlanRemote(f); err != nil {
+				fs.Logf(action.Path, "The plan will copy the file from the source: %v", err)
+				return
+			}
+			action.CopyDest = fs.ConfigString(f)
+			action.CopyDestFingerprint = fs.Fingerprint(ctx, o, true)
+			return
+		}
+	}
+	fs.Logf(action.Path, "Couldn't find file in --copy-dest so the plan will copy it from the source")
+}
+
+// find the object at remote in f returning nil if it isn't found
+func findObject(ctx context.Context, f fs.Fs, remote string) (fs.Object, error) {
+	o, err := f.NewObject(ctx, remote)
+	if errors.Is(err, fs.ErrorObjectNotFound) {
+		return nil, nil
+	}
+	return o, err
+}
+
+// check the fingerprint of the object at remote in f is still want,
+// where want == "" means it should not exist, and return the object
+func checkFingerprint(ctx context.Context, f fs.Fs, remote string, want string) (fs.Object, error) {
+	o, err := findObject(ctx, f, remote)
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		if want != "" {
+			return nil, fmt.Errorf("%q in %v has been removed since the plan was made", remote, f)
+		}
+		return nil, nil
+	}
+	if want == "" {
+		return nil, fmt.Errorf("%q in %v has been created since the plan was made", remote, f)
+	}
+	if got := fs.Fingerprint(ctx, o, true); got != want {
+		return nil, fmt.Errorf("%q in %v has changed since the plan was made", remote, f)
+	}
+	return o, nil
+}
+
+// checkCopyDest checks the file in the --copy-dest recorded in action
+// is unchanged and returns it
+func checkCopyDest(ctx context.Context, action *PlanAction) (fs.Object, error) {
+	f, err := cache.Get(ctx, action.CopyDest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make fs for --copy-dest %q: %w", action.CopyDest, err)
+	}
+	return checkFingerprint(ctx, f, action.Path, action.CopyDestFingerprint)
+}
+
+// planObjects are the objects found for a PlanAction
+type planObjects struct {
+	src fs.Object // source for copies - the file in --copy-dest if set
+	dst fs.Object // destination which is overwritten, deleted or renamed
+}
+
+// ApplyPlan makes the changes in plan to fdst using fsrc as the
+// source of copies.
+//
+// Files found with --copy-dest when the plan was made are server-side
+// copied from the same place again. Files which are overwritten or
+// deleted are moved to --backup-dir or renamed with --suffix if set,
+// as with sync.
+//
+// It first checks that none of the files in the plan have changed
+// since it was made and returns an error without changing anything if
+// any of them have.
+func ApplyPlan(ctx context.Context, fdst, fsrc fs.Fs, plan *Plan) error {
+	ci := fs.GetConfig(ctx)
+
+	// Check everything first
+	objects := make([]planObjects, len(plan.Actions))
+	var changed int
+	var firstErr error
+	for i, action := range plan.Actions {
+		var err error
+		switch action.Action {
+		case PlanCopy:
+			objects[i].src, err = checkFingerprint(ctx, fsrc, action.Path, action.SrcFingerprint)
+			if err == nil && action.CopyDest != "" {
+				objects[i].src, err = checkCopyDest(ctx, action)
+			}
+			if err == nil {
+				objects[i].dst, err = checkFingerprint(ctx, fdst, action.Path, action.DstFingerprint)
+			}
+		case PlanDelete:
+			objects[i].dst, err = checkFingerprint(ctx, fdst, action.Path, action.DstFingerprint)
+		case PlanRename:
+			objects[i].dst, err = checkFingerprint(ctx, fdst, action.OldPath, action.DstFingerprint)
+		default:
+			err = fmt.Errorf("unknown action %q for %q", action.Action, action.Path)
+		}
+		if err != nil {
+			fs.Errorf(action.Path, "Can't apply plan: %v", err)
+			changed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if changed > 0 {
+		return fmt.Errorf("not applying plan as %d files have changed: %w", changed, firstErr)
+	}
+
+	// Make Fs for --backup-dir if required
+	var backupDir fs.Fs
+	if ci.BackupDir != "" || ci.Suffix != "" {
+		var err error
+		backupDir, err = operations.BackupDir(ctx, fdst, fsrc, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Then apply the actions in order, running actions of the
+	// same type in parallel
+	var lastErr error
+	for start := 0; start < len(plan.Actions); {
+		end := start + 1
+		for end < len(plan.Actions) && plan.Actions[end].Action == plan.Actions[start].Action {
+			end++
+		}
+		g, gCtx := errgroup.WithContext(ctx)
+		g.SetLimit(ci.Transfers)
+		for i := start; i < end; i++ {
+			action, obj := plan.Actions[i], objects[i]
+			g.Go(func() error {
+				var err error
+				switch action.Action {
+				case PlanCopy:
+					if obj.dst != nil && backupDir != nil {
+						err = operations.MoveBackupDir(gCtx, backupDir, obj.dst)
+						if err != nil {
+							err = fmt.Errorf("moving to --backup-dir failed: %w", err)
+							break
+						}
+						obj.dst = nil
+					}
+					_, err = operations.Copy(gCtx, fdst, obj.dst, action.Path, obj.src)
+				case PlanDelete:
+					err = operations.DeleteFileWithBackupDir(gCtx, obj.dst, backupDir)
+				case PlanRename:
+					_, err = operations.Move(gCtx, fdst, nil, action.Path, obj.dst)
+				}
+				if err != nil {
+					err = fs.CountError(err)
+					fs.Errorf(action.Path, "Failed to %s: %v", action.Action, err)
+					return err
+				}
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			lastErr = err
+			// Don't delete anything if the copies failed
+			if plan.Actions[start].Action != PlanDelete {
+				return fmt.Errorf("failed to apply plan: %w", lastErr)
+			}
+		}
+		start = end
+	}
+	if lastErr != nil {
+		return fmt.Errorf("failed to apply plan: %w", lastErr)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile("new", "new file", t1)
+	file2 := r.WriteBoth(ctx, "unchanged", "unchanged", t1)
+	file3 := r.WriteFile("changed", "source version", t2)
+	file3dst := r.WriteObject(ctx, "changed", "dest version", t1)
+	extra := r.WriteObject(ctx, "extra", "only in destination", t1)
+
+	plan, err := MakePlan(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	// Nothing should have changed
+	r.CheckRemoteItems(t, file2, file3dst, extra)
+
+	require.Equal(t, 3, len(plan.Actions))
+	assert.Equal(t, PlanCopy, plan.Actions[0].Action)
+	assert.Equal(t, "changed", plan.Actions[0].Path)
+	assert.NotEqual(t, "", plan.Actions[0].SrcFingerprint)
+	assert.NotEqual(t, "", plan.Actions[0].DstFingerprint)
+	assert.Equal(t, PlanCopy, plan.Actions[1].Action)
+	assert.Equal(t, "new", plan.Actions[1].Path)
+	assert.Equal(t, "", plan.Actions[1].DstFingerprint)
+	assert.Equal(t, PlanDelete, plan.Actions[2].Action)
+	assert.Equal(t, "extra", plan.Actions[2].Path)
+	assert.Equal(t, int64(19), plan.Actions[2].Size)
+
+	require.NoError(t, ApplyPlan(ctx, r.Fremote, r.Flocal, plan))
+	r.CheckRemoteItems(t, file1, file2, file3)
+}
+
+func TestPlanParameters(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	r.WriteFile("file1", "file one", t1)
+
+	// Remotes with parameters in their names are refused
+	fsrc, err := fs.NewFs(ctx, ":local,case_insensitive=false:"+r.LocalName)
+	require.NoError(t, err)
+	_, err = MakePlan(ctx, r.Fremote, fsrc, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "put them in the config file")
+
+	// Others are written without their parameters
+	plan, err := MakePlan(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	assert.Equal(t, fs.ConfigString(r.Flocal), plan.Source)
+	assert.Equal(t, fs.ConfigString(r.Fremote), plan.Destination)
+	assert.NotContains(t, plan.Source, "{")
+}
+
+func TestPlanChanged(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	r.WriteFile("file1", "file one", t1)
+	r.WriteFile("file2", "file two", t1)
+	extra := r.WriteObject(ctx, "extra", "only in destination", t1)
+
+	plan, err := MakePlan(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(plan.Actions))
+
+	// Change a source file after making the plan
+	r.WriteFile("file2", "file two has changed", t2)
+
+	err = ApplyPlan(ctx, r.Fremote, r.Flocal, plan)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "have changed")
+
+	// Nothing should have been applied
+	r.CheckRemoteItems(t, extra)
+}
+
+func TestPlanCopyDestBackupDir(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	if r.Fremote.Features().Copy == nil {
+		t.Skip("Skipping test as remote does not support server-side copy")
+	}
+	fdst, err := fs.NewFs(ctx, r.FremoteName+"/dst")
+	require.NoError(t, err)
+
+	file1 := r.WriteFile("one", "one", t1)
+	file2 := r.WriteFile("changed", "source version", t2)
+	copyDest := r.WriteObject(ctx, "CopyDest/one", "one", t1)
+	file2dst := r.WriteObject(ctx, "dst/changed", "dest version", t1)
+	extra := r.WriteObject(ctx, "dst/extra", "only in destination", t1)
+
+	ci.CopyDest = []string{r.FremoteName + "/CopyDest"}
+	plan, err := MakePlan(ctx, fdst, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, copyDest, file2dst, extra)
+
+	require.Equal(t, 3, len(plan.Actions))
+	assert.Equal(t, "changed", plan.Actions[0].Path)
+	assert.Equal(t, "", plan.Actions[0].CopyDest)
+	assert.Equal(t, "one", plan.Actions[1].Path)
+	assert.NotEqual(t, "", plan.Actions[1].CopyDest)
+	assert.NotEqual(t, "", plan.Actions[1].CopyDestFingerprint)
+	assert.Equal(t, PlanDelete, plan.Actions[2].Action)
+
+	// --copy-dest comes from the plan but --backup-dir must be given
+	ci.CopyDest = nil
+	ci.BackupDir = r.FremoteName + "/BackupDir"
+	require.NoError(t, ApplyPlan(ctx, fdst, r.Flocal, plan))
+
+	file1.Path = "dst/one"
+	file2.Path = "dst/changed"
+	file2dst.Path = "BackupDir/changed"
+	extra.Path = "BackupDir/extra"
+	r.CheckRemoteItems(t, copyDest, file1, file2, file2dst, extra)
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/march"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ErrorMaxDurationReached defines error when transfer duration is reached
@@ -38,50 +40,52 @@ type syncCopyMove struct {
 	deleteEmptySrcDirs bool
 	dir                string
 	// internal state
-	ci                     *fs.ConfigInfo         // global config
-	fi                     *filter.Filter         // filter config
-	ctx                    context.Context        // internal context for controlling go-routines
-	cancel                 func()                 // cancel the context
-	inCtx                  context.Context        // internal context for controlling march
-	inCancel               func()                 // cancel the march context
-	noTraverse             bool                   // if set don't traverse the dst
-	noCheckDest            bool                   // if set transfer all objects regardless without checking dst
-	noUnicodeNormalization bool                   // don't normalize unicode characters in filenames
-	deletersWg             sync.WaitGroup         // for delete before go routine
-	deleteFilesCh          chan fs.Object         // channel to receive deletes if delete before
-	trackRenames           bool                   // set if we should do server-side renames
-	trackRenamesStrategy   trackRenamesStrategy   // strategies used for tracking renames
-	dstFilesMu             sync.Mutex             // protect dstFiles
-	dstFiles               map[string]fs.Object   // dst files, always filled
-	srcFiles               map[string]fs.Object   // src files, only used if deleteBefore
-	srcFilesChan           chan fs.Object         // passes src objects
-	srcFilesResult         chan error             // error result of src listing
-	dstFilesResult         chan error             // error result of dst listing
-	dstEmptyDirsMu         sync.Mutex             // protect dstEmptyDirs
-	dstEmptyDirs           map[string]fs.DirEntry // potentially empty directories
-	srcEmptyDirsMu         sync.Mutex             // protect srcEmptyDirs
-	srcEmptyDirs           map[string]fs.DirEntry // potentially empty directories
-	checkerWg              sync.WaitGroup         // wait for checkers
-	toBeChecked            *pipe                  // checkers channel
-	transfersWg            sync.WaitGroup         // wait for transfers
-	toBeUploaded           *pipe                  // copiers channel
-	errorMu                sync.Mutex             // Mutex covering the errors variables
-	err                    error                  // normal error from copy process
-	noRetryErr             error                  // error with NoRetry set
-	fatalErr               error                  // fatal error
-	commonHash             hash.Type              // common hash type between src and dst
-	modifyWindow           time.Duration          // modify window between fsrc, fdst
-	renameMapMu            sync.Mutex             // mutex to protect the below
-	renameMap              map[string][]fs.Object // dst files by hash - only used by trackRenames
-	renamerWg              sync.WaitGroup         // wait for renamers
-	toBeRenamed            *pipe                  // renamers channel
-	trackRenamesWg         sync.WaitGroup         // wg for background track renames
-	trackRenamesCh         chan fs.Object         // objects are pumped in here
-	renameCheck            []fs.Object            // accumulate files to check for rename here
-	compareCopyDest        []fs.Fs                // place to check for files to server side copy
-	backupDir              fs.Fs                  // place to store overwrites/deletes
-	checkFirst             bool                   // if set run all the checkers before starting transfers
-	maxDurationEndTime     time.Time              // end time if --max-duration is set
+	ci                     *fs.ConfigInfo           // global config
+	fi                     *filter.Filter           // filter config
+	ctx                    context.Context          // internal context for controlling go-routines
+	cancel                 func()                   // cancel the context
+	inCtx                  context.Context          // internal context for controlling march
+	inCancel               func()                   // cancel the march context
+	noTraverse             bool                     // if set don't traverse the dst
+	noCheckDest            bool                     // if set transfer all objects regardless without checking dst
+	noUnicodeNormalization bool                     // don't normalize unicode characters in filenames
+	deletersWg             sync.WaitGroup           // for delete before go routine
+	deleteFilesCh          chan fs.Object           // channel to receive deletes if delete before
+	trackRenames           bool                     // set if we should do server-side renames
+	trackRenamesStrategy   trackRenamesStrategy     // strategies used for tracking renames
+	dstFilesMu             sync.Mutex               // protect dstFiles
+	dstFiles               map[string]fs.Object     // dst files, always filled
+	srcFiles               map[string]fs.Object     // src files, only used if deleteBefore
+	srcFilesChan           chan fs.Object           // passes src objects
+	srcFilesResult         chan error               // error result of src listing
+	dstFilesResult         chan error               // error result of dst listing
+	dstEmptyDirsMu         sync.Mutex               // protect dstEmptyDirs
+	dstEmptyDirs           map[string]fs.DirEntry   // potentially empty directories
+	srcEmptyDirsMu         sync.Mutex               // protect srcEmptyDirs
+	srcEmptyDirs           map[string]fs.DirEntry   // potentially empty directories
+	checkerWg              sync.WaitGroup           // wait for checkers
+	toBeChecked            *pipe                    // checkers channel
+	transfersWg            sync.WaitGroup           // wait for transfers
+	toBeUploaded           *pipe                    // copiers channel
+	errorMu                sync.Mutex               // Mutex covering the errors variables
+	err                    error                    // normal error from copy process
+	noRetryErr             error                    // error with NoRetry set
+	fatalErr               error                    // fatal error
+	commonHash             hash.Type                // common hash type between src and dst
+	modifyWindow           time.Duration            // modify window between fsrc, fdst
+	renameMapMu            sync.Mutex               // mutex to protect the below
+	renameMap              map[string][]fs.Object   // dst files by hash - only used by trackRenames
+	renamerWg              sync.WaitGroup           // wait for renamers
+	toBeRenamed            *pipe                    // renamers channel
+	trackRenamesWg         sync.WaitGroup           // wg for background track renames
+	trackRenamesCh         chan fs.Object           // objects are pumped in here
+	renameCheck            []fs.Object              // accumulate files to check for rename here
+	compareCopyDest        []fs.Fs                  // place to check for files to server side copy
+	backupDir              fs.Fs                    // place to store overwrites/deletes
+	checkFirst             bool                     // if set run all the checkers before starting transfers
+	maxDurationEndTime     time.Time                // end time if --max-duration is set
+	results                *operations.ResultWriter // if set write a Result for each file here
+	reasons                sync.Map                 // reason each file is being transferred - only used if results is set
 }
 
 type trackRenamesStrategy byte
@@ -108,6 +112,9 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 	if (deleteMode != fs.DeleteModeOff || DoMove) && operations.OverlappingFilterCheck(ctx, fdst, fsrc) {
 		return nil, fserrors.FatalError(fs.ErrorOverlapping)
 	}
+	if fi := filter.GetConfig(ctx); deleteMode != fs.DeleteModeOff && fi.UsesAttributes() && !fi.Opt.FilterExprSync {
+		return nil, fserrors.FatalError(errors.New("--filter-expr with fields other than path, name, dir and ext can't be used with sync as changed files can be included on the source and excluded on the destination so the wrong files could be deleted - use copy instead or --filter-expr-sync"))
+	}
 	ci := fs.GetConfig(ctx)
 	fi := filter.GetConfig(ctx)
 	s := &syncCopyMove{
@@ -134,6 +141,7 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		modifyWindow:           fs.GetModifyWindow(ctx, fsrc, fdst),
 		trackRenamesCh:         make(chan fs.Object, ci.Checkers),
 		checkFirst:             ci.CheckFirst,
+		results:                operations.GetResultWriter(ctx),
 	}
 	backlog := ci.MaxBacklog
 	if s.checkFirst {
@@ -284,7 +292,7 @@ func (s *syncCopyMove) processError(err error) {
 		err = fserrors.NoRetryError(err)
 	} else if err == accounting.ErrorMaxTransferLimitReachedGraceful {
 		if s.inCtx.Err() == nil {
-			fs.Logf(nil, "%v - stopping transfers", err)
+			fs.LogfCtx(s.ctx, nil, "%v - stopping transfers", err)
 			// Cancel the march and stop the pipes
 			s.inCancel()
 		}
@@ -297,7 +305,7 @@ func (s *syncCopyMove) processError(err error) {
 	switch {
 	case fserrors.IsFatalError(err):
 		if !s.aborting() {
-			fs.Errorf(nil, "Cancelling sync due to fatal error: %v", err)
+			fs.ErrorfCtx(s.ctx, nil, "Cancelling sync due to fatal error: %v", err)
 			s.cancel()
 		}
 		s.fatalErr = err
@@ -338,9 +346,10 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 		src := pair.Src
 		var err error
 		tr := accounting.Stats(s.ctx).NewCheckingTransfer(src, "checking")
+		result := s.newResult(src, pair.Dst)
 		// Check to see if can store this
 		if src.Storable() {
-			needTransfer := operations.NeedTransfer(s.ctx, pair.Dst, pair.Src)
+			needTransfer, reason := operations.NeedTransferReason(s.ctx, pair.Dst, pair.Src)
 			if needTransfer {
 				NoNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, pair.Dst, pair.Src, s.compareCopyDest, s.backupDir)
 				if err != nil {
@@ -348,20 +357,27 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 				}
 				if NoNeedTransfer {
 					needTransfer = false
+					s.writeCompareOrCopyDestResult(result, err)
+					result = nil
 				}
 			}
 			if needTransfer {
 				// If files are treated as immutable, fail if destination exists and does not match
 				if s.ci.Immutable && pair.Dst != nil {
 					err := fs.CountError(fserrors.NoRetryError(fs.ErrorImmutableModified))
-					fs.Errorf(pair.Dst, "Source and destination exist but do not match: %v", err)
+					fs.ErrorfCtx(s.ctx, pair.Dst, "Source and destination exist but do not match: %v", err)
 					s.processError(err)
+					s.writeResult(result, operations.ResultError, reason, err)
 				} else {
+					if s.results != nil {
+						s.reasons.Store(src.Remote(), reason)
+					}
 					// If destination already exists, then we must move it into --backup-dir if required
 					if pair.Dst != nil && s.backupDir != nil {
 						err := operations.MoveBackupDir(s.ctx, s.backupDir, pair.Dst)
 						if err != nil {
 							s.processError(err)
+							s.writeResult(result, operations.ResultError, reason, err)
 						} else {
 							// If successful zero out the dst as it is no longer there and copy the file
 							pair.Dst = nil
@@ -378,6 +394,10 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 					}
 				}
 			} else {
+				if result != nil && s.ci.CheckSum {
+					result.SetHashes(s.ctx, src, pair.Dst)
+				}
+				s.writeResult(result, operations.ResultSkipped, reason, nil)
 				// If moving need to delete the files we don't need to copy
 				if s.DoMove {
 					// Delete src if no error on copy
@@ -403,6 +423,34 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 	}
 }
 
+// newResult starts a Result for src and dst if results are being
+// written, otherwise it returns nil.
+func (s *syncCopyMove) newResult(src, dst fs.Object) *operations.Result {
+	if s.results == nil {
+		return nil
+	}
+	return operations.NewResult(src.Remote(), src, dst)
+}
+
+// writeResult finishes result and writes it if it isn't nil
+func (s *syncCopyMove) writeResult(result *operations.Result, action operations.ResultAction, reason string, err error) {
+	if result == nil {
+		return
+	}
+	result.Done(action, reason, err)
+	s.results.Write(result)
+}
+
+// writeCompareOrCopyDestResult writes result for a file which didn't
+// need transferring because of --compare-dest or --copy-dest
+func (s *syncCopyMove) writeCompareOrCopyDestResult(result *operations.Result, err error) {
+	if len(s.ci.CompareDest) > 0 {
+		s.writeResult(result, operations.ResultSkipped, operations.ReasonCompareDest, err)
+	} else {
+		s.writeResult(result, operations.ResultCopied, operations.ReasonCopyDest, err)
+	}
+}
+
 // pairRenamer reads Objects~s on in and attempts to rename them,
 // otherwise it sends them out if they need transferring.
 func (s *syncCopyMove) pairRenamer(in *pipe, out *pipe, fraction int, wg *sync.WaitGroup) {
@@ -435,17 +483,34 @@ func (s *syncCopyMove) pairCopyOrMove(ctx context.Context, in *pipe, fdst fs.Fs,
 		}
 		src := pair.Src
 		dst := pair.Dst
+		result := s.newResult(src, dst)
+		action := operations.ResultCopied
+		var newDst fs.Object
 		if s.DoMove {
 			if src != dst {
-				_, err = operations.Move(ctx, fdst, dst, src.Remote(), src)
+				action = operations.ResultMoved
+				newDst, err = operations.Move(ctx, fdst, dst, src.Remote(), src)
 			} else {
 				// src == dst signals delete the src
+				action = operations.ResultDeleted
 				err = operations.DeleteFile(ctx, src)
 			}
 		} else {
-			_, err = operations.Copy(ctx, fdst, dst, src.Remote(), src)
+			newDst, err = operations.Copy(ctx, fdst, dst, src.Remote(), src)
 		}
 		s.processError(err)
+		if result != nil {
+			reason := operations.ReasonNew
+			if value, ok := s.reasons.LoadAndDelete(src.Remote()); ok {
+				reason = value.(string)
+			}
+			if action == operations.ResultDeleted {
+				reason = ""
+			} else if err == nil && s.ci.CheckSum && newDst != nil {
+				result.SetHashes(ctx, src, newDst)
+			}
+			s.writeResult(result, action, reason, err)
+		}
 	}
 }
 
@@ -555,7 +620,7 @@ func (s *syncCopyMove) stopDeleters() {
 // have been found have been removed from dstFiles already.
 func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 	if accounting.Stats(s.ctx).Errored() && !s.ci.IgnoreErrors {
-		fs.Errorf(s.fdst, "%v", fs.ErrorNotDeleting)
+		fs.ErrorfCtx(s.ctx, s.fdst, "%v", fs.ErrorNotDeleting)
 		return fs.ErrorNotDeleting
 	}
 
@@ -641,7 +706,7 @@ func copyEmptyDirectories(ctx context.Context, f fs.Fs, entries map[string]fs.Di
 		if ok {
 			err := operations.Mkdir(ctx, f, dir.Remote())
 			if err != nil {
-				fs.Errorf(fs.LogDirName(f, dir.Remote()), "Failed to Mkdir: %v", err)
+				fs.ErrorfCtx(ctx, fs.LogDirName(f, dir.Remote()), "Failed to Mkdir: %v", err)
 			} else {
 				okCount++
 			}
@@ -838,18 +903,20 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	dstOverwritten, _ := s.fdst.NewObject(s.ctx, src.Remote())
 
 	// Rename dst to have name src.Remote()
+	result := s.newResult(src, dst)
 	_, err := operations.Move(s.ctx, s.fdst, dstOverwritten, src.Remote(), dst)
 	if err != nil {
 		fs.Debugf(src, "Failed to rename to %q: %v", dst.Remote(), err)
 		return false
 	}
+	s.writeResult(result, operations.ResultMoved, operations.ReasonRenamed, nil)
 
 	// remove file from dstFiles if present
 	s.dstFilesMu.Lock()
 	delete(s.dstFiles, dst.Remote())
 	s.dstFilesMu.Unlock()
 
-	fs.Infof(src, "Renamed from %q", dst.Remote())
+	fs.InfofCtx(s.ctx, src, "Renamed from %q", dst.Remote())
 	return true
 }
 
@@ -921,7 +988,7 @@ func (s *syncCopyMove) run() error {
 	// Delete files after
 	if s.deleteMode == fs.DeleteModeAfter {
 		if s.currentError() != nil && !s.ci.IgnoreErrors {
-			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeleting)
+			fs.ErrorfCtx(s.ctx, s.fdst, "%v", fs.ErrorNotDeleting)
 		} else {
 			s.processError(s.deleteFiles(false))
 		}
@@ -930,7 +997,7 @@ func (s *syncCopyMove) run() error {
 	// Prune empty directories
 	if s.deleteMode != fs.DeleteModeOff {
 		if s.currentError() != nil && !s.ci.IgnoreErrors {
-			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeletingDirs)
+			fs.ErrorfCtx(s.ctx, s.fdst, "%v", fs.ErrorNotDeletingDirs)
 		} else {
 			s.processError(s.deleteEmptyDirectories(s.ctx, s.fdst, s.dstEmptyDirs))
 		}
@@ -949,13 +1016,13 @@ func (s *syncCopyMove) run() error {
 
 	// If the duration was exceeded then add a Fatal Error so we don't retry
 	if !s.maxDurationEndTime.IsZero() && time.Since(s.maxDurationEndTime) > 0 {
-		fs.Errorf(s.fdst, "%v", ErrorMaxDurationReachedFatal)
+		fs.ErrorfCtx(s.ctx, s.fdst, "%v", ErrorMaxDurationReachedFatal)
 		s.processError(ErrorMaxDurationReachedFatal)
 	}
 
 	// Print nothing to transfer message if there were no transfers and no errors
 	if s.deleteMode != fs.DeleteModeOnly && accounting.Stats(s.ctx).GetTransfers() == 0 && s.currentError() == nil {
-		fs.Infof(nil, "There was nothing to transfer")
+		fs.InfofCtx(s.ctx, nil, "There was nothing to transfer")
 	}
 
 	// cancel the contexts to free resources
@@ -1025,11 +1092,14 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 			}
 		} else {
 			// Check CompareDest && CopyDest
+			result := s.newResult(x, nil)
 			NoNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, nil, x, s.compareCopyDest, s.backupDir)
 			if err != nil {
 				s.processError(err)
 			}
-			if !NoNeedTransfer {
+			if NoNeedTransfer {
+				s.writeCompareOrCopyDestResult(result, err)
+			} else {
 				// No need to check since doesn't exist
 				fs.Debugf(src, "Need to transfer - File not found at Destination")
 				ok := s.toBeUploaded.Put(s.inCtx, fs.ObjectPair{Src: x, Dst: nil})
@@ -1072,7 +1142,7 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 		} else {
 			// FIXME src is file, dst is directory
 			err := errors.New("can't overwrite directory with file")
-			fs.Errorf(dst, "%v", err)
+			fs.ErrorfCtx(s.ctx, dst, "%v", err)
 			s.processError(err)
 		}
 	case fs.Directory:
@@ -1092,7 +1162,7 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 		}
 		// FIXME src is dir, dst is file
 		err := errors.New("can't overwrite file with directory")
-		fs.Errorf(dst, "%v", err)
+		fs.ErrorfCtx(s.ctx, dst, "%v", err)
 		s.processError(err)
 	default:
 		panic("Bad object in DirEntries")
@@ -1107,7 +1177,15 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 // If DoMove is true then files will be moved instead of copied.
 //
 // dir is the start directory, "" for root
-func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "sync",
+		attribute.String("src", fs.ConfigString(fsrc)),
+		attribute.String("dst", fs.ConfigString(fdst)),
+		attribute.Bool("move", DoMove),
+	)
+	defer func() {
+		span.End(err)
+	}()
 	ci := fs.GetConfig(ctx)
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
@@ -3,7 +3,9 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime"
@@ -304,6 +306,50 @@ func TestCopyRedownload(t *testing.T) {
 // Create a file and sync it. Change the last modified date and resync.
 // If we're only doing sync by size and checksum, we expect nothing to
 // to be transferred on the second sync.
+// Test a Result is written for each file synced
+func TestSyncResults(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	var buf bytes.Buffer
+	ctx = operations.WithResultWriter(ctx, operations.NewResultWriter(&buf))
+
+	r.WriteFile("new", "new file", t1)
+	r.WriteBoth(ctx, "unchanged", "unchanged", t1)
+	r.WriteFile("size", "source version", t1)
+	r.WriteObject(ctx, "size", "dest version", t1)
+	r.WriteObject(ctx, "extra", "only in destination", t1)
+
+	accounting.GlobalStats().ResetCounters()
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	results := map[string]operations.Result{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var result operations.Result
+		require.NoError(t, json.Unmarshal([]byte(line), &result), line)
+		results[result.Path] = result
+	}
+	require.Equal(t, 4, len(results))
+
+	assert.Equal(t, operations.ResultCopied, results["new"].Action)
+	assert.Equal(t, operations.ReasonNew, results["new"].Reason)
+	assert.Nil(t, results["new"].DstSize)
+
+	assert.Equal(t, operations.ResultSkipped, results["unchanged"].Action)
+	assert.Equal(t, operations.ReasonUnchanged, results["unchanged"].Reason)
+
+	assert.Equal(t, operations.ResultCopied, results["size"].Action)
+	assert.Equal(t, operations.ReasonSize, results["size"].Reason)
+	require.NotNil(t, results["size"].SrcSize)
+	assert.Equal(t, int64(14), *results["size"].SrcSize)
+	require.NotNil(t, results["size"].DstSize)
+	assert.Equal(t, int64(12), *results["size"].DstSize)
+
+	assert.Equal(t, operations.ResultDeleted, results["extra"].Action)
+	assert.Nil(t, results["extra"].SrcSize)
+	assert.False(t, results["extra"].Start.IsZero())
+}
+
 func TestSyncBasedOnCheckSum(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -896,6 +942,63 @@ func TestSyncWithExclude(t *testing.T) {
 	r.CheckLocalItems(t, file2, file1, file3)
 }
 
+// Test sync refuses filter expressions on file attributes as changed
+// files would be excluded on one side and deleted on the other
+func TestSyncWithAttributeFilterExpr(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	if !r.Flocal.Hashes().Contains(hash.MD5) || !r.Fremote.Hashes().Contains(hash.MD5) {
+		t.Skip("Can't run this test without MD5 hashes")
+	}
+	file1 := r.WriteFile("potato", "new contents", t2)
+	file2 := r.WriteObject(ctx, "potato", "old", t1)
+	file3 := r.WriteObject(ctx, "other", "other", t1)
+	r.CheckLocalItems(t, file1)
+	r.CheckRemoteItems(t, file2, file3)
+
+	setExpr := func(text string, sync bool) *filter.Filter {
+		opt := filter.DefaultOpt
+		opt.FilterExpr = []string{text}
+		opt.FilterExprSync = sync
+		fi, err := filter.NewFilter(&opt)
+		require.NoError(t, err)
+		ctx = filter.ReplaceConfig(context.Background(), fi)
+		return fi
+	}
+
+	for _, text := range []string{"hash.md5 ~ '*'", "size > 4", "modtime > 2000-01-01", "meta.owner == ''", "tier == ''"} {
+		fi := setExpr(text, false)
+		assert.True(t, fi.UsesAttributes(), text)
+		accounting.GlobalStats().ResetCounters()
+		err := Sync(ctx, r.Fremote, r.Flocal, false)
+		require.Error(t, err, text)
+		assert.Contains(t, err.Error(), "can't be used with sync", text)
+		r.CheckRemoteItems(t, file2, file3)
+	}
+
+	// Copy doesn't delete anything so is OK
+	setExpr("hash.md5 ~ '*'", false)
+	accounting.GlobalStats().ResetCounters()
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1, file3)
+
+	// --filter-expr-sync allows them
+	setExpr("size > 5", true)
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1, file3)
+
+	// Expressions on paths are OK with sync
+	fi := setExpr("bucket(1) == 0 && name != 'x'", false)
+	assert.False(t, fi.UsesAttributes())
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	r.CheckRemoteItems(t, file1)
+}
+
 // Test with exclude and delete excluded
 func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	ctx := context.Background()
@@ -925,6 +1028,33 @@ func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	r.CheckLocalItems(t, file2)
 }
 
+// Test with filter files read from each directory
+func TestSyncWithFilterFile(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	file1 := r.WriteFile(".rcloneignore", "- *.log\n", t1)
+	file2 := r.WriteFile("file.txt", "text", t1)
+	file3 := r.WriteFile("file.log", "log", t1)
+	file4 := r.WriteFile("sub/.rcloneignore", "+ keep.log\n", t1)
+	file5 := r.WriteFile("sub/keep.log", "keep", t1)
+	file6 := r.WriteFile("sub/other.log", "other", t1)
+	file7 := r.WriteObject(ctx, "dst.log", "dst", t1)
+	r.CheckLocalItems(t, file1, file2, file3, file4, file5, file6)
+	r.CheckRemoteItems(t, file7)
+
+	fi, err := filter.NewFilter(nil)
+	require.NoError(t, err)
+	fi.Opt.FilterFileName = []string{".rcloneignore"}
+	ctx = filter.ReplaceConfig(ctx, fi)
+
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	// dst.log is excluded by the source filter file so isn't deleted
+	r.CheckRemoteItems(t, file1, file2, file4, file5, file7)
+}
+
 // Test with UpdateOlder set
 func TestSyncWithUpdateOlder(t *testing.T) {
 	ctx := context.Background()
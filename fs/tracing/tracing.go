@@ -0,0 +1,202 @@
+// Package tracing sends OpenTelemetry traces of rclone's operations
+// to an OTLP/HTTP endpoint.
+//
+// Spans are only made if --trace-endpoint is set so the functions in
+// this package are cheap to call otherwise.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/pacer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The name of the tracer
+const tracerName = "github.com/rclone/rclone"
+
+// Pacer waits shorter than this aren't traced
+const minPacerWait = time.Millisecond
+
+var (
+	enabled atomic.Bool
+	tracer  atomic.Pointer[trace.Tracer] // set when enabled
+)
+
+// Start sends traces to --trace-endpoint if it is set.
+//
+// It returns a function which sends any traces not yet sent and
+// stops tracing. This should be called before rclone exits.
+func Start(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	ci := fs.GetConfig(ctx)
+	if ci.TraceEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	opts, err := exporterOptions(ci.TraceEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName("rclone"),
+			semconv.ServiceVersion(fs.Version),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ci.TraceSampleRatio))),
+	)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		fs.Errorf(nil, "Failed to send traces: %v", err)
+	}))
+	setProvider(provider)
+	fs.Infof(nil, "Sending traces to %s", ci.TraceEndpoint)
+	return func(ctx context.Context) error {
+		setProvider(nil)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// exporterOptions parses the endpoint into options for the exporter
+func exporterOptions(endpoint string) (opts []otlptracehttp.Option, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --trace-endpoint: %w", err)
+	}
+	if u.Host == "" {
+		return nil, errors.New("--trace-endpoint needs to be a URL, e.g. http://localhost:4318")
+	}
+	opts = append(opts, otlptracehttp.WithEndpoint(u.Host))
+	switch u.Scheme {
+	case "http":
+		opts = append(opts, otlptracehttp.WithInsecure())
+	case "https":
+	default:
+		return nil, fmt.Errorf("--trace-endpoint must be an http or https URL not %q", u.Scheme)
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	return opts, nil
+}
+
+// setProvider sets the provider spans are made with or turns tracing
+// off if it is nil.
+func setProvider(provider trace.TracerProvider) {
+	if provider == nil {
+		enabled.Store(false)
+		fs.SetLogExtraFields(nil)
+		pacer.SetOnWait(nil)
+		return
+	}
+	t := provider.Tracer(tracerName, trace.WithInstrumentationVersion(fs.Version))
+	tracer.Store(&t)
+	otel.SetTracerProvider(provider)
+	fs.SetLogExtraFields(logFields)
+	pacer.SetOnWait(pacerWait)
+	enabled.Store(true)
+}
+
+// Enabled returns true if spans are being made
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Span is an operation being traced. A nil *Span does nothing so it
+// is safe to use when tracing is disabled.
+type Span struct {
+	span trace.Span
+}
+
+// getTracer returns the tracer or nil if tracing is disabled
+func getTracer() trace.Tracer {
+	if !enabled.Load() {
+		return nil
+	}
+	t := tracer.Load()
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// StartSpan starts a span called name as a child of the span in ctx
+// if there is one.
+//
+// The context returned has the new span in so pass it to anything
+// called which should be part of the span. Messages logged with it
+// by fs.InfofCtx and friends are tagged with the trace and span IDs.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, *Span) {
+	t := getTracer()
+	if t == nil {
+		return ctx, nil
+	}
+	ctx, span := t.Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, &Span{span: span}
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...attribute.KeyValue) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attrs...)
+}
+
+// End the span recording err if it isn't nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// logFields returns the trace and span IDs for messages logged with ctx
+func logFields(ctx context.Context) []string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []string{"trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String()}
+}
+
+// pacerWait records a span for a wait for the pacer as a child of
+// the span in ctx.
+//
+// Waits by calls made without a span in ctx aren't recorded as they
+// would make a trace of their own.
+func pacerWait(ctx context.Context, start time.Time) {
+	end := time.Now()
+	if end.Sub(start) < minPacerWait {
+		return
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	t := getTracer()
+	if t == nil {
+		return
+	}
+	_, span := t.Start(ctx, "pacer wait", trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// start tracing to an in memory exporter
+func startTest(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	setProvider(provider)
+	t.Cleanup(func() {
+		setProvider(nil)
+		require.NoError(t, provider.Shutdown(context.Background()))
+	})
+	return exporter
+}
+
+func TestDisabled(t *testing.T) {
+	assert.False(t, Enabled())
+	ctx := context.Background()
+	newCtx, span := StartSpan(ctx, "test")
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, newCtx)
+	span.SetAttributes()
+	span.End(nil)
+}
+
+func TestSpans(t *testing.T) {
+	exporter := startTest(t)
+	ctx := context.Background()
+
+	ctx, parent := StartSpan(ctx, "parent")
+	require.NotNil(t, parent)
+
+	// Log messages have the trace and span IDs
+	assert.Nil(t, logFields(context.Background()))
+	fields := logFields(ctx)
+	require.Len(t, fields, 4)
+	assert.Equal(t, "trace_id", fields[0])
+	assert.Equal(t, parent.span.SpanContext().TraceID().String(), fields[1])
+	assert.Equal(t, "span_id", fields[2])
+	assert.Equal(t, parent.span.SpanContext().SpanID().String(), fields[3])
+
+	// A child of the span in the context
+	_, child := StartSpan(ctx, "child")
+	child.End(errors.New("boom"))
+
+	// A child of the span in the context passed to a goroutine
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, span := StartSpan(ctx, "goroutine")
+		span.End(nil)
+	}()
+	wg.Wait()
+
+	// Not a child without the context
+	_, other := StartSpan(context.Background(), "other")
+	other.End(nil)
+
+	parent.End(nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	parentID := byName["parent"].SpanContext.SpanID()
+	assert.Equal(t, parentID, byName["child"].Parent.SpanID())
+	assert.Equal(t, parentID, byName["goroutine"].Parent.SpanID())
+	assert.False(t, byName["other"].Parent.IsValid())
+	assert.Equal(t, codes.Error, byName["child"].Status.Code)
+	assert.Equal(t, "boom", byName["child"].Status.Description)
+	assert.Equal(t, codes.Unset, byName["parent"].Status.Code)
+}
+
+func TestPacerWait(t *testing.T) {
+	exporter := startTest(t)
+	ctx, parent := StartSpan(context.Background(), "parent")
+	pacerWait(ctx, time.Now())
+	assert.Len(t, exporter.GetSpans(), 0)
+
+	// Waits without a span aren't recorded
+	pacerWait(context.Background(), time.Now().Add(-10*time.Millisecond))
+	assert.Len(t, exporter.GetSpans(), 0)
+
+	pacerWait(ctx, time.Now().Add(-10*time.Millisecond))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "pacer wait", spans[0].Name)
+	parent.End(nil)
+	spans = exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+}
+
+func TestExporterOptions(t *testing.T) {
+	for _, test := range []struct {
+		endpoint string
+		n        int
+		err      bool
+	}{
+		{"http://localhost:4318", 2, false},
+		{"https://collector.example.com/", 1, false},
+		{"https://collector.example.com/custom/traces", 2, false},
+		{"localhost:4318", 0, true},
+		{"ftp://localhost:4318", 0, true},
+		{"::", 0, true},
+	} {
+		opts, err := exporterOptions(test.endpoint)
+		if test.err {
+			assert.Error(t, err, test.endpoint)
+		} else {
+			assert.NoError(t, err, test.endpoint)
+			assert.Len(t, opts, test.n, test.endpoint)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.TraceEndpoint = server.URL
+	stop, err := Start(ctx)
+	require.NoError(t, err)
+	assert.True(t, Enabled())
+
+	_, span := StartSpan(ctx, "test")
+	span.End(nil)
+
+	require.NoError(t, stop(ctx))
+	assert.False(t, Enabled())
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"POST /v1/traces"}, requests)
+}
+
+// Check tracing can be stopped while spans are being made - run with -race
+func TestStopWhileRunning(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	setProvider(provider)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			ctx, span := StartSpan(context.Background(), "test")
+			fs.DebugfCtx(ctx, nil, "message %d", i)
+			pacerWait(ctx, time.Now().Add(-10*time.Millisecond))
+			span.End(nil)
+		}
+	}()
+	setProvider(nil)
+	wg.Wait()
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
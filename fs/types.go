@@ -191,6 +191,15 @@ type Metadataer interface {
 	Metadata(ctx context.Context) (Metadata, error)
 }
 
+// SetMetadataer is an optional interface for Object
+type SetMetadataer interface {
+	// SetMetadata sets the keys in metadata on the object without
+	// uploading it again, leaving any other metadata alone.
+	//
+	// It should return ErrorNotImplemented if it can't do this.
+	SetMetadata(ctx context.Context, metadata Metadata) error
+}
+
 // FullObjectInfo contains all the read-only optional interfaces
 //
 // Use for checking making wrapping ObjectInfos implement everything
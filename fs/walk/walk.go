@@ -65,11 +65,14 @@ func Walk(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel i
 	ci := fs.GetConfig(ctx)
 	fi := filter.GetConfig(ctx)
 	ctx = filter.SetUseFilter(ctx, f.Features().FilterAware && !includeAll) // make filter-aware backends constrain List
+	if fi.UsesFilterFiles() {
+		ctx = filter.WithDirFilters(ctx, nil)
+	}
 	if ci.NoTraverse && fi.HaveFilesFrom() {
 		return walkR(ctx, f, path, includeAll, maxLevel, fn, fi.MakeListR(ctx, f.NewObject))
 	}
 	// FIXME should this just be maxLevel < 0 - why the maxLevel > 1
-	if (maxLevel < 0 || maxLevel > 1) && ci.UseListR && f.Features().ListR != nil {
+	if (maxLevel < 0 || maxLevel > 1) && ci.UseListR && f.Features().ListR != nil && !fi.UsesFilterFiles() {
 		return walkListR(ctx, f, path, includeAll, maxLevel, fn)
 	}
 	return walkListDirSorted(ctx, f, path, includeAll, maxLevel, fn)
@@ -591,10 +594,13 @@ func NewDirTree(ctx context.Context, f fs.Fs, path string, includeAll bool, maxL
 	if ci.NoTraverse && fi.HaveFilesFrom() {
 		return walkRDirTree(ctx, f, path, includeAll, maxLevel, fi.MakeListR(ctx, f.NewObject))
 	}
-	// if have ListR; and recursing; and not using --files-from; then build a DirTree with ListR
-	if ListR := f.Features().ListR; (maxLevel < 0 || maxLevel > 1) && ListR != nil && !fi.HaveFilesFrom() {
+	// if have ListR; and recursing; and not using --files-from or --filter-file-name; then build a DirTree with ListR
+	if ListR := f.Features().ListR; (maxLevel < 0 || maxLevel > 1) && ListR != nil && !fi.HaveFilesFrom() && !fi.UsesFilterFiles() {
 		return walkRDirTree(ctx, f, path, includeAll, maxLevel, ListR)
 	}
+	if fi.UsesFilterFiles() {
+		ctx = filter.WithDirFilters(ctx, nil)
+	}
 	// otherwise just use List
 	return walkNDirTree(ctx, f, path, includeAll, maxLevel, list.DirSorted)
 }
@@ -154,6 +154,7 @@ func MiddlewareAuthCustom(fn CustomAuthFn, realm string, userFromContext bool) M
 				return
 			}
 
+			r = r.WithContext(context.WithValue(r.Context(), ctxKeyUser, user))
 			if value != nil {
 				r = r.WithContext(context.WithValue(r.Context(), ctxKeyAuth, value))
 			}
@@ -463,6 +463,15 @@ func (s *Server) HTMLTemplate() *template.Template {
 	return s.htmlTemplate
 }
 
+// Addr returns the address of the first listener or nil if there
+// are none
+func (s *Server) Addr() net.Addr {
+	if len(s.instances) == 0 {
+		return nil
+	}
+	return s.instances[0].listener.Addr()
+}
+
 // URLs returns all configured URLS
 func (s *Server) URLs() []string {
 	var out []string
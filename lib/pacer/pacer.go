@@ -2,7 +2,9 @@
 package pacer
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	liberrors "github.com/rclone/rclone/lib/errors"
@@ -31,12 +33,15 @@ type Pacer struct {
 	pacer      chan struct{} // To pace the operations
 	connTokens chan struct{} // Connection tokens
 	state      State
+	metrics    *remoteMetrics
 }
 type pacerOptions struct {
-	maxConnections int         // Maximum number of concurrent connections
-	retries        int         // Max number of retries
-	calculator     Calculator  // switchable pacing algorithm - call with mu held
-	invoker        InvokerFunc // wrapper function used to invoke the target function
+	maxConnections int             // Maximum number of concurrent connections
+	connDispenser  *TokenDispenser // Connection tokens shared with other Pacers
+	retries        int             // Max number of retries
+	calculator     Calculator      // switchable pacing algorithm - call with mu held
+	invoker        InvokerFunc     // wrapper function used to invoke the target function
+	remote         string          // name of the remote for the metrics
 }
 
 // InvokerFunc is the signature of the wrapper function used to invoke the
@@ -61,11 +66,47 @@ func MaxConnectionsOption(maxConnections int) Option {
 	return func(p *pacerOptions) { p.maxConnections = maxConnections }
 }
 
+// TokenDispenserOption shares the connection tokens in td between
+// the new Pacer and any others made with it, so the number of
+// concurrent connections they make together is limited to the number
+// of tokens in td.
+//
+// This is as well as any limit set with MaxConnectionsOption.
+func TokenDispenserOption(td *TokenDispenser) Option {
+	return func(p *pacerOptions) { p.connDispenser = td }
+}
+
 // InvokerOption sets an InvokerFunc for the new Pacer.
 func InvokerOption(invoker InvokerFunc) Option {
 	return func(p *pacerOptions) { p.invoker = invoker }
 }
 
+// RemoteOption sets the name of the remote the new Pacer is for. This
+// is used to label its metrics.
+func RemoteOption(name string) Option {
+	return func(p *pacerOptions) { p.remote = name }
+}
+
+// OnWaitFn is called when a call has finished waiting for the pacer
+// with the context of the call and the time it started waiting. It is
+// used for tracing.
+type OnWaitFn func(ctx context.Context, start time.Time)
+
+// onWait is set with SetOnWait
+var onWait atomic.Pointer[OnWaitFn]
+
+// SetOnWait sets fn to be called when any call has finished waiting
+// for a pacer. Set fn to nil to remove it.
+//
+// This is safe to call while calls are being made.
+func SetOnWait(fn OnWaitFn) {
+	if fn == nil {
+		onWait.Store(nil)
+		return
+	}
+	onWait.Store(&fn)
+}
+
 // Paced is a function which is called by the Call and CallNoRetry
 // methods.  It should return a boolean, true if it would like to be
 // retried, and an error.  This error may be returned or returned
@@ -84,6 +125,7 @@ func New(options ...Option) *Pacer {
 	p := &Pacer{
 		pacerOptions: opts,
 		pacer:        make(chan struct{}, 1),
+		metrics:      DefaultMetrics.forRemote(opts.remote),
 	}
 	if p.calculator == nil {
 		p.SetCalculator(nil)
@@ -151,15 +193,24 @@ func (p *Pacer) ModifyCalculator(f func(Calculator)) {
 // This must be called as a pair with endCall.
 //
 // This waits for the pacer token
-func (p *Pacer) beginCall() {
+func (p *Pacer) beginCall(ctx context.Context) {
 	// pacer starts with a token in and whenever we take one out
 	// XXX ms later we put another in.  We could do this with a
 	// Ticker more accurately, but then we'd have to work out how
 	// not to run it when it wasn't needed
+	start := time.Now()
 	<-p.pacer
+	slept := time.Now()
 	if p.maxConnections > 0 {
 		<-p.connTokens
 	}
+	if p.connDispenser != nil {
+		p.connDispenser.Get()
+	}
+	p.metrics.onBeginCall(slept.Sub(start), time.Since(slept))
+	if fn := onWait.Load(); fn != nil {
+		(*fn)(ctx, start)
+	}
 
 	p.mu.Lock()
 	// Restart the timer
@@ -175,6 +226,9 @@ func (p *Pacer) beginCall() {
 // This should calculate a new sleepTime.  It takes a boolean as to
 // whether the operation should be retried or not.
 func (p *Pacer) endCall(retry bool, err error) {
+	if p.connDispenser != nil {
+		p.connDispenser.Put()
+	}
 	if p.maxConnections > 0 {
 		p.connTokens <- struct{}{}
 	}
@@ -187,13 +241,14 @@ func (p *Pacer) endCall(retry bool, err error) {
 	p.state.LastError = err
 	p.state.SleepTime = p.calculator.Calculate(p.state)
 	p.mu.Unlock()
+	p.metrics.onEndCall(retry)
 }
 
 // call implements Call but with settable retries
-func (p *Pacer) call(fn Paced, retries int) (err error) {
+func (p *Pacer) call(ctx context.Context, fn Paced, retries int) (err error) {
 	var retry bool
 	for i := 1; i <= retries; i++ {
-		p.beginCall()
+		p.beginCall(ctx)
 		retry, err = p.invoker(i, retries, fn)
 		p.endCall(retry, err)
 		if !retry {
@@ -210,10 +265,16 @@ func (p *Pacer) call(fn Paced, retries int) (err error) {
 // error. This error may be returned wrapped in a RetryError if the
 // number of retries is exceeded.
 func (p *Pacer) Call(fn Paced) (err error) {
+	return p.CallCtx(context.Background(), fn)
+}
+
+// CallCtx is like Call but passes ctx to the hook set with SetOnWait
+// so the waits can be traced as part of the call.
+func (p *Pacer) CallCtx(ctx context.Context, fn Paced) (err error) {
 	p.mu.Lock()
 	retries := p.retries
 	p.mu.Unlock()
-	return p.call(fn, retries)
+	return p.call(ctx, fn, retries)
 }
 
 // CallNoRetry paces the remote operations to not exceed the limits
@@ -222,7 +283,13 @@ func (p *Pacer) Call(fn Paced) (err error) {
 // This calls fn and wraps the output in a RetryError if it would like
 // it to be retried
 func (p *Pacer) CallNoRetry(fn Paced) error {
-	return p.call(fn, 1)
+	return p.CallNoRetryCtx(context.Background(), fn)
+}
+
+// CallNoRetryCtx is like CallNoRetry but passes ctx to the hook set
+// with SetOnWait so the waits can be traced as part of the call.
+func (p *Pacer) CallNoRetryCtx(ctx context.Context, fn Paced) error {
+	return p.call(ctx, fn, 1)
 }
 
 func invoke(try, tries int, f Paced) (bool, error) {
@@ -1,11 +1,13 @@
 package pacer
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,6 +42,34 @@ func TestMaxConnections(t *testing.T) {
 	assert.Nil(t, p.connTokens)
 }
 
+func TestTokenDispenserOption(t *testing.T) {
+	td := NewTokenDispenser(1)
+	p1 := New(TokenDispenserOption(td), CalculatorOption(NewDefault(MinSleep(1*time.Millisecond))))
+	p2 := New(TokenDispenserOption(td), CalculatorOption(NewDefault(MinSleep(1*time.Millisecond))))
+
+	// Taking the token in one pacer blocks the other
+	p1.beginCall(context.Background())
+	assert.Equal(t, 0, len(td.tokens))
+	started := make(chan struct{})
+	go func() {
+		p2.beginCall(context.Background())
+		close(started)
+	}()
+	select {
+	case <-started:
+		t.Fatal("second pacer didn't wait for the shared token")
+	case <-time.After(10 * time.Millisecond):
+	}
+	p1.endCall(false, nil)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("second pacer didn't get the shared token")
+	}
+	p2.endCall(false, nil)
+	assert.Equal(t, 1, len(td.tokens))
+}
+
 func TestDecay(t *testing.T) {
 	c := NewDefault(MinSleep(1*time.Microsecond), MaxSleep(1*time.Second))
 	for _, test := range []struct {
@@ -106,7 +136,7 @@ func waitForPace(p *Pacer, duration time.Duration) (when time.Time) {
 func TestBeginCall(t *testing.T) {
 	p := New(MaxConnectionsOption(10), CalculatorOption(NewDefault(MinSleep(1*time.Millisecond))))
 	emptyTokens(p)
-	go p.beginCall()
+	go p.beginCall(context.Background())
 	if !waitForPace(p, 10*time.Millisecond).IsZero() {
 		t.Errorf("beginSleep fired too early #1")
 	}
@@ -129,7 +159,7 @@ func TestBeginCall(t *testing.T) {
 func TestBeginCallZeroConnections(t *testing.T) {
 	p := New(MaxConnectionsOption(0), CalculatorOption(NewDefault(MinSleep(1*time.Millisecond))))
 	emptyTokens(p)
-	go p.beginCall()
+	go p.beginCall(context.Background())
 	if !waitForPace(p, 10*time.Millisecond).IsZero() {
 		t.Errorf("beginSleep fired too early #1")
 	}
@@ -325,7 +355,7 @@ func TestCallFixed(t *testing.T) {
 	p := New(CalculatorOption(NewDefault(MinSleep(1*time.Millisecond), MaxSleep(2*time.Millisecond))))
 
 	dp := &dummyPaced{retry: false}
-	err := p.call(dp.fn, 10)
+	err := p.call(context.Background(), dp.fn, 10)
 	assert.Equal(t, 1, dp.called)
 	assert.Equal(t, errFoo, err)
 }
@@ -334,7 +364,7 @@ func Test_callRetry(t *testing.T) {
 	p := New(CalculatorOption(NewDefault(MinSleep(1*time.Millisecond), MaxSleep(2*time.Millisecond))))
 
 	dp := &dummyPaced{retry: true}
-	err := p.call(dp.fn, 10)
+	err := p.call(context.Background(), dp.fn, 10)
 	assert.Equal(t, 10, dp.called)
 	assert.Equal(t, errFoo, err)
 }
@@ -382,3 +412,38 @@ func TestCallParallel(t *testing.T) {
 	assert.Equal(t, 5, called)
 	wait.Broadcast()
 }
+
+func TestCallMetrics(t *testing.T) {
+	oldMetrics := DefaultMetrics
+	DefaultMetrics = NewMetrics("test")
+	defer func() { DefaultMetrics = oldMetrics }()
+	calculator := CalculatorOption(NewDefault(MinSleep(1*time.Millisecond), MaxSleep(2*time.Millisecond)))
+	p := New(calculator, RemoteOption("drive"))
+	other := New(calculator, RemoteOption("nas"))
+
+	dp := &dummyPaced{retry: true}
+	err := p.call(context.Background(), dp.fn, 3)
+	assert.Equal(t, errFoo, err)
+	dp = &dummyPaced{retry: false}
+	err = other.call(context.Background(), dp.fn, 3)
+	assert.Equal(t, errFoo, err)
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(DefaultMetrics.Calls.WithLabelValues("drive")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(DefaultMetrics.Retries.WithLabelValues("drive")))
+	assert.Greater(t, testutil.ToFloat64(DefaultMetrics.Sleep.WithLabelValues("drive")), 0.0)
+	assert.Equal(t, 1.0, testutil.ToFloat64(DefaultMetrics.Calls.WithLabelValues("nas")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(DefaultMetrics.Retries.WithLabelValues("nas")))
+
+	// A call waiting for a connection counts that as connection wait
+	// not sleep
+	limited := New(calculator, RemoteOption("limited"), MaxConnectionsOption(1))
+	limited.beginCall(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		limited.endCall(false, nil)
+	}()
+	limited.beginCall(context.Background())
+	limited.endCall(false, nil)
+	assert.GreaterOrEqual(t, testutil.ToFloat64(DefaultMetrics.ConnWait.WithLabelValues("limited")), 0.04)
+	assert.Less(t, testutil.ToFloat64(DefaultMetrics.Sleep.WithLabelValues("limited")), 0.04)
+}
@@ -0,0 +1,105 @@
+package pacer
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics provide pacer metrics labelled with the remote the pacer
+// is for.
+type Metrics struct {
+	Calls    *prometheus.CounterVec
+	Retries  *prometheus.CounterVec
+	Sleep    *prometheus.CounterVec
+	ConnWait *prometheus.CounterVec
+}
+
+// NewMetrics creates a new metrics instance, the instance shall be assigned to
+// DefaultMetrics before any pacers are created.
+func NewMetrics(namespace string) *Metrics {
+	return &Metrics{
+		Calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pacer",
+			Name:      "calls_total",
+			Help:      "Number of calls made through the pacers including retries",
+		}, []string{"remote"}),
+		Retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pacer",
+			Name:      "retries_total",
+			Help:      "Number of calls the pacers were asked to retry",
+		}, []string{"remote"}),
+		Sleep: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pacer",
+			Name:      "sleep_seconds_total",
+			Help:      "Total time calls spent sleeping in the pacers",
+		}, []string{"remote"}),
+		ConnWait: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pacer",
+			Name:      "connection_wait_seconds_total",
+			Help:      "Total time calls spent waiting for a free connection",
+		}, []string{"remote"}),
+	}
+}
+
+// DefaultMetrics specifies metrics used for new Pacers.
+var DefaultMetrics = (*Metrics)(nil)
+
+// Collectors returns all prometheus metrics as collectors for registration.
+func (m *Metrics) Collectors() []prometheus.Collector {
+	if m == nil {
+		return nil
+	}
+	return []prometheus.Collector{
+		m.Calls,
+		m.Retries,
+		m.Sleep,
+		m.ConnWait,
+	}
+}
+
+// remoteMetrics are the counters of Metrics for a single remote
+type remoteMetrics struct {
+	calls    prometheus.Counter
+	retries  prometheus.Counter
+	sleep    prometheus.Counter
+	connWait prometheus.Counter
+}
+
+// forRemote returns the counters for the remote called name or nil if
+// m is nil.
+func (m *Metrics) forRemote(name string) *remoteMetrics {
+	if m == nil {
+		return nil
+	}
+	return &remoteMetrics{
+		calls:    m.Calls.WithLabelValues(name),
+		retries:  m.Retries.WithLabelValues(name),
+		sleep:    m.Sleep.WithLabelValues(name),
+		connWait: m.ConnWait.WithLabelValues(name),
+	}
+}
+
+// onBeginCall records a call which slept for sleep in the pacer then
+// waited for connWait for a free connection.
+func (m *remoteMetrics) onBeginCall(sleep, connWait time.Duration) {
+	if m == nil {
+		return
+	}
+	m.calls.Inc()
+	m.sleep.Add(sleep.Seconds())
+	m.connWait.Add(connWait.Seconds())
+}
+
+func (m *remoteMetrics) onEndCall(retry bool) {
+	if m == nil {
+		return
+	}
+	if retry {
+		m.retries.Inc()
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"sync"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/tracing"
 	"github.com/rclone/rclone/lib/readers"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Client contains the info to sustain the API
@@ -244,6 +246,10 @@ func (api *Client) Call(ctx context.Context, opts *Opts) (resp *http.Response, e
 	if opts.ContentLength != nil && *opts.ContentLength == 0 {
 		body = nil
 	}
+	ctx, span := tracing.StartSpan(ctx, "rest "+opts.Method, attribute.String("rest.path", opts.Path))
+	defer func() {
+		span.End(err)
+	}()
 	req, err := http.NewRequestWithContext(ctx, opts.Method, url, body)
 	if err != nil {
 		return
@@ -98,6 +98,71 @@ void testCopyFile() {
             "}");
 }
 
+// stream a file to /tmp and back using RcloneWriteOpen and RcloneReadOpen
+void testStream() {
+    printf("test stream\n");
+    const char *data = "hello from librclone streaming";
+    struct RcloneRPCResult out = RcloneWriteOpen("{"
+            "\"fs\": \"/tmp\","
+            "\"remote\": \"librclone-stream-test\""
+            "}");
+    printf("status: %d\n", out.Status);
+    printf("output: %s\n", out.Output);
+    if (out.Status != 200) {
+        fprintf(stderr, "WriteOpen failed: %s\n", out.Output);
+        exit(EXIT_FAILURE);
+    }
+    long long handle = strtoll(strstr(out.Output, "\"handle\":") + 9, NULL, 10);
+    free(out.Output);
+    // write in chunks of 5 bytes
+    for (int i = 0; i < strlen(data); i += 5) {
+        int n = strlen(data) - i < 5 ? strlen(data) - i : 5;
+        if (RcloneWrite(handle, (char *)data + i, n) != n) {
+            fprintf(stderr, "Write failed\n");
+            break;
+        }
+    }
+    out = RcloneClose(handle);
+    printf("status: %d\n", out.Status);
+    printf("output: %s\n", out.Output);
+    if (out.Status != 200) {
+        fprintf(stderr, "Close failed: %s\n", out.Output);
+        exit(EXIT_FAILURE);
+    }
+    free(out.Output);
+
+    out = RcloneReadOpen("{"
+            "\"fs\": \"/tmp\","
+            "\"remote\": \"librclone-stream-test\""
+            "}");
+    printf("status: %d\n", out.Status);
+    printf("output: %s\n", out.Output);
+    if (out.Status != 200) {
+        fprintf(stderr, "ReadOpen failed: %s\n", out.Output);
+        exit(EXIT_FAILURE);
+    }
+    handle = strtoll(strstr(out.Output, "\"handle\":") + 9, NULL, 10);
+    free(out.Output);
+    // read in chunks of 7 bytes
+    char got[256] = "";
+    char buf[7];
+    int n;
+    while ((n = RcloneRead(handle, buf, sizeof(buf))) > 0) {
+        strncat(got, buf, n);
+    }
+    out = RcloneClose(handle);
+    if (n < 0 || out.Status != 200) {
+        fprintf(stderr, "Read failed: %s\n", out.Output);
+        exit(EXIT_FAILURE);
+    }
+    free(out.Output);
+    if (strcmp(data, got) != 0) {
+        fprintf(stderr, "Wrong data.\nWant: %s\nGot: %s\n", data, got);
+        exit(EXIT_FAILURE);
+    }
+    remove("/tmp/librclone-stream-test");
+}
+
 // list the remotes
 void testListRemotes() {
     printf("test operations/listremotes\n");
@@ -110,6 +175,7 @@ int main(int argc, char** argv) {
 
     testNoOp();
     testError();
+    testStream();
     /* testCopyFile(); */
     /* testListRemotes(); */
 
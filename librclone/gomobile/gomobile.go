@@ -2,6 +2,8 @@
 package gomobile
 
 import (
+	"io"
+
 	"github.com/rclone/rclone/librclone/librclone"
 
 	_ "github.com/rclone/rclone/backend/all" // import all backends
@@ -40,3 +42,62 @@ func RcloneRPC(method string, input string) (result *RcloneRPCResult) { //nolint
 		Status: status,
 	}
 }
+
+// RcloneReadOpen opens an object for reading with RcloneRead. See
+// librclone.ReadOpen for the input and output.
+func RcloneReadOpen(input string) (result *RcloneRPCResult) { //nolint:deadcode
+	output, status := librclone.ReadOpen(input)
+	return &RcloneRPCResult{
+		Output: output,
+		Status: status,
+	}
+}
+
+// RcloneRead reads up to size bytes from the handle opened with
+// RcloneReadOpen. It returns an empty slice at the end of the object.
+func RcloneRead(handle int64, size int) ([]byte, error) { //nolint:deadcode
+	p := make([]byte, size)
+	n, err := librclone.Read(handle, p)
+	if err == io.EOF {
+		return []byte{}, nil
+	}
+	return p[:n], err
+}
+
+// RcloneWriteOpen opens an object for writing with RcloneWrite. See
+// librclone.WriteOpen for the input and output.
+func RcloneWriteOpen(input string) (result *RcloneRPCResult) { //nolint:deadcode
+	output, status := librclone.WriteOpen(input)
+	return &RcloneRPCResult{
+		Output: output,
+		Status: status,
+	}
+}
+
+// RcloneWrite writes data to the handle opened with RcloneWriteOpen.
+func RcloneWrite(handle int64, data []byte) error { //nolint:deadcode
+	_, err := librclone.Write(handle, data)
+	return err
+}
+
+// RcloneClose closes a handle opened with RcloneReadOpen or
+// RcloneWriteOpen, finishing the upload if it was opened for
+// writing. See librclone.Close for the output.
+func RcloneClose(handle int64) (result *RcloneRPCResult) { //nolint:deadcode
+	output, status := librclone.Close(handle)
+	return &RcloneRPCResult{
+		Output: output,
+		Status: status,
+	}
+}
+
+// RcloneAbort closes a handle opened with RcloneReadOpen or
+// RcloneWriteOpen without finishing the upload if it was opened for
+// writing. See librclone.Abort for the output.
+func RcloneAbort(handle int64) (result *RcloneRPCResult) { //nolint:deadcode
+	output, status := librclone.Abort(handle)
+	return &RcloneRPCResult{
+		Output: output,
+		Status: status,
+	}
+}
@@ -30,6 +30,7 @@ struct RcloneRPCResult {
 import "C"
 
 import (
+	"io"
 	"unsafe"
 
 	"github.com/rclone/rclone/librclone/librclone"
@@ -100,5 +101,134 @@ func RcloneFreeString(str *C.char) {
 	C.free(unsafe.Pointer(str))
 }
 
+// RcloneReadOpen opens an object for reading in chunks with
+// RcloneRead. The input is a string with a serialized JSON object
+// with
+//
+//	fs - the remote name, eg "drive:"
+//	remote - the path of the object within fs, eg "dir/file.txt"
+//	offset - byte offset to start reading at (optional)
+//	count - maximum number of bytes to read (optional)
+//
+// result.Output will be a serialized JSON object with "handle" to
+// pass to RcloneRead and RcloneClose along with the "size" and
+// "modTime" of the object. result.Status is a HTTP status return
+// (200=OK anything else fail).
+//
+// Caller is responsible for freeing the memory for result.Output
+// (see RcloneFreeString) and for closing the handle with RcloneClose.
+//
+//export RcloneReadOpen
+func RcloneReadOpen(input *C.char) (result C.struct_RcloneRPCResult) { //nolint:golint
+	output, status := librclone.ReadOpen(C.GoString(input))
+	result.Output = C.CString(output)
+	result.Status = C.int(status)
+	return result
+}
+
+// RcloneRead reads up to size bytes from the handle opened with
+// RcloneReadOpen into buf.
+//
+// It returns the number of bytes read which will be size unless the
+// end of the object has been reached, 0 at the end of the object or
+// -1 on error. The error can be read from the result of RcloneClose.
+//
+//export RcloneRead
+func RcloneRead(handle C.longlong, buf *C.char, size C.int) C.int {
+	p := unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(size))
+	n, err := librclone.Read(int64(handle), p)
+	if err == io.EOF {
+		return 0
+	} else if err != nil {
+		return -1
+	}
+	return C.int(n)
+}
+
+// RcloneWriteOpen opens an object for writing in chunks with
+// RcloneWrite. The input is a string with a serialized JSON object
+// with
+//
+//	fs - the remote name, eg "drive:"
+//	remote - the path of the object within fs, eg "dir/file.txt"
+//	size - the size of the object if known (optional)
+//	modTime - the modification time of the object (optional)
+//
+// result.Output will be a serialized JSON object with "handle" to
+// pass to RcloneWrite and RcloneClose. result.Status is a HTTP status
+// return (200=OK anything else fail).
+//
+// Caller is responsible for freeing the memory for result.Output
+// (see RcloneFreeString) and for closing the handle with
+// RcloneClose which finishes the upload.
+//
+//export RcloneWriteOpen
+func RcloneWriteOpen(input *C.char) (result C.struct_RcloneRPCResult) { //nolint:golint
+	output, status := librclone.WriteOpen(C.GoString(input))
+	result.Output = C.CString(output)
+	result.Status = C.int(status)
+	return result
+}
+
+// RcloneWrite writes size bytes from buf to the handle opened with
+// RcloneWriteOpen.
+//
+// It returns the number of bytes written or -1 on error. The error
+// can be read from the result of RcloneClose, or the upload abandoned
+// with RcloneAbort.
+//
+//export RcloneWrite
+func RcloneWrite(handle C.longlong, buf *C.char, size C.int) C.int {
+	p := C.GoBytes(unsafe.Pointer(buf), size)
+	n, err := librclone.Write(int64(handle), p)
+	if err != nil {
+		return -1
+	}
+	return C.int(n)
+}
+
+// RcloneClose closes a handle opened with RcloneReadOpen or
+// RcloneWriteOpen.
+//
+// For a handle opened with RcloneWriteOpen this waits for the upload
+// to finish and result.Output will be a serialized JSON object with
+// the "remote", "size" and "modTime" of the uploaded object.
+// result.Status is a HTTP status return (200=OK anything else fail)
+// and if it failed result.Output will describe the error.
+//
+// Caller is responsible for freeing the memory for result.Output
+// (see RcloneFreeString).
+//
+//export RcloneClose
+func RcloneClose(handle C.longlong) (result C.struct_RcloneRPCResult) { //nolint:golint
+	output, status := librclone.Close(int64(handle))
+	result.Output = C.CString(output)
+	result.Status = C.int(status)
+	return result
+}
+
+// RcloneAbort closes a handle opened with RcloneReadOpen or
+// RcloneWriteOpen without finishing it.
+//
+// For a handle opened with RcloneWriteOpen this stops the upload so
+// the data written so far isn't stored. Use this instead of
+// RcloneClose if writing fails part way through.
+//
+// result.Output will be a serialized JSON object, empty unless there
+// was an error. result.Status is a HTTP status return (200=OK anything
+// else fail). It fails with 409 if the upload had already finished so
+// couldn't be aborted.
+//
+// Caller is responsible for freeing the memory for result.Output
+// (see RcloneFreeString).
+//
+//export RcloneAbort
+func RcloneAbort(handle C.longlong) (result C.struct_RcloneRPCResult) { //nolint:golint
+	output, status := librclone.Abort(int64(handle))
+	result.Output = C.CString(output)
+	result.Status = C.int(status)
+	return result
+}
+
 // do nothing here - necessary for building into a C library
 func main() {}
@@ -70,6 +70,18 @@ func writeError(path string, in rc.Params, err error, status int) (string, int)
 	return w.String(), status
 }
 
+// readInput decodes the JSON input into parameters
+func readInput(input string) (in rc.Params, err error) {
+	in = make(rc.Params)
+	if input != "" {
+		err = json.NewDecoder(strings.NewReader(input)).Decode(&in)
+		if err != nil {
+			return in, fmt.Errorf("failed to read input JSON: %w", err)
+		}
+	}
+	return in, nil
+}
+
 // RPC runs a transaction over the RC
 //
 // Calling an rc function using JSON to input parameters and output the resulted JSON.
@@ -88,11 +100,9 @@ func RPC(method string, input string) (output string, status int) {
 	}()
 
 	// create a buffer to capture the output
-	if input != "" {
-		err := json.NewDecoder(strings.NewReader(input)).Decode(&in)
-		if err != nil {
-			return writeError(method, in, fmt.Errorf("failed to read input JSON: %w", err), http.StatusBadRequest)
-		}
+	in, err := readInput(input)
+	if err != nil {
+		return writeError(method, in, err, http.StatusBadRequest)
 	}
 
 	// Find the call
@@ -114,7 +124,7 @@ func RPC(method string, input string) (output string, status int) {
 
 	fs.Debugf(nil, "rc: %q: with parameters %+v", method, in)
 
-	_, out, err := jobs.NewJob(context.Background(), call.Fn, in)
+	_, out, err := jobs.NewCallJob(context.Background(), call, in)
 	if err != nil {
 		return writeError(method, in, err, http.StatusInternalServerError)
 	}
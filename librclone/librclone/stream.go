@@ -0,0 +1,402 @@
+package librclone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// The streams are opened with a JSON input like RPC and return a
+// handle which is then used to read or write the contents of the
+// object in chunks, so they can be passed across the C boundary
+// without needing temporary files.
+
+// ErrorBadHandle is returned when a handle isn't open or is of the
+// wrong type
+var ErrorBadHandle = errors.New("stream handle not found")
+
+// ErrorAborted is the error an upload fails with when it is aborted
+// with Abort
+var ErrorAborted = errors.New("upload aborted")
+
+// stream is an open object being read or written
+type stream struct {
+	mu     sync.Mutex
+	remote string
+	// for reading
+	in      io.ReadCloser
+	tr      *accounting.Transfer
+	readErr error // first error returned by Read
+	// for writing
+	pipe *io.PipeWriter
+	done chan struct{} // closed when the upload has finished
+	obj  fs.Object     // the uploaded object, valid after done is closed
+	err  error         // error from the upload, valid after done is closed
+}
+
+var (
+	streamsMu  sync.Mutex
+	streams    = map[int64]*stream{}
+	lastHandle int64
+)
+
+// addStream adds s to the open streams returning its handle
+func addStream(s *stream) int64 {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	lastHandle++
+	streams[lastHandle] = s
+	return lastHandle
+}
+
+// getStream returns the stream for handle
+func getStream(handle int64) (*stream, error) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	s := streams[handle]
+	if s == nil {
+		return nil, ErrorBadHandle
+	}
+	return s, nil
+}
+
+// removeStream removes the stream for handle returning it
+func removeStream(handle int64) (*stream, error) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	s := streams[handle]
+	if s == nil {
+		return nil, ErrorBadHandle
+	}
+	delete(streams, handle)
+	return s, nil
+}
+
+// writeOutput returns params as a JSON string with http.StatusOK
+func writeOutput(path string, in, out rc.Params) (string, int) {
+	var w strings.Builder
+	err := rc.WriteJSON(&w, out)
+	if err != nil {
+		return writeError(path, in, err, http.StatusInternalServerError)
+	}
+	return w.String(), http.StatusOK
+}
+
+// objectOutput describes an object for the output of the stream calls
+func objectOutput(ctx context.Context, handle int64, o fs.Object) rc.Params {
+	out := rc.Params{
+		"remote":  o.Remote(),
+		"size":    o.Size(),
+		"modTime": o.ModTime(ctx),
+	}
+	if handle != 0 {
+		out["handle"] = handle
+	}
+	return out
+}
+
+// ReadOpen opens an object for reading. The input is a JSON object
+// with
+//
+//	fs - the remote name, e.g. "drive:"
+//	remote - the path of the object within fs, e.g. "dir/file.txt"
+//	offset - byte offset to start reading at, negative to read from the end (optional)
+//	count - maximum number of bytes to read, 0 to read nothing (optional)
+//
+// A negative offset larger than the size of the object reads from the
+// start of the object.
+//
+// The output is a JSON object with
+//
+//	handle - the handle to pass to Read and Close
+//	remote - the path of the object
+//	size - the size of the object
+//	modTime - the modification time of the object
+//
+// The status is an HTTP status, 200 for OK, as returned by RPC.
+//
+// The handle must be closed with Close when finished with.
+func ReadOpen(input string) (output string, status int) {
+	const path = "ReadOpen"
+	ctx := context.Background()
+	in, err := readInput(input)
+	if err != nil {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	f, remote, err := rc.GetFsAndRemote(ctx, in)
+	if err != nil {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	offset, err := in.GetInt64("offset")
+	if rc.NotErrParamNotFound(err) {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	count, err := in.GetInt64("count")
+	if rc.IsErrParamNotFound(err) {
+		count = -1
+	} else if err != nil {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	o, err := f.NewObject(ctx, remote)
+	if err != nil {
+		return writeError(path, in, err, http.StatusNotFound)
+	}
+
+	var options []fs.OpenOption
+	opt := fs.RangeOption{Start: offset, End: -1}
+	if opt.Start < 0 {
+		opt.Start += o.Size()
+		if opt.Start < 0 {
+			opt.Start = 0
+		}
+	}
+	if count > 0 {
+		opt.End = opt.Start + count - 1
+	}
+	if opt.Start > 0 || opt.End >= 0 {
+		options = append(options, &opt)
+	}
+	for _, option := range fs.GetConfig(ctx).DownloadHeaders {
+		options = append(options, option)
+	}
+	tr := accounting.Stats(ctx).NewTransfer(o)
+	var body io.ReadCloser
+	if count == 0 {
+		// Don't open the object to read nothing from it
+		body = io.NopCloser(strings.NewReader(""))
+	} else {
+		rd, err := operations.Open(ctx, o, options...)
+		if err != nil {
+			tr.Done(ctx, err)
+			return writeError(path, in, fmt.Errorf("failed to open: %w", err), http.StatusInternalServerError)
+		}
+		body = rd
+		if count > 0 {
+			body = readCloser{Reader: io.LimitReader(rd, count), Closer: rd}
+		}
+	}
+	handle := addStream(&stream{
+		remote: remote,
+		in:     tr.Account(ctx, body),
+		tr:     tr,
+	})
+	return writeOutput(path, in, objectOutput(ctx, handle, o))
+}
+
+// readCloser joins a Reader and a Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Read reads up to len(p) bytes from the stream opened with ReadOpen
+// into p returning the number of bytes read.
+//
+// At the end of the stream it returns 0, io.EOF.
+func Read(handle int64, p []byte) (n int, err error) {
+	s, err := getStream(handle)
+	if err != nil {
+		return 0, err
+	}
+	if s.in == nil {
+		return 0, ErrorBadHandle
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Fill p unless we reach the end so the caller gets full
+	// chunks where possible
+	if s.readErr != nil {
+		return 0, s.readErr
+	}
+	n, err = io.ReadFull(s.in, p)
+	if err == io.ErrUnexpectedEOF || (err == io.EOF && n > 0) {
+		err = nil
+	}
+	if err != nil && err != io.EOF {
+		s.readErr = err
+	}
+	return n, err
+}
+
+// WriteOpen opens an object for writing. The input is a JSON object
+// with
+//
+//	fs - the remote name, e.g. "drive:"
+//	remote - the path of the object within fs, e.g. "dir/file.txt"
+//	size - the size of the object if known (optional)
+//	modTime - the modification time for the object (optional, default now)
+//
+// This uploads the data written with Write in the same way as
+// "rclone rcat" does. If the size is set then exactly size bytes
+// must be written.
+//
+// The output is a JSON object with
+//
+//	handle - the handle to pass to Write and Close
+//
+// The status is an HTTP status, 200 for OK, as returned by RPC.
+//
+// The upload finishes when the handle is closed with Close.
+func WriteOpen(input string) (output string, status int) {
+	const path = "WriteOpen"
+	ctx := context.Background()
+	in, err := readInput(input)
+	if err != nil {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	f, remote, err := rc.GetFsAndRemote(ctx, in)
+	if err != nil {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	if remote == "" {
+		return writeError(path, in, errors.New("remote must be set to the name of the object"), http.StatusBadRequest)
+	}
+	size, err := in.GetInt64("size")
+	if rc.IsErrParamNotFound(err) {
+		size = -1
+	} else if err != nil {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	modTime := time.Now()
+	modTimeString, err := in.GetString("modTime")
+	if rc.NotErrParamNotFound(err) {
+		return writeError(path, in, err, http.StatusBadRequest)
+	}
+	if modTimeString != "" {
+		modTime, err = time.Parse(time.RFC3339Nano, modTimeString)
+		if err != nil {
+			return writeError(path, in, rc.NewErrParamInvalid(fmt.Errorf("bad modTime: %w", err)), http.StatusBadRequest)
+		}
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	s := &stream{
+		remote: remote,
+		pipe:   pipeWriter,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		if size >= 0 {
+			s.obj, s.err = operations.RcatSize(ctx, f, remote, pipeReader, size, modTime, nil)
+		} else {
+			s.obj, s.err = operations.Rcat(ctx, f, remote, pipeReader, modTime, nil)
+		}
+		// Stop any further writes if the upload failed early
+		_ = pipeReader.CloseWithError(s.err)
+	}()
+	handle := addStream(s)
+	return writeOutput(path, in, rc.Params{"handle": handle})
+}
+
+// Write writes p to the stream opened with WriteOpen returning the
+// number of bytes written.
+//
+// If the upload has failed this returns an error and Close will
+// return the reason.
+func Write(handle int64, p []byte) (n int, err error) {
+	s, err := getStream(handle)
+	if err != nil {
+		return 0, err
+	}
+	if s.pipe == nil {
+		return 0, ErrorBadHandle
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pipe.Write(p)
+}
+
+// Close closes a handle opened with ReadOpen or WriteOpen.
+//
+// For a handle opened with WriteOpen this waits for the upload to
+// finish and the output is a JSON object describing the uploaded
+// object with
+//
+//	remote - the path of the object
+//	size - the size of the object
+//	modTime - the modification time of the object
+//
+// The status is an HTTP status, 200 for OK, as returned by RPC. If
+// a read or the write failed the output will describe the error.
+//
+// If writing fails part way through, use Abort instead of Close so
+// the partial data isn't uploaded.
+func Close(handle int64) (output string, status int) {
+	const path = "Close"
+	ctx := context.Background()
+	in := rc.Params{"handle": handle}
+	s, err := removeStream(handle)
+	if err != nil {
+		return writeError(path, in, err, http.StatusNotFound)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.in != nil {
+		err = s.in.Close()
+		if s.readErr != nil {
+			err = s.readErr
+		}
+		s.tr.Done(ctx, err)
+		if err != nil {
+			return writeError(path, in, err, http.StatusInternalServerError)
+		}
+		return writeOutput(path, in, rc.Params{})
+	}
+	_ = s.pipe.Close()
+	<-s.done
+	if s.err != nil {
+		return writeError(path, in, s.err, http.StatusInternalServerError)
+	}
+	if s.obj == nil {
+		return writeError(path, in, fmt.Errorf("%s: no object uploaded", s.remote), http.StatusInternalServerError)
+	}
+	return writeOutput(path, in, objectOutput(ctx, 0, s.obj))
+}
+
+// Abort closes a handle opened with ReadOpen or WriteOpen without
+// finishing it.
+//
+// For a handle opened with WriteOpen this stops the upload with
+// ErrorAborted so the data written so far isn't stored as the
+// object. If the upload had already finished, which can happen if the
+// size was given and all of it was written, this returns an error as
+// it can no longer be aborted.
+//
+// The output is an empty JSON object and the status is an HTTP
+// status, 200 for OK, as returned by RPC.
+func Abort(handle int64) (output string, status int) {
+	const path = "Abort"
+	ctx := context.Background()
+	in := rc.Params{"handle": handle}
+	s, err := removeStream(handle)
+	if err != nil {
+		return writeError(path, in, err, http.StatusNotFound)
+	}
+	if s.pipe != nil {
+		// Do this before locking to unblock any Write in progress
+		_ = s.pipe.CloseWithError(ErrorAborted)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.in != nil {
+		_ = s.in.Close()
+		s.tr.Done(ctx, ErrorAborted)
+		return writeOutput(path, in, rc.Params{})
+	}
+	<-s.done
+	if s.err == nil {
+		return writeError(path, in, fmt.Errorf("%s: upload had already finished", s.remote), http.StatusConflict)
+	}
+	return writeOutput(path, in, rc.Params{})
+}
@@ -0,0 +1,144 @@
+package librclone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// check returns a function to decode the JSON output of a call
+// checking its status is wantStatus
+func check(t *testing.T, wantStatus int) func(output string, status int) map[string]interface{} {
+	return func(output string, status int) map[string]interface{} {
+		require.Equal(t, wantStatus, status, output)
+		out := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(output), &out))
+		return out
+	}
+}
+
+func TestStream(t *testing.T) {
+	dir := t.TempDir()
+	const data = "hello world, this is a stream"
+	input := fmt.Sprintf(`{"fs": %q, "remote": "file.txt"}`, dir)
+
+	// Write the file in chunks
+	out := check(t, http.StatusOK)(WriteOpen(input))
+	handle := int64(out["handle"].(float64))
+	for i := 0; i < len(data); i += 4 {
+		end := i + 4
+		if end > len(data) {
+			end = len(data)
+		}
+		n, err := Write(handle, []byte(data[i:end]))
+		require.NoError(t, err)
+		assert.Equal(t, end-i, n)
+	}
+	_, err := Read(handle, make([]byte, 10))
+	assert.Equal(t, ErrorBadHandle, err)
+	out = check(t, http.StatusOK)(Close(handle))
+	assert.Equal(t, "file.txt", out["remote"])
+	assert.Equal(t, float64(len(data)), out["size"])
+	got, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, data, string(got))
+
+	// Closing twice is an error
+	check(t, http.StatusNotFound)(Close(handle))
+
+	// Read it back in chunks
+	read := func(input string) string {
+		out := check(t, http.StatusOK)(ReadOpen(input))
+		assert.Equal(t, float64(len(data)), out["size"])
+		handle := int64(out["handle"].(float64))
+		var got []byte
+		buf := make([]byte, 5)
+		for {
+			n, err := Read(handle, buf)
+			got = append(got, buf[:n]...)
+			if err == io.EOF {
+				assert.Equal(t, 0, n)
+				break
+			}
+			require.NoError(t, err)
+		}
+		check(t, http.StatusOK)(Close(handle))
+		return string(got)
+	}
+	assert.Equal(t, data, read(input))
+	assert.Equal(t, "world", read(fmt.Sprintf(`{"fs": %q, "remote": "file.txt", "offset": 6, "count": 5}`, dir)))
+	assert.Equal(t, "stream", read(fmt.Sprintf(`{"fs": %q, "remote": "file.txt", "offset": -6}`, dir)))
+	assert.Equal(t, data, read(fmt.Sprintf(`{"fs": %q, "remote": "file.txt", "offset": -1000}`, dir)))
+	assert.Equal(t, "", read(fmt.Sprintf(`{"fs": %q, "remote": "file.txt", "offset": 6, "count": 0}`, dir)))
+
+	// Errors
+	check(t, http.StatusNotFound)(ReadOpen(fmt.Sprintf(`{"fs": %q, "remote": "notfound.txt"}`, dir)))
+	check(t, http.StatusBadRequest)(ReadOpen(`{"potato"`))
+	check(t, http.StatusBadRequest)(WriteOpen(fmt.Sprintf(`{"fs": %q}`, dir)))
+	_, err = Write(123456, []byte("hello"))
+	assert.Equal(t, ErrorBadHandle, err)
+}
+
+func TestStreamWriteSize(t *testing.T) {
+	dir := t.TempDir()
+	out := check(t, http.StatusOK)(WriteOpen(fmt.Sprintf(`{"fs": %q, "remote": "file.txt", "size": 5, "modTime": "2001-02-03T04:05:06Z"}`, dir)))
+	handle := int64(out["handle"].(float64))
+	_, err := Write(handle, []byte("hello"))
+	require.NoError(t, err)
+	out = check(t, http.StatusOK)(Close(handle))
+	assert.Equal(t, float64(5), out["size"])
+	assert.Equal(t, "2001-02-03T04:05:06Z", out["modTime"])
+}
+
+func TestStreamReadError(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0666))
+	input := fmt.Sprintf(`{"fs": %q, "remote": "file.txt"}`, dir)
+
+	out := check(t, http.StatusOK)(ReadOpen(input))
+	handle := int64(out["handle"].(float64))
+
+	// Make the reads fail
+	s, err := getStream(handle)
+	require.NoError(t, err)
+	readErr := errors.New("potato")
+	s.in = readCloser{Reader: iotest.ErrReader(readErr), Closer: s.in}
+	_, err = Read(handle, make([]byte, 10))
+	assert.Equal(t, readErr, err)
+
+	// The error is returned by Close
+	out = check(t, http.StatusInternalServerError)(Close(handle))
+	assert.Contains(t, out["error"], "potato")
+}
+
+func TestStreamAbort(t *testing.T) {
+	dir := t.TempDir()
+	input := fmt.Sprintf(`{"fs": %q, "remote": "file.txt"}`, dir)
+
+	// Abort an upload part way through
+	out := check(t, http.StatusOK)(WriteOpen(input))
+	handle := int64(out["handle"].(float64))
+	_, err := Write(handle, []byte("partial"))
+	require.NoError(t, err)
+	check(t, http.StatusOK)(Abort(handle))
+	_, err = os.Stat(filepath.Join(dir, "file.txt"))
+	assert.True(t, os.IsNotExist(err), "partial upload was stored")
+	check(t, http.StatusNotFound)(Close(handle))
+
+	// Abort a read
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0666))
+	out = check(t, http.StatusOK)(ReadOpen(input))
+	handle = int64(out["handle"].(float64))
+	check(t, http.StatusOK)(Abort(handle))
+	check(t, http.StatusNotFound)(Abort(handle))
+}
@@ -9,6 +9,13 @@ Then call rpc calls on it
 
     rclone.rpc("rc/noop", a=42, b="string", c=[1234])
 
+Objects can be streamed to and from a remote in chunks
+
+    with rclone.open_write("remote:", "file.txt") as f:
+        f.write(b"hello")
+    with rclone.open_read("remote:", "file.txt") as f:
+        data = f.read()
+
 When finished, close it
 
     rclone.close()
@@ -54,6 +61,76 @@ class RcloneException(Exception):
         message = self.output.get('error', 'Unknown rclone error')
         super().__init__(message)
 
+class RcloneStream():
+    """
+    A file like object for reading or writing an object opened with
+    Rclone.open_read or Rclone.open_write
+
+    The output of the open call is available as the info attribute.
+    After close it will contain the output of the close call, which
+    for a write describes the uploaded object.
+    """
+    def __init__(self, rclone, handle, info):
+        self.rclone = rclone
+        self.handle = handle
+        self.info = info
+    def read(self, size=-1, chunk_size=1024*1024):
+        """
+        Read up to size bytes, or to the end of the object if size is
+        negative, returning them as bytes.
+
+        An empty bytes object is returned at the end of the object.
+        """
+        chunks = []
+        while size != 0:
+            n = chunk_size if size < 0 else min(size, chunk_size)
+            buf = create_string_buffer(n)
+            got = self.rclone.rclone.RcloneRead(self.handle, buf, n)
+            if got < 0:
+                self.close()
+            if got <= 0:
+                break
+            chunks.append(buf.raw[:got])
+            if size > 0:
+                size -= got
+            if got < n:
+                break
+        return b"".join(chunks)
+    def write(self, data):
+        """
+        Write the bytes in data returning the number of bytes written
+        """
+        n = self.rclone.rclone.RcloneWrite(self.handle, data, len(data))
+        if n < 0:
+            self.close()
+        return n
+    def abort(self):
+        """
+        Close the stream without finishing the upload if writing, so
+        the data written so far isn't stored.
+        """
+        if self.handle is None:
+            return
+        handle, self.handle = self.handle, None
+        self.rclone._call(self.rclone.rclone.RcloneAbort, handle)
+    def close(self):
+        """
+        Close the stream, finishing the upload if writing.
+
+        Raises RcloneException if the read or write failed.
+        """
+        if self.handle is None:
+            return
+        handle, self.handle = self.handle, None
+        self.info = self.rclone._call(self.rclone.rclone.RcloneClose, handle)
+    def __enter__(self):
+        return self
+    def __exit__(self, exc_type, exc_value, traceback):
+        if exc_type is not None:
+            self.abort()
+        else:
+            self.close()
+
 class Rclone():
     """
     Interface to Rclone via librclone.so
@@ -70,7 +147,30 @@ class Rclone():
         self.rclone.RcloneInitialize.argtypes = ()
         self.rclone.RcloneFinalize.restype = None
         self.rclone.RcloneFinalize.argtypes = ()
+        self.rclone.RcloneReadOpen.restype = RcloneRPCResult
+        self.rclone.RcloneReadOpen.argtypes = (c_char_p,)
+        self.rclone.RcloneRead.restype = c_int
+        self.rclone.RcloneRead.argtypes = (c_longlong, c_char_p, c_int)
+        self.rclone.RcloneWriteOpen.restype = RcloneRPCResult
+        self.rclone.RcloneWriteOpen.argtypes = (c_char_p,)
+        self.rclone.RcloneWrite.restype = c_int
+        self.rclone.RcloneWrite.argtypes = (c_longlong, c_char_p, c_int)
+        self.rclone.RcloneClose.restype = RcloneRPCResult
+        self.rclone.RcloneClose.argtypes = (c_longlong,)
+        self.rclone.RcloneAbort.restype = RcloneRPCResult
+        self.rclone.RcloneAbort.argtypes = (c_longlong,)
         self.rclone.RcloneInitialize()
+    def _call(self, fn, *args):
+        """
+        Call fn which returns an RcloneRPCResult and decode it
+        """
+        resp = fn(*args)
+        output = json.loads(resp.Output.value.decode("utf-8"))
+        self.rclone.RcloneFreeString(resp.Output)
+        status = resp.Status
+        if status != 200:
+            raise RcloneException(output, status)
+        return output
     def rpc(self, method, **kwargs):
         """
         Call an rclone RC API call with the kwargs given.
@@ -82,13 +182,37 @@ class Rclone():
         """
         method = method.encode("utf-8")
         parameters = json.dumps(kwargs).encode("utf-8")
-        resp = self.rclone.RcloneRPC(method, parameters)
-        output = json.loads(resp.Output.value.decode("utf-8"))
-        self.rclone.RcloneFreeString(resp.Output)
-        status = resp.Status
-        if status != 200:
-            raise RcloneException(output, status)
-        return output
+        return self._call(self.rclone.RcloneRPC, method, parameters)
+    def open_read(self, fs, remote, offset=None, count=None):
+        """
+        Open the object remote on fs for reading returning an
+        RcloneStream.
+
+        If offset is set reading starts from there, and if count is
+        set at most count bytes are read.
+        """
+        parameters = dict(fs=fs, remote=remote)
+        if offset is not None:
+            parameters["offset"] = offset
+        if count is not None:
+            parameters["count"] = count
+        info = self._call(self.rclone.RcloneReadOpen, json.dumps(parameters).encode("utf-8"))
+        return RcloneStream(self, info["handle"], info)
+    def open_write(self, fs, remote, size=None, mod_time=None):
+        """
+        Open the object remote on fs for writing returning an
+        RcloneStream. The upload finishes when it is closed.
+
+        If size is set then exactly that many bytes must be written.
+        If mod_time is set it should be an RFC 3339 string.
+        """
+        parameters = dict(fs=fs, remote=remote)
+        if size is not None:
+            parameters["size"] = size
+        if mod_time is not None:
+            parameters["modTime"] = mod_time
+        info = self._call(self.rclone.RcloneWriteOpen, json.dumps(parameters).encode("utf-8"))
+        return RcloneStream(self, info["handle"], info)
     def close(self):
         """
         Call to finish with the rclone connection
@@ -5,6 +5,7 @@ Test program for librclone
 
 import os
 import subprocess
+import tempfile
 import unittest
 from rclone import *
 
@@ -38,5 +39,27 @@ class TestRclone(unittest.TestCase):
         else:
             raise ValueError("Expecting exception")
 
+    def test_stream(self):
+        with tempfile.TemporaryDirectory() as d:
+            data = b"hello world, this is a stream"
+            with self.rclone.open_write(d, "file.txt") as f:
+                f.write(data[:10])
+                f.write(data[10:])
+            self.assertEqual(f.info["remote"], "file.txt")
+            self.assertEqual(f.info["size"], len(data))
+            with self.rclone.open_read(d, "file.txt") as f:
+                self.assertEqual(f.info["size"], len(data))
+                self.assertEqual(f.read(5, chunk_size=2), data[:5])
+                self.assertEqual(f.read(), data[5:])
+                self.assertEqual(f.read(), b"")
+            with self.rclone.open_read(d, "file.txt", offset=6, count=5) as f:
+                self.assertEqual(f.read(), b"world")
+
+    def test_stream_error(self):
+        with tempfile.TemporaryDirectory() as d:
+            with self.assertRaises(RcloneException) as cm:
+                self.rclone.open_read(d, "notfound.txt")
+            self.assertEqual(cm.exception.status, 404)
+
 if __name__ == '__main__':
     unittest.main()
@@ -159,6 +159,33 @@ func (d *Dir) Mode() (mode os.FileMode) {
 	return d.vfs.Opt.DirPerms
 }
 
+// Owner returns the uid and gid of the directory - satisfies Node interface
+func (d *Dir) Owner() (uid, gid uint32) {
+	return d.vfs.Opt.UID, d.vfs.Opt.GID
+}
+
+// Chmod changes the permissions of the directory - satisfies Node interface
+//
+// Directories don't store permissions so this returns ENOSYS with
+// --vfs-metadata-perms, otherwise it is ignored as for files.
+func (d *Dir) Chmod(mode os.FileMode) error {
+	if d.vfs.Opt.MetadataPerms {
+		return ENOSYS
+	}
+	return nil
+}
+
+// Chown changes the owner of the directory - satisfies Node interface
+//
+// Directories don't store ownership so this returns ENOSYS with
+// --vfs-metadata-perms, otherwise it is ignored as for files.
+func (d *Dir) Chown(uid, gid int) error {
+	if d.vfs.Opt.MetadataPerms {
+		return ENOSYS
+	}
+	return nil
+}
+
 // Name (base) of the directory - satisfies Node interface
 func (d *Dir) Name() (name string) {
 	d.mu.RLock()
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/log"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/vfs/vfscache"
 	"github.com/rclone/rclone/vfs/vfscommon"
 )
 
@@ -53,6 +55,8 @@ type File struct {
 	sys              atomic.Value                    // user defined info to be attached here
 	nwriters         atomic.Int32                    // len(writers)
 	appendMode       bool                            // file was opened with O_APPEND
+	metadata         fs.Metadata                     // metadata for --vfs-metadata-perms - nil if not read yet
+	metadataRead     time.Time                       // when metadata was read or set
 }
 
 // newFile creates a new File
@@ -92,15 +96,33 @@ func (f *File) IsDir() bool {
 
 // Mode bits of the file or directory - satisfies Node interface
 func (f *File) Mode() (mode os.FileMode) {
+	perms, hasPerms := f.metadataInt(f.permsMetadata(), "mode", 8)
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 	mode = f.d.vfs.Opt.FilePerms
+	if hasPerms {
+		mode = os.FileMode(perms) & os.ModePerm
+	}
 	if f.appendMode {
 		mode |= os.ModeAppend
 	}
 	return mode
 }
 
+// Owner returns the uid and gid of the file - satisfies Node interface
+func (f *File) Owner() (uid, gid uint32) {
+	opt := &f.VFS().Opt
+	uid, gid = opt.UID, opt.GID
+	metadata := f.permsMetadata()
+	if value, ok := f.metadataInt(metadata, "uid", 10); ok {
+		uid = uint32(value)
+	}
+	if value, ok := f.metadataInt(metadata, "gid", 10); ok {
+		gid = uint32(value)
+	}
+	return uid, gid
+}
+
 // Name (base) of the directory - satisfies Node interface
 func (f *File) Name() (name string) {
 	f.mu.RLock()
@@ -458,6 +480,211 @@ func (f *File) applyPendingModTime() error {
 	return f._applyPendingModTime()
 }
 
+// modeRegular is the file type bits of a regular file as stored in
+// the "mode" metadata
+const modeRegular = 0100000
+
+// metadataPermsKeys are the metadata keys which store the
+// permissions and ownership of the file
+var metadataPermsKeys = []string{"mode", "uid", "gid"}
+
+// _cachedMetadata returns the metadata used to store the permissions
+// and ownership of the file if it doesn't need reading from the
+// object, or nil if it does.
+//
+// Call with the lock held
+func (f *File) _cachedMetadata() fs.Metadata {
+	if f.metadata != nil && time.Since(f.metadataRead) < f.d.vfs.Opt.DirCacheTime {
+		return f.metadata
+	}
+	// Read the metadata from a dirty item if it exists as it will
+	// be written to the object when it is uploaded
+	if f.d.vfs.cache != nil {
+		if item := f.d.vfs.cache.DirtyItem(f._path()); item != nil {
+			if metadata := item.GetMetadata(); metadata != nil {
+				return metadata
+			}
+		}
+	}
+	if f.o == nil {
+		// Not uploaded yet so nothing to read
+		if f.metadata != nil {
+			return f.metadata
+		}
+		return fs.Metadata{}
+	}
+	return nil
+}
+
+// getMetadata returns a copy of the metadata used to store the
+// permissions and ownership of the file or nil if
+// --vfs-metadata-perms isn't set.
+//
+// The metadata is kept for --dir-cache-time. Reading it from the
+// object may need a network call so this is done without the lock
+// held, and it is read again next time if it fails.
+func (f *File) getMetadata() (metadata fs.Metadata, err error) {
+	f.mu.RLock()
+	if !f.d.vfs.Opt.MetadataPerms {
+		f.mu.RUnlock()
+		return nil, nil
+	}
+	cached := f._cachedMetadata()
+	if cached != nil {
+		metadata = fs.Metadata{}
+		metadata.Merge(cached)
+	}
+	o := f.o
+	f.mu.RUnlock()
+	if cached != nil {
+		return metadata, nil
+	}
+
+	start := time.Now()
+	read, err := fs.GetMetadata(context.TODO(), o)
+	if err != nil {
+		return nil, err
+	}
+	metadata = fs.Metadata{}
+	for _, key := range metadataPermsKeys {
+		if value, ok := read[key]; ok {
+			metadata[key] = value
+		}
+	}
+
+	f.mu.Lock()
+	// Only keep the metadata if it is still for the same object
+	// and hasn't been set since it was read
+	if f.o == o && f.metadataRead.Before(start) {
+		f.metadata = fs.Metadata{}
+		f.metadata.Merge(metadata)
+		f.metadataRead = time.Now()
+	}
+	f.mu.Unlock()
+	return metadata, nil
+}
+
+// permsMetadata returns the metadata used to store the permissions
+// and ownership of the file, logging an error and returning nil if it
+// couldn't be read.
+func (f *File) permsMetadata() fs.Metadata {
+	metadata, err := f.getMetadata()
+	if err != nil {
+		fs.Errorf(f.Path(), "Failed to read metadata: %v", err)
+		return nil
+	}
+	return metadata
+}
+
+// metadataInt reads the integer in key from metadata in the base
+// given returning ok if it was found
+func (f *File) metadataInt(metadata fs.Metadata, key string, base int) (value uint64, ok bool) {
+	s, ok := metadata[key]
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		fs.Debugf(f.Path(), "Ignoring invalid %q metadata %q: %v", key, s, err)
+		return 0, false
+	}
+	return value, true
+}
+
+// Chmod changes the permissions of the file - satisfies Node interface
+//
+// The permissions are only stored if --vfs-metadata-perms is set,
+// otherwise this is ignored.
+func (f *File) Chmod(mode os.FileMode) error {
+	return f.updateMetadata(func(metadata fs.Metadata) {
+		metadata["mode"] = fmt.Sprintf("%0o", modeRegular|uint32(mode.Perm()))
+	})
+}
+
+// Chown changes the numeric uid and gid of the file - satisfies Node
+// interface. A uid or gid of -1 means don't change that value.
+//
+// The ownership is only stored if --vfs-metadata-perms is set,
+// otherwise this is ignored.
+func (f *File) Chown(uid, gid int) error {
+	return f.updateMetadata(func(metadata fs.Metadata) {
+		if uid >= 0 {
+			metadata["uid"] = strconv.Itoa(uid)
+		}
+		if gid >= 0 {
+			metadata["gid"] = strconv.Itoa(gid)
+		}
+	})
+}
+
+// updateMetadata calls update on the metadata of the file then
+// arranges for it to be written to the object.
+//
+// If the backend can set metadata on the object then that is used,
+// otherwise if the file isn't being uploaded already then it is
+// uploaded again from the cache to write the new metadata.
+func (f *File) updateMetadata(update func(metadata fs.Metadata)) error {
+	f.mu.RLock()
+	opt := &f.d.vfs.Opt
+	f.mu.RUnlock()
+	if !opt.MetadataPerms {
+		return nil
+	}
+	if opt.ReadOnly {
+		return EROFS
+	}
+	metadata, err := f.getMetadata()
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	update(metadata)
+
+	f.mu.Lock()
+	f.metadata = fs.Metadata{}
+	f.metadata.Merge(metadata)
+	f.metadataRead = time.Now()
+	cache, name, writing, o := f.d.vfs.cache, f._path(), f._writingInProgress(), f.o
+	f.mu.Unlock()
+
+	dirty := cache != nil && cache.DirtyItem(name) != nil
+	if !writing && !dirty {
+		if do, ok := o.(fs.SetMetadataer); ok {
+			err := do.SetMetadata(context.TODO(), metadata)
+			if !errors.Is(err, fs.ErrorNotImplemented) {
+				return err
+			}
+		}
+	}
+	if cache == nil {
+		return EPERM
+	}
+	item := cache.Item(name)
+	item.SetMetadata(metadata)
+
+	// The metadata will be written when the writers close or the
+	// pending upload happens
+	if writing || dirty {
+		return nil
+	}
+	return f.rewriteMetadata(cache, item)
+}
+
+// rewriteMetadata uploads the file again from the cache so that the
+// metadata set on item is written to the object
+func (f *File) rewriteMetadata(cache *vfscache.Cache, item *vfscache.Item) error {
+	f.muRW.Lock()
+	defer f.muRW.Unlock()
+	o := f.getObject()
+	err := item.Open(o)
+	if err != nil {
+		return fmt.Errorf("failed to open cache file to write metadata: %w", err)
+	}
+	// Marking the item dirty sets its modtime so put it back
+	item.Dirty()
+	cache.SetModTime(f.Path(), o.ModTime(context.TODO()))
+	return item.Close(f.setObject)
+}
+
 // _writingInProgress returns true of there are any open writers
 // Call with read lock held
 func (f *File) _writingInProgress() bool {
@@ -493,6 +720,7 @@ func (f *File) setObject(o fs.Object) {
 func (f *File) setObjectNoUpdate(o fs.Object) {
 	f.mu.Lock()
 	f.o = o
+	f.metadata = nil
 	f.virtualModTime = nil
 	fs.Debugf(f._path(), "Reset virtual modtime")
 	f.mu.Unlock()
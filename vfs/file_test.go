@@ -415,6 +415,81 @@ func TestFileRename(t *testing.T) {
 	}
 }
 
+func TestFileMetadataPerms(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeWrites
+	opt.WriteBack = writeBackDelay
+	opt.MetadataPerms = true
+	r, vfs := newTestVFSOpt(t, &opt)
+	if !vfs.Opt.MetadataPerms {
+		t.Skip("remote doesn't support metadata")
+	}
+	ctx := context.Background()
+	uid, gid := os.Getuid(), os.Getgid()
+
+	file1 := r.WriteObject(ctx, "dir/file1", "file1 contents", t1)
+	r.CheckRemoteItems(t, file1)
+
+	node, err := vfs.Stat("dir/file1")
+	require.NoError(t, err)
+	file := node.(*File)
+
+	// Change the permissions and ownership
+	require.NoError(t, file.Chmod(0640))
+	require.NoError(t, file.Chown(uid, -1))
+	require.NoError(t, file.Chown(-1, gid))
+	assert.Equal(t, os.FileMode(0640), file.Mode())
+	gotUID, gotGID := file.Owner()
+	assert.Equal(t, uint32(uid), gotUID)
+	assert.Equal(t, uint32(gid), gotGID)
+
+	// Check they have been written to the object
+	vfs.WaitForWriters(waitForWritersDelay)
+	r.CheckRemoteItems(t, file1)
+	o, err := r.Fremote.NewObject(ctx, "dir/file1")
+	require.NoError(t, err)
+	metadata, err := fs.GetMetadata(ctx, o)
+	require.NoError(t, err)
+	assert.Equal(t, "100640", metadata["mode"])
+	assert.Equal(t, fmt.Sprint(uid), metadata["uid"])
+	assert.Equal(t, fmt.Sprint(gid), metadata["gid"])
+
+	// Check they are read back from the object
+	file.setObjectNoUpdate(o)
+	assert.Equal(t, os.FileMode(0640), file.Mode())
+
+	// Check they are kept when the file is modified
+	fd, err := file.Open(os.O_WRONLY | os.O_TRUNC)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	vfs.WaitForWriters(waitForWritersDelay)
+	o, err = r.Fremote.NewObject(ctx, "dir/file1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), o.Size())
+	metadata, err = fs.GetMetadata(ctx, o)
+	require.NoError(t, err)
+	assert.Equal(t, "100640", metadata["mode"])
+
+	// Check changes to the object are read when the cache expires
+	if do, ok := o.(fs.SetMetadataer); ok {
+		require.NoError(t, do.SetMetadata(ctx, fs.Metadata{"mode": "100600"}))
+		assert.Equal(t, os.FileMode(0640), file.Mode())
+		file.mu.Lock()
+		file.metadataRead = file.metadataRead.Add(-vfs.Opt.DirCacheTime)
+		file.mu.Unlock()
+		assert.Equal(t, os.FileMode(0600), file.Mode())
+	}
+
+	// Check directories can't be changed
+	dir, err := vfs.Stat("dir")
+	require.NoError(t, err)
+	assert.Equal(t, ENOSYS, dir.Chmod(0700))
+	assert.Equal(t, ENOSYS, dir.Chown(uid, gid))
+	assert.Equal(t, vfs.Opt.DirPerms, dir.Mode())
+}
+
 func TestFileStructSize(t *testing.T) {
 	t.Logf("File struct has size %d bytes", unsafe.Sizeof(File{}))
 }
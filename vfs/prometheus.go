@@ -0,0 +1,132 @@
+package vfs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+const namespace = "rclone_vfs_"
+
+// collector is a Prometheus collector for the active VFSes
+type collector struct {
+	inUse             *prometheus.Desc
+	cacheBytesUsed    *prometheus.Desc
+	cacheFiles        *prometheus.Desc
+	cacheDirtyFiles   *prometheus.Desc
+	cacheErroredFiles *prometheus.Desc
+	cacheOutOfSpace   *prometheus.Desc
+	uploadsInProgress *prometheus.Desc
+	uploadsQueued     *prometheus.Desc
+	uploadsFailed     *prometheus.Desc
+	readHits          *prometheus.Desc
+	readMisses        *prometheus.Desc
+}
+
+func init() {
+	prometheus.MustRegister(newCollector())
+}
+
+// newCollector makes a new collector
+func newCollector() *collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(namespace+name, help, []string{"fs"}, nil)
+	}
+	return &collector{
+		inUse:             desc("in_use", "Number of users of the VFS"),
+		cacheBytesUsed:    desc("cache_bytes_used", "Bytes used by the VFS disk cache"),
+		cacheFiles:        desc("cache_files", "Number of files in the VFS disk cache"),
+		cacheDirtyFiles:   desc("cache_dirty_files", "Number of files in the VFS disk cache which need uploading"),
+		cacheErroredFiles: desc("cache_errored_files", "Number of files in the VFS disk cache which failed to upload"),
+		cacheOutOfSpace:   desc("cache_out_of_space", "Whether the VFS disk cache is out of space"),
+		uploadsInProgress: desc("uploads_in_progress", "Number of uploads from the VFS disk cache in progress"),
+		uploadsQueued:     desc("uploads_queued", "Number of uploads from the VFS disk cache waiting to start"),
+		uploadsFailed:     desc("uploads_failed", "Number of uploads from the VFS disk cache which failed and will be retried"),
+		readHits:          desc("cache_read_hits_total", "Number of reads found in the VFS disk cache"),
+		readMisses:        desc("cache_read_misses_total", "Number of reads not found in the VFS disk cache"),
+	}
+}
+
+// Describe is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inUse
+	ch <- c.cacheBytesUsed
+	ch <- c.cacheFiles
+	ch <- c.cacheDirtyFiles
+	ch <- c.cacheErroredFiles
+	ch <- c.cacheOutOfSpace
+	ch <- c.uploadsInProgress
+	ch <- c.uploadsQueued
+	ch <- c.uploadsFailed
+	ch <- c.readHits
+	ch <- c.readMisses
+}
+
+// Collect is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
+//
+// The stats of VFSes on the same remote with different options are
+// added together.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	activeMu.Lock()
+	vfsesByName := make(map[string][]*VFS, len(active))
+	for name, vfses := range active {
+		vfsesByName[name] = append([]*VFS(nil), vfses...)
+	}
+	activeMu.Unlock()
+
+	for name, vfses := range vfsesByName {
+		var inUse float64
+		cacheStats := map[*prometheus.Desc]float64{}
+		haveCache := false
+		for _, vfs := range vfses {
+			inUse += float64(vfs.inUse.Load())
+			if vfs.cache == nil {
+				continue
+			}
+			haveCache = true
+			stats := vfs.cache.Stats()
+			for desc, key := range map[*prometheus.Desc]string{
+				c.cacheBytesUsed:    "bytesUsed",
+				c.cacheFiles:        "files",
+				c.cacheDirtyFiles:   "dirtyFiles",
+				c.cacheErroredFiles: "erroredFiles",
+				c.cacheOutOfSpace:   "outOfSpace",
+				c.uploadsInProgress: "uploadsInProgress",
+				c.uploadsQueued:     "uploadsQueued",
+				c.uploadsFailed:     "uploadsFailed",
+				c.readHits:          "readHits",
+				c.readMisses:        "readMisses",
+			} {
+				cacheStats[desc] += statFloat(stats, key)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, inUse, name)
+		if !haveCache {
+			continue
+		}
+		for desc, value := range cacheStats {
+			valueType := prometheus.GaugeValue
+			if desc == c.readHits || desc == c.readMisses {
+				valueType = prometheus.CounterValue
+			}
+			ch <- prometheus.MustNewConstMetric(desc, valueType, value, name)
+		}
+	}
+}
+
+// statFloat returns the numeric value of key in stats as a float64
+func statFloat(stats rc.Params, key string) float64 {
+	switch x := stats[key].(type) {
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	}
+	fs.Debugf(nil, "vfs: metrics: unexpected type %T for %q", stats[key], key)
+	return 0
+}
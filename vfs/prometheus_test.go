@@ -0,0 +1,49 @@
+package vfs
+
+import (
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeWrites
+	r, vfs := newTestVFSOpt(t, &opt)
+	name := fs.ConfigString(r.Fremote)
+
+	// Write a file so it is in the cache
+	fd, err := vfs.OpenFile("file1", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0777)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(newCollector()))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() != name {
+				continue
+			}
+			if metric.GetGauge() != nil {
+				values[family.GetName()] = metric.GetGauge().GetValue()
+			} else {
+				values[family.GetName()] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, 1.0, values["rclone_vfs_in_use"])
+	assert.Equal(t, 1.0, values["rclone_vfs_cache_files"])
+	assert.Contains(t, values, "rclone_vfs_cache_dirty_files")
+	assert.Contains(t, values, "rclone_vfs_uploads_queued")
+}
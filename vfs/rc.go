@@ -11,6 +11,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs/vfscache/writeback"
 )
 
 const getVFSHelp = ` 
@@ -54,9 +55,10 @@ func getVFS(in rc.Params) (vfs *VFS, err error) {
 
 func init() {
 	rc.Add(rc.Call{
-		Path:  "vfs/refresh",
-		Fn:    rcRefresh,
-		Title: "Refresh the directory cache.",
+		Path:   "vfs/refresh",
+		Global: true,
+		Fn:     rcRefresh,
+		Title:  "Refresh the directory cache.",
 		Help: `
 This reads the directories for the specified paths and freshens the
 directory cache.
@@ -169,9 +171,10 @@ func rcRefresh(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 // Add remote control for the VFS
 func init() {
 	rc.Add(rc.Call{
-		Path:  "vfs/forget",
-		Fn:    rcForget,
-		Title: "Forget files or directories in the directory cache.",
+		Path:   "vfs/forget",
+		Global: true,
+		Fn:     rcForget,
+		Title:  "Forget files or directories in the directory cache.",
 		Help: `
 This forgets the paths in the directory cache causing them to be
 re-read from the remote when needed.
@@ -287,9 +290,10 @@ func getStatus(vfs *VFS, in rc.Params) (out rc.Params, err error) {
 
 func init() {
 	rc.Add(rc.Call{
-		Path:  "vfs/poll-interval",
-		Fn:    rcPollInterval,
-		Title: "Get the status or update the value of the poll-interval option.",
+		Path:   "vfs/poll-interval",
+		Global: true,
+		Fn:     rcPollInterval,
+		Title:  "Get the status or update the value of the poll-interval option.",
 		Help: `
 Without any parameter given this returns the current status of the
 poll-interval setting.
@@ -360,8 +364,9 @@ func rcPollInterval(ctx context.Context, in rc.Params) (out rc.Params, err error
 
 func init() {
 	rc.Add(rc.Call{
-		Path:  "vfs/list",
-		Title: "List active VFSes.",
+		Path:   "vfs/list",
+		Global: true,
+		Title:  "List active VFSes.",
 		Help: `
 This lists the active VFSes.
 
@@ -392,8 +397,9 @@ func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 
 func init() {
 	rc.Add(rc.Call{
-		Path:  "vfs/stats",
-		Title: "Stats for a VFS.",
+		Path:   "vfs/stats",
+		Global: true,
+		Title:  "Stats for a VFS.",
 		Help: `
 This returns stats for the selected VFS.
 
@@ -401,12 +407,18 @@ This returns stats for the selected VFS.
         // Status of the disk cache - only present if --vfs-cache-mode > off
         "diskCache": {
             "bytesUsed": 0,
+            "dirtyFiles": 0,
             "erroredFiles": 0,
             "files": 0,
             "hashType": 1,
             "outOfSpace": false,
             "path": "/home/user/.cache/rclone/vfs/local/mnt/a",
             "pathMeta": "/home/user/.cache/rclone/vfsMeta/local/mnt/a",
+            "readHits": 0,
+            "readMisses": 0,
+            "readRandom": 0,
+            "readSequential": 0,
+            "uploadsFailed": 0,
             "uploadsInProgress": 0,
             "uploadsQueued": 0
         },
@@ -437,3 +449,230 @@ func rcStats(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	}
 	return vfs.Stats(), nil
 }
+
+func init() {
+	rc.Add(rc.Call{
+		Path:   "vfs/queue",
+		Global: true,
+		Title:  "Queue info for a VFS.",
+		Help: strings.ReplaceAll(`
+This returns info about the upload queue for the selected VFS.
+
+This is only useful if |--vfs-cache-mode| > off. If you call it when
+the |--vfs-cache-mode| is off, it will return an empty result.
+
+    {
+        "queue": // an array of files queued for upload
+        [
+            {
+                "name":      "file",   // string: name (full path) of the file,
+                "id":        123,      // integer: id of this item in the queue,
+                "size":      79,       // integer: size of the file in bytes
+                "expiry":    1.5       // float: time until file is eligible for transfer, lowest goes first
+                "tries":     1,        // integer: number of times we have tried to upload
+                "delay":     5.0,      // float: seconds between upload attempts
+                "uploading": false,    // boolean: true if item is being uploaded
+                "failed":    false,    // boolean: true if the upload has been given up
+                "error":     "",       // string: error from the last upload attempt if any
+            },
+        ],
+    }
+
+The |expiry| time is the time until the file is eligible for being
+uploaded in floating point seconds. This may go negative. As rclone
+only transfers |--transfers| files at once, only the lowest
+|--transfers| expiry times will have |uploading| as |true|. So there
+may be files with negative expiry times for which |uploading| is
+|false|.
+
+If |--vfs-write-back-retries| is set then an upload which has failed
+that many times will have |failed| set to |true| and won't be retried
+until |vfs/queue-retry| is called, the file is modified or the upload
+is abandoned with |vfs/queue-abandon|.
+
+`, "|", "`") + getVFSHelp,
+		Fn: rcQueue,
+	})
+}
+
+func rcQueue(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, nil
+	}
+	return vfs.cache.Queue(), nil
+}
+
+// getQueueID gets the "id" parameter for the vfs/queue-* calls
+func getQueueID(in rc.Params) (vfs *VFS, id writeback.Handle, err error) {
+	vfs, err = getVFS(in)
+	if err != nil {
+		return nil, 0, err
+	}
+	if vfs.cache == nil {
+		return nil, 0, rc.NewErrParamInvalid(errors.New("can't call this unless using the VFS cache"))
+	}
+	// Read the values from the input
+	rawID, err := in.GetInt64("id")
+	if err != nil {
+		return nil, 0, err
+	}
+	return vfs, writeback.Handle(rawID), nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:   "vfs/queue-set-expiry",
+		Global: true,
+		Title:  "Set the expiry time for an item queued for upload.",
+		Help: strings.ReplaceAll(`
+
+Use this to adjust the |expiry| time for an item in the upload queue.
+You will need to read the |id| of the item using |vfs/queue| before
+using this call.
+
+You can then set |expiry| to a floating point number of seconds from
+now when the item is eligible for upload. If you want the item to be
+uploaded as soon as possible then set it to a large negative number (eg
+-1000000000). If you want the upload of the item to be delayed
+for a long time then set it to a large positive number.
+
+Setting the |expiry| of an item which has already has started uploading
+will have no effect - the item will carry on being uploaded.
+
+This will return an error if called with |--vfs-cache-mode| off or if
+the |id| passed is not found.
+
+This takes the following parameters
+
+- |fs| - select the VFS in use (optional)
+- |id| - a numeric ID as returned from |vfs/queue|
+- |expiry| - a new expiry time as floating point seconds
+- |relative| - if set, expiry is to be treated as relative to the current expiry (optional, boolean)
+
+This returns an empty result on success, or an error.
+
+`, "|", "`") + getVFSHelp,
+		Fn: rcQueueSetExpiry,
+	})
+}
+
+func rcQueueSetExpiry(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, id, err := getQueueID(in)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := in.GetFloat64("expiry")
+	if err != nil {
+		return nil, err
+	}
+	relative, err := in.GetBool("relative")
+	if err != nil && !rc.IsErrParamNotFound(err) {
+		return nil, err
+	}
+
+	// Set expiry
+	var refTime time.Time
+	if relative {
+		info, err := vfs.cache.QueueInfo(id)
+		if err != nil {
+			return nil, err
+		}
+		refTime = time.Now().Add(time.Duration(float64(time.Second) * info.Expiry))
+	} else {
+		refTime = time.Now()
+	}
+	expiryTime := refTime.Add(time.Duration(float64(time.Second) * expiry))
+	err = vfs.cache.QueueSetExpiry(id, expiryTime)
+	return nil, err
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:   "vfs/queue-retry",
+		Global: true,
+		Title:  "Retry the upload of an item in the upload queue now.",
+		Help: strings.ReplaceAll(`
+
+Use this to upload an item in the upload queue now with a fresh set of
+|--vfs-write-back-retries|. This is normally used on items whose upload
+has |failed|. You will need to read the |id| of the item using
+|vfs/queue| before using this call.
+
+This will return an error if called with |--vfs-cache-mode| off, if
+the |id| passed is not found or if the item is being uploaded.
+
+This takes the following parameters
+
+- |fs| - select the VFS in use (optional)
+- |id| - a numeric ID as returned from |vfs/queue|
+
+This returns an empty result on success, or an error.
+
+`, "|", "`") + getVFSHelp,
+		Fn: rcQueueRetry,
+	})
+}
+
+func rcQueueRetry(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, id, err := getQueueID(in)
+	if err != nil {
+		return nil, err
+	}
+	return nil, vfs.cache.QueueRetry(id)
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:   "vfs/queue-abandon",
+		Global: true,
+		Title:  "Abandon the upload of an item in the upload queue.",
+		Help: strings.ReplaceAll(`
+
+Use this to give up uploading an item in the upload queue. You will
+need to read the |id| of the item using |vfs/queue| before using this
+call.
+
+**Warning** this discards the changes to the file which haven't been
+uploaded. The file is removed from the VFS cache and will be read from
+the remote again when next needed, or will disappear if it never
+existed on the remote.
+
+This will return an error if called with |--vfs-cache-mode| off, if
+the |id| passed is not found or if the file is open.
+
+This takes the following parameters
+
+- |fs| - select the VFS in use (optional)
+- |id| - a numeric ID as returned from |vfs/queue|
+
+This returns the name of the abandoned file in |name| on success, or an
+error.
+
+`, "|", "`") + getVFSHelp,
+		Fn: rcQueueAbandon,
+	})
+}
+
+func rcQueueAbandon(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, id, err := getQueueID(in)
+	if err != nil {
+		return nil, err
+	}
+	name, err := vfs.cache.QueueAbandon(id)
+	if err != nil {
+		return nil, err
+	}
+	// Make the directory listing forget the file
+	root, err := vfs.Root()
+	if err != nil {
+		return nil, err
+	}
+	root.ForgetPath(name, fs.EntryObject)
+	return rc.Params{
+		"name": name,
+	}, nil
+}
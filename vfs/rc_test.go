@@ -3,10 +3,12 @@ package vfs
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscache/writeback"
 	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -125,3 +127,68 @@ func TestRcStats(t *testing.T) {
 	assert.Equal(t, 1, out["metadataCache"].(rc.Params)["dirs"])
 	assert.Equal(t, vfs.Opt, out["opt"].(vfscommon.Options))
 }
+
+func TestRcQueue(t *testing.T) {
+	if *fstest.RemoteName != "" {
+		t.Skip("Skipping test on non local remote")
+	}
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.WriteBack = time.Hour
+	_, vfs := newTestVFSOpt(t, &opt)
+	ctx := context.Background()
+
+	queue := rc.Calls.Get("vfs/queue")
+	setExpiry := rc.Calls.Get("vfs/queue-set-expiry")
+	retry := rc.Calls.Get("vfs/queue-retry")
+	abandon := rc.Calls.Get("vfs/queue-abandon")
+
+	out, err := queue.Fn(ctx, rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"queue": []writeback.QueueInfo{}}, out)
+
+	// Write a file so it is queued for upload
+	fd, err := vfs.Create("file1")
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	out, err = queue.Fn(ctx, rc.Params{})
+	require.NoError(t, err)
+	items := out["queue"].([]writeback.QueueInfo)
+	require.Equal(t, 1, len(items))
+	assert.Equal(t, "file1", items[0].Name)
+	assert.Equal(t, int64(5), items[0].Size)
+	assert.Greater(t, items[0].Expiry, 3500.0)
+	id := int64(items[0].ID)
+
+	// Unknown id
+	_, err = setExpiry.Fn(ctx, rc.Params{"id": id + 1, "expiry": 10.0})
+	assert.Equal(t, writeback.ErrorIDNotFound, err)
+
+	// Delay relative to now and to the current expiry
+	_, err = setExpiry.Fn(ctx, rc.Params{"id": id, "expiry": 100.0})
+	require.NoError(t, err)
+	info, err := vfs.cache.QueueInfo(writeback.Handle(id))
+	require.NoError(t, err)
+	assert.InDelta(t, 100.0, info.Expiry, 10.0)
+	_, err = setExpiry.Fn(ctx, rc.Params{"id": id, "expiry": 100.0, "relative": true})
+	require.NoError(t, err)
+	info, err = vfs.cache.QueueInfo(writeback.Handle(id))
+	require.NoError(t, err)
+	assert.InDelta(t, 200.0, info.Expiry, 10.0)
+
+	// Missing id
+	_, err = retry.Fn(ctx, rc.Params{})
+	assert.True(t, rc.IsErrParamNotFound(err))
+
+	// Abandon the upload
+	out, err = abandon.Fn(ctx, rc.Params{"id": id})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"name": "file1"}, out)
+	out, err = queue.Fn(ctx, rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"queue": []writeback.QueueInfo{}}, out)
+	assert.False(t, vfs.cache.Exists("file1"))
+}
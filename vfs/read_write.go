@@ -9,6 +9,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/log"
 	"github.com/rclone/rclone/vfs/vfscache"
+	"github.com/rclone/rclone/vfs/vfscache/downloaders"
 )
 
 // RWFileHandle is a handle that can be open for read and write.
@@ -17,10 +18,11 @@ import (
 // transferred to the remote.
 type RWFileHandle struct {
 	// read only variables
-	file  *File
-	d     *Dir
-	flags int            // open flags
-	item  *vfscache.Item // cached file item
+	file   *File
+	d      *Dir
+	flags  int                 // open flags
+	item   *vfscache.Item      // cached file item
+	stream *downloaders.Stream // access pattern of reads for read ahead
 
 	// read write variables protected by mutex
 	mu          sync.Mutex
@@ -53,10 +55,23 @@ func newRWFileHandle(d *Dir, f *File, flags int) (fh *RWFileHandle, err error) {
 	}
 
 	fh = &RWFileHandle{
-		file:  f,
-		d:     d,
-		flags: flags,
-		item:  item,
+		file:   f,
+		d:      d,
+		flags:  flags,
+		item:   item,
+		stream: item.NewStream(),
+	}
+
+	// make sure the permissions and ownership in the metadata are
+	// kept when the file is uploaded
+	if !fh.readOnly() {
+		metadata, err := f.getMetadata()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata: %w", err)
+		}
+		if len(metadata) > 0 {
+			item.SetMetadata(metadata)
+		}
 	}
 
 	// truncate immediately if O_TRUNC is set or O_CREATE is set and file doesn't exist
@@ -273,7 +288,7 @@ func (fh *RWFileHandle) _readAt(b []byte, off int64, release bool) (n int, err e
 		fh.mu.Unlock()
 	}
 
-	n, err = fh.item.ReadAt(b, off)
+	n, err = fh.item.ReadAtStream(b, off, fh.stream)
 
 	if release {
 		fh.mu.Lock()
@@ -440,12 +455,12 @@ func (fh *RWFileHandle) Chdir() error {
 
 // Chmod changes the mode of the file to mode.
 func (fh *RWFileHandle) Chmod(mode os.FileMode) error {
-	return ENOSYS
+	return fh.file.Chmod(mode)
 }
 
 // Chown changes the numeric uid and gid of the named file.
 func (fh *RWFileHandle) Chown(uid, gid int) error {
-	return ENOSYS
+	return fh.file.Chown(uid, gid)
 }
 
 // Fd returns the integer Unix file descriptor referencing the open file.
@@ -63,6 +63,9 @@ type Node interface {
 	Truncate(size int64) error
 	Path() string
 	SetSys(interface{})
+	Owner() (uid, gid uint32)
+	Chmod(mode os.FileMode) error
+	Chown(uid, gid int) error
 }
 
 // Check interfaces
@@ -207,6 +210,19 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	// Fill out anything else
 	vfs.Opt.Init()
 
+	// Permissions in metadata need the remote to support metadata
+	// and the cache to change them
+	if vfs.Opt.MetadataPerms {
+		features := f.Features()
+		if !features.ReadMetadata || !features.WriteMetadata {
+			fs.Logf(f, "--vfs-metadata-perms is not supported by this remote as it can't read and write metadata")
+			vfs.Opt.MetadataPerms = false
+		} else if !vfs.Opt.ReadOnly && vfs.Opt.CacheMode < vfscommon.CacheModeWrites {
+			fs.Logf(f, "--vfs-metadata-perms needs --vfs-cache-mode writes or full - using --vfs-cache-mode writes")
+			vfs.Opt.CacheMode = vfscommon.CacheModeWrites
+		}
+	}
+
 	// Find a VFS with the same name and options and return it if possible
 	activeMu.Lock()
 	defer activeMu.Unlock()
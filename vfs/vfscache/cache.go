@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	sysdnotify "github.com/iguanesolutions/go-systemd/v5/notify"
@@ -25,6 +26,7 @@ import (
 	"github.com/rclone/rclone/lib/diskusage"
 	"github.com/rclone/rclone/lib/encoder"
 	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/vfs/vfscache/downloaders"
 	"github.com/rclone/rclone/vfs/vfscache/writeback"
 	"github.com/rclone/rclone/vfs/vfscommon"
 )
@@ -62,6 +64,13 @@ type Cache struct {
 	kickerMu      sync.Mutex       // mutex for cleanerKicked
 	kick          chan struct{}    // channel for kicking clear to start
 
+	// read statistics - atomic so no locking needed
+	readHits       atomic.Int64 // number of reads found in the cache
+	readMisses     atomic.Int64 // number of reads which needed downloading
+	readSequential atomic.Int64 // number of reads detected as sequential
+	readRandom     atomic.Int64 // number of reads detected as random
+
+	dirtyFiles atomic.Int64 // number of items which are dirty - atomic as updated with the item lock held
 }
 
 // AddVirtualFn if registered by the WithAddVirtual method, can be
@@ -155,9 +164,17 @@ func (c *Cache) Stats() (out rc.Params) {
 	out["pathMeta"] = c.metaRoot
 	out["hashType"] = c.hashType
 
-	uploadsInProgress, uploadsQueued := c.writeback.Stats()
+	uploadsInProgress, uploadsQueued, uploadsFailed := c.writeback.Stats()
 	out["uploadsInProgress"] = uploadsInProgress
 	out["uploadsQueued"] = uploadsQueued
+	out["uploadsFailed"] = uploadsFailed
+
+	out["readHits"] = c.readHits.Load()
+	out["readMisses"] = c.readMisses.Load()
+	out["readSequential"] = c.readSequential.Load()
+	out["readRandom"] = c.readRandom.Load()
+
+	out["dirtyFiles"] = c.dirtyFiles.Load()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -170,6 +187,79 @@ func (c *Cache) Stats() (out rc.Params) {
 	return out
 }
 
+// Queue returns info about the Cache writeback queue
+func (c *Cache) Queue() (out rc.Params) {
+	out = make(rc.Params)
+	out["queue"] = c.writeback.Queue()
+	return out
+}
+
+// QueueInfo returns info about a single item in the upload queue
+func (c *Cache) QueueInfo(id writeback.Handle) (writeback.QueueInfo, error) {
+	return c.writeback.Get(id)
+}
+
+// QueueSetExpiry updates the expiry of a single item in the upload queue
+func (c *Cache) QueueSetExpiry(id writeback.Handle, expiry time.Time) error {
+	return c.writeback.SetExpiry(id, expiry)
+}
+
+// QueueRetry retries the upload of a single item in the upload queue
+// now with a fresh set of retries
+func (c *Cache) QueueRetry(id writeback.Handle) error {
+	return c.writeback.Retry(id)
+}
+
+// QueueAbandon abandons the upload of a single item in the upload
+// queue, discarding the changes to it in the cache.
+//
+// It returns the name of the abandoned item.
+func (c *Cache) QueueAbandon(id writeback.Handle) (name string, err error) {
+	info, err := c.writeback.Get(id)
+	if err != nil {
+		return "", err
+	}
+	name = info.Name
+	c.mu.Lock()
+	item := c.item[name]
+	if item != nil {
+		if item.isOpen() {
+			c.mu.Unlock()
+			return name, fmt.Errorf("can't abandon upload of %q as it is open", name)
+		}
+		delete(c.item, name)
+	}
+	c.mu.Unlock()
+	if item == nil {
+		// Not in the cache so just remove it from the queue
+		c.writeback.Remove(id)
+	} else {
+		item.remove("upload abandoned")
+	}
+	fs.Infof(name, "vfs cache: abandoned upload")
+	return name, nil
+}
+
+// countRead updates the read statistics for a read which was present
+// in the cache or not. If stream is not nil the read was recorded in
+// it as access.
+func (c *Cache) countRead(present bool, stream *downloaders.Stream, access downloaders.Access) {
+	if present {
+		c.readHits.Add(1)
+	} else {
+		c.readMisses.Add(1)
+	}
+	if stream == nil {
+		return
+	}
+	switch access {
+	case downloaders.AccessSequential:
+		c.readSequential.Add(1)
+	case downloaders.AccessRandom:
+		c.readRandom.Add(1)
+	}
+}
+
 // createDir creates a directory path, along with any necessary parents
 func createDir(dir string) error {
 	return file.MkdirAll(dir, 0700)
@@ -368,8 +458,9 @@ func rename(osOldPath, osNewPath string) error {
 		}
 		return fmt.Errorf("failed to stat source: %s: %w", osOldPath, err)
 	}
-	if !sfi.Mode().IsRegular() {
-		// cannot copy non-regular files (e.g., directories, symlinks, devices, etc.)
+	// directories are allowed as they hold chunked cache files
+	if !sfi.Mode().IsRegular() && !sfi.IsDir() {
+		// cannot copy non-regular files (e.g., symlinks, devices, etc.)
 		return fmt.Errorf("non-regular source file: %s (%q)", sfi.Name(), sfi.Mode().String())
 	}
 	dfi, err := os.Stat(osNewPath)
@@ -383,12 +474,19 @@ func rename(osOldPath, osNewPath string) error {
 			return fmt.Errorf("failed to create parent dir: %s: %w", parent, err)
 		}
 	} else {
-		if !(dfi.Mode().IsRegular()) {
+		if !(dfi.Mode().IsRegular() || dfi.IsDir()) || dfi.IsDir() != sfi.IsDir() {
 			return fmt.Errorf("non-regular destination file: %s (%q)", dfi.Name(), dfi.Mode().String())
 		}
 		if os.SameFile(sfi, dfi) {
 			return nil
 		}
+		// os.Rename can't replace a non empty directory
+		if dfi.IsDir() {
+			err = os.RemoveAll(osNewPath)
+			if err != nil {
+				return fmt.Errorf("failed to remove destination: %s: %w", osNewPath, err)
+			}
+		}
 	}
 	if err = os.Rename(osOldPath, osNewPath); err != nil {
 		return fmt.Errorf("failed to rename in cache: %s to %s: %w", osOldPath, osNewPath, err)
@@ -523,7 +621,16 @@ func (c *Cache) reload(ctx context.Context) error {
 	for _, dir := range []string{c.root, c.metaRoot} {
 		err := c.walk(dir, func(osPath string, fi os.FileInfo, name string) error {
 			if fi.IsDir() {
-				return nil
+				// A directory with a metadata file is a chunked cache
+				// file. If chunking is off it was left by a previous
+				// run and getting the item removes it.
+				if dir != c.root || name == "" {
+					return nil
+				}
+				mfi, err := os.Stat(c.toOSPathMeta(name))
+				if err != nil || !mfi.Mode().IsRegular() {
+					return nil
+				}
 			}
 			item, found := c.get(name)
 			if !found {
@@ -532,6 +639,9 @@ func (c *Cache) reload(ctx context.Context) error {
 					fs.Errorf(name, "vfs cache: failed to reload item: %v", err)
 				}
 			}
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		})
 		if err != nil {
@@ -756,6 +866,11 @@ func (c *Cache) purgeOverQuota() {
 
 	sort.Sort(items)
 
+	// Evict the oldest chunks of chunked files first
+	if c.opt.CacheChunkSize > 0 {
+		c.purgeChunks(items)
+	}
+
 	// Remove items until the quota is OK
 	for _, item := range items {
 		c.removeNotInUse(item, 0, c.quotasOK())
@@ -766,6 +881,34 @@ func (c *Cache) purgeOverQuota() {
 	}
 }
 
+// Evict chunks of the items passed in until the total space is
+// reduced below quota starting from the least recently used chunk.
+//
+// Items which become empty are removed by purgeOverQuota.
+//
+// must be called with mu held.
+func (c *Cache) purgeChunks(items Items) {
+	type itemChunk struct {
+		item  *Item
+		chunk chunkInfo
+	}
+	var chunks []itemChunk
+	for _, item := range items {
+		for _, chunk := range item.chunks() {
+			chunks = append(chunks, itemChunk{item: item, chunk: chunk})
+		}
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].chunk.atime.Before(chunks[j].chunk.atime)
+	})
+	for _, ic := range chunks {
+		if c.quotasOK() {
+			break
+		}
+		c.used -= ic.item.evictChunk(ic.chunk.index)
+	}
+}
+
 // clean empties the cache of stuff if it can
 func (c *Cache) clean(kicked bool) {
 	// Cache may be empty so end
@@ -809,10 +952,10 @@ func (c *Cache) clean(kicked bool) {
 		}
 	}
 	c.mu.Unlock()
-	uploadsInProgress, uploadsQueued := c.writeback.Stats()
+	uploadsInProgress, uploadsQueued, uploadsFailed := c.writeback.Stats()
 
-	stats := fmt.Sprintf("objects %d (was %d) in use %d, to upload %d, uploading %d, total size %v (was %v)",
-		newItems, oldItems, totalInUse, uploadsQueued, uploadsInProgress, newUsed, oldUsed)
+	stats := fmt.Sprintf("objects %d (was %d) in use %d, to upload %d, uploading %d, upload failed %d, total size %v (was %v)",
+		newItems, oldItems, totalInUse, uploadsQueued, uploadsInProgress, uploadsFailed, newUsed, oldUsed)
 	fs.Infof(nil, "vfs cache: cleaned: %s", stats)
 	if err = sysdnotify.Status(fmt.Sprintf("[%s] vfs cache: %s", time.Now().Format("15:04"), stats)); err != nil {
 		fs.Errorf(nil, "vfs cache: updating systemd status with current stats failed: %s", err)
@@ -726,4 +726,8 @@ func TestCacheStats(t *testing.T) {
 	assert.Equal(t, 0, out["files"])
 	assert.Equal(t, 0, out["uploadsInProgress"])
 	assert.Equal(t, 0, out["uploadsQueued"])
+	assert.Equal(t, int64(0), out["readHits"])
+	assert.Equal(t, int64(0), out["readMisses"])
+	assert.Equal(t, int64(0), out["readSequential"])
+	assert.Equal(t, int64(0), out["readRandom"])
 }
@@ -0,0 +1,516 @@
+package vfscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/lib/ranges"
+)
+
+// maximum number of chunk files a chunkedFile keeps open at once
+const maxOpenChunks = 16
+
+// cacheFile is the storage for the data of an Item in the cache.
+//
+// It is satisfied by *os.File for the default sparse file layout and
+// by *chunkedFile when --vfs-cache-chunk-size is set.
+type cacheFile interface {
+	io.ReaderAt
+	io.WriterAt
+	io.Closer
+	Truncate(size int64) error
+	Stat() (os.FileInfo, error)
+	Sync() error
+}
+
+// chunkedFile stores the data of an Item in the cache as fixed size
+// chunk files in a directory rather than as a single sparse file.
+//
+// Chunk n holds the bytes from n*chunkSize up to (n+1)*chunkSize and
+// is stored in a file named n in decimal. Chunk files may be shorter
+// than chunkSize or missing entirely in which case the missing bytes
+// read as zeroes. This means only the chunks which have been read or
+// written take up space on disk, even on file systems which don't
+// support sparse files.
+//
+// The modification time of a chunk file is the last time it was
+// accessed which is used to evict the least recently used chunks.
+//
+// Chunk 0 always exists so the directory is never empty, otherwise it
+// would be removed when the cache purges empty directories.
+type chunkedFile struct {
+	dir       string // OS path of the directory holding the chunks
+	chunkSize int64  // size of each chunk
+
+	mu      sync.Mutex
+	size    int64              // logical size of the file
+	fds     map[int64]*os.File // open chunk files
+	touched map[int64]struct{} // chunks read since the access times were updated
+}
+
+// openChunkedFile opens the chunked file in dir which has the logical
+// size passed in.
+//
+// If flags contains os.O_CREATE then the directory will be created if
+// it doesn't exist, otherwise an error satisfying os.IsNotExist will
+// be returned.
+func openChunkedFile(dir string, flags int, chunkSize int64, size int64) (cf *chunkedFile, err error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("vfs cache: chunk size must be > 0")
+	}
+	if flags&os.O_CREATE != 0 {
+		err = file.MkdirAll(dir, 0700)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("vfs cache: chunked file %q is not a directory", dir)
+	}
+	if size < 0 {
+		size = 0
+	}
+	cf = &chunkedFile{
+		dir:       dir,
+		chunkSize: chunkSize,
+		size:      size,
+		fds:       make(map[int64]*os.File),
+		touched:   make(map[int64]struct{}),
+	}
+	// Make sure chunk 0 exists
+	if _, err = cf._chunk(0, true); err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+// chunkPath returns the OS path of chunk n of the chunked file in dir
+func chunkPath(dir string, n int64) string {
+	return filepath.Join(dir, strconv.FormatInt(n, 10))
+}
+
+// chunkPath returns the OS path of chunk n
+func (cf *chunkedFile) chunkPath(n int64) string {
+	return chunkPath(cf.dir, n)
+}
+
+// _chunk returns the open file for chunk n, opening it if necessary.
+//
+// If create is not set and the chunk doesn't exist it returns nil, nil.
+//
+// call with lock held
+func (cf *chunkedFile) _chunk(n int64, create bool) (fd *os.File, err error) {
+	if fd = cf.fds[n]; fd != nil {
+		return fd, nil
+	}
+	oFlags := os.O_RDWR
+	if create {
+		oFlags |= os.O_CREATE
+	}
+	fd, err = file.OpenFile(cf.chunkPath(n), oFlags, 0600)
+	if err != nil {
+		if !create && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("vfs cache: failed to open chunk %d: %w", n, err)
+	}
+	// Don't keep too many chunks open - close an arbitrary one
+	if len(cf.fds) >= maxOpenChunks {
+		for i, oldFd := range cf.fds {
+			delete(cf.fds, i)
+			if err := oldFd.Close(); err != nil {
+				fs.Errorf(cf.dir, "vfs cache: failed to close chunk %d: %v", i, err)
+			}
+			break
+		}
+	}
+	cf.fds[n] = fd
+	return fd, nil
+}
+
+// ReadAt reads len(b) bytes from the chunked file starting at byte
+// offset off. Parts of the file which haven't been written read as
+// zeroes. At end of file it returns io.EOF like os.File.
+func (cf *chunkedFile) ReadAt(b []byte, off int64) (n int, err error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	if off < 0 {
+		return 0, errors.New("vfs cache: negative offset")
+	}
+	if off >= cf.size {
+		return 0, io.EOF
+	}
+	if remaining := cf.size - off; int64(len(b)) > remaining {
+		b = b[:remaining]
+		err = io.EOF
+	}
+	for len(b) > 0 {
+		i, chunkOff := off/cf.chunkSize, off%cf.chunkSize
+		size := cf.chunkSize - chunkOff
+		if size > int64(len(b)) {
+			size = int64(len(b))
+		}
+		part := b[:size]
+		fd, chunkErr := cf._chunk(i, false)
+		if chunkErr != nil {
+			return n, chunkErr
+		}
+		nn := 0
+		if fd != nil {
+			nn, chunkErr = fd.ReadAt(part, chunkOff)
+			if chunkErr != nil && chunkErr != io.EOF {
+				return n + nn, chunkErr
+			}
+			cf.touched[i] = struct{}{}
+		}
+		// Zero anything past the end of the chunk file
+		for j := nn; j < len(part); j++ {
+			part[j] = 0
+		}
+		n += len(part)
+		off += size
+		b = b[size:]
+	}
+	return n, err
+}
+
+// WriteAt writes len(b) bytes to the chunked file starting at byte
+// offset off, extending it if necessary.
+func (cf *chunkedFile) WriteAt(b []byte, off int64) (n int, err error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	if off < 0 {
+		return 0, errors.New("vfs cache: negative offset")
+	}
+	for len(b) > 0 {
+		i, chunkOff := off/cf.chunkSize, off%cf.chunkSize
+		size := cf.chunkSize - chunkOff
+		if size > int64(len(b)) {
+			size = int64(len(b))
+		}
+		fd, err := cf._chunk(i, true)
+		if err != nil {
+			return n, err
+		}
+		nn, err := fd.WriteAt(b[:size], chunkOff)
+		n += nn
+		off += int64(nn)
+		if off > cf.size {
+			cf.size = off
+		}
+		if err != nil {
+			return n, err
+		}
+		b = b[size:]
+	}
+	return n, nil
+}
+
+// Truncate changes the logical size of the file, removing any chunks
+// which are wholly beyond the new size. Extending the file takes no
+// space on disk.
+func (cf *chunkedFile) Truncate(size int64) (err error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	if size < 0 {
+		return errors.New("vfs cache: negative size")
+	}
+	chunks, err := listChunks(cf.dir)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		start := chunk.index * cf.chunkSize
+		if chunk.index != 0 && start >= size {
+			if fd := cf.fds[chunk.index]; fd != nil {
+				delete(cf.fds, chunk.index)
+				_ = fd.Close()
+			}
+			delete(cf.touched, chunk.index)
+			err = os.Remove(cf.chunkPath(chunk.index))
+		} else if start+chunk.size > size {
+			err = os.Truncate(cf.chunkPath(chunk.index), size-start)
+		}
+		if err != nil {
+			return fmt.Errorf("vfs cache: failed to truncate chunk %d: %w", chunk.index, err)
+		}
+	}
+	cf.size = size
+	return nil
+}
+
+// chunkedFileInfo is the os.FileInfo for a chunked file
+type chunkedFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+// Size returns the logical size of the chunked file
+func (fi chunkedFileInfo) Size() int64 {
+	return fi.size
+}
+
+// statChunkedFile returns the os.FileInfo of the chunked file in dir
+// which has the logical size passed in
+func statChunkedFile(dir string, size int64) (os.FileInfo, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	return chunkedFileInfo{FileInfo: fi, size: size}, nil
+}
+
+// Stat returns the os.FileInfo of the chunked file with its logical
+// size
+func (cf *chunkedFile) Stat() (os.FileInfo, error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	return statChunkedFile(cf.dir, cf.size)
+}
+
+// Sync commits the open chunks to stable storage
+func (cf *chunkedFile) Sync() (err error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	for _, fd := range cf.fds {
+		if syncErr := fd.Sync(); syncErr != nil {
+			err = syncErr
+		}
+	}
+	return err
+}
+
+// Close the chunked file, recording the access times of any chunks
+// which have been read
+func (cf *chunkedFile) Close() (err error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	for i, fd := range cf.fds {
+		if closeErr := fd.Close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(cf.fds, i)
+	}
+	now := time.Now()
+	for i := range cf.touched {
+		// Ignore errors - this is only used for choosing chunks to evict
+		_ = os.Chtimes(cf.chunkPath(i), now, now)
+		delete(cf.touched, i)
+	}
+	return err
+}
+
+// removeChunkedFile removes the chunked file in dir
+func removeChunkedFile(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// chunkInfo describes a single chunk file on disk
+type chunkInfo struct {
+	index int64     // chunk number
+	size  int64     // size of the chunk file
+	atime time.Time // last time the chunk was accessed
+}
+
+// listChunks lists the chunk files in dir
+func listChunks(dir string) (chunks []chunkInfo, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		i, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || i < 0 {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		chunks = append(chunks, chunkInfo{
+			index: i,
+			size:  fi.Size(),
+			atime: fi.ModTime(),
+		})
+	}
+	return chunks, nil
+}
+
+// chunkedDiskSize returns the space on disk used by the chunks
+// holding rs
+func chunkedDiskSize(rs ranges.Ranges, chunkSize int64) (size int64) {
+	var (
+		lastChunk int64 = -1 // last chunk counted
+		lastEnd   int64      // end of the data counted in lastChunk
+	)
+	for _, r := range rs {
+		for pos := r.Pos; pos < r.End(); {
+			i := pos / chunkSize
+			start := i * chunkSize
+			end := start + chunkSize
+			if end > r.End() {
+				end = r.End()
+			}
+			if i != lastChunk {
+				// A chunk file is as long as the last byte written to it
+				lastChunk, lastEnd = i, start
+			}
+			size += end - lastEnd
+			lastEnd = end
+			pos = end
+		}
+	}
+	return size
+}
+
+// removeRange returns rs with r removed from it
+func removeRange(rs ranges.Ranges, r ranges.Range) (newRs ranges.Ranges) {
+	for _, keep := range []ranges.Range{
+		{Pos: 0, Size: r.Pos},
+		{Pos: r.End(), Size: (1 << 63) - 1 - r.End()},
+	} {
+		for _, kept := range rs.Intersection(keep) {
+			newRs.Insert(kept)
+		}
+	}
+	return newRs
+}
+
+// chunkedObject is an fs.Object which reads the data of an Item
+// stored as a chunked file so it can be uploaded with operations.Copy
+type chunkedObject struct {
+	item    *Item
+	dir     string
+	remote  string
+	size    int64
+	modTime time.Time
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *chunkedObject) Fs() fs.Info {
+	return o.item.c.fcache
+}
+
+// Remote returns the remote path
+func (o *chunkedObject) Remote() string {
+	return o.remote
+}
+
+// String returns a description of the Object
+func (o *chunkedObject) String() string {
+	return o.remote
+}
+
+// ModTime returns the modification date of the file
+func (o *chunkedObject) ModTime(ctx context.Context) time.Time {
+	return o.modTime
+}
+
+// Size returns the size of the file
+func (o *chunkedObject) Size() int64 {
+	return o.size
+}
+
+// Storable says whether this object can be stored
+func (o *chunkedObject) Storable() bool {
+	return true
+}
+
+// Hash returns the requested hash of the contents
+func (o *chunkedObject) Hash(ctx context.Context, ht hash.Type) (string, error) {
+	in, err := o.Open(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer fs.CheckClose(in, &err)
+	hasher, err := hash.NewMultiHasherTypes(hash.Set(ht))
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(hasher, in)
+	if err != nil {
+		return "", err
+	}
+	return hasher.Sums()[ht], nil
+}
+
+// SetModTime is not supported
+func (o *chunkedObject) SetModTime(ctx context.Context, modTime time.Time) error {
+	return fs.ErrorCantSetModTime
+}
+
+// chunkedReader reads a section of a chunkedFile closing it when done
+type chunkedReader struct {
+	*io.SectionReader
+	cf *chunkedFile
+}
+
+// Close the underlying chunked file
+func (r chunkedReader) Close() error {
+	return r.cf.Close()
+}
+
+// Open opens the file for read.  Call Close() on the returned io.ReadCloser
+func (o *chunkedObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	offset, limit := int64(0), int64(-1)
+	for _, option := range options {
+		switch x := option.(type) {
+		case *fs.RangeOption:
+			offset, limit = x.Decode(o.size)
+		case *fs.SeekOption:
+			offset = x.Offset
+		default:
+			if option.Mandatory() {
+				fs.Logf(o, "Unsupported mandatory option: %v", option)
+			}
+		}
+	}
+	if limit < 0 || offset+limit > o.size {
+		limit = o.size - offset
+	}
+	cf, err := openChunkedFile(o.dir, os.O_RDONLY, int64(o.item.c.opt.CacheChunkSize), o.size)
+	if err != nil {
+		return nil, err
+	}
+	return chunkedReader{
+		SectionReader: io.NewSectionReader(cf, offset, limit),
+		cf:            cf,
+	}, nil
+}
+
+// Update is not supported
+func (o *chunkedObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	return errors.New("vfs cache: can't update chunked object")
+}
+
+// Remove is not supported
+func (o *chunkedObject) Remove(ctx context.Context) error {
+	return errors.New("vfs cache: can't remove chunked object")
+}
+
+// Check interfaces
+var (
+	_ cacheFile = (*os.File)(nil)
+	_ cacheFile = (*chunkedFile)(nil)
+	_ fs.Object = (*chunkedObject)(nil)
+)
@@ -0,0 +1,244 @@
+package vfscache
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// check the chunk files in dir have the sizes given
+func checkChunks(t *testing.T, dir string, want map[int64]int64) {
+	chunks, err := listChunks(dir)
+	require.NoError(t, err)
+	got := map[int64]int64{}
+	for _, chunk := range chunks {
+		got[chunk.index] = chunk.size
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestChunkedFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "file")
+
+	_, err := openChunkedFile(dir, os.O_RDWR, 4, 0)
+	assert.True(t, os.IsNotExist(err))
+
+	cf, err := openChunkedFile(dir, os.O_CREATE|os.O_RDWR, 4, 0)
+	require.NoError(t, err)
+	checkChunks(t, dir, map[int64]int64{0: 0})
+
+	n, err := cf.WriteAt([]byte("HELLO"), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	checkChunks(t, dir, map[int64]int64{0: 0, 2: 4, 3: 3})
+
+	fi, err := cf.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), fi.Size())
+	assert.True(t, fi.IsDir())
+
+	buf := make([]byte, 20)
+	n, err = cf.ReadAt(buf, 0)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 15, n)
+	assert.Equal(t, zeroes[:10]+"HELLO", string(buf[:n]))
+
+	n, err = cf.ReadAt(buf[:3], 11)
+	require.NoError(t, err)
+	assert.Equal(t, "ELL", string(buf[:n]))
+
+	n, err = cf.ReadAt(buf, 15)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// Truncate removes the chunks past the end
+	require.NoError(t, cf.Truncate(11))
+	checkChunks(t, dir, map[int64]int64{0: 0, 2: 3})
+
+	// Extending the file doesn't use any space
+	require.NoError(t, cf.Truncate(20))
+	checkChunks(t, dir, map[int64]int64{0: 0, 2: 3})
+	n, err = cf.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, zeroes[:10]+"H"+zeroes[:9], string(buf[:n]))
+
+	require.NoError(t, cf.Sync())
+	require.NoError(t, cf.Close())
+
+	// Check we can read it back
+	cf, err = openChunkedFile(dir, os.O_RDWR, 4, 20)
+	require.NoError(t, err)
+	n, err = cf.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, zeroes[:10]+"H"+zeroes[:9], string(buf[:n]))
+	require.NoError(t, cf.Close())
+
+	require.NoError(t, removeChunkedFile(dir))
+	assertPathNotExist(t, dir)
+}
+
+func TestChunkedDiskSize(t *testing.T) {
+	for _, test := range []struct {
+		rs   ranges.Ranges
+		want int64
+	}{
+		{rs: nil, want: 0},
+		{rs: ranges.Ranges{{Pos: 0, Size: 10}}, want: 10},
+		// chunk files are as long as the last byte written
+		{rs: ranges.Ranges{{Pos: 2, Size: 1}}, want: 3},
+		{rs: ranges.Ranges{{Pos: 10, Size: 5}}, want: 7},
+		{rs: ranges.Ranges{{Pos: 0, Size: 1}, {Pos: 3, Size: 1}}, want: 4},
+		{rs: ranges.Ranges{{Pos: 1, Size: 1}, {Pos: 9, Size: 1}}, want: 4},
+	} {
+		assert.Equal(t, test.want, chunkedDiskSize(test.rs, 4), test.rs)
+	}
+}
+
+func TestRemoveRange(t *testing.T) {
+	rs := ranges.Ranges{{Pos: 0, Size: 10}, {Pos: 20, Size: 10}}
+	assert.Equal(t, ranges.Ranges{{Pos: 0, Size: 4}, {Pos: 8, Size: 2}, {Pos: 20, Size: 10}}, removeRange(rs, ranges.Range{Pos: 4, Size: 4}))
+	assert.Equal(t, ranges.Ranges{{Pos: 20, Size: 10}}, removeRange(rs, ranges.Range{Pos: 0, Size: 12}))
+	assert.Equal(t, rs, removeRange(rs, ranges.Range{Pos: 12, Size: 4}))
+}
+
+func newChunkedTestCache(t *testing.T) (r *fstest.Run, c *Cache) {
+	opt := vfscommon.DefaultOpt
+
+	// Disable the cache cleaner as it interferes with these tests
+	opt.CachePollInterval = 0
+
+	// Disable synchronous write
+	opt.WriteBack = 0
+
+	opt.CacheChunkSize = 16
+
+	return newTestCacheOpt(t, opt)
+}
+
+func TestItemChunked(t *testing.T) {
+	r, c := newChunkedTestCache(t)
+
+	contents, obj, item := newFile(t, r, c, "existing")
+	require.NoError(t, item.Open(obj))
+
+	buf := make([]byte, 10)
+	n, err := item.ReadAt(buf, 40)
+	require.NoError(t, err)
+	assert.Equal(t, contents[40:50], string(buf[:n]))
+
+	fi, err := os.Stat(c.toOSPath("existing"))
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+
+	n, err = item.WriteAt([]byte("HELLO"), 98)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	size, err := item.GetSize()
+	require.NoError(t, err)
+	assert.Equal(t, int64(103), size)
+
+	require.NoError(t, item.Close(nil))
+
+	checkObject(t, r, "existing", contents[:98]+"HELLO")
+
+	// Check the uploaded object has the correct hash
+	cacheObj, err := item._cacheObject(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(103), cacheObj.Size())
+	md5, err := cacheObj.Hash(context.Background(), hash.MD5)
+	require.NoError(t, err)
+	wantMD5, err := hash.NewMultiHasherTypes(hash.NewHashSet(hash.MD5))
+	require.NoError(t, err)
+	_, err = wantMD5.Write([]byte(contents[:98] + "HELLO"))
+	require.NoError(t, err)
+	assert.Equal(t, wantMD5.Sums()[hash.MD5], md5)
+}
+
+func TestCachePurgeChunks(t *testing.T) {
+	r, c := newChunkedTestCache(t)
+
+	contents, obj, item := newFile(t, r, c, "existing")
+	require.NoError(t, item.Open(obj))
+
+	// Read the whole file into the cache
+	buf := make([]byte, 100)
+	n, err := item.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, contents, string(buf[:n]))
+	require.NoError(t, item.Close(nil))
+
+	c.updateUsed()
+	assert.Equal(t, int64(100), c.used)
+
+	// Make chunk 1 the least recently used then chunk 2 and so on
+	osPath := c.toOSPath("existing")
+	t0 := time.Now().Add(-time.Hour)
+	for i := int64(0); i < 7; i++ {
+		tChunk := t0.Add(time.Duration(i) * time.Minute)
+		if i == 0 {
+			tChunk = t0.Add(time.Hour)
+		}
+		require.NoError(t, os.Chtimes(chunkPath(osPath, i), tChunk, tChunk))
+	}
+
+	// Check only the oldest chunks were evicted
+	c.opt.CacheMaxSize = 60
+	c.purgeOverQuota()
+	assert.Equal(t, int64(52), c.used)
+	checkChunks(t, osPath, map[int64]int64{0: 16, 4: 16, 5: 16, 6: 4})
+	assert.Equal(t, ranges.Ranges{{Pos: 0, Size: 16}, {Pos: 64, Size: 36}}, item.info.Rs)
+
+	// Check the item can still be read
+	require.NoError(t, item.Open(obj))
+	n, err = item.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, contents, string(buf[:n]))
+	require.NoError(t, item.Close(nil))
+
+	// Now purge everything
+	c.updateUsed()
+	c.opt.CacheMaxSize = 1
+	c.purgeOverQuota()
+	assert.Equal(t, int64(0), c.used)
+	assert.Equal(t, []string(nil), itemAsString(c))
+	assertPathNotExist(t, osPath)
+}
+
+func TestCacheReloadChunkingOff(t *testing.T) {
+	r, c := newChunkedTestCache(t)
+
+	contents, obj, item := newFile(t, r, c, "existing")
+	require.NoError(t, item.Open(obj))
+	buf := make([]byte, 100)
+	n, err := item.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, contents, string(buf[:n]))
+	require.NoError(t, item.Close(nil))
+	osPath := c.toOSPath("existing")
+	fi, err := os.Stat(osPath)
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+
+	// Reload the cache with chunking turned off
+	c.mu.Lock()
+	c.item = map[string]*Item{}
+	c.mu.Unlock()
+	c.opt.CacheChunkSize = 0
+	require.NoError(t, c.reload(context.Background()))
+
+	// The chunks aren't loaded as items and the chunked file is removed
+	assert.Equal(t, []string{`name="existing" opens=0 size=0`}, itemAsString(c))
+	assertPathNotExist(t, osPath)
+	assertPathNotExist(t, c.toOSPathMeta("existing"))
+}
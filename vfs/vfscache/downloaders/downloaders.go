@@ -78,6 +78,7 @@ type Downloaders struct {
 // the range is found
 type waiter struct {
 	r       ranges.Range
+	stream  *Stream // access pattern of the reader - may be nil
 	errChan chan<- error
 }
 
@@ -248,7 +249,10 @@ func (dls *Downloaders) Close(inErr error) (err error) {
 
 // Download the range passed in returning when it has been downloaded
 // with an error from the downloading go routine.
-func (dls *Downloaders) Download(r ranges.Range) (err error) {
+//
+// If stream is not nil it is used to choose the read ahead, otherwise
+// the fixed --vfs-read-ahead is used.
+func (dls *Downloaders) Download(r ranges.Range, stream *Stream) (err error) {
 	// defer log.Trace(dls.src, "r=%+v", r)("err=%v", &err)
 
 	dls.mu.Lock()
@@ -256,10 +260,11 @@ func (dls *Downloaders) Download(r ranges.Range) (err error) {
 	errChan := make(chan error)
 	waiter := waiter{
 		r:       r,
+		stream:  stream,
 		errChan: errChan,
 	}
 
-	err = dls._ensureDownloader(r)
+	err = dls._ensureDownloader(r, stream)
 	if err != nil {
 		dls.mu.Unlock()
 		return err
@@ -284,15 +289,21 @@ func (dls *Downloaders) _closeWaiters(err error) {
 // then it starts it.
 //
 // call with lock held
-func (dls *Downloaders) _ensureDownloader(r ranges.Range) (err error) {
+func (dls *Downloaders) _ensureDownloader(r ranges.Range, stream *Stream) (err error) {
 	// defer log.Trace(dls.src, "r=%v", r)("err=%v", &err)
 
 	// The window includes potentially unread data in the buffer
 	window := int64(fs.GetConfig(context.TODO()).BufferSize)
 
+	// Find the read ahead from the access pattern if we have one
+	readAhead, speculate := int64(dls.opt.ReadAhead), true
+	if stream != nil {
+		readAhead, speculate = stream.get()
+	}
+
 	// Increase the read range by the read ahead if set
-	if dls.opt.ReadAhead > 0 {
-		r.Size += int64(dls.opt.ReadAhead)
+	if readAhead > 0 {
+		r.Size += readAhead
 	}
 
 	// We may be reopening a downloader after a failure here or
@@ -306,6 +317,10 @@ func (dls *Downloaders) _ensureDownloader(r ranges.Range) (err error) {
 	// downloader if the window isn't full.
 	startNew := true
 	if r.IsEmpty() {
+		// Reads are random so don't download anything speculatively
+		if !speculate {
+			return nil
+		}
 		// Make a new range which includes the window
 		rWindow := r
 		rWindow.Size += window
@@ -371,11 +386,14 @@ func (dls *Downloaders) _ensureDownloader(r ranges.Range) (err error) {
 // EnsureDownloader makes sure a downloader is running for the range
 // passed in.  If one isn't found then it starts it.
 //
-// It does not wait for the range to be downloaded
-func (dls *Downloaders) EnsureDownloader(r ranges.Range) (err error) {
+// It does not wait for the range to be downloaded.
+//
+// If stream is not nil it is used to choose the read ahead, otherwise
+// the fixed --vfs-read-ahead is used.
+func (dls *Downloaders) EnsureDownloader(r ranges.Range, stream *Stream) (err error) {
 	dls.mu.Lock()
 	defer dls.mu.Unlock()
-	return dls._ensureDownloader(r)
+	return dls._ensureDownloader(r, stream)
 }
 
 // _dispatchWaiters() sends any waiters which have completed back to
@@ -415,7 +433,7 @@ func (dls *Downloaders) kickWaiters() (err error) {
 	// However the number of waiters and the number of downloaders
 	// are both expected to be small.
 	for _, waiter := range dls.waiters {
-		err = dls._ensureDownloader(waiter.r)
+		err = dls._ensureDownloader(waiter.r, waiter.stream)
 		if err != nil {
 			// Failures here will be retried by background kicker
 			fs.Errorf(dls.src, "vfs cache: restart download failed: %v", err)
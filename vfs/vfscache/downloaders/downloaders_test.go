@@ -110,7 +110,7 @@ func TestDownloaders(t *testing.T) {
 			{Pos: 500, Size: 250},
 			{Pos: 25000000, Size: 250},
 		} {
-			err := dls.Download(r)
+			err := dls.Download(r, nil)
 			require.NoError(t, err)
 			assert.True(t, item.HasRange(r))
 		}
@@ -120,7 +120,7 @@ func TestDownloaders(t *testing.T) {
 		item, dls := newTest()
 		defer cancel(dls)
 		r := ranges.Range{Pos: 40 * 1024 * 1024, Size: 250}
-		err := dls.EnsureDownloader(r)
+		err := dls.EnsureDownloader(r, nil)
 		require.NoError(t, err)
 		// FIXME racy test
 		assert.False(t, item.HasRange(r))
@@ -0,0 +1,139 @@
+package downloaders
+
+import (
+	"sync"
+
+	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+const (
+	// reads starting within this many bytes of the end of the
+	// previous read are counted as sequential. This allows for
+	// the kernel issuing reads slightly out of order.
+	sequentialSlack = 1024 * 1024
+	// number of consecutive sequential reads before the read
+	// ahead window is grown
+	sequentialThreshold = 2
+	// number of consecutive random reads before speculative
+	// downloading is stopped
+	randomThreshold = 2
+)
+
+// Access describes how a read fitted the access pattern of a Stream
+type Access byte
+
+// Types of Access
+const (
+	AccessSequential Access = iota // read followed on from the previous one
+	AccessRandom                   // read was somewhere else in the file
+)
+
+// String converts an Access to a string
+func (a Access) String() string {
+	switch a {
+	case AccessSequential:
+		return "sequential"
+	case AccessRandom:
+		return "random"
+	}
+	return "unknown"
+}
+
+// Stream tracks the access pattern of a single reader of an item,
+// normally a file handle, so the read ahead can be adapted to it.
+//
+// If --vfs-read-ahead-max is larger than --vfs-read-ahead then the
+// read ahead window starts at --vfs-read-ahead and is doubled for
+// each sequential read up to --vfs-read-ahead-max. Repeated random
+// reads shrink the window to nothing and stop speculative
+// downloading until the reader becomes sequential again.
+//
+// Otherwise the read ahead is fixed at --vfs-read-ahead.
+type Stream struct {
+	opt *vfscommon.Options
+
+	mu         sync.Mutex
+	next       int64 // offset we expect the next sequential read at
+	sequential int   // number of consecutive sequential reads
+	random     int   // number of consecutive random reads
+	readAhead  int64 // current read ahead window
+}
+
+// NewStream makes a new Stream to track the access pattern of a reader
+func NewStream(opt *vfscommon.Options) *Stream {
+	return &Stream{
+		opt:       opt,
+		readAhead: int64(opt.ReadAhead),
+	}
+}
+
+// adaptive returns true if the read ahead should adapt to the access
+// pattern
+func (s *Stream) adaptive() bool {
+	return s.opt.ReadAheadMax > s.opt.ReadAhead
+}
+
+// Record the read of r returning how it fitted the access pattern
+// and adjusting the read ahead window accordingly
+func (s *Stream) Record(r ranges.Range) (access Access) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Pos >= s.next-sequentialSlack && r.Pos <= s.next+sequentialSlack {
+		access = AccessSequential
+		s.random = 0
+		s.sequential++
+		if s.adaptive() && s.sequential >= sequentialThreshold {
+			s._grow()
+		}
+	} else {
+		access = AccessRandom
+		s.sequential = 0
+		s.random++
+		if s.adaptive() {
+			if s.random >= randomThreshold {
+				s.readAhead = 0
+			} else {
+				// A single seek, for example in a media
+				// player, starts again from the initial
+				// read ahead
+				s.readAhead = int64(s.opt.ReadAhead)
+			}
+		}
+	}
+	// Don't move next backwards for reads which arrived slightly
+	// out of order
+	if end := r.End(); access == AccessRandom || end > s.next {
+		s.next = end
+	}
+	return access
+}
+
+// grow the read ahead window up to the maximum
+//
+// call with lock held
+func (s *Stream) _grow() {
+	if s.readAhead < minWindow {
+		s.readAhead = minWindow
+	} else {
+		s.readAhead *= 2
+	}
+	if maxReadAhead := int64(s.opt.ReadAheadMax); s.readAhead > maxReadAhead {
+		s.readAhead = maxReadAhead
+	}
+}
+
+// get the current read ahead and whether speculative downloading
+// should be done
+func (s *Stream) get() (readAhead int64, speculate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	speculate = !s.adaptive() || s.random < randomThreshold
+	return s.readAhead, speculate
+}
+
+// ReadAhead returns the current read ahead window
+func (s *Stream) ReadAhead() int64 {
+	readAhead, _ := s.get()
+	return readAhead
+}
@@ -0,0 +1,91 @@
+package downloaders
+
+import (
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/ranges"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+)
+
+const mebi = 1024 * 1024
+
+func TestStreamFixed(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.ReadAhead = 4 * fs.Mebi
+	s := NewStream(&opt)
+
+	assert.Equal(t, AccessSequential, s.Record(ranges.Range{Pos: 0, Size: 4096}))
+	assert.Equal(t, AccessSequential, s.Record(ranges.Range{Pos: 4096, Size: 4096}))
+	assert.Equal(t, AccessSequential, s.Record(ranges.Range{Pos: 8192, Size: 4096}))
+	assert.Equal(t, int64(4*mebi), s.ReadAhead())
+
+	assert.Equal(t, AccessRandom, s.Record(ranges.Range{Pos: 100 * mebi, Size: 4096}))
+	assert.Equal(t, AccessRandom, s.Record(ranges.Range{Pos: 10 * mebi, Size: 4096}))
+	assert.Equal(t, AccessRandom, s.Record(ranges.Range{Pos: 50 * mebi, Size: 4096}))
+	readAhead, speculate := s.get()
+	assert.Equal(t, int64(4*mebi), readAhead)
+	assert.True(t, speculate)
+}
+
+func TestStreamAdaptive(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.ReadAhead = 0
+	opt.ReadAheadMax = 8 * fs.Mebi
+	s := NewStream(&opt)
+	const size = 128 * 1024
+
+	// Sequential reads grow the window up to the maximum
+	var pos int64
+	read := func() Access {
+		access := s.Record(ranges.Range{Pos: pos, Size: size})
+		pos += size
+		return access
+	}
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(0), s.ReadAhead())
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(1*mebi), s.ReadAhead())
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(2*mebi), s.ReadAhead())
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(4*mebi), s.ReadAhead())
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(8*mebi), s.ReadAhead())
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(8*mebi), s.ReadAhead())
+
+	// Slightly out of order reads are still sequential
+	assert.Equal(t, AccessSequential, s.Record(ranges.Range{Pos: pos - 2*size, Size: size}))
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(8*mebi), s.ReadAhead())
+
+	// A single seek resets the window
+	pos = 500 * mebi
+	assert.Equal(t, AccessRandom, read())
+	readAhead, speculate := s.get()
+	assert.Equal(t, int64(0), readAhead)
+	assert.True(t, speculate)
+
+	// Repeated seeks stop speculation
+	pos = 100 * mebi
+	assert.Equal(t, AccessRandom, read())
+	readAhead, speculate = s.get()
+	assert.Equal(t, int64(0), readAhead)
+	assert.False(t, speculate)
+
+	// Going sequential again restarts speculation
+	assert.Equal(t, AccessSequential, read())
+	readAhead, speculate = s.get()
+	assert.Equal(t, int64(0), readAhead)
+	assert.True(t, speculate)
+	assert.Equal(t, AccessSequential, read())
+	assert.Equal(t, int64(1*mebi), s.ReadAhead())
+}
+
+func TestAccessString(t *testing.T) {
+	assert.Equal(t, "sequential", AccessSequential.String())
+	assert.Equal(t, "random", AccessRandom.String())
+	assert.Equal(t, "unknown", Access(99).String())
+}
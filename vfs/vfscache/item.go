@@ -62,12 +62,13 @@ type Item struct {
 	opens           int                      // number of times file is open
 	downloaders     *downloaders.Downloaders // a record of the downloaders in action - may be nil
 	o               fs.Object                // object we are caching - may be nil
-	fd              *os.File                 // handle we are using to read and write to the file
+	fd              cacheFile                // handle we are using to read and write to the file
 	info            Info                     // info about the file to persist to backing store
 	writeBackID     writeback.Handle         // id of any writebacks in progress
 	pendingAccesses int                      // number of threads - cache reset not allowed if not zero
 	modified        bool                     // set if the file has been modified since the last Open
 	beingReset      bool                     // cache cleaner is resetting the cache file, access not allowed
+	dirtyCounted    bool                     // set if the item is counted in the dirty files of the cache
 }
 
 // Info is persisted to backing store
@@ -78,6 +79,7 @@ type Info struct {
 	Rs          ranges.Ranges // which parts of the file are present
 	Fingerprint string        // fingerprint of remote object
 	Dirty       bool          // set if the backing file has been modified
+	Metadata    fs.Metadata   // metadata to write to the remote object, if set
 }
 
 // Items are a slice of *Item ordered by ATime
@@ -148,6 +150,10 @@ func newItem(c *Cache, name string) (item *Item) {
 		} else {
 			item.remove(fmt.Sprintf("failed to stat cache file: %v", statErr))
 		}
+	} else if fi.IsDir() != item.chunked() {
+		// The cache file was written with a different --vfs-cache-chunk-size
+		item.remove("cache layout changed")
+		statErr = os.ErrNotExist
 	}
 
 	// Try to load the metadata
@@ -159,7 +165,9 @@ func newItem(c *Cache, name string) (item *Item) {
 	}
 
 	// Get size estimate (which is best we can do until Open() called)
-	if statErr == nil {
+	//
+	// Chunked files have their size stored in the metadata only
+	if statErr == nil && !fi.IsDir() {
 		item.info.Size = fi.Size()
 	}
 	return item
@@ -172,6 +180,13 @@ func (item *Item) inUse() bool {
 	return item.opens != 0 || item.info.Dirty
 }
 
+// isOpen returns true if the item is open
+func (item *Item) isOpen() bool {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	return item.opens != 0
+}
+
 // getDiskSize returns the size on disk (approximately) of the item
 //
 // We return the sizes of the chunks we have fetched, however there is
@@ -179,9 +194,45 @@ func (item *Item) inUse() bool {
 func (item *Item) getDiskSize() int64 {
 	item.mu.Lock()
 	defer item.mu.Unlock()
+	return item._diskSize()
+}
+
+// _diskSize returns the size on disk (approximately) of the item
+//
+// call with the lock held
+func (item *Item) _diskSize() int64 {
+	if item.chunked() {
+		return chunkedDiskSize(item.info.Rs, int64(item.c.opt.CacheChunkSize))
+	}
 	return item.info.Rs.Size()
 }
 
+// chunked returns true if the item is stored as a chunked file
+func (item *Item) chunked() bool {
+	return item.c.opt.CacheChunkSize > 0
+}
+
+// _openFile opens the cache file at osPath with the flags passed in
+//
+// This opens a chunked file if --vfs-cache-chunk-size is set or a
+// sparse file otherwise.
+//
+// call with the lock held
+func (item *Item) _openFile(osPath string, flags int) (fd cacheFile, err error) {
+	if item.chunked() {
+		return openChunkedFile(osPath, flags, int64(item.c.opt.CacheChunkSize), item.info.Size)
+	}
+	osFd, err := file.OpenFile(osPath, flags, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = file.SetSparse(osFd)
+	if err != nil {
+		fs.Errorf(item.name, "vfs cache: failed to set as a sparse file: %v", err)
+	}
+	return osFd, nil
+}
+
 // load reads an item from the disk or returns nil if not found
 func (item *Item) load() (exists bool, err error) {
 	item.mu.Lock()
@@ -197,6 +248,7 @@ func (item *Item) load() (exists bool, err error) {
 	defer fs.CheckClose(in, &err)
 	decoder := json.NewDecoder(in)
 	err = decoder.Decode(&item.info)
+	item._countDirty()
 	if err != nil {
 		return true, fmt.Errorf("vfs cache item: corrupt metadata: %w", err)
 	}
@@ -243,26 +295,21 @@ func (item *Item) _truncate(size int64) (err error) {
 			oFlags |= os.O_CREATE
 		}
 		osPath := item.c.toOSPath(item.name) // No locking in Cache
-		fd, err = file.OpenFile(osPath, oFlags, 0600)
+		fd, err = item._openFile(osPath, oFlags)
 		if err != nil && os.IsNotExist(err) {
 			// If the metadata has info but the file doesn't
 			// not exist then it has been externally removed
 			fs.Errorf(item.name, "vfs cache: detected external removal of cache file")
 			item.info.Rs = nil      // show we have no blocks cached
-			item.info.Dirty = false // file can't be dirty if it doesn't exist
+			item._setDirty(false) // file can't be dirty if it doesn't exist
 			item._removeMeta("cache file externally deleted")
-			fd, err = file.OpenFile(osPath, os.O_CREATE|os.O_WRONLY, 0600)
+			fd, err = item._openFile(osPath, os.O_CREATE|os.O_WRONLY)
 		}
 		if err != nil {
 			return fmt.Errorf("vfs cache: truncate: failed to open cache file: %w", err)
 		}
 
 		defer fs.CheckClose(fd, &err)
-
-		err = file.SetSparse(fd)
-		if err != nil {
-			fs.Errorf(item.name, "vfs cache: truncate: failed to set as a sparse file: %v", err)
-		}
 	}
 
 	// Check to see what the current size is, and don't truncate
@@ -371,6 +418,9 @@ func (item *Item) _stat() (fi os.FileInfo, err error) {
 		return item.fd.Stat()
 	}
 	osPath := item.c.toOSPath(item.name) // No locking in Cache
+	if item.chunked() {
+		return statChunkedFile(osPath, item.info.Size)
+	}
 	return os.Stat(osPath)
 }
 
@@ -436,7 +486,7 @@ func (item *Item) _dirty() {
 		item.mu.Lock()
 	}
 	if !item.info.Dirty {
-		item.info.Dirty = true
+		item._setDirty(true)
 		err := item._save()
 		if err != nil {
 			fs.Errorf(item.name, "vfs cache: failed to save item info: %v", err)
@@ -444,6 +494,31 @@ func (item *Item) _dirty() {
 	}
 }
 
+// _setDirty sets whether the item data is dirty
+//
+// call with lock held
+func (item *Item) _setDirty(dirty bool) {
+	item.info.Dirty = dirty
+	item._countDirty()
+}
+
+// _countDirty updates the count of dirty files in the cache if the
+// item has become dirty or clean. This keeps Cache.Stats from having
+// to lock every item to count them.
+//
+// call with lock held
+func (item *Item) _countDirty() {
+	if item.info.Dirty == item.dirtyCounted {
+		return
+	}
+	item.dirtyCounted = item.info.Dirty
+	if item.dirtyCounted {
+		item.c.dirtyFiles.Add(1)
+	} else {
+		item.c.dirtyFiles.Add(-1)
+	}
+}
+
 // Dirty marks the item as changed and needing writeback
 func (item *Item) Dirty() {
 	item.preAccess()
@@ -468,15 +543,11 @@ func (item *Item) _createFile(osPath string) (err error) {
 	}
 	item.modified = false
 	// t0 := time.Now()
-	fd, err := file.OpenFile(osPath, os.O_RDWR, 0600)
+	fd, err := item._openFile(osPath, os.O_RDWR)
 	// fs.Debugf(item.name, "OpenFile took %v", time.Since(t0))
 	if err != nil {
 		return fmt.Errorf("vfs cache item: open failed: %w", err)
 	}
-	err = file.SetSparse(fd)
-	if err != nil {
-		fs.Errorf(item.name, "vfs cache: failed to set as a sparse file: %v", err)
-	}
 	item.fd = fd
 
 	err = item._save()
@@ -573,6 +644,31 @@ func (item *Item) open(o fs.Object) (err error) {
 	return err
 }
 
+// _cacheObject returns an fs.Object to read the cache file or
+// fs.ErrorObjectNotFound if it doesn't exist
+//
+// Call with lock held
+func (item *Item) _cacheObject(ctx context.Context) (fs.Object, error) {
+	if !item.chunked() {
+		return item.c.fcache.NewObject(ctx, item.name)
+	}
+	osPath := item.c.toOSPath(item.name) // No locking in Cache
+	fi, err := os.Stat(osPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fs.ErrorObjectNotFound
+		}
+		return nil, err
+	}
+	return &chunkedObject{
+		item:    item,
+		dir:     osPath,
+		remote:  item.name,
+		size:    item.info.Size,
+		modTime: fi.ModTime(),
+	}, nil
+}
+
 // Store stores the local cache file to the remote object, returning
 // the new remote object. objOld is the old object if known.
 //
@@ -581,13 +677,19 @@ func (item *Item) _store(ctx context.Context, storeFn StoreFn) (err error) {
 	// defer log.Trace(item.name, "item=%p", item)("err=%v", &err)
 
 	// Transfer the temp file to the remote
-	cacheObj, err := item.c.fcache.NewObject(ctx, item.name)
+	cacheObj, err := item._cacheObject(ctx)
 	if err != nil && err != fs.ErrorObjectNotFound {
 		return fmt.Errorf("vfs cache: failed to find cache file: %w", err)
 	}
 
 	// Object has disappeared if cacheObj == nil
 	if cacheObj != nil {
+		if item.info.Metadata != nil {
+			var ci *fs.ConfigInfo
+			ctx, ci = fs.AddConfig(ctx)
+			ci.Metadata = true
+			cacheObj = &metadataObject{Object: cacheObj, metadata: copyMetadata(item.info.Metadata)}
+		}
 		o, name := item.o, item.name
 		item.mu.Unlock()
 		o, err := operations.Copy(ctx, item.c.fremote, o, name, cacheObj)
@@ -616,7 +718,7 @@ func (item *Item) _store(ctx context.Context, storeFn StoreFn) (err error) {
 	}
 
 	// Show item is clean and is eligible for cache removal
-	item.info.Dirty = false
+	item._setDirty(false)
 	err = item._save()
 	if err != nil {
 		fs.Errorf(item.name, "vfs cache: failed to write metadata file: %v", err)
@@ -664,7 +766,7 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 	// would require keeping the downloaders alive after the item
 	// has been closed
 	if item.info.Dirty && item.o != nil {
-		err = item._ensure(0, item.info.Size)
+		err = item._ensure(0, item.info.Size, nil)
 		if err != nil {
 			return fmt.Errorf("vfs cache: failed to download missing parts of cache file: %w", err)
 		}
@@ -728,7 +830,7 @@ func (item *Item) Close(storeFn StoreFn) (err error) {
 			item.c.writeback.SetID(&item.writeBackID)
 			id := item.writeBackID
 			item.mu.Unlock()
-			item.c.writeback.Add(id, item.name, item.modified, func(ctx context.Context) error {
+			item.c.writeback.Add(id, item.name, item.info.Size, item.modified, func(ctx context.Context) error {
 				return item.store(ctx, storeFn)
 			})
 			item.mu.Lock()
@@ -843,7 +945,12 @@ func (item *Item) WrittenBack() bool {
 // call with lock held
 func (item *Item) _removeFile(reason string) {
 	osPath := item.c.toOSPath(item.name) // No locking in Cache
-	err := os.Remove(osPath)
+	var err error
+	if fi, statErr := os.Stat(osPath); statErr == nil && fi.IsDir() {
+		err = removeChunkedFile(osPath)
+	} else {
+		err = os.Remove(osPath)
+	}
 	if err != nil {
 		if !os.IsNotExist(err) {
 			fs.Errorf(item.name, "vfs cache: failed to remove cache file as %s: %v", reason, err)
@@ -880,6 +987,7 @@ func (item *Item) _remove(reason string) (wasWriting bool) {
 	wasWriting = item.c.writeback.Remove(item.writeBackID)
 	item.mu.Lock()
 	item.info.clean()
+	item._countDirty()
 	item._removeFile(reason)
 	item._removeMeta(reason)
 	return wasWriting
@@ -921,7 +1029,7 @@ func (item *Item) RemoveNotInUse(maxAge time.Duration, emptyOnly bool) (removed
 		}
 	}
 	if removeIt {
-		spaceUsed := item.info.Rs.Size()
+		spaceUsed := item._diskSize()
 		if !emptyOnly || spaceUsed == 0 {
 			spaceFreed = spaceUsed
 			removed = true
@@ -933,6 +1041,57 @@ func (item *Item) RemoveNotInUse(maxAge time.Duration, emptyOnly bool) (removed
 	return
 }
 
+// chunks returns the chunks of a chunked item which are eligible for
+// eviction, or nil if the item is in use
+func (item *Item) chunks() []chunkInfo {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	if !item.chunked() || item.opens != 0 || item.info.Dirty {
+		return nil
+	}
+	chunks, err := listChunks(item.c.toOSPath(item.name)) // No locking in Cache
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fs.Errorf(item.name, "vfs cache: failed to list chunks: %v", err)
+		}
+		return nil
+	}
+	return chunks
+}
+
+// evictChunk removes chunk n of a chunked item which is not in use
+// from the cache returning the space freed
+//
+// Chunk 0 is truncated rather than removed so the item still exists.
+func (item *Item) evictChunk(n int64) (spaceFreed int64) {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	if !item.chunked() || item.opens != 0 || item.info.Dirty {
+		return 0
+	}
+	chunkSize := int64(item.c.opt.CacheChunkSize)
+	r := ranges.Range{Pos: n * chunkSize, Size: chunkSize}
+	osPath := chunkPath(item.c.toOSPath(item.name), n) // No locking in Cache
+	var err error
+	if n == 0 {
+		err = os.Truncate(osPath, 0)
+	} else {
+		err = os.Remove(osPath)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		fs.Errorf(item.name, "vfs cache: failed to evict chunk %d: %v", n, err)
+		return 0
+	}
+	spaceFreed = chunkedDiskSize(item.info.Rs.Intersection(r), chunkSize)
+	item.info.Rs = removeRange(item.info.Rs, r)
+	err = item._save()
+	if err != nil {
+		fs.Errorf(item.name, "vfs cache: failed to save item info: %v", err)
+	}
+	fs.Debugf(item.name, "vfs cache: evicted chunk %d freeing %d bytes", n, spaceFreed)
+	return spaceFreed
+}
+
 // Reset is called by the cache purge functions only to reset (empty the contents) cache files that
 // are not dirty.  It is used when cache space runs out and we see some ENOSPC error.
 func (item *Item) Reset() (rr ResetResult, spaceFreed int64, err error) {
@@ -941,7 +1100,7 @@ func (item *Item) Reset() (rr ResetResult, spaceFreed int64, err error) {
 
 	// The item is not being used now.  Just remove it instead of resetting it.
 	if item.opens == 0 && !item.info.Dirty {
-		spaceFreed = item.info.Rs.Size()
+		spaceFreed = item._diskSize()
 		if item._remove("Removing old cache file not in use") {
 			fs.Errorf(item.name, "item removed when it was writing/uploaded")
 		}
@@ -1018,7 +1177,7 @@ func (item *Item) Reset() (rr ResetResult, spaceFreed int64, err error) {
 		item.fd = nil
 	}
 
-	spaceFreed = item.info.Rs.Size()
+	spaceFreed = item._diskSize()
 
 	// This should not be possible.  We get here only if cache data is not dirty.
 	if item._remove("cache out of space, item is clean") {
@@ -1122,8 +1281,10 @@ func (item *Item) FindMissing(r ranges.Range) (outr ranges.Range) {
 
 // ensure the range from offset, size is present in the backing file
 //
+// If stream is not nil it is used to decide how much to read ahead.
+//
 // call with the item lock held
-func (item *Item) _ensure(offset, size int64) (err error) {
+func (item *Item) _ensure(offset, size int64, stream *downloaders.Stream) (err error) {
 	// defer log.Trace(item.name, "offset=%d, size=%d", offset, size)("err=%v", &err)
 	if offset+size > item.info.Size {
 		size = item.info.Size - offset
@@ -1143,7 +1304,7 @@ func (item *Item) _ensure(offset, size int64) (err error) {
 			return nil
 		}
 		// Otherwise start the downloader for the future if required
-		return item.downloaders.EnsureDownloader(r)
+		return item.downloaders.EnsureDownloader(r, stream)
 	}
 	if item.downloaders == nil {
 		// Downloaders can be nil here if the file has been
@@ -1162,7 +1323,7 @@ func (item *Item) _ensure(offset, size int64) (err error) {
 		}
 		item.downloaders = downloaders.New(item, item.c.opt, item.name, item.o)
 	}
-	return item.downloaders.Download(r)
+	return item.downloaders.Download(r, stream)
 }
 
 // _written marks the (offset, size) as present in the backing file
@@ -1220,6 +1381,59 @@ func (item *Item) setModTime(modTime time.Time) {
 	item.mu.Unlock()
 }
 
+// SetMetadata sets the metadata to be written to the remote object
+// when the item is next uploaded, replacing any metadata of the cache
+// file.
+func (item *Item) SetMetadata(metadata fs.Metadata) {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	item.info.Metadata = copyMetadata(metadata)
+	_, err := item.c.createItemDir(item.name) // No locking in Cache
+	if err == nil {
+		err = item._save()
+	}
+	if err != nil {
+		fs.Errorf(item.name, "vfs cache: SetMetadata: failed to save item info: %v", err)
+	}
+}
+
+// GetMetadata returns the metadata to be written to the remote object
+// or nil if none has been set
+func (item *Item) GetMetadata() fs.Metadata {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	return copyMetadata(item.info.Metadata)
+}
+
+// copyMetadata returns a copy of metadata, nil if it is nil
+func copyMetadata(metadata fs.Metadata) fs.Metadata {
+	if metadata == nil {
+		return nil
+	}
+	newMetadata := make(fs.Metadata, len(metadata))
+	for k, v := range metadata {
+		newMetadata[k] = v
+	}
+	return newMetadata
+}
+
+// metadataObject overrides the metadata of the cache file with the
+// metadata set on the item when it is uploaded
+type metadataObject struct {
+	fs.Object
+	metadata fs.Metadata
+}
+
+// Metadata returns the metadata to upload with the object
+//
+// The times are set from the cache file as any in the metadata will
+// be out of date if the file has been modified.
+func (o *metadataObject) Metadata(ctx context.Context) (fs.Metadata, error) {
+	delete(o.metadata, "atime")
+	o.metadata["mtime"] = o.Object.ModTime(ctx).Format(time.RFC3339Nano)
+	return o.metadata, nil
+}
+
 // GetModTime of the cache file
 func (item *Item) GetModTime() (modTime time.Time, err error) {
 	// defer log.Trace(item.name, "modTime=%v", modTime)("")
@@ -1232,13 +1446,42 @@ func (item *Item) GetModTime() (modTime time.Time, err error) {
 	return modTime, nil
 }
 
+// NewStream returns a Stream to track the access pattern of a reader
+// of the item, normally a file handle, for use with ReadAtStream.
+func (item *Item) NewStream() *downloaders.Stream {
+	return downloaders.NewStream(item.c.opt)
+}
+
 // ReadAt bytes from the file at off
 func (item *Item) ReadAt(b []byte, off int64) (n int, err error) {
+	return item.ReadAtStream(b, off, nil)
+}
+
+// ReadAtStream reads bytes from the file at off recording the access
+// in stream which is used to adapt the read ahead. stream may be nil
+// in which case the fixed --vfs-read-ahead is used.
+func (item *Item) ReadAtStream(b []byte, off int64, stream *downloaders.Stream) (n int, err error) {
+	// Find out whether the read is a cache hit before reading as
+	// reading downloads any missing data
+	item.mu.Lock()
+	r := ranges.Range{Pos: off, Size: int64(len(b))}
+	r.Clip(item.info.Size)
+	present := item.info.Rs.Present(r)
+	item.mu.Unlock()
+
+	// Record the access before reading as the read ahead for this
+	// read is chosen from it. This is only done once however many
+	// times the read is retried.
+	var access downloaders.Access
+	if stream != nil && off >= 0 {
+		access = stream.Record(r)
+	}
+
 	n = 0
 	var expBackOff int
 	for retries := 0; retries < fs.GetConfig(context.TODO()).LowLevelRetries; retries++ {
 		item.preAccess()
-		n, err = item.readAt(b, off)
+		n, err = item.readAt(b, off, stream)
 		item.postAccess()
 		if err == nil || err == io.EOF {
 			break
@@ -1257,11 +1500,16 @@ func (item *Item) ReadAt(b []byte, off int64) (n int, err error) {
 		fs.Errorf(item.name, "vfs cache: failed to _ensure cache after retries %v", err)
 	}
 
+	// Count the read once however many times it was retried
+	if off >= 0 && (err == nil || err == io.EOF) {
+		item.c.countRead(present, stream, access)
+	}
+
 	return n, err
 }
 
 // ReadAt bytes from the file at off
-func (item *Item) readAt(b []byte, off int64) (n int, err error) {
+func (item *Item) readAt(b []byte, off int64, stream *downloaders.Stream) (n int, err error) {
 	item.mu.Lock()
 	if item.fd == nil {
 		item.mu.Unlock()
@@ -1273,7 +1521,7 @@ func (item *Item) readAt(b []byte, off int64) (n int, err error) {
 	}
 	defer item.mu.Unlock()
 
-	err = item._ensure(off, int64(len(b)))
+	err = item._ensure(off, int64(len(b)), stream)
 	if err != nil {
 		return 0, err
 	}
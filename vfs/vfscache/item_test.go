@@ -15,6 +15,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fstest"
 	"github.com/rclone/rclone/lib/random"
+	"github.com/rclone/rclone/lib/ranges"
 	"github.com/rclone/rclone/lib/readers"
 	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
@@ -100,21 +101,29 @@ func TestItemDirty(t *testing.T) {
 	require.NoError(t, item.Open(nil))
 
 	assert.Equal(t, false, item.IsDirty())
+	assert.Equal(t, int64(0), c.Stats()["dirtyFiles"])
 
 	n, err := item.WriteAt([]byte("hello"), 0)
 	require.NoError(t, err)
 	assert.Equal(t, 5, n)
 
 	assert.Equal(t, true, item.IsDirty())
+	assert.Equal(t, int64(1), c.Stats()["dirtyFiles"])
 
 	require.NoError(t, item.Close(nil))
 
 	// Sync writeback so expect clean here
 	assert.Equal(t, false, item.IsDirty())
+	assert.Equal(t, int64(0), c.Stats()["dirtyFiles"])
 
 	item.Dirty()
 
 	assert.Equal(t, true, item.IsDirty())
+	assert.Equal(t, int64(1), c.Stats()["dirtyFiles"])
+
+	// Removing the item cleans it
+	c.Remove("potato")
+	assert.Equal(t, int64(0), c.Stats()["dirtyFiles"])
 	checkObject(t, r, "potato", "hello")
 }
 
@@ -211,6 +220,83 @@ func TestItemReadAt(t *testing.T) {
 	require.NoError(t, item.Close(nil))
 }
 
+func TestItemReadAtStreamCounts(t *testing.T) {
+	r, c := newItemTestCache(t)
+
+	_, obj, item := newFile(t, r, c, "existing")
+	buf := make([]byte, 10)
+	stream := item.NewStream()
+	reads := func() int64 {
+		return c.readHits.Load() + c.readMisses.Load()
+	}
+	accesses := func() int64 {
+		return c.readSequential.Load() + c.readRandom.Load()
+	}
+
+	// Failed reads aren't counted
+	_, err := item.ReadAtStream(buf, 10, stream)
+	require.Error(t, err)
+	assert.Equal(t, int64(0), reads())
+	assert.Equal(t, int64(0), accesses())
+
+	require.NoError(t, item.Open(obj))
+
+	// Each read is counted once
+	for i, off := range []int64{0, 10, 20, 95} {
+		_, err = item.ReadAtStream(buf, off, stream)
+		if err != io.EOF {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(i+1), reads())
+		assert.Equal(t, int64(i+1), accesses())
+	}
+	assert.Equal(t, int64(1), c.readMisses.Load())
+
+	// Reads before the start aren't counted
+	_, err = item.ReadAtStream(buf, -1, stream)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, int64(4), reads())
+
+	require.NoError(t, item.Close(nil))
+}
+
+func TestItemReadAtStreamSeek(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CachePollInterval = 0
+	opt.WriteBack = 0
+	opt.ReadAhead = 0
+	opt.ReadAheadMax = 4 * fs.Mebi
+	r, c := newTestCacheOpt(t, opt)
+
+	const size = 16 * 1024 * 1024
+	_, obj, item := newFileLength(t, r, c, "existing", size)
+	require.NoError(t, item.Open(obj))
+	defer func() {
+		require.NoError(t, item.Close(nil))
+	}()
+	stream := item.NewStream()
+
+	// A sequential run grows the read ahead to the maximum
+	buf := make([]byte, 128*1024)
+	for off := int64(0); off < 5*int64(len(buf)); off += int64(len(buf)) {
+		_, err := item.ReadAtStream(buf, off, stream)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(opt.ReadAheadMax), stream.ReadAhead())
+
+	// A seek downloads the data read and only the minimum read
+	// ahead after it, not the grown read ahead
+	const seek = 10 * 1024 * 1024
+	_, err := item.ReadAtStream(buf[:1], seek, stream)
+	require.NoError(t, err)
+	assert.Equal(t, int64(opt.ReadAhead), stream.ReadAhead())
+	time.Sleep(500 * time.Millisecond)
+	item.mu.Lock()
+	grown := item.info.Rs.Present(ranges.Range{Pos: seek + 3*1024*1024, Size: 1})
+	item.mu.Unlock()
+	assert.False(t, grown, "downloaded the grown read ahead after the seek")
+}
+
 func TestItemWriteAtNew(t *testing.T) {
 	r, c := newItemTestCache(t)
 	item, _ := c.get("potato")
@@ -6,6 +6,7 @@ import (
 	"container/heap"
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,6 +19,12 @@ const (
 	maxUploadDelay = 5 * time.Minute // max delay between upload attempts
 )
 
+// Errors returned by the queue manipulation methods
+var (
+	ErrorIDNotFound = errors.New("id not found in queue")
+	ErrorUploading  = errors.New("item is being uploaded")
+)
+
 // PutFn is the interface that item provides to store the data
 type PutFn func(context.Context) error
 
@@ -62,6 +69,7 @@ func New(ctx context.Context, opt *vfscommon.Options) *WriteBack {
 // writeBack.mu must be held to manipulate this
 type writeBackItem struct {
 	name      string             // name of the item so we don't have to read it from item
+	size      int64              // size of the item so we don't have to read it from item
 	id        Handle             // id of the item
 	index     int                // index into the priority queue for update
 	expiry    time.Time          // When this expires we will write it back
@@ -72,6 +80,8 @@ type writeBackItem struct {
 	putFn     PutFn              // To write the object data
 	tries     int                // number of times we have tried to upload
 	delay     time.Duration      // delay between upload attempts
+	err       error              // error from the last upload attempt if any
+	failed    bool               // set if we have given up retrying the upload
 }
 
 // A writeBackItems implements a priority queue by implementing
@@ -135,10 +145,11 @@ func (wb *WriteBack) _newExpiry() time.Time {
 // make a new writeBackItem
 //
 // call with the lock held
-func (wb *WriteBack) _newItem(id Handle, name string) *writeBackItem {
+func (wb *WriteBack) _newItem(id Handle, name string, size int64) *writeBackItem {
 	wb.SetID(&id)
 	wbItem := &writeBackItem{
 		name:   name,
+		size:   size,
 		expiry: wb._newExpiry(),
 		delay:  wb.opt.WriteBack,
 		id:     id,
@@ -256,21 +267,27 @@ func (wb *WriteBack) SetID(pid *Handle) {
 //
 // If modified is false then it it doesn't cancel a pending upload if
 // there is one as there is no need.
-func (wb *WriteBack) Add(id Handle, name string, modified bool, putFn PutFn) Handle {
+func (wb *WriteBack) Add(id Handle, name string, size int64, modified bool, putFn PutFn) Handle {
 	wb.mu.Lock()
 	defer wb.mu.Unlock()
 
 	wbItem, ok := wb.lookup[id]
 	if !ok {
-		wbItem = wb._newItem(id, name)
+		wbItem = wb._newItem(id, name, size)
 	} else {
 		if wbItem.uploading && modified {
 			// We are uploading already so cancel the upload
 			wb._cancelUpload(wbItem)
 		}
+		if wbItem.failed {
+			// Give the upload a fresh set of retries
+			wb._resetTries(wbItem)
+			wb._pushItem(wbItem)
+		}
 		// Kick the timer on
 		wb.items._update(wbItem, wb._newExpiry())
 	}
+	wbItem.size = size
 	wbItem.putFn = putFn
 	wb._resetTimer()
 	return wbItem.id
@@ -360,7 +377,6 @@ func (wb *WriteBack) upload(ctx context.Context, wbItem *writeBackItem) {
 	wb.uploads--
 
 	if err != nil {
-		// FIXME should this have a max number of transfer attempts?
 		wbItem.delay *= 2
 		if wbItem.delay > maxUploadDelay {
 			wbItem.delay = maxUploadDelay
@@ -370,11 +386,21 @@ func (wb *WriteBack) upload(ctx context.Context, wbItem *writeBackItem) {
 			// Upload was cancelled so reset timer
 			wbItem.delay = wb.opt.WriteBack
 		} else {
-			fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, will retry in %v: %v", wbItem.tries, wbItem.delay, err)
+			wbItem.err = err
+			if wb.opt.WriteBackRetries > 0 && wbItem.tries >= wb.opt.WriteBackRetries {
+				wbItem.failed = true
+			}
+			if wbItem.failed {
+				fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, giving up: %v", wbItem.tries, err)
+			} else {
+				fs.Errorf(wbItem.name, "vfs cache: failed to upload try #%d, will retry in %v: %v", wbItem.tries, wbItem.delay, err)
+			}
+		}
+		if !wbItem.failed {
+			// push the item back on the queue for retry
+			wb._pushItem(wbItem)
+			wb.items._update(wbItem, time.Now().Add(wbItem.delay))
 		}
-		// push the item back on the queue for retry
-		wb._pushItem(wbItem)
-		wb.items._update(wbItem, time.Now().Add(wbItem.delay))
 	} else {
 		fs.Infof(wbItem.name, "vfs cache: upload succeeded try #%d", wbItem.tries)
 		// show that we are done with the item
@@ -457,9 +483,154 @@ func (wb *WriteBack) processItems(ctx context.Context) {
 	}
 }
 
-// Stats return the number of uploads in progress and queued
-func (wb *WriteBack) Stats() (uploadsInProgress, uploadsQueued int) {
+// Stats return the number of uploads in progress, queued and failed
+func (wb *WriteBack) Stats() (uploadsInProgress, uploadsQueued, uploadsFailed int) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	for _, wbItem := range wb.lookup {
+		if wbItem.failed {
+			uploadsFailed++
+		}
+	}
+	return wb.uploads, len(wb.items), uploadsFailed
+}
+
+// QueueInfo is information about an item queued for upload, returned
+// by Queue
+type QueueInfo struct {
+	Name      string  `json:"name"`      // name (full path) of the file
+	ID        Handle  `json:"id"`        // id of queue item
+	Size      int64   `json:"size"`      // integer size of the file in bytes
+	Expiry    float64 `json:"expiry"`    // seconds from now which the file is eligible for transfer, oldest goes first
+	Tries     int     `json:"tries"`     // number of times we have tried to upload
+	Delay     float64 `json:"delay"`     // delay between upload attempts (s)
+	Uploading bool    `json:"uploading"` // true if item is being uploaded
+	Failed    bool    `json:"failed"`    // true if we have given up retrying the upload
+	Error     string  `json:"error"`     // error from the last upload attempt or "" if none
+}
+
+// return a QueueInfo for the item
+//
+// call with lock held
+func (wbItem *writeBackItem) _info() QueueInfo {
+	info := QueueInfo{
+		Name:      wbItem.name,
+		ID:        wbItem.id,
+		Size:      wbItem.size,
+		Expiry:    time.Until(wbItem.expiry).Seconds(),
+		Tries:     wbItem.tries,
+		Delay:     wbItem.delay.Seconds(),
+		Uploading: wbItem.uploading,
+		Failed:    wbItem.failed,
+	}
+	if wbItem.err != nil {
+		info.Error = wbItem.err.Error()
+	}
+	return info
+}
+
+// Queue returns info about the items in the writeback queue, including
+// those being uploaded and those which have failed, sorted by expiry
+func (wb *WriteBack) Queue() []QueueInfo {
 	wb.mu.Lock()
 	defer wb.mu.Unlock()
-	return wb.uploads, len(wb.items)
+
+	items := make([]QueueInfo, 0, len(wb.lookup))
+	for _, wbItem := range wb.lookup {
+		items = append(items, wbItem._info())
+	}
+
+	// Sort by Uploading first then Expiry
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Uploading != items[j].Uploading {
+			return items[i].Uploading
+		}
+		if items[i].Expiry != items[j].Expiry {
+			return items[i].Expiry < items[j].Expiry
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return items
+}
+
+// Get returns info about the item with id in the writeback queue
+//
+// If the item isn't found then it will return ErrorIDNotFound
+func (wb *WriteBack) Get(id Handle) (info QueueInfo, err error) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	wbItem, ok := wb.lookup[id]
+	if !ok {
+		return info, ErrorIDNotFound
+	}
+	return wbItem._info(), nil
+}
+
+// SetExpiry sets the expiry time for an item in the writeback queue,
+// so it can be uploaded sooner or later than it otherwise would be.
+//
+// id should be as returned from the Queue call
+//
+// If the item isn't found then it will return ErrorIDNotFound. If
+// the item is being uploaded it will return ErrorUploading. Items
+// whose upload has failed are put back in the queue.
+func (wb *WriteBack) SetExpiry(id Handle, expiry time.Time) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	wbItem, ok := wb.lookup[id]
+	if !ok {
+		return ErrorIDNotFound
+	}
+	if wbItem.uploading {
+		return ErrorUploading
+	}
+	if wbItem.failed {
+		wb._resetTries(wbItem)
+	}
+
+	// Update the expiry with the user requested value
+	wb._pushItem(wbItem)
+	wb.items._update(wbItem, expiry)
+	wb._resetTimer()
+	return nil
+}
+
+// Retry puts an item back in the queue for upload now with a fresh
+// set of retries. This is normally used on items whose upload has
+// failed.
+//
+// id should be as returned from the Queue call
+//
+// If the item isn't found then it will return ErrorIDNotFound. If
+// the item is being uploaded it will return ErrorUploading.
+func (wb *WriteBack) Retry(id Handle) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	wbItem, ok := wb.lookup[id]
+	if !ok {
+		return ErrorIDNotFound
+	}
+	if wbItem.uploading {
+		return ErrorUploading
+	}
+	fs.Infof(wbItem.name, "vfs cache: retrying upload")
+	wb._resetTries(wbItem)
+	wb._pushItem(wbItem)
+	wb.items._update(wbItem, time.Now())
+	wb._resetTimer()
+	return nil
+}
+
+// reset the retry state of the item
+//
+// call with lock held
+func (wb *WriteBack) _resetTries(wbItem *writeBackItem) {
+	wbItem.tries = 0
+	wbItem.delay = wb.opt.WriteBack
+	wbItem.err = nil
+	wbItem.failed = false
 }
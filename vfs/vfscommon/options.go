@@ -22,18 +22,22 @@ type Options struct {
 	GID                uint32
 	DirPerms           os.FileMode
 	FilePerms          os.FileMode
+	MetadataPerms      bool          // if set read and write file mode and ownership in the object metadata
 	ChunkSize          fs.SizeSuffix // if > 0 read files in chunks
 	ChunkSizeLimit     fs.SizeSuffix // if > ChunkSize double the chunk size after each chunk until reached
 	CacheMode          CacheMode
 	CacheMaxAge        time.Duration
 	CacheMaxSize       fs.SizeSuffix
 	CacheMinFreeSpace  fs.SizeSuffix
+	CacheChunkSize     fs.SizeSuffix // if > 0 store cache files as chunks of this size
 	CachePollInterval  time.Duration
 	CaseInsensitive    bool
 	WriteWait          time.Duration // time to wait for in-sequence write
 	ReadWait           time.Duration // time to wait for in-sequence read
 	WriteBack          time.Duration // time to wait before writing back dirty files
+	WriteBackRetries   int           // number of times to try an upload before giving up, 0 for unlimited
 	ReadAhead          fs.SizeSuffix // bytes to read ahead in cache mode "full"
+	ReadAheadMax       fs.SizeSuffix // if > ReadAhead grow the read ahead up to this for sequential reads
 	UsedIsSize         bool          // if true, use the `rclone size` algorithm for Used size
 	FastFingerprint    bool          // if set use fast fingerprints
 	DiskSpaceTotalSize fs.SizeSuffix
@@ -53,6 +57,7 @@ var DefaultOpt = Options{
 	GID:                ^uint32(0), // overridden for non windows in mount_unix.go
 	DirPerms:           os.FileMode(0777),
 	FilePerms:          os.FileMode(0666),
+	MetadataPerms:      false,
 	CacheMode:          CacheModeOff,
 	CacheMaxAge:        3600 * time.Second,
 	CachePollInterval:  60 * time.Second,
@@ -60,11 +65,14 @@ var DefaultOpt = Options{
 	ChunkSizeLimit:     -1,
 	CacheMaxSize:       -1,
 	CacheMinFreeSpace:  -1,
+	CacheChunkSize:     0,
 	CaseInsensitive:    runtime.GOOS == "windows" || runtime.GOOS == "darwin", // default to true on Windows and Mac, false otherwise
 	WriteWait:          1000 * time.Millisecond,
 	ReadWait:           20 * time.Millisecond,
 	WriteBack:          5 * time.Second,
+	WriteBackRetries:   0,
 	ReadAhead:          0 * fs.Mebi,
+	ReadAheadMax:       0,
 	UsedIsSize:         false,
 	DiskSpaceTotalSize: -1,
 }